@@ -27,6 +27,7 @@ import (
 
 	"github.com/BurntSushi/toml"
 	"github.com/fluidkeys/fluidkeys/fingerprint"
+	"github.com/fluidkeys/fluidkeys/gpgwrapper"
 	"github.com/natefinch/atomic"
 )
 
@@ -173,7 +174,7 @@ func (c *Config) getConfig(fp fingerprint.Fingerprint) key {
 	keyConfigs := make(map[fingerprint.Fingerprint]key)
 
 	for configFingerprint, keyConfig := range c.parsedConfig.PgpKeys {
-		parsedFingerprint, err := fingerprint.Parse(configFingerprint)
+		parsedFingerprint, err := parseFingerprint(configFingerprint)
 		if err != nil {
 			log.Panicf("got invalid openpgp fingerprint: '%s'", configFingerprint)
 		}
@@ -188,6 +189,16 @@ func (c *Config) getConfig(fp fingerprint.Fingerprint) key {
 	}
 }
 
+// parseFingerprint parses a fingerprint typed into the config file by hand,
+// rejecting key IDs in favour of full fingerprints.
+func parseFingerprint(configFingerprint string) (fingerprint.Fingerprint, error) {
+	normalized, err := gpgwrapper.NormalizeFingerprint(configFingerprint)
+	if err != nil {
+		return fingerprint.Fingerprint{}, err
+	}
+	return fingerprint.Parse(normalized)
+}
+
 func parse(r io.Reader) (*Config, error) {
 	var parsedConfig tomlConfig
 	metadata, err := toml.DecodeReader(r, &parsedConfig)
@@ -198,9 +209,9 @@ func parse(r io.Reader) (*Config, error) {
 
 	// validate fingerprints
 	for configFingerprint, _ := range parsedConfig.PgpKeys {
-		_, err := fingerprint.Parse(configFingerprint)
+		_, err := parseFingerprint(configFingerprint)
 		if err != nil {
-			return nil, fmt.Errorf("got invalid openpgp fingerprint: '%s'", configFingerprint)
+			return nil, fmt.Errorf("got invalid openpgp fingerprint: %v", err)
 		}
 	}
 
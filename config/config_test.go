@@ -160,6 +160,16 @@ func TestParse(t *testing.T) {
 		assert.ErrorIsNotNil(t, err)
 	})
 
+	t.Run("return an error suggesting the full fingerprint for a key ID", func(t *testing.T) {
+		_, err := parse(strings.NewReader(`
+		[pgpkeys]
+		[pgpkeys.AAAA1111AAAA1111]
+		store_password = false
+		`))
+		assert.ErrorIsNotNil(t, err)
+		assert.Equal(t, true, strings.Contains(err.Error(), "full 40 character fingerprint"))
+	})
+
 	t.Run("return an error if an unrecognised config variable is encountered", func(t *testing.T) {
 		_, err := parse(strings.NewReader(`
 		[pgpkeys]
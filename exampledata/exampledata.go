@@ -239,3 +239,629 @@ TBAU6yBBSeV0Z8rL
 `
 
 var ExampleFingerprint4 = fingerprint.MustParse("BB3C 44BF 188D 56E6 35F4  A092 F73D 2F05 33D7 F9D6")
+
+// ExamplePublicKey5 designates key 4 (ExampleFingerprint4) as a revoker in
+// a direct key signature.
+var ExamplePublicKey5 = `
+-----BEGIN PGP PUBLIC KEY BLOCK-----
+Comment: gpg --export-options export-minimal --armor --export
+Comment: pub   rsa2048 2026-10-16 [SC] [expires: 2050-01-01]
+Comment:       6F3D 9EA2 6411 B777 EF3E  A76E E162 F6D1 7FEA BECC
+Comment: rev:  BB3C 44BF 188D 56E6 35F4  A092 F73D 2F05 33D7 F9D6
+
+mQENBGrR86QBCADXWX7HG48T84BifYTgyKMKHJfr8x54OgflKCb1FNa0sUFOoNwd
+VFOQ82XdKufPWC3NAzxDd3wqZlB3D8/nMoMKSacLx/GrTDelpQmAEkAFunpGBSt/
+ewUGcrrNc5Sby7TOJJ5ht66TwHXyOm3a9YiRO4/j43eEClmbu4crScF7hocwIu2d
+h3aqV3EAokM8KjEB9mNTaaKv8Ckm/Uve3s/pkjCvgRKoQsyjrYMkl0UhMYfvzdKL
+XhcZ4iE8iKtsnmrq1AUsQlJn2eyV9wysGcutCidPx/ibE6cBsJSgF+VPs7hmhdoq
+7CSXME7yMDtvgW1M37kvYahyeAuwXd2YJB4xABEBAAGJAU4EHwEKADgWIQRvPZ6i
+ZBG3d+8+p27hYvbRf+q+zAUCatHzqBcMgAG7PES/GI1W5jX0oJL3PS8FM9f51gIH
+AAAKCRDhYvbRf+q+zKd8B/0c9piJLQwigHqO6YKmohFRyq9k0Uh3OGJ2eFHElPTb
+XLywTnD/vguvU0YxV60zgSXOANlDE1SnR5jlrNjJu15o7QzHZA/Lq+UJkfULy5OX
+0Q281nrOrxzCC9Hf6JnLNSSCRBxnJuYtk/Wv8Jw69qOvhNhhrx6jIj/mQYdBw/ER
+YqYqzIhLUNTjDaxlOpU5L73oOT3y55It5onIzScu91ZxLuH2ESAQAmlZ6/RP2W1b
+iM+oI7Ciy1fmAc0ickr4/7nwwVCXBKmnmFZm+75q3BAZ3HLkLcJyiL8WyMD4KHzj
+Wr+clMRBnoG/KF8KGheNMLJIeiK+7etkgVdAQRAHWzmstChUZXN0IFJldm9rZXIg
+Rml4dHVyZSA8dGVzdDVAZXhhbXBsZS5jb20+iQFUBBMBCgA+FiEEbz2eomQRt3fv
+Pqdu4WL20X/qvswFAmrR86QCGwMFCSupKxwFCwkIBwIGFQoJCAsCBBYCAwECHgEC
+F4AACgkQ4WL20X/qvsx6HAf9Ggt9hTREJAPKLTNHsWIvvjUFCHcYC20Yt7XdEq5u
+GQteVJkzH7B0qmTXKtRTYe5Lkx+DvATiE07gZhz/lGJ5PqUcNLwSvmJvz16cGbEf
+2uR+1kvPmgURWCm7SpV5irRpUhRlAFmm2tFNiEohkJ9e78T4Z5a/Ztz5/ORP/pqU
+Px4P4AphPt2bK+XSPOK3l2BW7DPD/8i0ExhzgFcYBzbmuzlZCjXhSPd660vGHpFR
+DgGQs/rtSX/gMhbzLT/r/RGXSFalZtzlaIZY95gFbewfcAPfpruJ8Wzr+UgOKI5q
+di6ZzSFwpoITQr5vsFoeoKDaiBBjWaDzB7t7J301wGQWqrkBDQRq0fOnAQgAu6OZ
+Ea9/Jhn97CQ8KBVbJ9VoW2DpfRVZ8LvyV6X23c8OAiLGh/nHQacAoljLRNGTR13c
+c3uopqNKYqsj5l6w+KX9mT8duvvi8VCVY+zh+meiGFZ0ojSl/Jm8so4vbuGkM92t
+Xs849+vKUabx0SMDMlOL8xdyH2oIUahY1nB9rtxk0XS1l9zvA//tc9Xe12FdlbZv
+DoLtWq3gEdpzRlzzXZMIC5zLaEbhUqM3eOmwchkdPbcIlFWohDBJOjrpeNipCyJs
+X7gHo2V+cJPTk9JExV5duh0ob4jr1XV31gGcrMZn5D2f3RIeLTZooYvhUa13jYTU
+n+cdo9i6em3aulQ0pQARAQABiQE8BBgBCgAmFiEEbz2eomQRt3fvPqdu4WL20X/q
+vswFAmrR86cCGwwFCSupKxkACgkQ4WL20X/qvsxcpwgAnNXmkVESsVQZRUBDMS9V
+ifT2qIlp9WK3tHGfXKjRQoJHY4r75fXdKUIKV1mOf72LYwmBEU6/3YNlq5tjduL4
+flN4BZx0x6SvKO1PiTAEJhAsVi8C0eaqCkV7tPMK4RwE3dJMAtBETdY7qbEKjoH6
+PAizx/pMB1J084VcM40XY/7Srqq6Go3S08uvFYtJ+dK3fdP939eCu98GJfS2wrpW
+csOsmiwbORw7ObgTU4LZxDrA+HkAkWSz6sVKGT2PZp5MxhhV7os8zOu1f7EFpFI2
+fzADDpd2B8Vc/UeDB2v725xt2r4nh4mBM4sItJM+JOyfYd1cBmL7WqvuIT+SER0P
+qw==
+=xfOW
+-----END PGP PUBLIC KEY BLOCK-----
+`
+
+var ExampleFingerprint5 = fingerprint.MustParse("6F3D 9EA2 6411 B777 EF3E  A76E E162 F6D1 7FEA BECC")
+
+// ExamplePublicKey6 is a signing-only key with a DSA primary key.
+var ExamplePublicKey6 = `
+-----BEGIN PGP PUBLIC KEY BLOCK-----
+Comment: pub   dsa2048 2018-11-01 [SC] [expires: 2030-01-01]
+Comment:       22F4 8882 017B C964 810E  460D 4A91 A020 600A 0526
+
+mQMuBFva6sARCACJHH16K7QwWANB+mgvLxQolhxNLbZHLaBYzmIEx/7MIB1BWVwN
+5b4AaCuyVve/yR6NH2c0Yno+B/POykANZQCM33uWQR73Js+ORtzno86N0B2f5fkd
+mwf4DZRfNcAPGBb6yLb4WoWj56InEF7F5Gpm9zw3OPNNE/Ng9ESmPrQEUohwJwkl
+eYBuds+cJ9ydv778bsZpM+niA/ZnjbUQ6dx1MSBLZtDIvylCU5v9vG6v8EwlOU2s
+KUUXcWrZ9pTT3HTBTUfDC1UNile04Jz7yK62T5LTiWnhkbrORl2uA0wg0tvyLcIJ
++x2Xiav8wTC9uI2f9TvnZswiALqAbK9BT4VnAQCgXV9bsLOqQ13FPRkn9E55tLEI
+7pGb07EwmZGv4SUQDwf/WluHNZ3q+ZFsAA43eEP976CnOl7uT4KWBTSAAaLBbmp9
+asGsXBGvtS5LUG04HRIdgaWkxO9v/liN+zz/C7sG4eD+xOUVO3m4I5Mu4CwnL+xU
+WvvUY83FyPtkTpr/pGJZhsDQaOW6U2RQwG8k1NfeI4sBmT4yjXWA2TeX66P0mUtM
+HQJjHcAJMfjN5KP+WDQ9OY6Z4e4hdbMuk9h7s/mMLD//0nbY6NwjYfr2GqalI7zG
+o3s6amb+MlZ39eYxNeQ34fP1IqNK6V1jb5FK7mwJbKmCLQgUJGUHYnjIwRhUOVjI
+KOJmm6jkfXJFrRjuu8wADgnyWBnSVEdb+DK3CTQoBgf+Og3URC3iFh0gOnbfIimE
+Av45SK5AWMy8zzAM8DFUNr33XCh6A0CvRuKJWjl8qK8Nqtnyk9SA+kU7s0YRB15Z
+G85JQHAglPXG/LLIF1+km5zfZ29jpvEv2GRswz6fVlXEhyH1yzPVbct/l4MXBfud
+nVlAynJKZUWj3bTYJ161P9J4joxmZgtH7eiEtAiPtdICikYOuzT0Rgcr7ws2fITp
+PKST8HevyqD7Jo4JCCE+03blZYvY5jo7X+wBQ0/nMozHJm1OccpKpO+ZegrF4s/K
+iL08iFmhcPBMKQnigaf+qhFiJnEmm1E0ED7+Us/XJddV3/4lMjxJrre06OlIAvL+
+wrQTPHRlc3Q2QGV4YW1wbGUuY29tPoiWBBMRCAA+FiEEIvSIggF7yWSBDkYNSpGg
+IGAKBSYFAlva6sACGwMFCRUBloAFCwkIBwIGFQoJCAsCBBYCAwECHgECF4AACgkQ
+SpGgIGAKBSb3qQD+OwKpENmpRU8pW4Ktso9Jop9u5gUnhZKvqzXwCj5uGO8A/2jP
+A78Nq7hgCK1tvEHqxynGU8bkl2hsSdEpt3zvcAKF
+=R1DV
+-----END PGP PUBLIC KEY BLOCK-----
+`
+
+var ExampleFingerprint6 = fingerprint.MustParse("22F4 8882 017B C964 810E  460D 4A91 A020 600A 0526")
+
+// ExamplePublicKey7 has a user ID self signature and subkey binding signature
+// made with SHA1.
+var ExamplePublicKey7 = `
+-----BEGIN PGP PUBLIC KEY BLOCK-----
+Comment: pub   rsa2048 2018-11-01 [SC] [expires: 2030-01-01]
+Comment:       B2BB BB68 9424 1F31 5403  C5CA 64C0 0B12 C14A 3BA5
+Comment: sub   rsa2048/0x061DDECA7C8BCFE2 2018-11-01 [E] [expires: 2030-01-01]
+
+mQENBFva6sABCADPP8+DIdmu40asW2eN2mzItOWcJe2sncqd2hZcUDo27K+8sR/G
+Hch3rtArPAPf9ekB2h2qL6CduoxUv38z+HyygXSWRk4Jucd4rbPv5A8RpFoRNynA
+zhLAZlrwLIbYIAQuE+f6zSyAcuwvdwIx/K7E0/JLup2n5PxIcwzSqF7iYwKTIuW2
+zciNhDw+k95kh7SnDEIYldGqrIsRvhjlOCsz/mx+GJKSRs3vKs+OKvd99PrILgKy
+q1aeNep5pcnUPPfGbiW+ZRNBVRjwW0w8+cdieULl7eSyoQJ+IzLp+Uu65dZnqZ9b
+RNomzKEYxcMM80CcubMvAY5z9cC/7YI+5BmhABEBAAG0Ezx0ZXN0N0BleGFtcGxl
+LmNvbT6JAVQEEwECAD4WIQSyu7tolCQfMVQDxcpkwAsSwUo7pQUCW9rqwAIbAwUJ
+FQGWgAULCQgHAgYVCgkICwIEFgIDAQIeAQIXgAAKCRBkwAsSwUo7pepqB/9b6nYT
+MGT9gxhFCTEQ25/bvgFiJy01AE9hoGCdkI5iEE6hupYN2fjL2O3OPuWxCEJS1Ua3
+7KpKLLA1iHELKzhjdR6XZyXcS3Gb8dFHO88OqZDK2KXdVQfnV85y7WezaUj5kHtK
+dx6nUhbj3HKZpvh3VWHxdd5INiZ8zvwDSURT30ghuzUVbpkYoe1mBsKJLeoAt+Py
+WykexTdwaBfy6A2thvvMOxrhSUFZeEsmE+FmqhllQF0T3J0kBFpKJ9FqIkqV7ZAf
+dcyhJnAZACGQg7P7K2nKgw+pGwnX7uxnwVSlFDcEL1KmiqkkOi4a4CjvPrC64f5A
+0jmO8kMKD26hZ59nuQENBFva6sABCADB+Qf9gI0eyLchhTeGE741foLNSofG02OV
+8lpeF5JyIzBNoTBFfVXbATagyLPIz51F2uYl7pr7beCtR8DinNebfKGSi/nBC0d4
+WgkgJIih3pMQDzgiLBPNJQTMm/tX03MLIBWxvfuXn5rbzcwpvOnCyqBAj9Rf0KYD
+0yHpFU3Y9QNEXkd5Wuw9hL37oAzFpEQ8vhvbRFjVmY8U79hGoiIf0lHq26acOdTO
+oTpVQwh2a9FVkwhH8WPIXbDnZ8e2Kicgiu9ksZS+hdbuQMv2CJE3yAUCBhHiORZi
+yk+ZFGe0Y8eLNmvVtWUV7oZWCN88K11e+ybjuymR0SHuAQW5bHJlABEBAAGJATwE
+GAECACYWIQSyu7tolCQfMVQDxcpkwAsSwUo7pQUCW9rqwAIbDAUJFQGWgAAKCRBk
+wAsSwUo7pTM+B/9Fqmz5gxp2yxaZAz7bEIkQFUjBd62rKOl6btawdF/lKT7znG92
+/Z9Jpmuavl/n4j3Zdzzd3q/CRdCbo0Jq3+9sijJ3MXkr5GiKzHIv+ZGtR2/0l7ng
+qo1xp9H2/2eSLYy7jOe8WW+KpLWPNzR/Jcp5slLnEbUDNixKuOozgsu0+YjVwEI5
+J7Av0qGB2+jKHfJkEf/kTAD71MbPC0mC2vJRUzWak5h39O7ugwnK2G71TG1tEDj9
+inDmwigp9sPPKQB/upo9oyncDk0HkKtqG6RJoI/AiTFLO2t+CRngB/N8sHOo1EQ8
+F9IIe8sd05M7X1z2oGN892QQyNzjCyX4iAFE
+=EA4E
+-----END PGP PUBLIC KEY BLOCK-----
+`
+
+var ExampleFingerprint7 = fingerprint.MustParse("B2BB BB68 9424 1F31 5403  C5CA 64C0 0B12 C14A 3BA5")
+
+// ExamplePublicKey8 has a certify-only primary key and an encryption-only
+// subkey, so it has nothing that can make signatures.
+var ExamplePublicKey8 = `
+-----BEGIN PGP PUBLIC KEY BLOCK-----
+Comment: pub   rsa2048 2018-11-01 [C] [expires: 2030-01-01]
+Comment:       47DF 3C9C 60E9 2705 881E  F600 DE23 7DB7 203A 5B53
+Comment: sub   rsa2048/0xE1894A3AF248F4D0 2018-11-01 [E] [expires: 2030-01-01]
+
+mQENBFva6sABCAC7wWwe2BWoen3ajRPaas0CwG1ChZfkULvNNP29rCiES4KccQm0
+GZMzIkTZlAp3YHupacXGW9r4/PPg/vYVVrgchriFpctUyZI5Bt+O9kNuQtHMtB0h
+Ksq5anR3Omwm/gOEMzcic+l03d1/b879t4YDzOhtTM8MhatNUxemcCUE3o08M/jv
+WEWao8f8jEjCk2ofJ6WeUD63+5LjOnPPT0zEVTImQYtDmuGhz+U60NHMEXKF9i5Z
+OVwTzEoX/Lh/uy2uHO+IMx2Vb5WLqXyFkJ6Ht6h/Q9AlZyUtKtHVlJV0+k526RmJ
+cvFUP4mQObrLS9++Rd8JfM+otzBkvUnIi5kNABEBAAG0Ezx0ZXN0OEBleGFtcGxl
+LmNvbT6JAVQEEwEKAD4WIQRH3zycYOknBYge9gDeI323IDpbUwUCW9rqwAIbAQUJ
+FQGWgAULCQgHAgYVCgkICwIEFgIDAQIeAQIXgAAKCRDeI323IDpbU7xUB/915FZC
+VKbLhrNj8JzNT4fSNoNeslpiwZlJ02d8Y0yDoujVpfUDUc5v2q8JuIwCkxOVLnll
+UqhQq8A9VY/uv8t+uNfmruddGzZBI2ZcK/V7tPe6EUx8iIxR9ObWwqkRQb7eDkuX
+aYe7Vhp44grFwgfuSfMFfEbc+PpvH1sM/ESgAE+3fL29EYYWFFwMhXqI9/fON+KG
+fTBd7cU75nosLSxL85pIDTeuHxyxfjWLZtUWkpKcUhlCe4iSZv4r3yxie72pyJJo
+iXgktUkG6y3o3DiwSuSO7G6oVcjEGDyWkz5sql719LQM27fPdQOCLqkFLpG24U3O
+ok/dRuQztMeCGDHkuQENBFva6sABCAC5Cpp6xrObXRav6Hg+OtacxMwrfKQshtDe
+OG1qf32R6c8relMQxWcSbgmV40FNSs9MWDNHTDYjC+ep9GbIOoB9mOzcrIrJYQtt
+pxmn7YqHCu6E5JJKu9XpVWKJtMo6B6FgOevd++hXDVxmSFlViDdwK/XJbaxFZHpV
+V5iV06KG/YHsjCk693kCx6kuq4ttmxR/8IM1+pMyxFr2CYAObBbmhqIydeayGJV9
+9ZPbHg/hs9PiPMcqLCiftjXQ+cmpEAKvpT/OY076HlFJEjfmJ2LOPfOC57jR1RXp
+UJNoimTmAG14D3mYHwobjnm2SVZQlWEVpySwNTPy+9y6M8LWokozABEBAAGJATwE
+GAEKACYWIQRH3zycYOknBYge9gDeI323IDpbUwUCW9rqwAIbDAUJFQGWgAAKCRDe
+I323IDpbUyE7B/9qXt11bbVf50Q5wwAEgnAt2GyIpGE0Qip76e79bChm1FkM8jNX
+7WypdVtKAUV2l7/ROM+0NXAUaYMMXVJl3NTfcwJQ/xJat0qd+dWabUrSZiFMbiQi
+1G0xIKERjgZ7E8qP9ZnXnESILRVRsiURy/dsWvLXQLWSiRBDXvOtLnVpIbngqCq2
+o8nsWYSS0OQMEJKxPF9IlSOLsCCI/RTgvJNEo/KJlUL5twZGHDf+OKQ1bHVnxWgb
+md7jp/vQnV4eE+G4bU/XetYUYbuBQQZat/rN6cel0r1N4rkNfVRxQqiKKoOBo5pW
+bJheKzkagpvhCTnGWM1nOdGlY9LTPPp7fnyW
+=JV44
+-----END PGP PUBLIC KEY BLOCK-----
+`
+
+var ExampleFingerprint8 = fingerprint.MustParse("47DF 3C9C 60E9 2705 881E  F600 DE23 7DB7 203A 5B53")
+
+// ExamplePublicKey9 has a certify-only primary key, a signing-only subkey
+// and a newer subkey for both signing and encryption.
+var ExamplePublicKey9 = `
+-----BEGIN PGP PUBLIC KEY BLOCK-----
+Comment: pub   rsa2048 2018-11-01 [C] [expires: 2030-01-01]
+Comment:       D98D 6F9A A844 0C51 6BF9  ED6F 6E3C C54C DED6 8E66
+Comment: sub   rsa2048/0xCF9B0BAF9CC1633B 2018-11-01 [S] [expires: 2030-01-01]
+Comment: sub   rsa2048/0xD976E89E7D3A2633 2018-11-02 [SE] [expires: 2030-01-01]
+
+mQENBFva6sABCADk644FACuN+t7Y4GLbocdj67iCY/CWE4hdNuAJq6nrwBUjpIqj
+cOx1gaZOlgswLLLFlwaS4+WpAtoi0Zp3WYaZ84jYe6sySQnvudMbhNBGIPbm/RrO
+0X+Jd2FuRNuPefdPGGBZQzaA0FIXxWBFXhfc73wPgPN0fpuL8u6hfINMfY/4nven
+pNG4Weg+a4KQqJbKB+SVTqV2e3dN6wLx34PHMTdnwpgzf9QVHrvlsEtvD66QTT+2
+7Nx2utqEv4So2Fwz7HOneveffFefoThxRVVEY0qe3b2q9J4An754s5idzr6mw4X6
+1rGXrRpKnhqI530G/qXfkC0DNdqqSwWLWHHRABEBAAG0Ezx0ZXN0OUBleGFtcGxl
+LmNvbT6JAVQEEwEKAD4WIQTZjW+aqEQMUWv57W9uPMVM3taOZgUCW9rqwAIbAQUJ
+FQGWgAULCQgHAgYVCgkICwIEFgIDAQIeAQIXgAAKCRBuPMVM3taOZvouB/wLTWtJ
+3uAHbIIgjYyosyo2gL1MGuf3o4fjbKe2l5T3gtNl+9kMG/QOHMSmxu8jhH/LLWB+
+QY1YypQcntTiLBBTRmxzSnR/o/u18l8bGexOtxGi2WVXChgRS/+Oprk8dPBxZBSC
+rm4GVhljkF2CMqAX07mahOKfW0dfSJ9MeXNWXCVanGGPkSiwGuLCLzMTcJBuBfxL
+6imMX5+0pFn/sK/xWT/aXduPXpaK7ltG394ahrvWgVbk+sLMn6wmLqJlDHprLlDJ
+wclhw9YSgbsXgsEpZ3E/NL+WBBpQK9A3gshTAPdpu8RYnxtLTSdM7IKgx/aRrVES
+Z3LnZnh1swe7RRfvuQENBFva6sABCACnKpd2CS53bbq5QP4uEht94DFd0mzr9UQh
+4YPrj3lC9W6a3sIpIWtnX+v2LHMDe8h6TbSI8eFgphFQuyebZUhRz1nWvkg0gtgX
+m+2/C+0/vJngeiX0jfI11/0efAHJqZ3CXhO1WKpLMrzdP7Ycow33HZJAtnNScI4o
+ZH1kQRzInxiYtkHnj+OSde0WpNi+81cVq0QMnuNoymWZAd7vkMezMdqbj+O8mUmx
+o1eqaQitNrHug2ob0kHYDE1TTjW9Und/2i7V4v5FP2hftnjqGUof6zCMDZVrc7dw
+v//z0/WnTRaIod1z8cTNZ6A6+bVIpLmqaPY65tc4iVA3OURiox7rABEBAAGJAnIE
+GAEKACYWIQTZjW+aqEQMUWv57W9uPMVM3taOZgUCW9rqwAIbAgUJFQGWgAFACRBu
+PMVM3taOZsB0IAQZAQoAHRYhBDCbAVbE15j3bAyY9M+bC6+cwWM7BQJb2urAAAoJ
+EM+bC6+cwWM7D8oIAIhuLQL76qK8rfbMjNi6LAH5hWKvEUaUfnr9nVS8WLOHNTzW
+fx8CKF880lygwrkCQeOkv+v1CbBwUdJ1/j2up1+A7QlidCczI6kp6vj4zSzOvE4o
+jbxPvPb7ujR1molEX5lajdBJwhL35+PqNir3+PkNVbv9j79MznmmpVqZcnybD3VU
+a4E8YnH/vABXlz8Mev8bsNXZ14fqD2T32qWkGLoQvatexBnuOqv238veRPRjVuct
+//F99gx6E5/a0uipRKSZFPWDxpkV7Qh70qTUanfL3Hzv7zJv6y/dK9W1o3gMCUf2
+HkEozdPtBo/W9jK8OSff0U2dBaT2mWMoc6eVUTEeVQgAj850mMT0jI/SPrzh9IWY
+Zkt3R42OlttZNe+qD2TEMQN9HqZkOB25QxQ3c/3NuN4UPDbatcDuyr/dnpr05Tfx
+7cXEIb8eWvJ2/Ee6I2D0/TAQJ026gL5EB9jMX+66ODJndLUiVGmvukugrcro8J/I
+F57/WnfRiYAHBFYkLusWZ+4sOccREylaOsrFYw7BsvOQuRAmrkaWrlu12gnlnCN2
+dEirTf4txsIhwAcRO5TSmjm7ZY75FKAGZjDCac3o7piJFXEl+oGrkldtNy1yMi8n
+g0RvES1s+zQNVkGGhlZenBjYpmo9/cPYFgz9btabX5f9gUG3CXxZYU5kSfa8Nxyr
+k7kBDQRb3DxAAQgA53uD94XxSfOMi89ZJ/o1rQB8+4JZxuRVLbBq7SQRq7ZCVY1o
+ENFQgylT5q+89vumbIY09KXmmcd8T08ZmFGLVJdMeqArFkE8AnPPAFMA36f63dnc
+SCA2zvKKYfEE0KYvkQlOnBpVdfRbt6glj9+im+3ZI9BqgKVwznVy91Edjs2rt9iA
+STrvBsOAxD3x1bkIcEjGbpfrSqmwAsmU/BSOBMUYW9mL0kgpYwU2/PmbpIJ775C4
+BqfnQPVpA/MmBwT0gS+ngDaODCkWSffJ7qq+kEObTsx9l8wcdHAdCOYXiiPBcjis
+I0beC65OlauGZDuokHKqU4o67EiD8gJv6ex42QARAQABiQJyBBgBCgAmFiEE2Y1v
+mqhEDFFr+e1vbjzFTN7WjmYFAlvcPEACGw4FCRUARQABQAkQbjzFTN7WjmbAdCAE
+GQEKAB0WIQSAqCuExjg0v7MabmXZduiefTomMwUCW9w8QAAKCRDZduiefTomM1zx
+B/9/Qzy1nGzMwPkA8K0FfE5CAg2zZt+wmfEsCAWvp0SRaTzhWKiyquji//vdpdYF
+DrnM91A5lf649U7qLdE0ejvocuvkbZ1j+lkpReeUr7KhrIzQhDp2hQ3EXDPGKxCY
+LMO+9cbFFHR3YFeFrsXI2RwmXBP8WtiYWJg4YBPMQZDkTlCUIk4GGTi7J00EPLKH
+pyQMqUd6IHysDIijdU+645qePtxXicbT3aU/Sw4ArPv+3kyPCuAjy0zA63ctw0+B
+wgb0uRzQYClCk4VV3bW3k96tvYqyi9VYYRqdSEGVBiaLdP+dMhJHrd2jVNW0dKTj
+aJ+9SLgS2AKr2s/qtRKiz/5sg4cH/1IyxdkRdzNR8pNDEFPHPXmIYMcmopdklv+7
+9QcorCJk5o7sF/LGvw7oGB3/jCtVaJotaAEmhXTT0QLHF+9bsarwUoCNGTX6dczs
+uoH9gGCTGfRGO6vewI8GI6/05XM3O5ikGGC1E3NXE9aeCnfr/4cxrFQTGsHr0Xss
+XFFrgjzJIb+fr2J+uaUwu9y1sFk05Q3tLY+ZpZMVKnhlhzILPT8gzCtMpuIAe7rL
+GKCSMG23hjEB4acJH83y+n88PmYiInc1XCqITLIMAxDmmttygPvxekk4/Djci6V6
+P5QJDz+1ArdbI0tTSxsT1DKWvBpTwO5+9oU9TfRvlmpLDSnaQxo=
+=n9MV
+-----END PGP PUBLIC KEY BLOCK-----
+`
+
+var ExampleFingerprint9 = fingerprint.MustParse("D98D 6F9A A844 0C51 6BF9  ED6F 6E3C C54C DED6 8E66")
+
+// ExamplePublicKey10 has an encryption subkey and a newer encryption subkey
+// which has been revoked.
+var ExamplePublicKey10 = `
+-----BEGIN PGP PUBLIC KEY BLOCK-----
+Comment: pub   rsa2048 2018-11-01 [SC] [expires: 2030-01-01]
+Comment:       9644 C494 E233 2996 15C4  911A 91AB 75A0 5AA9 245C
+Comment: sub   rsa2048/0x0A5B7B748CBF7498 2018-11-01 [E] [expires: 2030-01-01]
+Comment: sub   rsa2048/0xF291DE5ADD97892E 2018-11-02 [E] [revoked: 2018-11-03]
+
+mQENBFva6sABCADQnflLS0U3hrBDjel5xUdh4DZ3ELbU4YHXU791ZrreowPuEnR7
+FOJabh/kbV8vcjA8GfFiET3p8n1wFlafLKyQwYVHjyLzgyDH4H4+55isUxJ8tW9m
+CZJ9C/F6AEGwEPP/Za3O18gatskDFvOY+/wzr2haJnEpbjAbkf1LrpG6iI082sIy
+6HYIFub9uG1sizlDG5TDISUIkR+aseHf4yTrWUBr/mjeTRmxyw2K2kLzS5FPgGN2
+xhu+VxjYzxAngCf5FxdI971Oqeyy/uySmUbkrub1NV6I5EdbnOPbPG9nPJtM7O9P
+T+Q0fQApBe6pQafUGoZ6/LYFRayrkOny3cnzABEBAAG0FDx0ZXN0MTBAZXhhbXBs
+ZS5jb20+iQFUBBMBCgA+FiEElkTElOIzKZYVxJEakat1oFqpJFwFAlva6sACGwMF
+CRUBloAFCwkIBwIGFQoJCAsCBBYCAwECHgECF4AACgkQkat1oFqpJFyHPAgAxjnr
+HXr+d/7IcZw6AeO1kVZl3g24HnRFXpcM9gHEcosW8LfOyRCd2E19QXTWznIxqhWs
+UnWKGFzYRUUQj5APV8Hs9kt7BTh1trSpGoD+/GfAL60xaswPJHqap2zS14gJDtaZ
+jUaEoGSOWWOGWi6IlywWIHwGfs0ND7pIL3+0gcGY2cyELqwoGUr5L6szhiiguXx7
+M4hOhSmQ5EwL2UbrJEdHWIWmnPJ0J9f3GGknSC3MObY7WTXNBVHMIXCchC90+ogs
+VgOTTnL9WzijUvNEyAne2M7I5eHE/AfQALINreDjTY1Sg5y45s4ezl6bpDf/6La6
+Ti9jViSZvCCREwMrBrkBDQRb2urAAQgAs9Qqli3iPdPVdFtMrjG+QiFaLPfIFhH7
+GVhJMucDf66WZ1IebIBTRbCr9oguEYlGT8tI5B3c8TvBLl6/dFTXea2lIECWrom0
+Bw27QOHq5z6Qpad7cLdNLVF9N7dCRI8ckfiV5hvuPAM8teT7hTzOiZiN+Q0AngY6
+fBe7RKeamayXlC3/42y9rS+Cmqj66S6guHLA3qDLvs38IhikxvKo8KdgjvTNbwus
+FKotlaHPU9NuaRwYskOXEXD8JJ0DINGFw6+hmQQ7M016sFWCf3JrC59ooun+s1IG
+/9AAgO8UTR0rF5M0RzQNubK4op2NxL+2dC04RbSqxaSQD7f9lXhDiwARAQABiQE8
+BBgBCgAmFiEElkTElOIzKZYVxJEakat1oFqpJFwFAlva6sACGwwFCRUBloAACgkQ
+kat1oFqpJFxmwggAuILxqSq2afWYtUqgEyub/VOpvTvUMqIfJd1Jvw6b8PYbdlcm
+oC6UjKSXUN04K7H1uu6YXrAkhOIIoRucerQveT1PNj2Kjuc75j5BqxmWqIkooF/P
+gp8FfJKONAHKdD/20G35VKksvtvmmkeZqMIU2l1C15pThP3RYMmKbKwVUzQn7vMS
+N0dmXdleDPjY2xGZiDkEg362+mr18wStTELNRgp+QwoyN9ckaZoulqcS+mRdDtJg
+SLMfXsMLyqi42z9HmhaseJdgPeYdda9O7H+eZXftZ8A4ot+TV2ygUk5Sth4k+8ZP
+ZmKUca/u+i6bS26S7ykFAwHeCryGYZS1eBeE57kBDQRb3DxAAQgAqoZ7ymbBGGUk
+iHTQSnpFtVH1YJoHicYvDbsr7pA6y+IqiEQqai1nREfqY/R2TELnMA6QA5xLpL/o
+lc04KyehAeM5xR4YhBwnEhF4507efFyNtVVu02xQTv7YKeVZcwDWVRBlb/1i+yL7
+dEVD9irPvk9lorcOxkzi9CE73sTYA5fHrxdxo5S2C8odPfcrqUruiL3mSuAiABz9
+domhEi6NR62pPpbX+mmMppMb26Q83irTKsN3E956fdrpEgSQdQF1UZWHoXstq7W/
++iZGaGbqqEccU6NPm6cI21NJ3ONxAvhM/QpzVN1FpU10M7w0hv+zIHD+yKGVXF0M
+TH6yYPes1QARAQABiQE2BCgBCgAgFiEElkTElOIzKZYVxJEakat1oFqpJFwFAlvd
+jcACHQAACgkQkat1oFqpJFw09wgAxTlHh9tXmr0RdrcDhyFsyvN8HYod3SGaji/9
+ewpxW7C+w2xoXyQ8WTPinUTRUgt1gHY6wpbJXsRL9b8Tx0/3RyskKf6MrPmkM//4
+T6IwA2ZXmhPHUzQMWPsSItX0BRd7Co23UOcSGfs8uYWeN6U75SkRGXzb14cZpBcF
+K3B1+/H9QFa6f0W+Ti/a1NGpXob66PYjtnoT/qW0czjsId/5uYwDwZ6HZdhCtnYT
+hMC0gjBAf1OGf4dPae0LkZ4cyMo5JLadKqjFJ36auwKCCvi11QwFd9nsLFRqSi2e
+Ok13fEwrSV69qo1Rb0k/LTCMkCB828NotouSZIBUmN3KQSbZQ4kBPAQYAQoAJhYh
+BJZExJTiMymWFcSRGpGrdaBaqSRcBQJb3DxAAhsMBQkVAEUAAAoJEJGrdaBaqSRc
+OxgH/2nAsGMOFmz6PxfDlau26Gz+HkJUiTB+l1oKb8gEHmlYQGhQc+87S5Egoasy
+tyApWfA7xPD3zmlPZumnFcP5GqWjEf7R1XuaFKABpKndvkAPabFyyeJN+gjesuyL
+Y1rBMgJ2bZs6FXRXaSj8dPYYs+nCCleVqDM5N192EVn/NgB0x5SOBy2A035LHkhN
+FtI281OlnE+tbcfDgiil+YBxXgLSmGpViEYjfO2EciOQETCTSWl7N02B3yauX8JP
+K55jUHM0Y9lxRCyJ15jlXHPXQihmo4ldhu9qs9SuChX/UrAIrGRbs8z31ndKWV4u
+DM7hV1pi3vrEpjarN9twkqN5M4Q=
+=Z9N/
+-----END PGP PUBLIC KEY BLOCK-----
+`
+
+var ExampleFingerprint10 = fingerprint.MustParse("9644 C494 E233 2996 15C4  911A 91AB 75A0 5AA9 245C")
+
+// ExamplePublicKey11 has two user IDs. "Old <old11@example.com>" expires in
+// 2025 but has been revoked, and "New <new11@example.com>" expires in 2030.
+var ExamplePublicKey11 = `
+-----BEGIN PGP PUBLIC KEY BLOCK-----
+Comment: pub   rsa2048 2018-11-01 [SC] [expires: 2030-01-01]
+Comment:       EE23 2F12 EA3E 7515 D20D  E064 8A7A 97C5 FE86 5AA1
+Comment: uid   New <new11@example.com>
+Comment: uid   [revoked] Old <old11@example.com>
+
+mQENBFva6sABCADa6kfElNZGdB4XtVcEYtCuwToFdcdOuz/+18xaJEIG+PGS252K
+nsWpM9jDiY1Ixmagc34w/BVhtb8p3ZNy80QDxmSxAsyULkPJIW8oVpDPidEUCL3S
+PQtGm5gODY0H6SuoetsN+V82PDR1+JPfufGTwI5vky2qYtJzCTadeHFir/hIMcVA
+THD42nSqIfHMiuTXVtOop86vkRqZ95mT8NwhHSIbczoKrEneBAWE3jWFFmBhEyaV
+NM4xJ+h5Q7hOAMhzc5FkesDtABb2JQEyt2l26OHBDiOdOGzmQSylsqRvMiUrt0mB
+W+cJtKFH6hfr/OUFDFc57O7qoKovzgbUlLy7ABEBAAG0F05ldyA8bmV3MTFAZXhh
+bXBsZS5jb20+iQFUBBMBCgA+AhsDBQsJCAcCBhUKCQgLAgQWAgMBAh4BAheAFiEE
+7iMvEuo+dRXSDeBkinqXxf6GWqEFAlve30AFCRUBloAACgkQinqXxf6GWqGINgf+
+O2A4m0GGWiIK2Lkc7eb8RE1nPoNodBkfatYLzvuds9SWSadPygqqg9yIYQbrmVUx
++33rpSHb2CmJeJO/g7DI+sS9FuPUd++YD8CAZdAAf5epD26RXu+S7Gtsg+d2IFUf
+TDTSFa693+lxK+IB3YhGwH9QEGn5QKfoNgT71pbq3UOXAEg+6fs15oz+sXG+HcoC
+PhEG6pDoz24oCE4lVgBXF68qBrkAbnfYhXyB1e0J2xzlQ4yuxP7T6dWGMXj9V2Ry
+2ap0jZHBNtm84kpt/6+cPmg7TRZt904LijJ7h2NlFk5JFIWS1nFN0EGmFAZzpi/i
+xkfpTSzhs+Cw4Zi5p56A17QXT2xkIDxvbGQxMUBleGFtcGxlLmNvbT6JATYEMAEK
+ACAWIQTuIy8S6j51FdIN4GSKepfF/oZaoQUCW92NwAIdIAAKCRCKepfF/oZaoT7u
+B/9n30DYhTgGK2bWhD1XXRLwJg+VI5uf16o5pujPV/E5GC0ifDhBltCXEbCuSrxR
+7AUJmzHZRMBC3+s5irDL99zM/I7MVt2qt3RQ6g/RGbFXPmRaC8PPpiUbnXyJubJn
+QjxrpXVy/Qz+aEzmUnFkCoDO1s/NhFZ3wV7qvfdqUtyYQroHfRL1wTjm7hlpwtNn
+cnQAtdwjetS8FQkgZ3ATGHdkstloIXUFZVi5DesYelSzzQ50p3kvnTCy+z9l6Xdu
+IP1WBjQ+82wCfyrMnq6DOsnXLy7TKHZ71u7Qc+a/s6jvK7j7Al2cmss7PzaoeLuH
+wFeKT1JJrvPTZ2DrQFIIvryaiQFUBBMBCgA+FiEE7iMvEuo+dRXSDeBkinqXxf6G
+WqEFAlva6sACGwMFCQuaQ4AFCwkIBwIGFQoJCAsCBBYCAwECHgECF4AACgkQinqX
+xf6GWqEVMQf/bOEjaR2kYp23LOCAsp60ldQ6VvwBBnp25kTH8nh3+7o6AqNsSW7/
+fXy5IuebqPrX0hHex8IwhEfpeAmNTD+Jm1hgdIhit941KLyt3CGGKatyGvFvGLKv
+qFVciKTcJYoBlOAcPGXMq9o1vJwqn/7MdhIvaLF5T61dh8Nn3KZLycpnwJDd18bR
+tGFBE2DR2b6upyzmhCTQJXKeHWmS37XN8SjrMb6q2S/8g2RMZDpdkrbOjzeeKMv6
+MPO1Gs4EjFKprzABnvKCYa98gwNa+IAfYa9hv9F30FYKc/k2xdOZ4KXjrlTtFcyq
+ZumkSW3KwfCWdcCaP3T7o69+Rzb0mMC9dw==
+=Y5r0
+-----END PGP PUBLIC KEY BLOCK-----
+`
+
+var ExampleFingerprint11 = fingerprint.MustParse("EE23 2F12 EA3E 7515 D20D  E064 8A7A 97C5 FE86 5AA1")
+
+// ExamplePublicKey12 has a primary key flagged for signing and encryption,
+// and no subkeys.
+var ExamplePublicKey12 = `
+-----BEGIN PGP PUBLIC KEY BLOCK-----
+Comment: pub   rsa2048 2018-11-01 [SCE] [expires: 2030-01-01]
+Comment:       E863 9D12 9E50 B540 10C8  02F5 3254 D925 BDC1 C22E
+Comment: uid   Twelve <twelve12@example.com>
+
+mQENBFvaQgABCACyss1ubbV+MgDfc4vkWp7muA+/rxz0KATYAuwRcP+7ePeLMvZI
+MoLYXpXmUNYlMe3nRL6LHZucK+RNWH4OARokTzsagmYF+PHG8m71YC11RyVl3nQF
+BVYknZvZO3dRBRReOzY0SmWEyRlzNa9G9TagtHccneknyRGEKY00v4Y3VN4UH8Bn
+k1yHD37U+VMb7dMjWhv5msUt8XT62GrxVUtqSYEpYmxshMd3pBBFTC81OqnRNaud
+cn01Wetpe2/ZYBNIm4Ey7z0XKkjCyxiuxCbTjS8xJ7+EvxYfgBAIMSdzqsdK9efP
+FhlvvVenHfI4IVh97+w83GAQDjlEub0k8VGHABEBAAG0HVR3ZWx2ZSA8dHdlbHZl
+MTJAZXhhbXBsZS5jb20+iQFUBBMBCgA+FiEE6GOdEp5QtUAQyAL1MlTZJb3Bwi4F
+AlvaQgACGw8FCRUCP0AFCwkIBwIGFQoJCAsCBBYCAwECHgECF4AACgkQMlTZJb3B
+wi68UAf/TSvR0ykO5qnbShkSQP+H3ygZ8D3umQnOe/Dl9AJcYbJ8P8LjwTS7aHMY
+clLDsvK+olvNJLRy4dOWlsoZ+6A4KSLv0HDT5UbAmtq/bV3N4ANPGO6swrxcexy9
+1ZvFUVaLjCrcBhfNqL4EcQlUo4FCbR/diVMkTLopalfrvoEo6wpMCHKbzUbIAinw
+AeFzcBQr5vwTes/y0D0iZy1TGa/nCOLL+qmfjLqhf7duKQntUkcfrKTbycg65twX
+tayUCcoY56V22R08VWVjpdrkNeEmNobMNA9AQNgjALqEmez43tckdCaI3uoKcXhL
+y8AhQXzhkMJlZPUOMciZ2Xchiw67lg==
+=zCmI
+-----END PGP PUBLIC KEY BLOCK-----
+`
+
+var ExampleFingerprint12 = fingerprint.MustParse("E863 9D12 9E50 B540 10C8  02F5 3254 D925 BDC1 C22E")
+
+// ExamplePublicKey13 has an encryption subkey and a newer encryption subkey
+// which expires sooner.
+var ExamplePublicKey13 = `
+-----BEGIN PGP PUBLIC KEY BLOCK-----
+Comment: pub   rsa2048 2018-11-01 [SC] [expires: 2030-01-01]
+Comment:       5F96 AD5C DC75 83F9 1C77  9E31 1AB8 B2F4 733E 36B0
+Comment: uid   Thirteen <thirteen13@example.com>
+Comment: sub   rsa2048/0xDDCFF77E7004C3AB 2018-11-01 [E] [expires: 2030-01-01]
+Comment: sub   rsa2048/0xCF17D17D6C71C4B2 2018-11-02 [E] [expires: 2019-01-01]
+
+mQENBFvaQgABCADUMZUp0un1NKYI5GbvFP2cBsNKlvcS1/iSq8ta8Mf+9CfLqbg8
+nQqoCqQ4VWNdnFbO8nFmu2Qz9oxC11W+DQBS2G692sumM11d7zdxs3AMeraK1UIZ
+gNCRm7AxFBpBlks/XlyBYA2krA48yY0Or+JBqgB1orKz2og9P7uc2gFm+L9OG7sg
+Tt1DFeLyOqc0y+lk8PmSWBNSS6Ddsb72ergNXWHxyqRtNg8VhkN+IHOCphq3H5V9
++v5e6+nOWq/uPrYKStSiuoVpW/+V4izlbO2BSPR8/WINyyaDFX7eqb7ASUT8UNk/
+Jri9/Qykx8p+6bTfFK1c/dXbuGKgwbAWAR/vABEBAAG0IVRoaXJ0ZWVuIDx0aGly
+dGVlbjEzQGV4YW1wbGUuY29tPokBVAQTAQoAPhYhBF+WrVzcdYP5HHeeMRq4svRz
+PjawBQJb2kIAAhsDBQkVAj9ABQsJCAcCBhUKCQgLAgQWAgMBAh4BAheAAAoJEBq4
+svRzPjaw8/4IAJUTmACq3YbRxhgG8mXyvkOIJwqltR6GutC8RXJtxiPCX0zAPYWO
+gvSEnW2NUGm6VOH9fOlKVQdIVPGTagcecN8JbM5dxZ6waqQU4F9kmKZycKdL3nwd
+hbIBfOIACB3joBdwAAD8BOj7V4yhySagm2ZHjwux9POa7hwG+H6rZmGwIezukpa2
+cihAaxLCYbcO4lWkWwyOMcdkIhFdo7etWRGCr0aUv68/qgdEldyqvW/O9K3tgBjm
+4U+gUd/ZK/99GNDZQ7sxs677yOzPT29nGbBfTwTNylUKHa8D1Jgwjn00aiRHAyr2
+hXWRIhJz6yfZmwX5x05JVa7LWRYAayzRFsC5AQ0EW9pCAAEIANPeSqg86RyuPo4x
+6ahHctIFQJi0M01RyNvPZUxlG97DR23U/BsoL+g6q6qlqzradQ7KwSa64m0bz2j1
+3c4d0j8qvrIFrXkn6CnW6sJcpXiYHiZwzfTxTug21NjcZ1AEEXAPGjea03Q1D6Kr
+tb/opG/D+F8MoaSB0NMaipm48C1d4xz0ZNsr9NYkbNfknwaWvVe63+tPipnLlibP
+888hz0Fxzt2L+esmlLDdOHeYxTVPY/CNXD9m2IZh59qcscAL8FOVX7OwzKNvxFaW
+VBSgTny8NG33UaARHbRb8Xts8VMvO1GLusN98IDAskK3um1pNRGuuSvZWW78U+8n
+f21wL+EAEQEAAYkBPAQYAQoAJhYhBF+WrVzcdYP5HHeeMRq4svRzPjawBQJb2kIA
+AhsMBQkVAj9AAAoJEBq4svRzPjawyD8H/37KMwcSUgYYx/KIIcaS8tfg7bZHAy/X
+BpOq7/x1oFFk4N12fTE6ftsRVY25waygwslikK63bdSqEMAlfHA4YgzZeaw6gojC
+3+kzxpPeH0PzrJG8m9NAvDJ1zD1zlZVJhfQeJg8956Xk9igRPgy+VP7JtW/2+1uK
+yp0gX9Q9xxLFzo9O5xiye+CC2kcGbf+cfZmnP10p1iIhOHcmU2rIhJwPXV59ekdm
+cWclILUf2tvjzd/i6z+Mn9w+0Q9HQmT9ySPaC5ladV/30hq2D0IcuIx31bTHrqaA
+KDY4SNhGbC60OkG+IDcDZZPwHgbxRVDb0V9VK38scwjVcI50gud3UWm5AQ0EW9uT
+gAEIAMvmzkHZtzQSUdq3mTNix36XgLVGCj3TpuzO8SU/rIjYK7+HODsSSbcN3thG
+IBZAs3RbW7pXqiPLZxO9oGuIDSQiRYVQkc0S3pdNn391SaKDFym1D3YPGOcsWtCh
+2nwx4nww2FKGZ8+imDyiv0xX+N9XaVp924xl8yEz0+pIS27YpU+NsgDHqxeNx72t
+AldTpCTOjlF4qW1w4FRjXg/HzvlEXKjxHDR7dSaA76cxW/GH8ohJOohNSBBQetKZ
+vjickJfhbVoJjc/q4VZcelUYLxt14ig/nSlVdSi7EmfwgG9kdsFVjxYVqdfu1uKJ
+oaworpneLc1NMPj5m1jaakb3bFkAEQEAAYkBPAQYAQoAJhYhBF+WrVzcdYP5HHee
+MRq4svRzPjawBQJb25OAAhsMBQkAT8LAAAoJEBq4svRzPjawxAgIAJMYa2DjjP2M
+EbTVHQIRBiVmb2AUXJLoSC2AQlrU+RZtpef+Pdqzt4ojF1ekLag3noBHpf/s3od4
+ex2XqbXwCy4gBWwJ75bGRlX4HdXL+X/h+2mVi6YX/NV9gyJT2zadG8YKA47nhNJ6
+NUnUqltjpn42IuDWNl9x9T5OLs/UPX9TgIgtvBKfAtzIKsGm3C6E8/hbScQXhfJj
+Bc8zuOFVQXO9dTO14iWg9HqpfMDra6IK9LWMLd3FbyVxP4i/zLOpeKehuMbrIIR8
+f1XQPzI7em6sF2By6xJY9rydqtlarle6ZLdLmrVUYPoTtQw0+RtiDztZ/J8Sm4tS
+moECsdXE6OE=
+=Bax3
+-----END PGP PUBLIC KEY BLOCK-----
+`
+
+var ExampleFingerprint13 = fingerprint.MustParse("5F96 AD5C DC75 83F9 1C77  9E31 1AB8 B2F4 733E 36B0")
+
+// ExamplePublicKey14 has a primary key which expires years after its
+// encryption subkey.
+var ExamplePublicKey14 = `
+-----BEGIN PGP PUBLIC KEY BLOCK-----
+Comment: pub   rsa2048 2018-11-01 [SC] [expires: 2030-01-01]
+Comment:       9493 E5ED 3C00 BCFF 67B2  0AF5 FCAC 8536 2FAC DB8D
+Comment: uid   Fourteen <fourteen14@example.com>
+Comment: sub   rsa2048/0xCEB2953975E4E846 2018-11-01 [E] [expires: 2019-06-01]
+
+mQENBFvaQgABCADaiX6uavGD7uTpdSqeFaC5nSOoiTgJJ6F/h6fGb6THn816iR4a
+1wvaijbwlvpMjbkJ1dJaPoSEHT/IAarVmDYMX6IwsHN1ePDdjO0sHKnVPk73LAcr
+zhPMLKL/VVi8Z2N685MX80LZS8cpPj50A4yELhD5wJTS47EWeLTH8xga9sQutH1m
+zOAjIL2rp2CyxovIyp+JdGE6I10Ao59OG72KUcMGkm0vDplUtUrSLIKoadBXF2Lt
+ks7G3Y1fu+YbaqwsSZOLxi6QeGjhUnglnvZjFuOOeXxmaRFy1j+SFkcOGOuJMQ9P
+V32k8QXxLJ89oazKyynHps7XHjcAlhowWGo7ABEBAAG0IUZvdXJ0ZWVuIDxmb3Vy
+dGVlbjE0QGV4YW1wbGUuY29tPokBVAQTAQoAPhYhBJST5e08ALz/Z7IK9fyshTYv
+rNuNBQJb2kIAAhsDBQkVAZaABQsJCAcCBhUKCQgLAgQWAgMBAh4BAheAAAoJEPys
+hTYvrNuNXW8IAIpMacDQrDYw40kFEUmeEph9/OX2yhiCaz+LB+59HHsU6xBtXhSc
+eoS8+w7pWSnhWweJWYJb2APkWv1eTMoY4CZwznrpdBWHLrVnb0x+UvFnQTAGZSap
+Q8bZmXHfkHh90WIyUP93XDmMjkcf0/MQLdzicqalx4CfCGHChV426+BNZ0HXWfWs
+YHSAw10lPsarsRtlVPhM7bjlVud3Tknlzj3Kt9ndBYWY+UL1LYZXfxSAD2prEtkh
+dHyW5SHblQZn3azFxK6Ht6CWZS+hn4LEMflApXB9xrYAeIpNvwB5Q04mvATzp7T7
+2gKNlWWe9fXAOWToPmMkcTkVaV+6YeOc4/m5AQ0EW9pCAAEIAKi9zDeOFi5xm0oR
+2PUdDdvf6Zr09+Vq/QN67mHwhqs+bS2l1pKIXaUvr9yEuIhENegBl2LMg/YJOsH4
+L1E1o1Ii74mLDztTWvHl0U+cYhu+izpZLvAxSbnJ3D/M+jNli4MVn6EVC6Vr5w6o
+CZWOYZVwekEsg8Yy6tfi9f7agUU4yyLudH8OTA4M6tozBMkvdpblffx6Xbs18UVL
+PJ5mFxAh/Qg71AUwZ2zcnxQuW+sgI7avstds3t3zuXLmW8w+T77wIyu0KtDgVXHF
+hBVpfDRXx8f6jTS7itFA553kxv/UfKNZdlQUwiCo08dx9inbdfmThPVgZ+spc5Up
+wrOX6YMAEQEAAYkBPAQYAQoAJhYhBJST5e08ALz/Z7IK9fyshTYvrNuNBQJb2kIA
+AhsMBQkBF34AAAoJEPyshTYvrNuNb1UH/310huSLfgr4yGSkQtec9acPIYU9KC8A
+PT282v9u04Ykc7jVWUIXm5K0qYc3UJOESNB/yOde7oDcqlCjgla6ZnCo9W2jg9CA
+fgNBH1tzwol3S5JCCe5D2KXAWr0SDv9I4eVSIbpbPgKfZ4ttonw3GzLKbY9jQE9T
+ohIS+fqVi0l6K0BRL7/k1cEG9gOw4nxvFKXfTLURaI18O4myh98Q1M8iSou72Ajs
+OCc0Jw2aoWrgFmgv/8MtvAsYP+CFu7s6XdSoXeZOv70Bs4e0vOO+ZA+ICvlYXmBP
+wiqQSAf7x+up5jfNaXN8dchZ4NkaRDO49A2//ITB68yUcu50mHI+S18=
+=48cG
+-----END PGP PUBLIC KEY BLOCK-----
+`
+
+var ExampleFingerprint14 = fingerprint.MustParse("9493 E5ED 3C00 BCFF 67B2  0AF5 FCAC 8536 2FAC DB8D")
+
+// ExamplePublicKey15 was created in 2037, so it's in the future for any
+// sensible test time.
+var ExamplePublicKey15 = `
+-----BEGIN PGP PUBLIC KEY BLOCK-----
+Comment: pub   rsa2048 2037-01-01 [SC] [expires: 2038-01-01]
+Comment:       F179 82D4 353C FE36 9D37  2C26 0B7C 9932 ADD8 F21D
+Comment: uid   Fifteen <fifteen15@example.com>
+Comment: sub   rsa2048/0xAF919067A1476E7B 2037-01-01 [E] [expires: 2038-01-01]
+
+mQENBH4G5AABCADC/LsEL1gR/qLUua2r53SbhFiGE1ATcHMPoqLiazUHAMI6Lr6l
+RwupLzKtjNLG6nAJNThsffExBaxeCKzzQCJdlQYSpy/OXP0GHAchxtoXRD5V+7M4
+Wu40wBtlghk26mmKPdNdfaulYCJPjZTOTW+UpYSO48aoUu1ARRv7IJgOaR/U3Yif
+00uFCxkrgrWQLD5pkoTQ9/DvwzdESsGWe/LwC9S8Y1A9+jr1/oqjfemCLkxNovz4
+O7oqf4vF7ZwZD9Ln1RJ+it6VwJ+C79pNJQRevFWo66lx3/fuxM+b9UQq2Zun8YWV
+hBWenFwzwGKUu+IA7atRtvU+QmorlEd5Lxq/ABEBAAG0H0ZpZnRlZW4gPGZpZnRl
+ZW4xNUBleGFtcGxlLmNvbT6JAVQEEwEKAD4WIQTxeYLUNTz+Np03LCYLfJkyrdjy
+HQUCfgbkAAIbAwUJAeHcQAULCQgHAgYVCgkICwIEFgIDAQIeAQIXgAAKCRALfJky
+rdjyHbt3CACt0//K2Pm2PastMN6ypJKi5PIX4SP40qyPIY0GLafi/4R58/rJGmAg
+UdHPiyn4FGaAGfxJE7UGdtZUPOBzRZYhGPlHC+RvRTRXXRF5TpaQw6vWyw2HwrYu
+S78HZTaxIjfamLdeB70zxjjq0O6z7lWKHsUcLP+B/oMDaiKKEGSoQ491d8yzAdPW
+qS6n8o+3ghc0Wr9tkJs8iy7GnLkT6++PaxOxPz2Srr/ePjzszAvTBKjpRANKG76W
+oVUcJhBOrKsI48c/qqsuCOR/sCQgzoM2SBmdsa4eQ0Ocxuq0eUp5syr12bkfXZnj
+IFasgDQz7XLWHpcbFih/p8bt0z1FOFLUuQENBH4G5AABCACuNSSpV3o6VapAbEIu
+H4+hnvZw33Xwbmhx1n9WJZahwyS+oLLGz68Ob0cbx8M0Lf17zxdJgDVhEyq8CJ8J
+8QQiWkRa1hRBvEKfewCPYBO0/TgKSJ5W8gBR7cDHcqvCSpt3gWOkgDGKWYIR0mcQ
+dxEXcgbK6QFiQbKsu+8r7sf2SCIckakwpljvOwFW2UNQWwca0Lt5iE1tiOaaiJRi
+K9bGpu8mEBbIV63+e7pKjtVqN3OsqR4ya/+dIlaPqzkRPEpTcgUN06faTK9lJ6jo
+wsZxptGPvuD7vKc9ll1hCbzhtquPpoPsws96V9BwvXsPj1OQ65bzA+/0x4CNdgil
+mCyjABEBAAGJATwEGAEKACYWIQTxeYLUNTz+Np03LCYLfJkyrdjyHQUCfgbkAAIb
+DAUJAeHcQAAKCRALfJkyrdjyHbnACACU9o10VTpPboMj+tXAjNSju53/xEA7HMNm
+qa9daXZAUTE1vlv9Jq2vKk65On/AeZ+NSyQ/E8Ovj4898Laf/vzafVHZQxTgO94/
+OCds2JkfvSdAvD/vKqyFODOHwmZM6+Q9BWdyLhBiTrj7F/y3l0SOdgnREsdAgpmX
+AUoCukJENBYgVAbg1xnf81sNe5m7N+UmWM9jnnFM/bW2n9txz/vlO8nyJAX66EpY
+xejFPMnJbvbSiU1rpSupb93EwCDf7yPBp5VGzNN5s1NAeQQiO1IMz+FMUii1UPsn
+20aAy2wy1+gaKYUHhpgLpmwZU3lDnIG3WAHPWn/DKE1pe2lTwGgr
+=AGZ3
+-----END PGP PUBLIC KEY BLOCK-----
+`
+
+var ExampleFingerprint15 = fingerprint.MustParse("F179 82D4 353C FE36 9D37  2C26 0B7C 9932 ADD8 F21D")
+
+// ExamplePublicKey16 has an encryption subkey which expired at 12:00 UTC on
+// 1st December 2018, long before its primary key.
+var ExamplePublicKey16 = `
+-----BEGIN PGP PUBLIC KEY BLOCK-----
+Comment: pub   rsa2048 2018-11-01 [SC] [expires: 2019-12-01]
+Comment:       BC95 07F6 DBC7 875D 932C  0913 5318 B69D C2F6 0E77
+Comment: uid   Sixteen <sixteen16@example.com>
+Comment: sub   rsa2048/0xA3B50F1D21870240 2018-11-01 [E] [expired: 2018-12-01]
+
+mQENBFva6sABCAC80wGuItWawfURtzJiZLix9mia1OGkh+UC7LqpNH/a7GyPNoj0
+sUvVEwNSJJJaBbm/HTaZLBdd8mDr9vEkAN49IZWblxCbIGbSz72RKBan08ajovOF
+QYyWbMykXs3GtuuMXomz616zYjU0lw+JRtLaGqyKgCmRmBTJd6dm79DwVl1jFlld
+KbFEKe/GbHUDDDT/FiWH9daLIII3E5VX0pZXY5161duva0Lqs3205KaVSBpvrWCg
+Q1CywwWr58SzS6AUnT28rAz6FVT40s1dBKALaJ1FTyvsrRXyNHJnENHugNeRUMAS
+q+Ro4HRXE6EACblDn/E8jolkWjd4wdLSb1rZABEBAAG0H1NpeHRlZW4gPHNpeHRl
+ZW4xNkBleGFtcGxlLmNvbT6JAVQEEwEKAD4WIQS8lQf228eHXZMsCRNTGLadwvYO
+dwUCW9rqwAIbAwUJAgjAgAULCQgHAgYVCgkICwIEFgIDAQIeAQIXgAAKCRBTGLad
+wvYOd1fpCACl2iHPPqVEJcnxcv0cBTaipY7H0IrD4vdBg7Zx/smL4oav976AfNBh
+9AeQ3GBgqJ3Zkg37HpOqKL1p63LnMqLW/YDmEB95GdMIpLTr5QZQ+ZeLeDXuYbwm
+S6zk5jOg/TFQDlsXgplBlbwdk+qeK8d9xXoFwKmwXXRo+H/4I3IocTuwf704bnAB
+ldYyD8jUEC5BCYNM3LVYYNl920i+zhC9lClqzMpmLShI6dfpK0wBMTAla8+AIC6p
+I+JEYTX+1ibzQhjIhc3fWNsBmEIYf0R238II03ECdhhC4SE4gMKpWlscGG7cd7UF
+Oxaa0JfTxz9m6B8Tsdu1fcGdb6QIruwcuQENBFva6sABCAC4wgikSlne1ehcQlc/
+5Px89FNjWtkgGAg+0cZIn2v8fgQwpkxuh2VqgHnY9qfne7RHPyhA6313PtE2FB3X
+rJ2M6s+Tl3YwrdhI6cVUSbHL6wxWvkQ4BBaH07Y1Woz3F1MAYGMzUnBbQQpxCQ74
+gqJFlRKWvxRm0rQ/aBpq9q6MsX42D0UvmT5YrjLDPkHVbEdUeopN47jxxLQYOC1k
+LKUzd2WDxfpbQyFRDY4gHRTblRKXue5sFImo8sxtDg8+0186rH173zb0jOA3F3Mq
+Dqfve99mPEfOU0HFX3vOKc84p+ssO6sSorUysz6P2cupue0HP9l3I/wYHC932E1a
+krONABEBAAGJATwEGAEKACYWIQS8lQf228eHXZMsCRNTGLadwvYOdwUCW9rqwAIb
+DAUJACeNAAAKCRBTGLadwvYOd0YAB/9kAStcnLxqL0Uaz+MzPlweR0ZBtkR+b+Bc
+qTvfzrq0JvDpIHO2waSmmiFlgFOfCAelk7fOK1DdqP1h/r1dx2JVM5Yrxgo/ZMEA
+BflFtHMI1HBj4OtFpxa3kdNrYGN4rnUW/dF6SuKJWJYpKsZzCAXt0EXOlc5YyweV
+S5jPzSeeagtZWxf2LRDLX+M6i7Xk6j0wqqXkLoI5Ezz2RiVGWkcdSRmMZHlii6GA
+rDtugkwwTdT5MjGOlciEskgjnJyV7rL9XZ6Fj8TAxsvuRcAPCcpVOICbzjhCV1/R
+3W1WtL1fTjgMWxRHk0bJw8Ic8U+Clvr9CixgLpkZpVpPw5+1EtWC
+=jtfl
+-----END PGP PUBLIC KEY BLOCK-----
+`
+
+var ExampleFingerprint16 = fingerprint.MustParse("BC95 07F6 DBC7 875D 932C  0913 5318 B69D C2F6 0E77")
+
+// ExamplePublicKey17 was revoked on 15th November 2018 because it was
+// compromised.
+var ExamplePublicKey17 = `
+-----BEGIN PGP PUBLIC KEY BLOCK-----
+Comment: pub   rsa2048 2018-11-01 [SC] [revoked: 2018-11-15]
+Comment:       28B0 607D 8158 C206 3C3E  884E 6D5D 4442 4C57 09F9
+Comment: uid   Seventeen <seventeen17@example.com>
+Comment: sub   rsa2048/0xFFE117E4732EE2BB 2018-11-01 [E] [revoked: 2018-11-15]
+
+mQENBFva6sABCADdVDrr5HNTTxlpmqYdKXQeQezgLJ48wy9FquKoE+ZmCDffLXuL
+o3YTAFqypmuD0l7asGPEkBbiCNxnI08RhgolbbW/b7zQB0IOHuwxjRjMIPFwfKDF
+cjlbqaBfVMDZgpZTm46rSfhBf3yYCXOlIrxq1truRX8ZjPOWHEPd269OrYVEGOPu
+GyPT5HB4DvIEen7qqDkvf/SXfBkCaNy3sV63Z0N3dfsDsU0mGgXpr/BKFGRVQWrC
+VPasap9MG84DnA4HDR49XVLHCtttDimYKM/3ovKkdq650iANTcKhA4VuuXURcg6J
+Kw2BAnKFmAQuvD4FT/jIB7CsDlEkPfwob7sJABEBAAGJAUkEIAEKADMWIQQosGB9
+gVjCBjw+iE5tXURCTFcJ+QUCW+1fwBUdAktleSB3YXMgY29tcHJvbWlzZWQACgkQ
+bV1EQkxXCflrHwf/WqyCMkrKRNuiZwWqK7n+nHf3KtRFnAKEkeOGNPlRrgs/QR2D
+somuOYisi/LunY3KHkTzgW8L76abUAjpQSIwhUqjzQZKaIS/yrXC1XzPE8ZSrlxR
+kocHUujoecoDyoqtmu0ifVHLtHFa1iGvmdn8yvcRQH0FcrHC0oJvTVttnT6GYles
+mKHIu04TRJL7aQOLm5n5ZwJIdYFKeJ+is6+R8N7hnncz8svk4fViyiyVbzKjm4CM
+sg9NEVk3rlz0YBpTM5GpvSHnDemr5ATKyaEfXjMxluJOU0K4av1TGGrvovBMyNFG
+ohvYOJEHKl9AByNxuzJqjVDNJpaK4DhqMD4lYbQjU2V2ZW50ZWVuIDxzZXZlbnRl
+ZW4xN0BleGFtcGxlLmNvbT6JAVQEEwEKAD4WIQQosGB9gVjCBjw+iE5tXURCTFcJ
++QUCW9rqwAIbAwUJAgjAgAULCQgHAgYVCgkICwIEFgIDAQIeAQIXgAAKCRBtXURC
+TFcJ+U/4B/0VLRLYCAQ4bw/x/biFX82wV7vj9d2JJif0Kjj/L2yPNMiX8r5UcS6J
+3Q7C43lt8+91bL1xedvgDO10XV2GkjMGIlBX8Zqyj39aEa3fDyc5xyew7LmEjhSh
+n7EDWdYjm5L+H8xat2xsieOBdKWuvf9CGwhz8zJk7RU2/zLcYCYDMOQP9bHsZP+p
+AVweubg3Vop4mX2oWQAJiz5WfOIEwThwqeBYYvNqnqtzMiUntrE/upZNd1eo96aG
+P4K82prq0GMs4kkgZtgkqAroRDJz2C5lC4Oum7ifswivqR+qFTGyQXRQTJrL/XSH
+x8NhPu6m0K1TJj74rEeFO71n3L1sRO2/uQENBFva6sABCAC4ZTpce8JAghQaSxKa
+LRXLTsksvoaWdF6F14kwf/Mkj/StbHZyks+ecuT0TF0WgKwUOCIDORvlew9jErAx
+cVpzmDgaUhiMrT6Gsh/IOBGziBfBxOceZyVavVkwaiTA3rWwPkda42o1HA3/63Mt
+4+vBwfpU6PFm2LtGxiDeGLo7NvVDpe73WO4ZVAj/vjbn3MG3Isuz90J4oAz/umEQ
+6kr1AzGjLdEh35saqEJs+EtQxNH7vQ7eOmIty3wzQ9fBVAQNxUpgj7n8IZ42sKXy
++pfOdB66PJY9y28xgWNnfk9RVcwyumMDrYebpA+RxS57YUQTwQ+cmoL3GG36fHYl
+Vw2dABEBAAGJATwEGAEKACYWIQQosGB9gVjCBjw+iE5tXURCTFcJ+QUCW9rqwAIb
+DAUJAgjAgAAKCRBtXURCTFcJ+Sj5B/98nJxk+IKY3VbK8pihzG56P3ad6UtWafgf
+X3H6sQJxDyU09287lOLwChgtlTXFNlNnDjI9QVHZidpJ4vZJcQXeoJrZXLdu4tWf
+W+Qsho2qdO46yuCryVYGVDBOZZlhhIvCH98EsFksRAJMspmIlYlUvKhMua1gGQtN
+f7/OQpMviTrT9P3iW2RIlNSxstF5l034ELZMNqQXGYwXCfgnE8ZvzQnhoQNzfFRK
+GaEsQQ1QocRtoKw08NHKkAxAR+Eb0/5mzFon1dvD4cugrhjO/Xd4AM5WxNAjnHNl
+b4WHm6Y1+xEflyaZUdluvP4P4EUjudnvWhfQ2O1ykOWFmFjOJCkO
+=rIcf
+-----END PGP PUBLIC KEY BLOCK-----
+`
+
+var ExampleFingerprint17 = fingerprint.MustParse("28B0 607D 8158 C206 3C3E  884E 6D5D 4442 4C57 09F9")
+
+// ExamplePublicKey18 has a certify-only primary key and an
+// authentication-only subkey, as used for SSH.
+var ExamplePublicKey18 = `
+-----BEGIN PGP PUBLIC KEY BLOCK-----
+Comment: pub   rsa2048 2018-11-01 [C] [expires: 2030-01-01]
+Comment:       2106 DCCF 7E83 420F 0637  CA93 3D10 22A2 36AD E80C
+Comment: uid   Eighteen <eighteen18@example.com>
+Comment: sub   rsa2048/0xEBA55377C49D12DF 2018-11-01 [A] [expires: 2030-01-01]
+
+mQENBFva6sABCADwvors0Aeas/MiaMf4NPcS11674vy8ClIq6rifWVwsm5oMyVk4
+1US/75tP8TbcI1L4bq5bnK1k6IrqlfM3gRiTKbFQSti6htwbyQVGlsIWudhNdqAc
+IcKOzmeU2B7MqQrvcDHu0kxek3GTu/Vv0twUyMjXHNC5L1ezsVBIfQaLBodvr6M7
+1NBfq8Dfq+0zDoJEjRVpc926IWLWKDk9vMCJXLyMGlN9sWUUacg5QXVKCpJLGLxz
++JmNQkOTGvicc7/RTdcG/SoOy4eRCUsaLxn/9grFX8rwN8RQIvuxGGOw6YlKsitu
+voGY2yo9z/3xA7vh94F38mKNzJX5oBuiJKkNABEBAAG0IUVpZ2h0ZWVuIDxlaWdo
+dGVlbjE4QGV4YW1wbGUuY29tPokBVAQTAQoAPhYhBCEG3M9+g0IPBjfKkz0QIqI2
+regMBQJb2urAAhsBBQkVAZaABQsJCAcCBhUKCQgLAgQWAgMBAh4BAheAAAoJED0Q
+IqI2regMtU4H/1MMFJ7AyD7BgNZ+Ta5mF2v8RmqbhpGnfetrDT37xskTOq5Z4zpW
+lsFgbxqfZC/y0l2569hkVnn4jNw7jLLE1lOpflmd+gJfNWwXdm29J7b4mYQygHSq
+HzPlAGSAkwwKicMr8US52VoNDJrXwcm4CRLGTeCs8oF0KZog4E1TG6JX3PmSF9lE
+al8dT1V1uGMJfGlLhdcQwseU8OtVbuTvX+2I3kOj6nKpsPdgZZdiH/tiDetZBRyL
+B4Iu20CpnfgnHPeqZoNuMaWk40sl7D9ShSz6jHDWd1Z0Eee/Zw6tayatzbVEODSp
+mjxa4pRCv3ZI15lfwByVR+pROrAO/PjAkYC5AQ0EW9rqwAEIAMzlpSSOpBrpWzDR
+EQ7vDQTq8Zq+BOgk5dh7w7D4ExAkD6wDg2ucAYrpqxyzrFWO3PkAwcNcG3hjlvLm
+ELL7r3e58ZO5ZBWN6qk3jP3G1F7OR4X24YKVj4uBHGnIsMi8AGo8mRhWzIlV4fZZ
+Y+ixtrqONNt29ZBK3yu+9rGSLGCO0Fu0mTjqm5foDh/nt+tFnEdst1LtUlyt8elP
+QXjDLewCDk2m4tlx8JuToxoiT6Z4VI54oEOX1Ipq8+9uUOnu1eGrOvcB4WcnaypN
+2lyEwkKCSYG1gDQexSRynjYlji8b4KMTkJPTywNvDP4+aS0N+AVQva/NhXSSSm/y
+ebtMntEAEQEAAYkBPAQYAQoAJhYhBCEG3M9+g0IPBjfKkz0QIqI2regMBQJb2urA
+AhsgBQkVAZaAAAoJED0QIqI2regMHzIH/jpeU4E7cT/IsFLK60/syoaUGrwnNNlM
+ETy8tEja0YLikq1WjonXnGPifsqIYGs8cD9hqyHg3zpJRhh5taQ/aR5dtzT/mRHA
+tk3K3vnB6mQqWX16rS/AJt1lfXmb5li698dDxm6h+QcPa60ja1uPupSkfdfIYxPG
+BwgC8MldmD/ZcNdvCjm59FJY0Ck9adr8aELX/WH7T4tiow+Jlzhnd9xocWilPYbH
+PBQ99P/Y69mcMGiRBmgxfvOK76SZwcZRaoQjizNYNPZufvVz18jmoch890/Epyr3
+MuZl27rQnV/5Ko60w6xqapW1dP4A/v+AqhqLad7PP3+XayqbTfa1EmI=
+=FS11
+-----END PGP PUBLIC KEY BLOCK-----
+`
+
+var ExampleFingerprint18 = fingerprint.MustParse("2106 DCCF 7E83 420F 0637  CA93 3D10 22A2 36AD E80C")
@@ -40,6 +40,76 @@ func TestArmoredKeys(t *testing.T) {
 			ExamplePrivateKey4,
 			ExampleFingerprint4,
 		},
+		{
+			`public key 5`,
+			ExamplePublicKey5,
+			ExampleFingerprint5,
+		},
+		{
+			`public key 6`,
+			ExamplePublicKey6,
+			ExampleFingerprint6,
+		},
+		{
+			`public key 7`,
+			ExamplePublicKey7,
+			ExampleFingerprint7,
+		},
+		{
+			`public key 8`,
+			ExamplePublicKey8,
+			ExampleFingerprint8,
+		},
+		{
+			`public key 9`,
+			ExamplePublicKey9,
+			ExampleFingerprint9,
+		},
+		{
+			`public key 10`,
+			ExamplePublicKey10,
+			ExampleFingerprint10,
+		},
+		{
+			`public key 11`,
+			ExamplePublicKey11,
+			ExampleFingerprint11,
+		},
+		{
+			`public key 12`,
+			ExamplePublicKey12,
+			ExampleFingerprint12,
+		},
+		{
+			`public key 13`,
+			ExamplePublicKey13,
+			ExampleFingerprint13,
+		},
+		{
+			`public key 14`,
+			ExamplePublicKey14,
+			ExampleFingerprint14,
+		},
+		{
+			`public key 15`,
+			ExamplePublicKey15,
+			ExampleFingerprint15,
+		},
+		{
+			`public key 16`,
+			ExamplePublicKey16,
+			ExampleFingerprint16,
+		},
+		{
+			`public key 17`,
+			ExamplePublicKey17,
+			ExampleFingerprint17,
+		},
+		{
+			`public key 18`,
+			ExamplePublicKey18,
+			ExampleFingerprint18,
+		},
 	}
 
 	for _, test := range tests {
@@ -82,16 +82,16 @@ func keyFromGpg() exitCode {
 	return 0
 }
 
-// keysAvailableToGetFromGpg returns a filtered slice of SecretKeyListings, removing
-// any keys that Fluidkeys is already managing.
-func keysAvailableToGetFromGpg() ([]gpgwrapper.SecretKeyListing, error) {
+// keysAvailableToGetFromGpg returns a filtered slice of KeyListings for the
+// secret keys in GnuPG, removing any keys that Fluidkeys is already managing.
+func keysAvailableToGetFromGpg() ([]gpgwrapper.KeyListing, error) {
 
 	importedFingerprints, err := db.GetFingerprintsImportedIntoGnuPG()
 	if err != nil {
 		return nil, fmt.Errorf("Failed to get fingerprints from database: %v\n", err)
 	}
 
-	var availableKeys []gpgwrapper.SecretKeyListing
+	var availableKeys []gpgwrapper.KeyListing
 
 	allGpgKeys, err := gpg.ListSecretKeys()
 	if err != nil {
@@ -106,7 +106,7 @@ func keysAvailableToGetFromGpg() ([]gpgwrapper.SecretKeyListing, error) {
 	return availableKeys, nil
 }
 
-func formatListedKeysForImportingFromGpg(secretKeyListings []gpgwrapper.SecretKeyListing) string {
+func formatListedKeysForImportingFromGpg(secretKeyListings []gpgwrapper.KeyListing) string {
 	str := "Found " + humanize.Pluralize(len(secretKeyListings), "key", "keys") +
 		" with " + colour.CommandLineCode("gpg --list-secret-keys") + ":\n\n"
 	for index, key := range secretKeyListings {
@@ -115,7 +115,7 @@ func formatListedKeysForImportingFromGpg(secretKeyListings []gpgwrapper.SecretKe
 	return str
 }
 
-func printSecretKeyListing(listNumber int, key gpgwrapper.SecretKeyListing) string {
+func printSecretKeyListing(listNumber int, key gpgwrapper.KeyListing) string {
 	formattedListNumber := colour.Info(fmt.Sprintf("%-4s", (strconv.Itoa(listNumber) + ".")))
 	output := fmt.Sprintf("%s%s\n", formattedListNumber, key.Fingerprint)
 	output += fmt.Sprintf("    Created on %s\n", key.Created.Format("2 January 2006"))
@@ -126,7 +126,7 @@ func printSecretKeyListing(listNumber int, key gpgwrapper.SecretKeyListing) stri
 	return output
 }
 
-func promptForKeyToImportFromGpg(secretKeyListings []gpgwrapper.SecretKeyListing) *gpgwrapper.SecretKeyListing {
+func promptForKeyToImportFromGpg(secretKeyListings []gpgwrapper.KeyListing) *gpgwrapper.KeyListing {
 	var selectedKey int
 	if len(secretKeyListings) == 1 {
 		onlyKey := secretKeyListings[0]
@@ -21,12 +21,12 @@ func (m *mockExportPrivateKey) ExportPrivateKey(fingerprint fingerprint.Fingerpr
 }
 
 type mockImportArmoredKey struct {
-	returnString string
+	returnResult gpgwrapper.ImportResult
 	returnError  error
 }
 
-func (m *mockImportArmoredKey) ImportArmoredKey(armoredKey string) (string, error) {
-	return m.returnString, m.returnError
+func (m *mockImportArmoredKey) ImportArmoredKey(armoredKey string) (gpgwrapper.ImportResult, error) {
+	return m.returnResult, m.returnError
 }
 
 type mockLoadFromArmoredEncryptedPrivateKey struct {
@@ -206,7 +206,7 @@ func TestPushPrivateKeyBackToGpg(t *testing.T) {
 		}
 
 		mockImporter := mockImportArmoredKey{
-			returnString: "",
+			returnResult: gpgwrapper.ImportResult{},
 			returnError:  nil,
 		}
 
@@ -223,7 +223,7 @@ func TestPushPrivateKeyBackToGpg(t *testing.T) {
 		}
 
 		mockImporter := mockImportArmoredKey{
-			returnString: "",
+			returnResult: gpgwrapper.ImportResult{},
 			returnError:  nil,
 		}
 		err := pushPrivateKeyBackToGpg(&mockKey, "[irrelevant]", &mockImporter)
@@ -239,7 +239,7 @@ func TestPushPrivateKeyBackToGpg(t *testing.T) {
 		}
 
 		mockImporter := mockImportArmoredKey{
-			returnString: "",
+			returnResult: gpgwrapper.ImportResult{},
 			returnError:  nil,
 		}
 		err := pushPrivateKeyBackToGpg(&mockKey, "[irrelevant]", &mockImporter)
@@ -255,7 +255,7 @@ func TestPushPrivateKeyBackToGpg(t *testing.T) {
 		}
 
 		mockImporter := mockImportArmoredKey{
-			returnString: "",
+			returnResult: gpgwrapper.ImportResult{},
 			returnError:  fmt.Errorf("some error in ImportedArmoredKey"),
 		}
 		err := pushPrivateKeyBackToGpg(&mockKey, "[irrelevant]", &mockImporter)
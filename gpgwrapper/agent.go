@@ -0,0 +1,183 @@
+// Copyright 2018 Paul Furley and Ian Drysdale
+//
+// This file is part of Fluidkeys Client which makes it simple to use OpenPGP.
+//
+// Fluidkeys Client is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Fluidkeys Client is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with Fluidkeys Client.  If not, see <https://www.gnu.org/licenses/>.
+
+package gpgwrapper
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// EnsureLoopbackAllowed makes sure gpg-agent.conf in the GnuPG home directory
+// contains `allow-loopback-pinentry`, which is required for passing
+// passwords to GnuPG via `--pinentry-mode loopback` without a TTY.
+//
+// If the option is missing it's appended to the file (creating it if
+// necessary) and the agent is told to reload its configuration. Calling it
+// again once the option is present does nothing.
+func (g *GnuPG) EnsureLoopbackAllowed() error {
+	gpgHomeDir, err := g.HomeDir()
+	if err != nil {
+		return fmt.Errorf("error finding GPG home directory: %v", err)
+	}
+
+	agentConfFilename := filepath.Join(gpgHomeDir, "gpg-agent.conf")
+
+	existingConf, err := ioutil.ReadFile(agentConfFilename)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("error reading %s: %v", agentConfFilename, err)
+	}
+
+	if hasAgentOption(string(existingConf), allowLoopbackPinentry) {
+		return nil
+	}
+
+	if err := appendAgentOption(agentConfFilename, string(existingConf), allowLoopbackPinentry); err != nil {
+		if os.IsPermission(err) {
+			return fmt.Errorf("can't add %s to %s, is the directory read-only? %v",
+				allowLoopbackPinentry, agentConfFilename, err)
+		}
+		return fmt.Errorf("error writing %s: %v", agentConfFilename, err)
+	}
+
+	return g.reloadAgent()
+}
+
+// reloadAgent tells a running gpg-agent to re-read its configuration. If no
+// agent is running, the next one started will read the new configuration
+// anyway.
+func (g *GnuPG) reloadAgent() error {
+	ctx, cancel := context.WithTimeout(context.Background(), agentCommandTimeout)
+	defer cancel()
+
+	out, err := g.runConnectAgent(ctx, "reloadagent", "/bye")
+	if err != nil {
+		return fmt.Errorf("error reloading gpg-agent: %v: %s", err, out)
+	}
+	return nil
+}
+
+// WaitForAgent polls gpg-agent (via `gpg-connect-agent /bye`) until it
+// responds or the timeout elapses. If the agent isn't running,
+// gpg-connect-agent starts it. This avoids the first GnuPG operation failing
+// because the agent is still starting, for example on a freshly booted
+// machine.
+//
+// The agent is always tried at least once, even with a zero timeout. Each
+// attempt is killed if it takes longer than agentCommandTimeout.
+func (g *GnuPG) WaitForAgent(timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+
+	for {
+		err := g.pingAgent()
+		if err == nil {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("gpg-agent didn't respond within %s: %v", timeout, err)
+		}
+		time.Sleep(agentPollInterval)
+	}
+}
+
+// pingAgent returns nil if gpg-agent responds to a no-op command. Each
+// attempt gets its own deadline so a hung gpg-connect-agent can't stall
+// WaitForAgent.
+func (g *GnuPG) pingAgent() error {
+	ctx, cancel := context.WithTimeout(context.Background(), agentCommandTimeout)
+	defer cancel()
+
+	out, err := g.runConnectAgent(ctx, "/bye")
+	if err != nil {
+		return fmt.Errorf("%v: %s", err, strings.TrimSpace(out))
+	}
+	// gpg-connect-agent exits 0 even if it couldn't reach the agent
+	if strings.Contains(out, "no gpg-agent running") || strings.Contains(out, "can't connect") {
+		return fmt.Errorf("%s", strings.TrimSpace(out))
+	}
+	return nil
+}
+
+// runConnectAgent runs gpg-connect-agent with the given commands, through
+// the same runner as gpg, and returns its stdout followed by its stderr.
+func (g *GnuPG) runConnectAgent(ctx context.Context, commands ...string) (string, error) {
+	args := []string{}
+	if g.homeDir != "" {
+		args = append(args, "--homedir", g.homeDir)
+	}
+	args = append(args, commands...)
+
+	stdout, stderr, err := g.runBinary(ctx, g.companionBinary("gpg-connect-agent"), nil, nil, args...)
+	return stdout + stderr, err
+}
+
+const (
+	agentPollInterval = 100 * time.Millisecond
+
+	// agentCommandTimeout is how long a single gpg-connect-agent command
+	// may take before it's killed.
+	agentCommandTimeout = 10 * time.Second
+)
+
+// companionBinary returns the path to one of the tools that ships alongside
+// gpg (e.g. gpg-connect-agent), preferring the one in the same directory as
+// the gpg binary we're using.
+func (g *GnuPG) companionBinary(name string) string {
+	if g.fullGpgPath != "" {
+		candidate := filepath.Join(filepath.Dir(g.fullGpgPath), name)
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate
+		}
+	}
+	if fullPath, err := exec.LookPath(name); err == nil {
+		return fullPath
+	}
+	return name
+}
+
+// hasAgentOption returns true if the given option appears on a line of its
+// own (ignoring surrounding whitespace) in the gpg-agent.conf contents.
+func hasAgentOption(agentConf string, option string) bool {
+	for _, line := range strings.Split(agentConf, "\n") {
+		if strings.TrimSpace(line) == option {
+			return true
+		}
+	}
+	return false
+}
+
+func appendAgentOption(filename string, existingConf string, option string) error {
+	f, err := os.OpenFile(filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if existingConf != "" && !strings.HasSuffix(existingConf, "\n") {
+		option = "\n" + option
+	}
+	_, err = f.WriteString(option + "\n")
+	return err
+}
+
+const allowLoopbackPinentry = "allow-loopback-pinentry"
@@ -0,0 +1,125 @@
+package gpgwrapper
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fluidkeys/fluidkeys/assert"
+)
+
+func TestEnsureLoopbackAllowed(t *testing.T) {
+	t.Run("creates gpg-agent.conf if missing", func(t *testing.T) {
+		gpg := makeGpgWithTempHome(t)
+
+		err := gpg.EnsureLoopbackAllowed()
+		assert.ErrorIsNil(t, err)
+
+		assert.Equal(t, "allow-loopback-pinentry\n", readAgentConf(t, gpg.homeDir))
+	})
+
+	t.Run("appends to existing gpg-agent.conf", func(t *testing.T) {
+		gpg := makeGpgWithTempHome(t)
+		writeAgentConf(t, gpg.homeDir, "default-cache-ttl 600")
+
+		err := gpg.EnsureLoopbackAllowed()
+		assert.ErrorIsNil(t, err)
+
+		assert.Equal(t, "default-cache-ttl 600\nallow-loopback-pinentry\n", readAgentConf(t, gpg.homeDir))
+	})
+
+	t.Run("is idempotent", func(t *testing.T) {
+		gpg := makeGpgWithTempHome(t)
+
+		assert.ErrorIsNil(t, gpg.EnsureLoopbackAllowed())
+		assert.ErrorIsNil(t, gpg.EnsureLoopbackAllowed())
+
+		assert.Equal(t, "allow-loopback-pinentry\n", readAgentConf(t, gpg.homeDir))
+	})
+
+	t.Run("returns an error if the home directory is read-only", func(t *testing.T) {
+		if os.Geteuid() == 0 {
+			t.Skip("root can write to read-only directories")
+		}
+		gpg := makeGpgWithTempHome(t)
+		if err := os.Chmod(gpg.homeDir, 0500); err != nil {
+			t.Fatalf("failed to make home directory read-only: %v", err)
+		}
+		defer os.Chmod(gpg.homeDir, 0700)
+
+		assert.ErrorIsNotNil(t, gpg.EnsureLoopbackAllowed())
+	})
+}
+
+func TestWaitForAgent(t *testing.T) {
+	t.Run("starts and waits for the agent", func(t *testing.T) {
+		gpg := makeGpgWithTempHome(t)
+		assert.ErrorIsNil(t, gpg.WaitForAgent(10*time.Second))
+	})
+
+	t.Run("returns an error if the agent never comes up", func(t *testing.T) {
+		gpg := GnuPG{fullGpgPath: "gpg2", homeDir: "/nonexistent/fluidkeys-test"}
+		assert.ErrorIsNotNil(t, gpg.WaitForAgent(200*time.Millisecond))
+	})
+
+	t.Run("runs gpg-connect-agent through the runner", func(t *testing.T) {
+		runner := &fakeRunner{}
+		gpg := makeGpgWithFakeRunner(runner)
+
+		assert.ErrorIsNil(t, gpg.WaitForAgent(0))
+
+		assert.Equal(t, 1, len(runner.calls))
+		assert.Equal(t, []string{"/bye"}, runner.calls[0])
+	})
+
+	t.Run("logs the command and doesn't run it with DryRun", func(t *testing.T) {
+		runner := &fakeRunner{exitCode: 2}
+		gpg := makeGpgWithFakeRunner(runner)
+		gpg.DryRun = true
+		var logged [][]string
+		gpg.CommandLogger = func(args []string) { logged = append(logged, args) }
+
+		assert.ErrorIsNil(t, gpg.WaitForAgent(0))
+
+		assert.Equal(t, 0, len(runner.calls))
+		assert.Equal(t, 1, len(logged))
+		assert.Equal(t, true, strings.HasSuffix(logged[0][0], "gpg-connect-agent"))
+		assert.Equal(t, "/bye", logged[0][len(logged[0])-1])
+	})
+}
+
+func TestHasAgentOption(t *testing.T) {
+	var tests = []struct {
+		agentConf string
+		expected  bool
+	}{
+		{"", false},
+		{"allow-loopback-pinentry", true},
+		{"default-cache-ttl 600\n  allow-loopback-pinentry  \n", true},
+		{"# allow-loopback-pinentry\n", false},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expected, hasAgentOption(test.agentConf, allowLoopbackPinentry))
+	}
+}
+
+func readAgentConf(t *testing.T, homeDir string) string {
+	t.Helper()
+	contents, err := ioutil.ReadFile(filepath.Join(homeDir, "gpg-agent.conf"))
+	if err != nil {
+		t.Fatalf("failed to read gpg-agent.conf: %v", err)
+	}
+	return string(contents)
+}
+
+func writeAgentConf(t *testing.T, homeDir string, contents string) {
+	t.Helper()
+	err := ioutil.WriteFile(filepath.Join(homeDir, "gpg-agent.conf"), []byte(contents), 0600)
+	if err != nil {
+		t.Fatalf("failed to write gpg-agent.conf: %v", err)
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright 2018 Paul Furley and Ian Drysdale
+//
+// This file is part of Fluidkeys Client which makes it simple to use OpenPGP.
+//
+// Fluidkeys Client is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Fluidkeys Client is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with Fluidkeys Client.  If not, see <https://www.gnu.org/licenses/>.
+
+package gpgwrapper
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CleanArmoredBlock extracts the ascii-armored PGP block(s) from text that a
+// user has pasted, for example from an email or a chat message. It:
+//
+//   - normalises Windows and old Mac line endings to \n
+//   - removes email quoting (lines starting "> " or "> > ")
+//   - removes indentation and trailing whitespace from every line
+//   - ignores any text before, after or between the blocks, such as markdown
+//     code fences
+//
+// It returns an error if there's no complete PGP block in the text.
+func CleanArmoredBlock(raw string) (string, error) {
+	normalised := strings.Replace(raw, "\r\n", "\n", -1)
+	normalised = strings.Replace(normalised, "\r", "\n", -1)
+
+	var blocks []string
+	var currentBlock []string
+	inBlock := false
+
+	for _, line := range strings.Split(normalised, "\n") {
+		line = unquoteEmailLine(line)
+
+		if !inBlock && strings.HasPrefix(line, armorBeginPrefix) {
+			inBlock = true
+			currentBlock = nil
+		}
+
+		if inBlock {
+			currentBlock = append(currentBlock, line)
+
+			if strings.HasPrefix(line, armorEndPrefix) {
+				blocks = append(blocks, strings.Join(currentBlock, "\n")+"\n")
+				inBlock = false
+			}
+		}
+	}
+
+	if len(blocks) == 0 {
+		return "", fmt.Errorf("no PGP block found (expected a line starting '%s')", armorBeginPrefix)
+	}
+	return strings.Join(blocks, "\n"), nil
+}
+
+// unquoteEmailLine removes surrounding whitespace and any number of email
+// quote markers from the start of the line. Armored data never starts with
+// `>` so this can't damage a valid block.
+func unquoteEmailLine(line string) string {
+	line = strings.TrimSpace(line)
+	for strings.HasPrefix(line, ">") {
+		line = strings.TrimSpace(strings.TrimPrefix(line, ">"))
+	}
+	return line
+}
+
+const armorBeginPrefix = "-----BEGIN PGP "
+const armorEndPrefix = "-----END PGP "
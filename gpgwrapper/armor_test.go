@@ -0,0 +1,86 @@
+package gpgwrapper
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/fluidkeys/fluidkeys/assert"
+	"github.com/fluidkeys/fluidkeys/exampledata"
+)
+
+func TestCleanArmoredBlock(t *testing.T) {
+	block := "-----BEGIN PGP PUBLIC KEY BLOCK-----\n" +
+		"\n" +
+		"mDMEW5pXzxYJKwYBBAHaRw8BAQdA\n" +
+		"=abcd\n" +
+		"-----END PGP PUBLIC KEY BLOCK-----\n"
+
+	var tests = []struct {
+		name  string
+		input string
+	}{
+		{
+			"already clean",
+			block,
+		},
+		{
+			"with windows line endings",
+			strings.Replace(block, "\n", "\r\n", -1),
+		},
+		{
+			"with trailing whitespace",
+			strings.Replace(block, "\n", "  \n", -1),
+		},
+		{
+			"in a markdown code fence",
+			"Here's my key:\n\n```\n" + block + "```\n",
+		},
+		{
+			"indented as a markdown code block",
+			"    " + strings.Replace(strings.TrimSuffix(block, "\n"), "\n", "\n    ", -1) + "\n",
+		},
+		{
+			"quoted in an email",
+			"On Monday, Alice wrote:\n> " + strings.Replace(strings.TrimSuffix(block, "\n"), "\n", "\n> ", -1) + "\n",
+		},
+		{
+			"quoted twice in an email",
+			"> > " + strings.Replace(strings.TrimSuffix(block, "\n"), "\n", "\n> > ", -1) + "\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := CleanArmoredBlock(test.input)
+			assertNoError(t, err)
+			assert.Equal(t, block, got)
+		})
+	}
+
+	t.Run("with two blocks", func(t *testing.T) {
+		got, err := CleanArmoredBlock(block + "some text in between\n" + block)
+		assertNoError(t, err)
+		assert.Equal(t, block+"\n"+block, got)
+	})
+
+	t.Run("with no PGP block", func(t *testing.T) {
+		_, err := CleanArmoredBlock("hello")
+		assert.ErrorIsNotNil(t, err)
+	})
+
+	t.Run("with an unterminated PGP block", func(t *testing.T) {
+		_, err := CleanArmoredBlock(strings.Replace(block, "-----END PGP PUBLIC KEY BLOCK-----", "", 1))
+		assert.ErrorIsNotNil(t, err)
+	})
+}
+
+func TestImportArmoredKeyFromEmailQuote(t *testing.T) {
+	gpg := makeGpgWithTempHome(t)
+	quoted := "```\r\n> " + strings.Replace(exampledata.ExamplePublicKey4, "\n", "\r\n> ", -1) + "\r\n```\r\n"
+
+	_, err := gpg.ImportArmoredKey(quoted)
+	assertNoError(t, err)
+
+	_, err = gpg.ExportPublicKey(exampledata.ExampleFingerprint4)
+	assertNoError(t, err)
+}
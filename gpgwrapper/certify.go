@@ -0,0 +1,74 @@
+// Copyright 2018 Paul Furley and Ian Drysdale
+//
+// This file is part of Fluidkeys Client which makes it simple to use OpenPGP.
+//
+// Fluidkeys Client is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Fluidkeys Client is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with Fluidkeys Client.  If not, see <https://www.gnu.org/licenses/>.
+
+package gpgwrapper
+
+import (
+	"github.com/fluidkeys/fluidkeys/fingerprint"
+)
+
+// HaveMutuallyCertified returns true if each of the two keys has a valid
+// certification (signature) from the other on at least one of its user IDs.
+// Both keys must be in the keyring.
+//
+// It returns false (and no error) if either key hasn't certified the other.
+func (g *GnuPG) HaveMutuallyCertified(a fingerprint.Fingerprint, b fingerprint.Fingerprint) (bool, error) {
+	aCertifiedB, err := g.hasCertified(a, b)
+	if err != nil {
+		return false, err
+	}
+	if !aCertifiedB {
+		return false, nil
+	}
+
+	return g.hasCertified(b, a)
+}
+
+// hasCertified returns true if signer has made a valid certification on
+// one of signee's user IDs.
+func (g *GnuPG) hasCertified(signer fingerprint.Fingerprint, signee fingerprint.Fingerprint) (bool, error) {
+	key, err := g.getKeyListing(signee, "--check-sigs")
+	if err != nil {
+		return false, err
+	}
+
+	for _, issuer := range uidCertifiers(*key) {
+		if issuer == signer {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// uidCertifiers takes a key listed by `gpg --check-sigs` and returns the
+// fingerprints of the keys which made valid signatures on its user IDs
+// (including the key itself, for self signatures).
+//
+// Signatures on subkeys (binding signatures) are ignored.
+func uidCertifiers(key KeyListing) []fingerprint.Fingerprint {
+	var issuers []fingerprint.Fingerprint
+
+	for _, signature := range key.Signatures {
+		if signature.Uid == "" || signature.Revocation || !signature.Good {
+			continue
+		}
+		if signature.IssuerFingerprint.IsSet() {
+			issuers = append(issuers, signature.IssuerFingerprint)
+		}
+	}
+	return issuers
+}
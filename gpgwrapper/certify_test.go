@@ -0,0 +1,108 @@
+package gpgwrapper
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/fluidkeys/fluidkeys/assert"
+	"github.com/fluidkeys/fluidkeys/fingerprint"
+)
+
+func TestHaveMutuallyCertified(t *testing.T) {
+	gpg := makeGpgWithTempHome(t)
+	for _, armoredKey := range []string{exampleAlicePublicKey, exampleBobPublicKey, exampleCarolPublicKey} {
+		_, err := gpg.ImportArmoredKey(armoredKey)
+		assertNoError(t, err)
+	}
+
+	var tests = []struct {
+		name     string
+		a        fingerprint.Fingerprint
+		b        fingerprint.Fingerprint
+		expected bool
+	}{
+		{"alice and bob certified each other", exampleAliceFingerprint, exampleBobFingerprint, true},
+		{"bob and alice certified each other", exampleBobFingerprint, exampleAliceFingerprint, true},
+		{"alice certified carol but not vice versa", exampleAliceFingerprint, exampleCarolFingerprint, false},
+		{"carol hasn't certified alice", exampleCarolFingerprint, exampleAliceFingerprint, false},
+		{"bob and carol haven't certified each other", exampleBobFingerprint, exampleCarolFingerprint, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := gpg.HaveMutuallyCertified(test.a, test.b)
+			assertNoError(t, err)
+			assert.Equal(t, test.expected, got)
+		})
+	}
+
+	t.Run("with a key that's not in the keyring", func(t *testing.T) {
+		unknown := fingerprint.MustParse("0000 0000 0000 0000 0000 0000 0000 0000 0000 0000")
+		_, err := gpg.HaveMutuallyCertified(exampleAliceFingerprint, unknown)
+		assert.ErrorIsNotNil(t, err)
+	})
+}
+
+func TestUidCertifiers(t *testing.T) {
+	colonOutput := `pub:u:255:22:765354F03E8A421D:1792143051:::u:::scSC:::::ed25519:::0:
+fpr:::::::::8AADA686735E9B66FBD190AC765354F03E8A421D:
+uid:u::::1792143051::8584F67CD949FA2AE8DDC42E4B29A1BE2888DA32::alice@example.com::::::::::0:
+sig:!::22:765354F03E8A421D:1792143051::::alice@example.com:13x::8AADA686735E9B66FBD190AC765354F03E8A421D:::8:
+sig:!::22:CC84DE91352C5306:1792143054::::bob@example.com:10x::728A2A87FD8D05035A97678FCC84DE91352C5306:::8:
+sig:-::22:7538C71522249BEB:1792143054::::carol@example.com:10x::59919DD3C8C7C1634AD3650D7538C71522249BEB:::8:
+sub:u:255:18:AAAAAAAAAAAAAAAA:1792143051::::::e:::::cv25519::
+sig:!::22:0000000000000000:1792143051::::unrelated:18x::0000000000000000000000000000000000000000:::8:`
+
+	keys, err := parseColonDelimitedKeys(strings.NewReader(colonOutput))
+	assertNoError(t, err)
+
+	expected := []fingerprint.Fingerprint{exampleAliceFingerprint, exampleBobFingerprint}
+	got := uidCertifiers(keys[0])
+
+	assert.Equal(t, expected, got)
+}
+
+var exampleAliceFingerprint = fingerprint.MustParse("8AADA686735E9B66FBD190AC765354F03E8A421D")
+var exampleBobFingerprint = fingerprint.MustParse("728A2A87FD8D05035A97678FCC84DE91352C5306")
+var exampleCarolFingerprint = fingerprint.MustParse("59919DD3C8C7C1634AD3650D7538C71522249BEB")
+
+// exampleAlicePublicKey has been certified by bob
+const exampleAlicePublicKey = `-----BEGIN PGP PUBLIC KEY BLOCK-----
+
+mDMEatHuyxYJKwYBBAHaRw8BAQdAkjHXQ6MvkNjdqNOIU5DZ2plgO35h0536D6Hq
+XJ2gyNS0EWFsaWNlQGV4YW1wbGUuY29tiJAEExYIADgWIQSKraaGc16bZvvRkKx2
+U1TwPopCHQUCatHuywIbAwULCQgHAgYVCgkICwIEFgIDAQIeAQIXgAAKCRB2U1Tw
+PopCHeN3AQDZUyKIgYd6zUj0EvYJo/8++tCANgS3ThIXhNsOstwChgEA0qUv2jIu
+WDyTUpvWLBje7cnOVnzkvvMSrpkS6UGa0wyIdQQQFggAHRYhBHKKKof9jQUDWpdn
+j8yE3pE1LFMGBQJq0e7OAAoJEMyE3pE1LFMGo4cA/3BeT17YPCMTk9n6oid75mJF
+4jBYFBC/6tMBfKf94wiGAQDC2KMsEZ870ZGdPn6xZASajWXKWy71X0Y8Rb2KYB/X
+AQ==
+=l+6G
+-----END PGP PUBLIC KEY BLOCK-----`
+
+// exampleBobPublicKey has been certified by alice
+const exampleBobPublicKey = `-----BEGIN PGP PUBLIC KEY BLOCK-----
+
+mDMEatHuyxYJKwYBBAHaRw8BAQdA1Zps1XQs+yA7vPyGsmzvCdgrKr6ucRnbhgc+
+B16JOYW0D2JvYkBleGFtcGxlLmNvbYiQBBMWCAA4FiEEcooqh/2NBQNal2ePzITe
+kTUsUwYFAmrR7ssCGwMFCwkIBwIGFQoJCAsCBBYCAwECHgECF4AACgkQzITekTUs
+UwZclgEA6bnSUhPkAyr/aT1Ad8Yh4oatWvDodc0rT+nxKO8ZQW4BAIregC+RV9iv
+lL7nTt03BNeRTrnwcH9j6ln5N4g2m24EiHUEEBYIAB0WIQSKraaGc16bZvvRkKx2
+U1TwPopCHQUCatHuzgAKCRB2U1TwPopCHZGWAP9Izca6nkxmh90IA9bTgmY1g+4O
+ia/Yj2BlObc25kqokAEA0UsLWui/LGOmOZuhYZmc010eMaBI7qd6zvXrLsE7/gE=
+=Jpv6
+-----END PGP PUBLIC KEY BLOCK-----`
+
+// exampleCarolPublicKey has been certified by alice
+const exampleCarolPublicKey = `-----BEGIN PGP PUBLIC KEY BLOCK-----
+
+mDMEatHuyxYJKwYBBAHaRw8BAQdALwtyfleqayFcf9D5p5A+hMI/ktzWCM1t7WMf
+gevi/7e0EWNhcm9sQGV4YW1wbGUuY29tiJAEExYIADgWIQRZkZ3TyMfBY0rTZQ11
+OMcVIiSb6wUCatHuywIbAwULCQgHAgYVCgkICwIEFgIDAQIeAQIXgAAKCRB1OMcV
+IiSb63GBAPwILn95UtI956fv9VJZKVQ9MvFg7KSRo6rMG6QxVyjYsAD/eZ56YuFS
+Nx83Se34Kb7gmeFidJFADoeqj1kXvdnAKAWIdQQQFggAHRYhBIqtpoZzXptm+9GQ
+rHZTVPA+ikIdBQJq0e7OAAoJEHZTVPA+ikId5vUBAL9ZKPd/aE8xKw/yX8WVzl2I
+bGlxzXnhvsrGhoypW31bAQCvifM1fVNCYh5llFaHG2uG4TlnH+2wr4YHMisngLMa
+AA==
+=TWYs
+-----END PGP PUBLIC KEY BLOCK-----`
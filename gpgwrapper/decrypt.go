@@ -0,0 +1,95 @@
+// Copyright 2018 Paul Furley and Ian Drysdale
+//
+// This file is part of Fluidkeys Client which makes it simple to use OpenPGP.
+//
+// Fluidkeys Client is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Fluidkeys Client is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with Fluidkeys Client.  If not, see <https://www.gnu.org/licenses/>.
+
+package gpgwrapper
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// MessageRecipients returns the key IDs (e.g. "0xCE7881186F55FA9E") that the
+// given encrypted message was encrypted to, without decrypting it. Callers
+// can use this to check which of their keys is needed before prompting for
+// a password.
+//
+// The key IDs are usually of encryption subkeys rather than primary keys.
+// Recipients hidden with --throw-keyids are returned as "0x0000000000000000".
+func (g *GnuPG) MessageRecipients(ciphertext string) ([]string, error) {
+	stdout, stderr, err := g.runWithStdin(
+		ciphertext,
+		"--status-fd", "1",
+		"--list-only", // list the recipients, don't decrypt
+		"--decrypt",
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error listing message recipients: %v: %s", err, stderr)
+	}
+
+	keyIds := parseEncToStatus(stdout)
+	if len(keyIds) == 0 {
+		return nil, fmt.Errorf("message isn't encrypted to any keys")
+	}
+	return keyIds, nil
+}
+
+// parseEncToStatus returns the key IDs from the ENC_TO lines of gpg's
+// status output, for example:
+// [GNUPG:] ENC_TO CE7881186F55FA9E 1 0
+func parseEncToStatus(statusOutput string) []string {
+	keyIds := []string{}
+
+	for _, line := range strings.Split(statusOutput, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 3 || fields[0] != "[GNUPG:]" || fields[1] != "ENC_TO" {
+			continue
+		}
+		keyIds = append(keyIds, "0x"+strings.ToUpper(fields[2]))
+	}
+	return keyIds
+}
+
+// DecryptMessage decrypts the ciphertext (ascii-armored or binary) and
+// returns the plaintext. The passphrase unlocks the secret key and is passed
+// to gpg over a pipe, so it never appears in the process's argument list.
+//
+// It returns ErrBadPassphrase if the passphrase is wrong, or ErrNoSecretKey
+// if none of the message's recipients have a secret key in the keyring.
+func (g *GnuPG) DecryptMessage(ciphertext string, passphrase string) (string, error) {
+	passphraseReader, err := makePassphrasePipe(passphrase)
+	if err != nil {
+		return "", err
+	}
+	defer passphraseReader.Close()
+
+	stdout, stderr, err := g.runCommand(
+		context.Background(),
+		strings.NewReader(ciphertext),
+		[]*os.File{passphraseReader},
+		"--pinentry-mode", "loopback", // don't use OS password prompt
+		"--passphrase-fd", "3", // the first of the extra files
+		"--decrypt",
+	)
+	if isSecretKeyError(err) {
+		return "", err
+	} else if err != nil {
+		return "", fmt.Errorf("error decrypting message: %v: %s", err, stderr)
+	}
+	return stdout, nil
+}
@@ -0,0 +1,74 @@
+package gpgwrapper
+
+import (
+	"testing"
+
+	"github.com/fluidkeys/fluidkeys/assert"
+	"github.com/fluidkeys/fluidkeys/exampledata"
+	"github.com/fluidkeys/fluidkeys/fingerprint"
+)
+
+func TestMessageRecipients(t *testing.T) {
+	gpg := makeGpgWithTempHome(t)
+	_, err := gpg.ImportArmoredKey(exampledata.ExamplePublicKey4)
+	assertNoError(t, err)
+
+	t.Run("with a message encrypted to key 4", func(t *testing.T) {
+		ciphertext, err := gpg.EncryptMessage("hello", []fingerprint.Fingerprint{exampledata.ExampleFingerprint4}, EncryptOptions{})
+		assertNoError(t, err)
+
+		// a separate homedir checks we don't need the key to list recipients
+		otherGpg := makeGpgWithTempHome(t)
+		got, err := otherGpg.MessageRecipients(ciphertext)
+		assertNoError(t, err)
+		assert.Equal(t, []string{"0xCE7881186F55FA9E"}, got)
+	})
+
+	t.Run("with something that isn't a message", func(t *testing.T) {
+		_, err := gpg.MessageRecipients("garbage")
+		assert.ErrorIsNotNil(t, err)
+	})
+}
+
+func TestDecryptMessage(t *testing.T) {
+	gpg := makeGpgWithTempHome(t)
+	_, err := gpg.ImportArmoredKey(exampledata.ExamplePublicKey4)
+	assertNoError(t, err)
+	_, err = gpg.ImportArmoredKey(exampledata.ExamplePrivateKey4)
+	assertNoError(t, err)
+
+	ciphertext, err := gpg.EncryptMessage("hello", []fingerprint.Fingerprint{exampledata.ExampleFingerprint4}, EncryptOptions{})
+	assertNoError(t, err)
+
+	// gpg-agent caches the passphrase once it's been used, so try the
+	// wrong one first
+	t.Run("with the wrong passphrase", func(t *testing.T) {
+		_, err := gpg.DecryptMessage(ciphertext, "wrong passphrase")
+		if _, ok := err.(*BadPasswordError); !ok {
+			t.Fatalf("expected ErrBadPassphrase, got %v", err)
+		}
+	})
+
+	t.Run("with the right passphrase", func(t *testing.T) {
+		plaintext, err := gpg.DecryptMessage(ciphertext, "test4")
+		assertNoError(t, err)
+		assert.Equal(t, "hello", plaintext)
+	})
+
+	t.Run("without the secret key", func(t *testing.T) {
+		otherGpg := makeGpgWithTempHome(t)
+		_, err := otherGpg.DecryptMessage(ciphertext, "test4")
+		if err != ErrNoSecretKey {
+			t.Fatalf("expected ErrNoSecretKey, got %v", err)
+		}
+	})
+}
+
+func TestParseEncToStatus(t *testing.T) {
+	status := "[GNUPG:] ENC_TO 9769C9E8732F89A4 1 0\n" +
+		"[GNUPG:] ENC_TO CE7881186F55FA9E 1 0\n" +
+		"[GNUPG:] NO_SECKEY CE7881186F55FA9E\n" +
+		"[GNUPG:] BEGIN_DECRYPTION\n"
+
+	assert.Equal(t, []string{"0x9769C9E8732F89A4", "0xCE7881186F55FA9E"}, parseEncToStatus(status))
+}
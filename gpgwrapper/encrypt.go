@@ -0,0 +1,254 @@
+// Copyright 2018 Paul Furley and Ian Drysdale
+//
+// This file is part of Fluidkeys Client which makes it simple to use OpenPGP.
+//
+// Fluidkeys Client is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Fluidkeys Client is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with Fluidkeys Client.  If not, see <https://www.gnu.org/licenses/>.
+
+package gpgwrapper
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/fluidkeys/crypto/openpgp/armor"
+	"github.com/fluidkeys/crypto/openpgp/packet"
+	"github.com/fluidkeys/fluidkeys/fingerprint"
+	"github.com/fluidkeys/fluidkeys/openpgpdefs/symmetric"
+	"github.com/fluidkeys/fluidkeys/pgpkey"
+	"github.com/fluidkeys/fluidkeys/policy"
+)
+
+// TrustModel controls how GnuPG decides whether a recipient's key is valid
+// (i.e. really belongs to the person named on it) before encrypting to it.
+// See `--trust-model` in `man gpg`.
+type TrustModel string
+
+const (
+	// TrustModelAlways skips key validation entirely: any key in the keyring
+	// can be encrypted to. This is what Fluidkeys uses by default, since it
+	// manages which keys are in the keyring itself rather than relying on
+	// signatures from other keys. It offers no protection against an
+	// attacker who manages to get a key into the keyring.
+	TrustModelAlways TrustModel = "always"
+
+	// TrustModelPgp uses the web of trust: a key must be signed by the
+	// user, or by enough keys they trust, to be encrypted to. This is the
+	// safest, but recipients will be refused until someone has certified
+	// their key.
+	TrustModelPgp TrustModel = "pgp"
+
+	// TrustModelTofu ("trust on first use") accepts the first key seen for
+	// an email address and warns if a different key appears later. It
+	// protects against keys being swapped, but not against the first key
+	// being wrong.
+	TrustModelTofu TrustModel = "tofu"
+
+	// TrustModelDirect only accepts keys whose owner trust has been set
+	// directly by the user, ignoring signatures from other keys.
+	TrustModelDirect TrustModel = "direct"
+)
+
+// EncryptOptions modify the behaviour of EncryptMessage. The zero value
+// gives the default behaviour.
+type EncryptOptions struct {
+	// TrustModel is passed to GnuPG as `--trust-model`. If empty,
+	// TrustModelAlways is used.
+	TrustModel TrustModel
+
+	// RequireStrongCipher refuses to encrypt unless every recipient's key
+	// advertises policy.StrongSymmetricCipher. Without it, a single
+	// recipient with old preferences can silently downgrade the whole
+	// message to a weak cipher like TripleDES.
+	RequireStrongCipher bool
+
+	// Binary outputs the message as raw OpenPGP packets rather than
+	// ascii-armored.
+	Binary bool
+}
+
+// EncryptResult is the outcome of Encrypt.
+type EncryptResult struct {
+	// Ciphertext is the PGP message, ascii-armored unless
+	// EncryptOptions.Binary was set.
+	Ciphertext string
+
+	// RecipientKeyIds are the IDs of the keys (usually encryption subkeys)
+	// that the message was actually encrypted to, in the order they appear
+	// in the message.
+	RecipientKeyIds []uint64
+}
+
+// EncryptMessage encrypts the plaintext to the given recipients and returns
+// an ascii-armored PGP message (or a binary one, see EncryptOptions.Binary).
+// If any recipient's key isn't in the keyring, it returns ErrKeyNotFound.
+func (g *GnuPG) EncryptMessage(plaintext string, recipients []fingerprint.Fingerprint, options EncryptOptions) (string, error) {
+	result, err := g.Encrypt(plaintext, recipients, options)
+	if err != nil {
+		return "", err
+	}
+	return result.Ciphertext, nil
+}
+
+// Encrypt is like EncryptMessage, but also returns the key IDs the message
+// was actually encrypted to, so the caller can check every intended
+// recipient was covered (GnuPG picks which subkey to use for each
+// recipient).
+//
+// GnuPG only reports the keys a message is encrypted to (as ENC_TO, see
+// parseEncToStatus) when decrypting, so here they're read from the
+// public-key encrypted session key packets in the ciphertext.
+func (g *GnuPG) Encrypt(plaintext string, recipients []fingerprint.Fingerprint, options EncryptOptions) (EncryptResult, error) {
+	if len(recipients) == 0 {
+		return EncryptResult{}, fmt.Errorf("no recipients given")
+	}
+
+	args, err := getArgsEncrypt(recipients, options)
+	if err != nil {
+		return EncryptResult{}, err
+	}
+
+	if options.RequireStrongCipher {
+		if err := g.checkRecipientsAdvertiseCipher(recipients, policy.StrongSymmetricCipher); err != nil {
+			return EncryptResult{}, err
+		}
+	}
+
+	stdout, stderr, err := g.runWithStdin(plaintext, args...)
+	if err != nil {
+		if _, ok := err.(*ErrKeyNotFound); ok {
+			return EncryptResult{}, &ErrKeyNotFound{Fingerprint: findSkippedRecipient(stderr, recipients)}
+		}
+		return EncryptResult{}, fmt.Errorf("problem encrypting message, %v: %s", err, stderr)
+	}
+
+	var keyIds []uint64
+	if options.Binary {
+		keyIds, err = readRecipientKeyIds(strings.NewReader(stdout))
+	} else {
+		if !strings.Contains(stdout, messageHeader) {
+			return EncryptResult{}, fmt.Errorf("GnuPG didn't output an encrypted message: %s", stderr)
+		}
+		keyIds, err = parseRecipientKeyIds(stdout)
+	}
+	if err != nil {
+		return EncryptResult{}, fmt.Errorf("failed to read recipients of encrypted message: %v", err)
+	}
+
+	return EncryptResult{Ciphertext: stdout, RecipientKeyIds: keyIds}, nil
+}
+
+// findSkippedRecipient returns the recipient GnuPG skipped for having no
+// public key, from a line like
+// gpg: BB3C44BF188D56E635F4A092F73D2F0533D7F9D6: skipped: No public key
+// If none of the recipients are named, it returns an unset fingerprint.
+func findSkippedRecipient(stderr string, recipients []fingerprint.Fingerprint) fingerprint.Fingerprint {
+	for _, recipient := range recipients {
+		if strings.Contains(stderr, recipient.Hex()+": skipped") {
+			return recipient
+		}
+	}
+	return fingerprint.Fingerprint{}
+}
+
+// parseRecipientKeyIds returns the key IDs from the public-key encrypted
+// session key packets at the start of an ascii-armored PGP message.
+func parseRecipientKeyIds(armoredMessage string) ([]uint64, error) {
+	block, err := armor.Decode(strings.NewReader(armoredMessage))
+	if err != nil {
+		return nil, err
+	}
+	return readRecipientKeyIds(block.Body)
+}
+
+// readRecipientKeyIds is like parseRecipientKeyIds for a binary message.
+func readRecipientKeyIds(message io.Reader) ([]uint64, error) {
+	var keyIds []uint64
+	packets := packet.NewReader(message)
+
+	for {
+		p, err := packets.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		encryptedKey, ok := p.(*packet.EncryptedKey)
+		if !ok {
+			break // session keys always come first, so we're done
+		}
+		keyIds = append(keyIds, encryptedKey.KeyId)
+	}
+
+	if len(keyIds) == 0 {
+		return nil, fmt.Errorf("message isn't encrypted to any keys")
+	}
+	return keyIds, nil
+}
+
+// checkRecipientsAdvertiseCipher returns an error listing any recipients
+// whose keys don't advertise the given cipher in their preferences.
+func (g *GnuPG) checkRecipientsAdvertiseCipher(recipients []fingerprint.Fingerprint, cipher symmetric.SymmetricAlgorithm) error {
+	var weakRecipients []string
+
+	for _, recipient := range recipients {
+		armoredKey, err := g.ExportPublicKey(recipient)
+		if _, ok := err.(*ErrKeyNotFound); ok {
+			return err
+		} else if err != nil {
+			return fmt.Errorf("failed to export key for %s: %v", recipient, err)
+		}
+
+		key, err := pgpkey.LoadFromArmoredPublicKey(armoredKey)
+		if err != nil {
+			return fmt.Errorf("failed to load key for %s: %v", recipient, err)
+		}
+
+		if !key.AdvertisesCipher(cipher) {
+			weakRecipients = append(weakRecipients, recipient.String())
+		}
+	}
+
+	if len(weakRecipients) > 0 {
+		return fmt.Errorf("recipients would force a cipher weaker than %s: %s",
+			symmetric.Name(cipher), strings.Join(weakRecipients, ", "))
+	}
+	return nil
+}
+
+func getArgsEncrypt(recipients []fingerprint.Fingerprint, options EncryptOptions) ([]string, error) {
+	trustModel := options.TrustModel
+	if trustModel == "" {
+		trustModel = TrustModelAlways
+	}
+
+	switch trustModel {
+	case TrustModelAlways, TrustModelPgp, TrustModelTofu, TrustModelDirect:
+	default:
+		return nil, fmt.Errorf("unknown trust model '%s'", trustModel)
+	}
+
+	args := []string{"--trust-model", string(trustModel)}
+	if !options.Binary {
+		args = append(args, "--armor")
+	}
+	args = append(args, "--encrypt")
+	for _, recipient := range recipients {
+		args = append(args, "--recipient", recipient.Hex())
+	}
+	return args, nil
+}
+
+const messageHeader = "-----BEGIN PGP MESSAGE-----"
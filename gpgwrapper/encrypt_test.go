@@ -0,0 +1,223 @@
+package gpgwrapper
+
+import (
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fluidkeys/crypto/openpgp"
+	"github.com/fluidkeys/crypto/openpgp/armor"
+	"github.com/fluidkeys/fluidkeys/assert"
+	"github.com/fluidkeys/fluidkeys/exampledata"
+	"github.com/fluidkeys/fluidkeys/fingerprint"
+	"github.com/fluidkeys/fluidkeys/openpgpdefs/symmetric"
+	"github.com/fluidkeys/fluidkeys/pgpkey"
+)
+
+func TestEncryptMessage(t *testing.T) {
+	gpg := makeGpgWithTempHome(t)
+	_, err := gpg.ImportArmoredKey(exampledata.ExamplePublicKey4)
+	assertNoError(t, err)
+
+	recipients := []fingerprint.Fingerprint{exampledata.ExampleFingerprint4}
+
+	t.Run("with default options", func(t *testing.T) {
+		ciphertext, err := gpg.EncryptMessage("hello", recipients, EncryptOptions{})
+		assertNoError(t, err)
+
+		if !strings.HasPrefix(ciphertext, messageHeader) {
+			t.Fatalf("expected ascii-armored message, got '%s'", ciphertext)
+		}
+	})
+
+	t.Run("with pgp trust model and an uncertified recipient", func(t *testing.T) {
+		_, err := gpg.EncryptMessage("hello", recipients, EncryptOptions{TrustModel: TrustModelPgp})
+		assert.ErrorIsNotNil(t, err)
+	})
+
+	t.Run("with an unknown trust model", func(t *testing.T) {
+		_, err := gpg.EncryptMessage("hello", recipients, EncryptOptions{TrustModel: "foo"})
+		assert.ErrorIsNotNil(t, err)
+	})
+
+	t.Run("with no recipients", func(t *testing.T) {
+		_, err := gpg.EncryptMessage("hello", nil, EncryptOptions{})
+		assert.ErrorIsNotNil(t, err)
+	})
+
+	t.Run("with a recipient that isn't in the keyring", func(t *testing.T) {
+		unknown := []fingerprint.Fingerprint{exampledata.ExampleFingerprint4, exampledata.ExampleFingerprint2}
+		_, err := gpg.EncryptMessage("hello", unknown, EncryptOptions{})
+		assert.Equal(t, &ErrKeyNotFound{Fingerprint: exampledata.ExampleFingerprint2}, err)
+	})
+
+	t.Run("round trips when armored", func(t *testing.T) {
+		ciphertext, err := gpg.EncryptMessage("hello", recipients, EncryptOptions{})
+		assertNoError(t, err)
+
+		block, err := armor.Decode(strings.NewReader(ciphertext))
+		assertNoError(t, err)
+		assert.Equal(t, "hello", decryptWithKey4(t, block.Body))
+	})
+
+	t.Run("round trips when binary", func(t *testing.T) {
+		ciphertext, err := gpg.EncryptMessage("hello", recipients, EncryptOptions{Binary: true})
+		assertNoError(t, err)
+
+		if strings.Contains(ciphertext, messageHeader) {
+			t.Fatalf("expected binary message, got ascii-armored")
+		}
+		assert.Equal(t, "hello", decryptWithKey4(t, strings.NewReader(ciphertext)))
+	})
+}
+
+// decryptWithKey4 decrypts the binary message with example key 4.
+func decryptWithKey4(t *testing.T, message io.Reader) string {
+	t.Helper()
+	key, err := pgpkey.LoadFromArmoredEncryptedPrivateKey(exampledata.ExamplePrivateKey4, "test4")
+	assertNoError(t, err)
+
+	messageDetails, err := openpgp.ReadMessage(message, openpgp.EntityList{&key.Entity}, nil, nil)
+	assertNoError(t, err)
+
+	plaintext, err := ioutil.ReadAll(messageDetails.UnverifiedBody)
+	assertNoError(t, err)
+	return string(plaintext)
+}
+
+func TestEncrypt(t *testing.T) {
+	gpg := makeGpgWithTempHome(t)
+	_, err := gpg.ImportArmoredKey(exampledata.ExamplePublicKey2)
+	assertNoError(t, err)
+	_, err = gpg.ImportArmoredKey(exampledata.ExamplePublicKey4)
+	assertNoError(t, err)
+
+	t.Run("returns the encryption subkeys used", func(t *testing.T) {
+		recipients := []fingerprint.Fingerprint{
+			exampledata.ExampleFingerprint2,
+			exampledata.ExampleFingerprint4,
+		}
+		result, err := gpg.Encrypt("hello", recipients, EncryptOptions{})
+		assertNoError(t, err)
+
+		if !strings.HasPrefix(result.Ciphertext, messageHeader) {
+			t.Fatalf("expected ascii-armored message, got '%s'", result.Ciphertext)
+		}
+
+		gotKeyIds := map[uint64]bool{}
+		for _, keyId := range result.RecipientKeyIds {
+			gotKeyIds[keyId] = true
+		}
+		expectedKeyIds := map[uint64]bool{
+			0xA810C52C47D52528: true, // key 2's encryption subkey
+			0xCE7881186F55FA9E: true, // key 4's encryption subkey
+		}
+		assert.Equal(t, expectedKeyIds, gotKeyIds)
+	})
+
+	t.Run("with a recipient not in the keyring", func(t *testing.T) {
+		recipients := []fingerprint.Fingerprint{exampledata.ExampleFingerprint3}
+		_, err := gpg.Encrypt("hello", recipients, EncryptOptions{})
+		assert.ErrorIsNotNil(t, err)
+	})
+}
+
+func TestParseRecipientKeyIds(t *testing.T) {
+	t.Run("with something that isn't a message", func(t *testing.T) {
+		_, err := parseRecipientKeyIds("hello")
+		assert.ErrorIsNotNil(t, err)
+	})
+
+	t.Run("with a signature rather than an encrypted message", func(t *testing.T) {
+		_, err := parseRecipientKeyIds(exampleAliceSignature)
+		assert.ErrorIsNotNil(t, err)
+	})
+}
+
+func TestEncryptMessageRequireStrongCipher(t *testing.T) {
+	recipients := []fingerprint.Fingerprint{exampledata.ExampleFingerprint4}
+	options := EncryptOptions{RequireStrongCipher: true}
+
+	t.Run("with a recipient advertising AES256", func(t *testing.T) {
+		gpg := makeGpgWithTempHome(t)
+		_, err := gpg.ImportArmoredKey(exampledata.ExamplePublicKey4)
+		assertNoError(t, err)
+
+		_, err = gpg.EncryptMessage("hello", recipients, options)
+		assertNoError(t, err)
+	})
+
+	t.Run("with a recipient only advertising weak ciphers", func(t *testing.T) {
+		gpg := makeGpgWithTempHome(t)
+		_, err := gpg.ImportArmoredKey(makeKey4WithCipherPreferences(t, symmetric.CAST5, symmetric.TripleDES))
+		assertNoError(t, err)
+
+		_, err = gpg.EncryptMessage("hello", recipients, options)
+		assert.ErrorIsNotNil(t, err)
+		assert.Equal(t,
+			"recipients would force a cipher weaker than AES256: "+exampledata.ExampleFingerprint4.String(),
+			err.Error())
+
+		t.Run("encrypts anyway without the option", func(t *testing.T) {
+			_, err = gpg.EncryptMessage("hello", recipients, EncryptOptions{})
+			assertNoError(t, err)
+		})
+	})
+
+	t.Run("with a recipient that isn't in the keyring", func(t *testing.T) {
+		gpg := makeGpgWithTempHome(t)
+
+		_, err := gpg.EncryptMessage("hello", recipients, options)
+		assert.Equal(t, &ErrKeyNotFound{Fingerprint: exampledata.ExampleFingerprint4}, err)
+	})
+}
+
+func makeKey4WithCipherPreferences(t *testing.T, ciphers ...symmetric.SymmetricAlgorithm) string {
+	t.Helper()
+	key, err := pgpkey.LoadFromArmoredEncryptedPrivateKey(exampledata.ExamplePrivateKey4, "test4")
+	assertNoError(t, err)
+
+	err = key.SetPreferredSymmetricAlgorithms(ciphers, time.Now())
+	assertNoError(t, err)
+
+	armoredKey, err := key.Armor()
+	assertNoError(t, err)
+	return armoredKey
+}
+
+func TestGetArgsEncrypt(t *testing.T) {
+	recipients := []fingerprint.Fingerprint{exampledata.ExampleFingerprint4}
+
+	t.Run("defaults to trust model always", func(t *testing.T) {
+		args, err := getArgsEncrypt(recipients, EncryptOptions{})
+		assertNoError(t, err)
+
+		assert.AssertEqualSliceOfStrings(t, []string{
+			"--trust-model", "always",
+			"--armor",
+			"--encrypt",
+			"--recipient", "BB3C44BF188D56E635F4A092F73D2F0533D7F9D6",
+		}, args)
+	})
+
+	for _, trustModel := range []TrustModel{TrustModelPgp, TrustModelTofu, TrustModelDirect} {
+		t.Run("passes through trust model "+string(trustModel), func(t *testing.T) {
+			args, err := getArgsEncrypt(recipients, EncryptOptions{TrustModel: trustModel})
+			assertNoError(t, err)
+			assert.Equal(t, string(trustModel), args[1])
+		})
+	}
+
+	t.Run("leaves out --armor for binary output", func(t *testing.T) {
+		args, err := getArgsEncrypt(recipients, EncryptOptions{Binary: true})
+		assertNoError(t, err)
+
+		assert.AssertEqualSliceOfStrings(t, []string{
+			"--trust-model", "always",
+			"--encrypt",
+			"--recipient", "BB3C44BF188D56E635F4A092F73D2F0533D7F9D6",
+		}, args)
+	})
+}
@@ -17,7 +17,217 @@
 
 package gpgwrapper
 
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+
+	"github.com/fluidkeys/fluidkeys/fingerprint"
+)
+
+// ErrGpgNotInstalled is returned when the gpg binary can't be found.
+var ErrGpgNotInstalled = errors.New("gpg isn't installed")
+
+// ErrNoSecretKey is returned when GnuPG needs a secret key that isn't in
+// the keyring, for example to decrypt or sign.
+var ErrNoSecretKey = errors.New("secret key not available")
+
+// ErrBadPassphrase is the BadPasswordError returned when a passphrase is
+// wrong. Check for it with a type assertion to *BadPasswordError.
+var ErrBadPassphrase error = &BadPasswordError{}
+
 type BadPasswordError struct {
 }
 
 func (e *BadPasswordError) Error() string { return "bad password" }
+
+// Is returns true if target is also a BadPasswordError, such as
+// ErrBadPassphrase.
+func (e *BadPasswordError) Is(target error) bool {
+	_, ok := target.(*BadPasswordError)
+	return ok
+}
+
+// ErrKeyNotFound is returned when GnuPG has no key in its keyring matching
+// the given fingerprint. The fingerprint isn't set if GnuPG didn't say which
+// key it was looking for.
+type ErrKeyNotFound struct {
+	Fingerprint fingerprint.Fingerprint
+}
+
+func (e *ErrKeyNotFound) Error() string {
+	if !e.Fingerprint.IsSet() {
+		return "key not found in GnuPG"
+	}
+	return fmt.Sprintf("no key found in GnuPG with fingerprint %s", e.Fingerprint)
+}
+
+// Is returns true if target is also an ErrKeyNotFound. If the target has a
+// fingerprint, the fingerprints must match too.
+func (e *ErrKeyNotFound) Is(target error) bool {
+	t, ok := target.(*ErrKeyNotFound)
+	if !ok {
+		return false
+	}
+	return !t.Fingerprint.IsSet() || t.Fingerprint == e.Fingerprint
+}
+
+// ErrSubkeyNotFound is returned when the key with the given fingerprint
+// doesn't have the given subkey.
+type ErrSubkeyNotFound struct {
+	Fingerprint       fingerprint.Fingerprint
+	SubkeyFingerprint fingerprint.Fingerprint
+}
+
+func (e *ErrSubkeyNotFound) Error() string {
+	return fmt.Sprintf("key %s has no subkey with fingerprint %s", e.Fingerprint, e.SubkeyFingerprint)
+}
+
+// Is returns true if target is also an ErrSubkeyNotFound.
+func (e *ErrSubkeyNotFound) Is(target error) bool {
+	_, ok := target.(*ErrSubkeyNotFound)
+	return ok
+}
+
+// ErrMultipleKeysFound is returned when a search (for example by email) is
+// expected to find one key but matches several.
+type ErrMultipleKeysFound struct {
+	Email        string
+	Fingerprints []fingerprint.Fingerprint
+}
+
+func (e *ErrMultipleKeysFound) Error() string {
+	return fmt.Sprintf("found %d keys in GnuPG for %s", len(e.Fingerprints), e.Email)
+}
+
+// Is returns true if target is also an ErrMultipleKeysFound.
+func (e *ErrMultipleKeysFound) Is(target error) bool {
+	_, ok := target.(*ErrMultipleKeysFound)
+	return ok
+}
+
+// ErrUnknownSigner is returned when a signature was made by a key that isn't
+// in the keyring, so it can't be checked.
+type ErrUnknownSigner struct {
+	// KeyId is the ID of the key (often a signing subkey) that made the
+	// signature.
+	KeyId uint64
+}
+
+func (e *ErrUnknownSigner) Error() string {
+	return fmt.Sprintf("signature made by unknown key 0x%016X", e.KeyId)
+}
+
+// Is returns true if target is also an ErrUnknownSigner.
+func (e *ErrUnknownSigner) Is(target error) bool {
+	_, ok := target.(*ErrUnknownSigner)
+	return ok
+}
+
+// ErrGpgFailed is returned when gpg exits with an error that isn't
+// recognised as one of the more specific errors.
+type ErrGpgFailed struct {
+	Arguments []string
+	ExitCode  int
+
+	// Stderr is what gpg wrote to stderr (or its combined output, for
+	// commands which don't separate them)
+	Stderr string
+}
+
+func (e *ErrGpgFailed) Error() string {
+	return fmt.Sprintf("error executing GPG with %s: %s", e.Arguments, e.Stderr)
+}
+
+// classifyGpgError returns a typed error for why gpg failed, based on err
+// (as returned by exec.Cmd) and what gpg wrote to stderr.
+func classifyGpgError(err error, stderr string, arguments []string) error {
+	switch {
+	case isGpgMissing(err):
+		return ErrGpgNotInstalled
+
+	case os.IsPermission(err):
+		// gpg (or its home directory) exists but isn't executable
+		return fmt.Errorf("can't run gpg: %v", err)
+
+	case strings.Contains(stderr, badPassphrase),
+		strings.Contains(stderr, badPassphraseStatus),
+		strings.Contains(stderr, noPassphrase):
+		return &BadPasswordError{}
+
+	case strings.Contains(stderr, noSecretKey):
+		return ErrNoSecretKey
+
+	case strings.Contains(stderr, noPublicKey):
+		return &ErrKeyNotFound{}
+	}
+
+	return &ErrGpgFailed{Arguments: arguments, ExitCode: getExitCode(err), Stderr: stderr}
+}
+
+// getExitCode returns the exit code of the process that err came from, or -1
+// if it didn't run to completion.
+func getExitCode(err error) int {
+	switch e := err.(type) {
+	case *exec.ExitError:
+		if status, ok := e.Sys().(syscall.WaitStatus); ok {
+			return status.ExitStatus()
+		}
+	case exitCoder:
+		return e.ExitCode()
+	}
+	return -1
+}
+
+// isSecretKeyError returns true if err is a BadPasswordError or
+// ErrNoSecretKey, which callers return unwrapped so they can be checked for.
+func isSecretKeyError(err error) bool {
+	if _, ok := err.(*BadPasswordError); ok {
+		return true
+	}
+	return err == ErrNoSecretKey
+}
+
+// isGpgMissing returns true if err shows the gpg binary doesn't exist, either
+// from looking it up in the PATH or from trying to start it.
+func isGpgMissing(err error) bool {
+	if execErr, ok := err.(*exec.Error); ok && execErr.Err == exec.ErrNotFound {
+		return true
+	}
+	return os.IsNotExist(err)
+}
+
+// ErrFileAccess is returned when a file to import from or export to can't be
+// opened, so it's not confused with gpg itself failing. Use
+// os.IsPermission(e.Err) to check for a permissions problem.
+type ErrFileAccess struct {
+	Path string
+	Err  error
+}
+
+func (e *ErrFileAccess) Error() string {
+	return fmt.Sprintf("can't access %s: %v", e.Path, e.Err)
+}
+
+// exitCoder is implemented by errors which carry a process's exit code
+// without being an *exec.ExitError, such as those from a fake commandRunner.
+type exitCoder interface {
+	ExitCode() int
+}
+
+// ErrKeyTooLarge is returned by ImportWithLimits if the input exceeds one of
+// the ImportLimits, for example a key flooded with junk signatures.
+type ErrKeyTooLarge struct {
+	// Limit names the limit that was exceeded, e.g. "signatures"
+	Limit string
+
+	Got int
+	Max int
+}
+
+func (e *ErrKeyTooLarge) Error() string {
+	return fmt.Sprintf("key too large: %d %s exceeds the limit of %d", e.Got, e.Limit, e.Max)
+}
@@ -0,0 +1,116 @@
+package gpgwrapper
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fluidkeys/fluidkeys/assert"
+	"github.com/fluidkeys/fluidkeys/exampledata"
+)
+
+func TestGpgErrors(t *testing.T) {
+	var tests = []struct {
+		name        string
+		script      string
+		expectedErr error
+	}{
+		{
+			"bad passphrase",
+			"echo 'gpg: public key decryption failed: Bad passphrase' >&2; exit 2",
+			ErrBadPassphrase,
+		},
+		{
+			"no secret key",
+			"echo 'gpg: decryption failed: No secret key' >&2; exit 2",
+			ErrNoSecretKey,
+		},
+		{
+			"key not found",
+			"echo 'gpg: error reading key: No public key' >&2; exit 2",
+			&ErrKeyNotFound{},
+		},
+	}
+
+	for _, test := range tests {
+		gpg := GnuPG{fullGpgPath: makeFakeGpgScript(t, test.script)}
+
+		t.Run(test.name+" from run", func(t *testing.T) {
+			_, err := gpg.run("--foo")
+			if !matchesError(err, test.expectedErr) {
+				t.Fatalf("expected %v, got %v", test.expectedErr, err)
+			}
+		})
+
+		t.Run(test.name+" from runWithStdin", func(t *testing.T) {
+			_, _, err := gpg.runWithStdin("", "--foo")
+			if !matchesError(err, test.expectedErr) {
+				t.Fatalf("expected %v, got %v", test.expectedErr, err)
+			}
+		})
+	}
+
+	t.Run("gpg not installed", func(t *testing.T) {
+		gpg := GnuPG{fullGpgPath: "/nonexistent/gpg"}
+
+		_, err := gpg.run("--version")
+		if err != ErrGpgNotInstalled {
+			t.Fatalf("expected ErrGpgNotInstalled, got %v", err)
+		}
+
+		_, _, err = gpg.runWithStdin("", "--version")
+		if err != ErrGpgNotInstalled {
+			t.Fatalf("expected ErrGpgNotInstalled, got %v", err)
+		}
+	})
+
+	t.Run("gpg isn't executable", func(t *testing.T) {
+		path := filepath.Join(makeTempGnupgHome(t), "gpg")
+		if err := ioutil.WriteFile(path, []byte("#!/bin/sh\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		gpg := GnuPG{fullGpgPath: path}
+
+		_, err := gpg.run("--version")
+		assert.ErrorIsNotNil(t, err)
+		if err == ErrGpgNotInstalled {
+			t.Fatalf("expected a permissions error, got %v", err)
+		}
+	})
+
+	t.Run("other failures carry stderr and the exit code", func(t *testing.T) {
+		gpg := GnuPG{fullGpgPath: makeFakeGpgScript(t, "echo 'gpg: something unexpected' >&2; exit 3")}
+
+		_, _, err := gpg.runWithStdin("", "--foo")
+
+		gpgFailed, ok := err.(*ErrGpgFailed)
+		if !ok {
+			t.Fatalf("expected ErrGpgFailed, got %v", err)
+		}
+		assert.Equal(t, 3, gpgFailed.ExitCode)
+		assert.Equal(t, "gpg: something unexpected\n", gpgFailed.Stderr)
+		if !strings.Contains(err.Error(), "something unexpected") {
+			t.Fatalf("expected error to include stderr, got %v", err)
+		}
+	})
+}
+
+func TestErrKeyNotFoundIs(t *testing.T) {
+	err := &ErrKeyNotFound{Fingerprint: exampledata.ExampleFingerprint4}
+
+	assert.Equal(t, true, err.Is(&ErrKeyNotFound{}))
+	assert.Equal(t, true, err.Is(&ErrKeyNotFound{Fingerprint: exampledata.ExampleFingerprint4}))
+	assert.Equal(t, false, err.Is(&ErrKeyNotFound{Fingerprint: exampledata.ExampleFingerprint5}))
+	assert.Equal(t, false, err.Is(ErrNoSecretKey))
+}
+
+// matchesError returns true if err is target, or err's Is method says it
+// matches target.
+func matchesError(err error, target error) bool {
+	if err == target {
+		return true
+	}
+	is, ok := err.(interface{ Is(error) bool })
+	return ok && is.Is(target)
+}
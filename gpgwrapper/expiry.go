@@ -0,0 +1,130 @@
+// Copyright 2018 Paul Furley and Ian Drysdale
+//
+// This file is part of Fluidkeys Client which makes it simple to use OpenPGP.
+//
+// Fluidkeys Client is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Fluidkeys Client is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with Fluidkeys Client.  If not, see <https://www.gnu.org/licenses/>.
+
+package gpgwrapper
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/fluidkeys/fluidkeys/fingerprint"
+)
+
+// SetExpiryDate sets the expiry of the primary key with the given
+// fingerprint, and of each of the given subkeys, to expiry. The secret key
+// is unlocked with passphrase, passed to gpg over a pipe.
+//
+// It uses `--quick-set-expire`, falling back to scripting `--edit-key` for
+// versions of GnuPG without it (before 2.1.22). The edit-key prompt only
+// takes a date, so the fallback sets the expiry to midday UTC on expiry's
+// day.
+func (g *GnuPG) SetExpiryDate(fp fingerprint.Fingerprint, expiry time.Time, subkeys []fingerprint.Fingerprint, passphrase string) error {
+	err := g.quickSetExpiry(fp, expiry, nil, passphrase)
+	if err != nil && isQuickSetExpireUnsupported(err) {
+		return g.setExpiryWithEditKey(fp, expiry, subkeys, passphrase)
+	} else if err != nil {
+		return err
+	}
+
+	if len(subkeys) > 0 {
+		// when given subkey fingerprints, --quick-set-expire only changes
+		// those subkeys, so the primary key needs a separate call
+		return g.quickSetExpiry(fp, expiry, subkeys, passphrase)
+	}
+	return nil
+}
+
+// quickSetExpiry runs `gpg --quick-set-expire`, which sets the primary key's
+// expiry if no subkeys are given, otherwise only the subkeys' expiry.
+func (g *GnuPG) quickSetExpiry(fp fingerprint.Fingerprint, expiry time.Time, subkeys []fingerprint.Fingerprint, passphrase string) error {
+	passphraseReader, err := makePassphrasePipe(passphrase)
+	if err != nil {
+		return err
+	}
+	defer passphraseReader.Close()
+
+	_, stderr, err := g.runCommand(
+		context.Background(),
+		nil,
+		[]*os.File{passphraseReader},
+		getArgsQuickSetExpiry(fp, expiry, subkeys)...,
+	)
+	if isSecretKeyError(err) || isQuickSetExpireUnsupported(err) {
+		return err
+	} else if err != nil {
+		return fmt.Errorf("error setting expiry: %v: %s", err, stderr)
+	}
+	return nil
+}
+
+func getArgsQuickSetExpiry(fp fingerprint.Fingerprint, expiry time.Time, subkeys []fingerprint.Fingerprint) []string {
+	args := []string{
+		"--pinentry-mode", "loopback", // don't use OS password prompt
+		"--passphrase-fd", "3", // the first of the extra files
+		"--quick-set-expire", fp.Hex(), expiry.UTC().Format(isoTimestampFormat),
+	}
+	for _, subkey := range subkeys {
+		args = append(args, subkey.Hex())
+	}
+	return args
+}
+
+// setExpiryWithEditKey sets the expiry of the primary key and subkeys by
+// sending commands to the `--edit-key` prompt.
+func (g *GnuPG) setExpiryWithEditKey(fp fingerprint.Fingerprint, expiry time.Time, subkeys []fingerprint.Fingerprint, passphrase string) error {
+	_, stderr, err := g.runEditKey(fp, passphrase, getEditKeyExpiryCommands(expiry, subkeys))
+	if _, ok := err.(*BadPasswordError); ok {
+		return err
+	} else if err != nil {
+		return fmt.Errorf("error setting expiry: %v: %s", err, stderr)
+	}
+	return nil
+}
+
+// getEditKeyExpiryCommands returns the edit-key commands to set the expiry
+// of the primary key, then select each subkey in turn and set its expiry.
+func getEditKeyExpiryCommands(expiry time.Time, subkeys []fingerprint.Fingerprint) string {
+	date := expiry.UTC().Format("2006-01-02")
+
+	commands := []string{"expire", date}
+	for _, subkey := range subkeys {
+		commands = append(commands,
+			"key "+subkey.Hex(), // select the subkey
+			"expire", date,
+			"key "+subkey.Hex(), // deselect it again
+		)
+	}
+	commands = append(commands, "save")
+	return strings.Join(commands, "\n") + "\n"
+}
+
+// isQuickSetExpireUnsupported returns true if gpg failed because it doesn't
+// know the --quick-set-expire command.
+func isQuickSetExpireUnsupported(err error) bool {
+	gpgFailed, ok := err.(*ErrGpgFailed)
+	if !ok {
+		return false
+	}
+	return strings.Contains(strings.ToLower(gpgFailed.Stderr), `invalid option "--quick-set-expire"`)
+}
+
+// isoTimestampFormat is the format GnuPG accepts for an exact time, e.g.
+// 20300101T120000
+const isoTimestampFormat = "20060102T150405"
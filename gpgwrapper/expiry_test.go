@@ -0,0 +1,114 @@
+package gpgwrapper
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fluidkeys/fluidkeys/assert"
+	"github.com/fluidkeys/fluidkeys/exampledata"
+	"github.com/fluidkeys/fluidkeys/fingerprint"
+)
+
+var exampleSubkeyFingerprint4 = fingerprint.MustParse("09D408F6DD1525735F1F54ABCE7881186F55FA9E")
+
+func TestSetExpiryDate(t *testing.T) {
+	gpg := makeGpgWithTempHome(t)
+	_, err := gpg.ImportArmoredKey(exampledata.ExamplePublicKey4)
+	assertNoError(t, err)
+	_, err = gpg.ImportArmoredKey(exampledata.ExamplePrivateKey4)
+	assertNoError(t, err)
+
+	subkeys := []fingerprint.Fingerprint{exampleSubkeyFingerprint4}
+
+	// gpg-agent caches the passphrase once it's been used, so try the
+	// wrong one first
+	t.Run("with the wrong passphrase", func(t *testing.T) {
+		expiry := time.Date(2031, 2, 3, 4, 5, 6, 0, time.UTC)
+		err := gpg.SetExpiryDate(exampledata.ExampleFingerprint4, expiry, subkeys, "wrong passphrase")
+		if _, ok := err.(*BadPasswordError); !ok {
+			t.Fatalf("expected ErrBadPassphrase, got %v", err)
+		}
+	})
+
+	t.Run("sets the primary key and subkey expiry", func(t *testing.T) {
+		expiry := time.Date(2031, 2, 3, 4, 5, 6, 0, time.UTC)
+		err := gpg.SetExpiryDate(exampledata.ExampleFingerprint4, expiry, subkeys, "test4")
+		assertNoError(t, err)
+
+		key := listKey4(t, gpg)
+		assert.AssertEqualTimes(t, expiry, *key.Expires)
+		assert.AssertEqualTimes(t, expiry, *key.Subkeys[0].Expires)
+	})
+
+	t.Run("with no subkeys only sets the primary key", func(t *testing.T) {
+		expiry := time.Date(2032, 2, 3, 4, 5, 6, 0, time.UTC)
+		err := gpg.SetExpiryDate(exampledata.ExampleFingerprint4, expiry, nil, "test4")
+		assertNoError(t, err)
+
+		key := listKey4(t, gpg)
+		assert.AssertEqualTimes(t, expiry, *key.Expires)
+		assert.AssertEqualTimes(t, time.Date(2031, 2, 3, 4, 5, 6, 0, time.UTC), *key.Subkeys[0].Expires)
+	})
+
+	t.Run("with edit-key, sets the primary key and subkey expiry", func(t *testing.T) {
+		err := gpg.setExpiryWithEditKey(exampledata.ExampleFingerprint4, time.Date(2033, 2, 3, 4, 5, 6, 0, time.UTC), subkeys, "test4")
+		assertNoError(t, err)
+
+		key := listKey4(t, gpg)
+		assert.Equal(t, "2033-02-03", key.Expires.Format("2006-01-02"))
+		assert.Equal(t, "2033-02-03", key.Subkeys[0].Expires.Format("2006-01-02"))
+	})
+}
+
+func TestSetExpiryDateFallsBackToEditKey(t *testing.T) {
+	argsFilename := filepath.Join(makeTempGnupgHome(t), "args")
+	gpg := GnuPG{fullGpgPath: makeFakeGpgScript(t, `case "$*" in
+  *--quick-set-expire*) echo 'gpg: invalid option "--quick-set-expire"' >&2; exit 2;;
+  *) cat > /dev/null; echo "$*" > `+argsFilename+`;;
+esac`)}
+
+	err := gpg.SetExpiryDate(exampledata.ExampleFingerprint4, time.Now(), nil, "test4")
+	assertNoError(t, err)
+
+	args, err := ioutil.ReadFile(argsFilename)
+	assertNoError(t, err)
+	if !strings.Contains(string(args), "--edit-key "+exampledata.ExampleFingerprint4.Hex()) {
+		t.Fatalf("expected gpg to be run with --edit-key, got '%s'", args)
+	}
+}
+
+func TestGetEditKeyExpiryCommands(t *testing.T) {
+	expiry := time.Date(2031, 2, 3, 4, 5, 6, 0, time.UTC)
+
+	t.Run("with no subkeys", func(t *testing.T) {
+		assert.Equal(t, "expire\n2031-02-03\nsave\n", getEditKeyExpiryCommands(expiry, nil))
+	})
+
+	t.Run("with a subkey", func(t *testing.T) {
+		expected := "expire\n2031-02-03\n" +
+			"key 09D408F6DD1525735F1F54ABCE7881186F55FA9E\n" +
+			"expire\n2031-02-03\n" +
+			"key 09D408F6DD1525735F1F54ABCE7881186F55FA9E\n" +
+			"save\n"
+		got := getEditKeyExpiryCommands(expiry, []fingerprint.Fingerprint{exampleSubkeyFingerprint4})
+		assert.Equal(t, expected, got)
+	})
+}
+
+// listKey4 returns the listing of example key 4 from the public keyring.
+func listKey4(t *testing.T, gpg GnuPG) KeyListing {
+	t.Helper()
+	keys, err := gpg.ListPublicKeys()
+	assertNoError(t, err)
+
+	for _, key := range keys {
+		if key.Fingerprint == exampledata.ExampleFingerprint4 {
+			return key
+		}
+	}
+	t.Fatalf("key 4 not in keyring")
+	return KeyListing{}
+}
@@ -0,0 +1,56 @@
+// Copyright 2018 Paul Furley and Ian Drysdale
+//
+// This file is part of Fluidkeys Client which makes it simple to use OpenPGP.
+//
+// Fluidkeys Client is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Fluidkeys Client is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with Fluidkeys Client.  If not, see <https://www.gnu.org/licenses/>.
+
+package gpgwrapper
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/fluidkeys/fluidkeys/fingerprint"
+)
+
+// NormalizeFingerprint takes a fingerprint in any of the forms people tend
+// to paste (spaced, 0x-prefixed, lowercase) and returns the canonical
+// 40-character uppercase hex form that GnuPG matches against, for example:
+// `AB01AB01AB01AB01AB01AB01AB01AB01AB01AB01`
+//
+// Short (8 character) and long (16 character) key IDs are rejected: they
+// can collide, so they're not safe for choosing which key to operate on.
+//
+// Functions in this package which take a fingerprint.Fingerprint are already
+// normalized (see fingerprint.Hex), this is for handling user input.
+func NormalizeFingerprint(input string) (string, error) {
+	withoutWhitespace := whitespaceRegexp.ReplaceAllString(input, "")
+	withoutLeading0x := strings.TrimPrefix(strings.TrimPrefix(withoutWhitespace, "0x"), "0X")
+
+	if keyIdRegexp.MatchString(withoutLeading0x) {
+		return "", fmt.Errorf(
+			"'%s' is a key ID, not a fingerprint: key IDs aren't unique, "+
+				"please use the full 40 character fingerprint", input)
+	}
+
+	fp, err := fingerprint.Parse(withoutLeading0x)
+	if err != nil {
+		return "", fmt.Errorf("invalid fingerprint '%s': %v", input, err)
+	}
+	return fp.Hex(), nil
+}
+
+var whitespaceRegexp = regexp.MustCompile(`\s+`)
+var keyIdRegexp = regexp.MustCompile(`^([A-Fa-f0-9]{8}|[A-Fa-f0-9]{16})$`)
@@ -0,0 +1,167 @@
+// Copyright 2018 Paul Furley and Ian Drysdale
+//
+// This file is part of Fluidkeys Client which makes it simple to use OpenPGP.
+//
+// Fluidkeys Client is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Fluidkeys Client is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with Fluidkeys Client.  If not, see <https://www.gnu.org/licenses/>.
+
+package gpgwrapper
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fluidkeys/fluidkeys/fingerprint"
+	"github.com/fluidkeys/fluidkeys/policy"
+)
+
+// KeyType is the algorithm for GenerateKey to use.
+type KeyType string
+
+const (
+	// KeyTypeRsa generates an RSA primary key and encryption subkey
+	KeyTypeRsa KeyType = "rsa"
+
+	// KeyTypeEd25519 generates an Ed25519 primary key with a Curve25519
+	// encryption subkey
+	KeyTypeEd25519 KeyType = "ed25519"
+)
+
+// GenerateKeyParams describe the key for GenerateKey to make.
+type GenerateKeyParams struct {
+	Name  string
+	Email string
+
+	// Passphrase protects the secret key. If empty, the key isn't
+	// protected at all.
+	Passphrase string
+
+	// KeyType defaults to KeyTypeRsa
+	KeyType KeyType
+
+	// KeyLength is the size of RSA keys in bits. If 0, the primary key is
+	// policy.PrimaryKeyRsaKeyBits and the encryption subkey is
+	// policy.EncryptionSubkeyRsaKeyBits.
+	KeyLength int
+
+	// Expiry applies to the primary key and the encryption subkey. If
+	// zero, the key doesn't expire.
+	Expiry time.Time
+}
+
+// GenerateKey makes a new key in the keyring with an encryption subkey and
+// returns the primary key's fingerprint.
+//
+// The key is described by a parameter file sent to `gpg --generate-key` on
+// stdin, so the passphrase never appears in the process's argument list.
+func (g *GnuPG) GenerateKey(params GenerateKeyParams) (fingerprint.Fingerprint, error) {
+	parameterFile, err := makeKeyParameterFile(params)
+	if err != nil {
+		return fingerprint.Fingerprint{}, err
+	}
+
+	stdout, stderr, err := g.runWithStdin(
+		parameterFile,
+		"--status-fd", "1",
+		"--pinentry-mode", "loopback", // don't use OS password prompt
+		"--generate-key",
+	)
+	if err != nil {
+		return fingerprint.Fingerprint{}, fmt.Errorf("error generating key: %v: %s", err, stderr)
+	}
+
+	return parseKeyCreatedStatus(stdout)
+}
+
+// makeKeyParameterFile returns the unattended key generation parameters
+// for the given params. For the format see "Unattended key generation" in
+// https://www.gnupg.org/documentation/manuals/gnupg/Unattended-GPG-key-generation.html
+func makeKeyParameterFile(params GenerateKeyParams) (string, error) {
+	if params.Name == "" && params.Email == "" {
+		return "", fmt.Errorf("key needs a name or email")
+	}
+	for _, value := range []string{params.Name, params.Email, params.Passphrase} {
+		// each parameter is a line, so a newline would start a new one
+		if strings.ContainsAny(value, "\r\n") {
+			return "", fmt.Errorf("key parameters can't contain newlines")
+		}
+	}
+
+	var lines []string
+
+	switch params.KeyType {
+	case KeyTypeRsa, "":
+		primaryLength, subkeyLength := params.KeyLength, params.KeyLength
+		if params.KeyLength == 0 {
+			primaryLength = policy.PrimaryKeyRsaKeyBits
+			subkeyLength = policy.EncryptionSubkeyRsaKeyBits
+		}
+		lines = append(lines,
+			"Key-Type: RSA",
+			"Key-Length: "+strconv.Itoa(primaryLength),
+			"Subkey-Type: RSA",
+			"Subkey-Length: "+strconv.Itoa(subkeyLength),
+		)
+
+	case KeyTypeEd25519:
+		lines = append(lines,
+			"Key-Type: EDDSA",
+			"Key-Curve: ed25519",
+			"Subkey-Type: ECDH",
+			"Subkey-Curve: cv25519",
+		)
+
+	default:
+		return "", fmt.Errorf("unknown key type '%s'", params.KeyType)
+	}
+
+	lines = append(lines, "Key-Usage: sign,cert", "Subkey-Usage: encrypt")
+
+	if params.Name != "" {
+		lines = append(lines, "Name-Real: "+params.Name)
+	}
+	if params.Email != "" {
+		lines = append(lines, "Name-Email: "+params.Email)
+	}
+
+	if params.Expiry.IsZero() {
+		lines = append(lines, "Expire-Date: 0")
+	} else {
+		lines = append(lines, "Expire-Date: "+params.Expiry.UTC().Format(isoTimestampFormat))
+	}
+
+	if params.Passphrase == "" {
+		lines = append(lines, "%no-protection")
+	} else {
+		lines = append(lines, "Passphrase: "+params.Passphrase)
+	}
+
+	lines = append(lines, "%commit")
+	return strings.Join(lines, "\n") + "\n", nil
+}
+
+// parseKeyCreatedStatus returns the fingerprint from the KEY_CREATED line of
+// gpg's status output, for example:
+// [GNUPG:] KEY_CREATED B 6F3D9EA26411B777EF3EA76EE162F6D17FEABECC
+func parseKeyCreatedStatus(statusOutput string) (fingerprint.Fingerprint, error) {
+	for _, line := range strings.Split(statusOutput, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 4 || fields[0] != statusPrefix || fields[1] != "KEY_CREATED" {
+			continue
+		}
+		return fingerprint.Parse(fields[3])
+	}
+	return fingerprint.Fingerprint{}, fmt.Errorf("GnuPG didn't report creating a key")
+}
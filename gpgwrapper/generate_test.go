@@ -0,0 +1,107 @@
+package gpgwrapper
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fluidkeys/fluidkeys/assert"
+	"github.com/fluidkeys/fluidkeys/fingerprint"
+)
+
+func TestGenerateKey(t *testing.T) {
+	if testing.Short() {
+		t.Skip("generating keys is slow")
+	}
+	gpg := makeGpgWithTempHome(t)
+
+	for _, keyType := range []KeyType{KeyTypeEd25519, KeyTypeRsa} {
+		t.Run("with key type "+string(keyType), func(t *testing.T) {
+			expiry := time.Date(2031, 2, 3, 4, 5, 6, 0, time.UTC)
+			fp, err := gpg.GenerateKey(GenerateKeyParams{
+				Name:       "Jane",
+				Email:      "jane-" + string(keyType) + "@example.com",
+				Passphrase: "test",
+				KeyType:    keyType,
+				KeyLength:  2048, // ignored for ed25519
+				Expiry:     expiry,
+			})
+			assertNoError(t, err)
+
+			secretKeys, err := gpg.ListSecretKeys()
+			assertNoError(t, err)
+
+			var got *KeyListing
+			for i := range secretKeys {
+				if secretKeys[i].Fingerprint == fp {
+					got = &secretKeys[i]
+				}
+			}
+			if got == nil {
+				t.Fatalf("generated key %s not in secret keys: %v", fp, secretKeys)
+			}
+			assert.Equal(t, []string{"Jane <jane-" + string(keyType) + "@example.com>"}, got.Uids)
+
+			publicKeys, err := gpg.ListPublicKeys()
+			assertNoError(t, err)
+			for _, key := range publicKeys {
+				if key.Fingerprint == fp {
+					assert.AssertEqualTimes(t, expiry, *key.Expires)
+					assert.Equal(t, 1, len(key.Subkeys))
+					assert.Equal(t, "e", key.Subkeys[0].Capabilities)
+				}
+			}
+		})
+	}
+}
+
+func TestMakeKeyParameterFile(t *testing.T) {
+	t.Run("with defaults", func(t *testing.T) {
+		got, err := makeKeyParameterFile(GenerateKeyParams{Email: "jane@example.com"})
+		assertNoError(t, err)
+
+		expected := "Key-Type: RSA\n" +
+			"Key-Length: 4096\n" +
+			"Subkey-Type: RSA\n" +
+			"Subkey-Length: 2048\n" +
+			"Key-Usage: sign,cert\n" +
+			"Subkey-Usage: encrypt\n" +
+			"Name-Email: jane@example.com\n" +
+			"Expire-Date: 0\n" +
+			"%no-protection\n" +
+			"%commit\n"
+		assert.Equal(t, expected, got)
+	})
+
+	t.Run("with a newline in a parameter", func(t *testing.T) {
+		_, err := makeKeyParameterFile(GenerateKeyParams{
+			Email:      "jane@example.com",
+			Passphrase: "foo\n%no-protection",
+		})
+		assert.ErrorIsNotNil(t, err)
+	})
+
+	t.Run("with no name or email", func(t *testing.T) {
+		_, err := makeKeyParameterFile(GenerateKeyParams{Passphrase: "foo"})
+		assert.ErrorIsNotNil(t, err)
+	})
+
+	t.Run("with an unknown key type", func(t *testing.T) {
+		_, err := makeKeyParameterFile(GenerateKeyParams{Email: "jane@example.com", KeyType: "dsa"})
+		assert.ErrorIsNotNil(t, err)
+	})
+}
+
+func TestParseKeyCreatedStatus(t *testing.T) {
+	t.Run("with KEY_CREATED", func(t *testing.T) {
+		status := "[GNUPG:] KEY_CONSIDERED 6F3D9EA26411B777EF3EA76EE162F6D17FEABECC 0\n" +
+			"[GNUPG:] KEY_CREATED B 6F3D9EA26411B777EF3EA76EE162F6D17FEABECC\n"
+		got, err := parseKeyCreatedStatus(status)
+		assertNoError(t, err)
+		assert.Equal(t, fingerprint.MustParse("6F3D9EA26411B777EF3EA76EE162F6D17FEABECC"), got)
+	})
+
+	t.Run("without KEY_CREATED", func(t *testing.T) {
+		_, err := parseKeyCreatedStatus("[GNUPG:] KEY_NOT_CREATED\n")
+		assert.ErrorIsNotNil(t, err)
+	})
+}
@@ -20,15 +20,19 @@
 package gpgwrapper
 
 import (
+	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"log"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"regexp"
 	"strings"
-	"time"
+	"sync"
 
 	"github.com/fluidkeys/fluidkeys/fingerprint"
 
@@ -39,7 +43,7 @@ var ErrNoVersionStringFound = errors.New("version string not found in GPG output
 var ErrNoHomeDirectoryStringFound = errors.New("home directory string not found in GPG output")
 
 func ErrProblemExecutingGPG(gpgStdout string, arguments ...string) error {
-	return fmt.Errorf("error executing GPG with %s: %s", arguments, gpgStdout)
+	return &ErrGpgFailed{Arguments: arguments, ExitCode: -1, Stderr: gpgStdout}
 }
 
 var VersionRegexp = regexp.MustCompile(`gpg \(GnuPG.*\) (\d+\.\d+\.\d+)`)
@@ -50,38 +54,168 @@ type GnuPG struct {
 	// It is set during Load.
 	fullGpgPath string
 
+	// homeDir is passed to every invocation as --homedir if set, otherwise
+	// GnuPG uses its default (GNUPGHOME or ~/.gnupg)
 	homeDir string
+
+	// running limits how many gpg processes run at once: each one holds a
+	// slot in the channel while it runs. If nil there's no limit.
+	running chan struct{}
+
+	// version caches the result of Version, see ClearVersionCache. It's a
+	// pointer so that copies of the GnuPG share it. If nil, nothing is
+	// cached.
+	version *versionCache
+
+	// runner runs gpg. If nil, it's run with os/exec, see execRunner.
+	runner commandRunner
+
+	// CommandLogger, if set, is called with the full command line (the
+	// gpg binary followed by every argument, including global ones like
+	// --homedir) before gpg is run. Passphrases are never passed as
+	// arguments, so it's safe to log.
+	CommandLogger func(args []string)
+
+	// DryRun stops gpg from actually being run: each command is passed to
+	// CommandLogger, then succeeds with no output. This is for debugging
+	// and support, so commands that parse gpg's output will likely fail.
+	DryRun bool
+
+	// Verbosity says how much gpg writes to stderr about what it's doing.
+	// Output that gets parsed is read from stdout, so it's unaffected.
+	Verbosity Verbosity
 }
 
-// SecretKeyListing refers to a key parsed from running `gpg --list-secret-keys`
-type SecretKeyListing struct {
+// Verbosity sets the verbosity options gpg is run with.
+type Verbosity int
+
+const (
+	// VerbosityVerbose runs gpg with --verbose twice, which helps when
+	// debugging keyring problems. It's the default.
+	VerbosityVerbose Verbosity = 0
 
-	// Fingerprint is the human-readable format of the fingerprint of the
-	// primary key, for example:
-	// `AB01 AB01 AB01 AB01 AB01  AB01 AB01 AB01 AB01 AB01`
-	Fingerprint fingerprint.Fingerprint
+	// VerbosityNormal runs gpg without any verbosity options.
+	VerbosityNormal Verbosity = 1
 
-	// Uids is a list of UTF-8 user ID strings as defined in
-	// https://tools.ietf.org/html/rfc4880#section-5.11
-	Uids []string
+	// VerbosityQuiet runs gpg with --quiet.
+	VerbosityQuiet Verbosity = 2
+)
 
-	// Created is the time the key was apparently created in UTC.
-	Created time.Time
+// arguments returns the gpg options for the verbosity.
+func (v Verbosity) arguments() []string {
+	switch v {
+	case VerbosityNormal:
+		return []string{}
+	case VerbosityQuiet:
+		return []string{"--quiet"}
+	}
+	return []string{"--verbose", "--verbose"}
+}
+
+// defaultMaxConcurrency is how many gpg processes a GnuPG from Load runs at
+// once. Concurrent gpg processes sharing a home directory contend for the
+// keyring lock and gpg-agent, and can fail with "resource temporarily
+// unavailable", so by default they take turns.
+const defaultMaxConcurrency = 1
+
+// versionCache holds the GnuPG version once it's been looked up.
+type versionCache struct {
+	sync.Mutex
+	version string
 }
 
 func Load() (*GnuPG, error) {
-	gpgBinary, err := findGpgBinary()
-	if err != nil {
-		return nil, fmt.Errorf("failed to find gpg: %v", err)
+	return LoadWithPaths("", "")
+}
+
+// LoadWithPaths returns a GnuPG that runs the binary at binaryPath (e.g.
+// /opt/gnupg/bin/gpg) with the given home directory. If binaryPath is empty,
+// the usual locations are searched for gpg2. If homeDir is empty, GnuPG's
+// default home directory is used.
+func LoadWithPaths(binaryPath string, homeDir string) (*GnuPG, error) {
+	if binaryPath == "" {
+		gpgBinary, err := findGpgBinary()
+		if err != nil {
+			return nil, fmt.Errorf("failed to find gpg: %v", err)
+		}
+		binaryPath = gpgBinary
+	}
+
+	gpg := GnuPG{fullGpgPath: binaryPath, homeDir: homeDir, version: &versionCache{}}
+	if _, err := gpg.Version(); err != nil {
+		return nil, fmt.Errorf("gpg at '%s' isn't working: %v", binaryPath, err)
 	}
-	return &GnuPG{fullGpgPath: gpgBinary}, nil
+	return gpg.WithMaxConcurrency(defaultMaxConcurrency), nil
+}
+
+// WithMaxConcurrency returns a copy of g which runs at most n gpg processes
+// at once, for example so that many goroutines can import and export keys
+// without gpg failing on a locked keyring. Further calls wait for a running
+// gpg to finish (or their context to be done). If n is 0 or less there's no
+// limit.
+//
+// The limit applies to the returned GnuPG (and copies of it) only.
+func (g *GnuPG) WithMaxConcurrency(n int) *GnuPG {
+	limited := *g
+	if n > 0 {
+		limited.running = make(chan struct{}, n)
+	} else {
+		limited.running = nil
+	}
+	return &limited
 }
 
 // Returns the GnuPG version string, e.g. "1.2.3"
+//
+// For a GnuPG from Load, gpg is only run the first time: after that the
+// version is remembered until ClearVersionCache is called.
 func (g *GnuPG) Version() (string, error) {
-	outString, err := g.run("--version")
+	return g.VersionContext(context.Background())
+}
+
+// VersionContext is like Version, but kills gpg and returns the context's
+// error if ctx is cancelled or times out first.
+func (g *GnuPG) VersionContext(ctx context.Context) (string, error) {
+	if g.version == nil {
+		return g.lookupVersion(ctx)
+	}
 
+	g.version.Lock()
+	cached := g.version.version
+	g.version.Unlock()
+	if cached != "" {
+		return cached, nil
+	}
+
+	version, err := g.lookupVersion(ctx)
 	if err != nil {
+		return "", err // don't cache failures
+	}
+
+	g.version.Lock()
+	g.version.version = version
+	g.version.Unlock()
+	return version, nil
+}
+
+// ClearVersionCache makes the next call to Version run gpg again, for
+// example after GnuPG has been upgraded.
+func (g *GnuPG) ClearVersionCache() {
+	if g.version == nil {
+		return
+	}
+	g.version.Lock()
+	g.version.version = ""
+	g.version.Unlock()
+}
+
+// lookupVersion runs `gpg --version` and returns the version it reports.
+func (g *GnuPG) lookupVersion(ctx context.Context) (string, error) {
+	outString, err := g.runContext(ctx, "--version")
+
+	if ctx.Err() != nil {
+		return "", ctx.Err()
+	} else if err != nil {
 		err = fmt.Errorf("problem running GPG, %v", err)
 		return "", err
 	}
@@ -116,9 +250,30 @@ func (g *GnuPG) HomeDir() (string, error) {
 	}
 }
 
-// Checks whether GPG is working
+// IsAvailable returns true if the gpg binary exists and is executable,
+// without running it, so it's quick enough to check before doing anything
+// else, for example to show a friendly "GnuPG not found" message. If g has no
+// binary path (for example a zero GnuPG, before calling Load), the usual
+// locations are checked for gpg2.
+//
+// Use IsWorking to check that gpg actually runs.
+func (g *GnuPG) IsAvailable() bool {
+	if g.fullGpgPath != "" {
+		_, err := exec.LookPath(g.fullGpgPath)
+		return err == nil
+	}
+
+	for _, binaryDir := range gpgSearchPaths {
+		if _, err := exec.LookPath(binaryDir + "/gpg2"); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// Checks whether GPG is working. Unlike Version, this always runs gpg.
 func (g *GnuPG) IsWorking() bool {
-	_, err := g.Version()
+	_, err := g.lookupVersion(context.Background())
 
 	if err != nil {
 		return false
@@ -127,23 +282,37 @@ func (g *GnuPG) IsWorking() bool {
 	return true
 }
 
-// Import an armored key into the GPG key ring
-func (g *GnuPG) ImportArmoredKey(armoredKey string) (string, error) {
-	stdout, stderr, err := g.runWithStdin(armoredKey, "--import")
+// Import an armored key into the GPG key ring. The key is first cleaned up
+// with CleanArmoredBlock, so it can be pasted straight from an email or a
+// markdown code block.
+//
+// It returns how many keys were imported or unchanged, or an error if GnuPG
+// didn't import anything (see importResult).
+func (g *GnuPG) ImportArmoredKey(armoredKey string) (ImportResult, error) {
+	cleanedKey, err := CleanArmoredBlock(armoredKey)
+	if err != nil {
+		return ImportResult{}, fmt.Errorf("problem importing key, %v", err)
+	}
+
+	stdout, stderr, err := g.runWithStdin(cleanedKey, "--status-fd", "1", "--import")
 	if err != nil {
-		err = fmt.Errorf("problem importing key, %v", err)
-		return stderr, err
+		return ImportResult{}, fmt.Errorf("problem importing key, %v: %s", err, stderr)
 	}
-	// TODO: are we correctly checking if GPG failed? I think it can return
-	// exit code 0 *but* set stderr to communicate a problem
 
-	return stdout, nil
+	result, err := importResult(stdout, stderr)
+	if err != nil {
+		return result, fmt.Errorf("problem importing key, %v", err)
+	}
+	return result, nil
 }
 
-func (g *GnuPG) ListSecretKeys() ([]SecretKeyListing, error) {
+// ListSecretKeys returns the valid (not revoked, not expired) keys in the
+// secret keyring, with the same fields as ListPublicKeys.
+func (g *GnuPG) ListSecretKeys() ([]KeyListing, error) {
 	args := []string{
 		"--with-colons",
 		"--with-fingerprint",
+		"--with-fingerprint", // twice to include subkey fingerprints
 		"--fixed-list-mode",
 		"--list-secret-keys",
 	}
@@ -156,113 +325,213 @@ func (g *GnuPG) ListSecretKeys() ([]SecretKeyListing, error) {
 }
 
 // ExportPublicKey returns 1 ascii armored public key for the given
-// fingerprint
+// fingerprint. If the key isn't in the keyring it returns ErrKeyNotFound.
 func (g *GnuPG) ExportPublicKey(fingerprint fingerprint.Fingerprint) (string, error) {
-	args := []string{
-		"--export-options", "export-minimal",
-		"--armor",
-		"--export",
-		fingerprint.Hex(),
+	output, err := g.ExportPublicKeyBytes(fingerprint, true)
+	return string(output), err
+}
+
+// ExportPublicKeyBytes is like ExportPublicKey, but if armor is false the key
+// is exported in binary OpenPGP format, which is about a quarter smaller.
+func (g *GnuPG) ExportPublicKeyBytes(fingerprint fingerprint.Fingerprint, armor bool) ([]byte, error) {
+	if !fingerprint.IsSet() {
+		return nil, fmt.Errorf("can't export public key: fingerprint isn't set")
 	}
 
-	stdout, err := g.run(args...)
+	args := []string{"--export-options", "export-minimal"}
+	if armor {
+		args = append(args, "--armor")
+	}
+	args = append(args, "--export", fingerprint.Hex())
+
+	// read stdout separately so GnuPG's notes on stderr don't end up in
+	// the exported key
+	stdout, stderr, err := g.runWithStdin("", args...)
 	if err != nil {
-		return "", err
+		return nil, fmt.Errorf("problem exporting public key, %v: %s", err, stderr)
+	}
+
+	if strings.Contains(stderr, nothingExported) || strings.TrimSpace(stdout) == "" {
+		return nil, &ErrKeyNotFound{Fingerprint: fingerprint}
 	}
 
-	if strings.Contains(stdout, nothingExported) {
-		return "", fmt.Errorf("GnuPG returned 'nothing exported' for fingerprint '%s'", fingerprint)
+	if !armor {
+		return []byte(stdout), nil
 	}
 
 	numHeaders := strings.Count(stdout, publicHeader)
 	numFooters := strings.Count(stdout, publicFooter)
 
 	if numHeaders != 1 || numFooters != 1 {
-		return "", fmt.Errorf(
+		return nil, fmt.Errorf(
 			"Expected exactly 1 ascii-armored public key, got %d headers and %d footers",
 			numHeaders, numFooters)
 	}
 
-	return stdout, nil
+	return []byte(stdout), nil
+}
+
+// ExportPublicKeyToFile writes the ascii armored public key for the given
+// fingerprint to the file at path, replacing it if it exists. GnuPG writes
+// the file itself with --output, so the key isn't held in memory.
+//
+// GnuPG writes to a temporary file in the same directory, which is only
+// renamed over path once the export has succeeded, so an existing file is
+// left alone if the export fails.
+//
+// If the file can't be created it returns an *ErrFileAccess.
+func (g *GnuPG) ExportPublicKeyToFile(fingerprint fingerprint.Fingerprint, path string) error {
+	if !fingerprint.IsSet() {
+		return fmt.Errorf("can't export public key: fingerprint isn't set")
+	}
+
+	// create the temporary file first, so a bad path or permissions
+	// aren't reported as gpg failing
+	f, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path)+".")
+	if err != nil {
+		return &ErrFileAccess{Path: path, Err: err}
+	}
+	tempPath := f.Name()
+	f.Close()
+	defer os.Remove(tempPath) // fails harmlessly once it's been renamed
+
+	_, stderr, err := g.runWithStdin("",
+		"--yes", "--armor", "--output", tempPath,
+		"--export-options", "export-minimal", "--export", fingerprint.Hex(),
+	)
+	if err != nil {
+		return fmt.Errorf("problem exporting public key, %v: %s", err, stderr)
+	}
+
+	if strings.Contains(stderr, nothingExported) {
+		return &ErrKeyNotFound{Fingerprint: fingerprint}
+	}
+
+	// TempFile creates the file readable only by us, but a public key is
+	// fine for anyone to read
+	if err := os.Chmod(tempPath, 0644); err != nil {
+		return &ErrFileAccess{Path: path, Err: err}
+	}
+	if err := os.Rename(tempPath, path); err != nil {
+		return &ErrFileAccess{Path: path, Err: err}
+	}
+	return nil
 }
 
 // ExportPrivateKey returns 1 ascii armored private key for the given
 // fingerprint, assuming it is encrypted with the given password.
 // The outputted private key is encrypted with the password.
 func (g *GnuPG) ExportPrivateKey(fingerprint fingerprint.Fingerprint, password string) (string, error) {
+	output, err := g.exportSecret(fingerprint, password, "--export-secret-keys", true)
+	return string(output), err
+}
 
+// ExportPrivateKeyBytes is like ExportPrivateKey, but if armor is false the
+// key is exported in binary OpenPGP format.
+func (g *GnuPG) ExportPrivateKeyBytes(fingerprint fingerprint.Fingerprint, password string, armor bool) ([]byte, error) {
+	return g.exportSecret(fingerprint, password, "--export-secret-keys", armor)
+}
+
+// ExportSecretSubkeys returns 1 ascii armored private key for the given
+// fingerprint containing only the secret subkeys, for carrying on a laptop
+// while the primary key is kept offline.
+// The primary key remains unexported: its secret part is replaced by a stub,
+// so the export can't be used to certify keys or change expiry dates.
+// As with ExportPrivateKey, the key must be encrypted with the given password
+// and the output is encrypted with it too.
+func (g *GnuPG) ExportSecretSubkeys(fingerprint fingerprint.Fingerprint, password string) (string, error) {
+	output, err := g.exportSecret(fingerprint, password, "--export-secret-subkeys", true)
+	return string(output), err
+}
+
+// exportSecret runs the given export command (--export-secret-keys or
+// --export-secret-subkeys), passing the password via stdin so it never
+// appears in the process's argument list. If armor is false the output is
+// binary.
+func (g *GnuPG) exportSecret(fingerprint fingerprint.Fingerprint, password string, exportCommand string, armor bool) ([]byte, error) {
 	stdout, stderr, err := g.runWithStdin(
 		password,
-		getArgsExportPrivateKeyWithPinentry(fingerprint)...,
+		getArgsExportPrivateKeyWithPinentry(fingerprint, exportCommand, armor)...,
 	)
 
 	if err != nil {
 		if strings.Contains(stderr, invalidOptionPinentryMode) { // TODO: is this really in stderr or in stdout?
 			stdout, stderr, err := g.runWithStdin(
 				password,
-				getArgsExportPrivateKeyWithoutPinentry(fingerprint)...,
+				getArgsExportPrivateKeyWithoutPinentry(fingerprint, exportCommand, armor)...,
 			)
 
 			if err != nil {
-				return stderr, err
+				return nil, fmt.Errorf("problem exporting private key, %v: %s", err, stderr)
 			}
 
-			return checkValidExportPrivateOutput(stdout, stderr)
+			return checkValidExportPrivateOutput(fingerprint, stdout, stderr, armor)
 		} else if strings.Contains(stderr, loopbackUnsupported) {
 			if version, err := g.Version(); err == nil && version == "2.1.11" {
-				return "", fmt.Errorf("for gpg-2.1.11, please see https://fluidkeys.com/tweak-gpg-2.1.11/")
+				return nil, fmt.Errorf("for gpg-2.1.11, please see https://fluidkeys.com/tweak-gpg-2.1.11/")
 			}
 		} else if strings.Contains(stderr, badPassphrase) || strings.Contains(stderr, noPassphrase) {
-			return stderr, &BadPasswordError{}
+			return nil, &BadPasswordError{}
 		}
 
-		return stderr, err
+		return nil, fmt.Errorf("problem exporting private key, %v: %s", err, stderr)
 	}
 
-	return checkValidExportPrivateOutput(stdout, stderr)
+	return checkValidExportPrivateOutput(fingerprint, stdout, stderr, armor)
 }
 
-func getArgsExportPrivateKeyWithPinentry(fingerprint fingerprint.Fingerprint) []string {
-	return []string{
+func getArgsExportPrivateKeyWithPinentry(fingerprint fingerprint.Fingerprint, exportCommand string, armor bool) []string {
+	args := []string{
 		"--pinentry-mode", "loopback", // don't use OS password prompt
 		"--passphrase-fd", "0", // read password from stdin
-		"--armor",
-		"--export-secret-keys",
-		fingerprint.Hex(),
 	}
+	if armor {
+		args = append(args, "--armor")
+	}
+	return append(args, exportCommand, fingerprint.Hex())
 }
 
-func getArgsExportPrivateKeyWithoutPinentry(fingerprint fingerprint.Fingerprint) []string {
-	return []string{
+func getArgsExportPrivateKeyWithoutPinentry(fingerprint fingerprint.Fingerprint, exportCommand string, armor bool) []string {
+	args := []string{
 		"--passphrase-fd", "0", // read password from stdin
-		"--armor",
-		"--export-secret-keys",
-		fingerprint.Hex(),
 	}
+	if armor {
+		args = append(args, "--armor")
+	}
+	return append(args, exportCommand, fingerprint.Hex())
 }
 
 // checkValidExportPrivateOutput takes the output of `gpg --export-secret-key ...`
 // and ensures:
-// 1. there's exactly 1 ascii-armored secret key
+// 1. there's exactly 1 ascii-armored secret key (or, if armor is false,
+//    that there's some output)
 // 2. there's no GnuPG warning message in stderr
 //
 // then it returns the output with err=nil if everything looks good.
-func checkValidExportPrivateOutput(stdout string, stderr string) (string, error) {
+// If GnuPG exported nothing, it returns ErrKeyNotFound.
+func checkValidExportPrivateOutput(fingerprint fingerprint.Fingerprint, stdout string, stderr string, armor bool) ([]byte, error) {
 
 	if strings.Contains(stderr, nothingExported) {
-		return "", fmt.Errorf("GnuPG returned 'nothing exported'")
+		return nil, &ErrKeyNotFound{Fingerprint: fingerprint}
+	}
+
+	if !armor {
+		if stdout == "" {
+			return nil, fmt.Errorf("GnuPG exported an empty secret key")
+		}
+		return []byte(stdout), nil
 	}
 
 	numHeaders := strings.Count(stdout, privateHeader)
 	numFooters := strings.Count(stdout, privateFooter)
 
 	if numHeaders != 1 || numFooters != 1 {
-		return "", fmt.Errorf(
+		return nil, fmt.Errorf(
 			"Expected exactly 1 ascii-armored secret key, got %d headers and %d footers",
 			numHeaders, numFooters)
 	}
 
-	return stdout, nil
+	return []byte(stdout), nil
 }
 
 func parseVersionString(gpgStdout string) (string, error) {
@@ -276,77 +545,160 @@ func parseVersionString(gpgStdout string) (string, error) {
 }
 
 func (g *GnuPG) run(arguments ...string) (string, error) {
-	fullArguments := g.prependGlobalArguments(arguments...)
-	out, err := exec.Command(g.fullGpgPath, fullArguments...).CombinedOutput()
+	return g.runContext(context.Background(), arguments...)
+}
 
+// runContext runs gpg with the given arguments and returns its stdout.
+// stderr is only used for the error if gpg fails, so diagnostic messages
+// don't get mixed into output that's being parsed.
+// If ctx is done before gpg exits, the gpg process is killed and ctx.Err()
+// is returned.
+func (g *GnuPG) runContext(ctx context.Context, arguments ...string) (string, error) {
+	stdout, _, err := g.runWithReaderContext(ctx, nil, arguments...)
 	if err != nil {
-		err := ErrProblemExecutingGPG(string(out), fullArguments...)
 		return "", err
 	}
-	outString := string(out)
-	return outString, nil
+	return stdout, nil
 }
 
 // runWithStdin runs the given command, sends textToSend via stdin, and returns
 // stdout, stderr and any error encountered
 func (g *GnuPG) runWithStdin(textToSend string, arguments ...string) (stdout string, stderr string, returnErr error) {
+	return g.runWithReader(strings.NewReader(textToSend), arguments...)
+}
+
+// runWithReader runs the given command, streams everything from stdin to
+// gpg's stdin, and returns stdout, stderr and any error encountered.
+// If stdin is nil, gpg reads from the null device.
+//
+// stdout and stderr are collected concurrently so gpg can't block writing to
+// one while we're waiting for the other.
+func (g *GnuPG) runWithReader(stdin io.Reader, arguments ...string) (stdout string, stderr string, returnErr error) {
+	return g.runWithReaderContext(context.Background(), stdin, arguments...)
+}
+
+// runWithReaderContext is like runWithReader, but kills gpg and returns
+// ctx.Err() if ctx is done before gpg exits.
+func (g *GnuPG) runWithReaderContext(ctx context.Context, stdin io.Reader, arguments ...string) (stdout string, stderr string, returnErr error) {
+	return g.runCommand(ctx, stdin, nil, arguments...)
+}
+
+// runCommand is like runWithReaderContext, and also passes extraFiles to gpg
+// as file descriptors 3, 4, ... for options like --passphrase-fd.
+func (g *GnuPG) runCommand(ctx context.Context, stdin io.Reader, extraFiles []*os.File, arguments ...string) (stdout string, stderr string, returnErr error) {
 	fullArguments := g.prependGlobalArguments(arguments...)
-	cmd := exec.Command(g.fullGpgPath, fullArguments...)
 
-	stdin, err := cmd.StdinPipe() // used to send textToSend
-	if err != nil {
-		returnErr = fmt.Errorf("Failed to get stdin pipe '%s'", err)
-		return
+	stdout, stderr, err := g.runBinary(ctx, g.fullGpgPath, stdin, extraFiles, fullArguments...)
+	if err != nil && ctx.Err() == nil {
+		err = classifyGpgError(err, stderr, fullArguments)
 	}
+	return stdout, stderr, err
+}
 
-	stdoutPipe, err := cmd.StdoutPipe()
-	if err != nil {
-		returnErr = fmt.Errorf("Failed to get stdout pipe '%s'", err)
-		return
+// runBinary runs the binary at path (gpg, or a companion tool like
+// gpg-connect-agent) with exactly the given arguments. It waits for a slot
+// if the number of running processes is limited, logs the command line,
+// respects DryRun and uses the GnuPG's runner.
+//
+// If the process can't be started or exits non-zero, the error from the
+// runner is returned unchanged. If ctx is done first, ctx.Err() is returned.
+func (g *GnuPG) runBinary(ctx context.Context, path string, stdin io.Reader, extraFiles []*os.File, arguments ...string) (stdout string, stderr string, returnErr error) {
+	if g.running != nil {
+		select {
+		case g.running <- struct{}{}:
+			defer func() { <-g.running }()
+		case <-ctx.Done():
+			returnErr = ctx.Err()
+			return
+		}
 	}
-	stderrPipe, err := cmd.StderrPipe()
-	if err != nil {
-		returnErr = fmt.Errorf("Failed to get stderr pipe '%s'", err)
-		return
+
+	if g.CommandLogger != nil {
+		g.CommandLogger(append([]string{path}, arguments...))
+	}
+	if g.DryRun {
+		return "", "", nil
 	}
 
-	io.WriteString(stdin, textToSend)
-	stdin.Close()
+	var runner commandRunner = execRunner{}
+	if g.runner != nil {
+		runner = g.runner
+	}
 
-	if err = cmd.Start(); err != nil {
-		returnErr = fmt.Errorf("error starting gpg: %v", err)
+	var stdoutBuffer, stderrBuffer bytes.Buffer
+	err := runner.run(ctx, command{
+		path:       path,
+		args:       arguments,
+		stdin:      stdin,
+		extraFiles: extraFiles,
+		stdout:     &stdoutBuffer,
+		stderr:     &stderrBuffer,
+	})
+	stdout = stdoutBuffer.String()
+	stderr = stderrBuffer.String()
+
+	if ctx.Err() != nil {
+		returnErr = ctx.Err()
 		return
 	}
+	returnErr = err
+	return
+}
 
-	if stdoutBytes, err := ioutil.ReadAll(stdoutPipe); err != nil {
-		returnErr = fmt.Errorf("error reading stdout: %v", err)
-		return
-	} else {
-		stdout = string(stdoutBytes)
+// makePassphrasePipe returns the read end of a pipe containing the
+// passphrase, to pass to runCommand for `--passphrase-fd 3`. The caller must
+// close it.
+func makePassphrasePipe(passphrase string) (*os.File, error) {
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		return nil, fmt.Errorf("error making passphrase pipe: %v", err)
 	}
 
-	if stderrBytes, err := ioutil.ReadAll(stderrPipe); err != nil {
-		returnErr = fmt.Errorf("error reading stderr: %v", err)
-		return
-	} else {
-		stderr = string(stderrBytes)
+	// a passphrase fits in the pipe's buffer, so write it before starting
+	// gpg rather than in a goroutine
+	_, err = writer.Write([]byte(passphrase + "\n"))
+	writer.Close()
+	if err != nil {
+		reader.Close()
+		return nil, fmt.Errorf("error writing passphrase: %v", err)
 	}
+	return reader, nil
+}
 
-	if err := cmd.Wait(); err != nil {
-		returnErr = fmt.Errorf("gpg failed with error '%s'", err)
-		return
+// runEditKey sends commands to the `--edit-key` prompt for the given key.
+// The passphrase goes through its own pipe rather than stdin, so it can't
+// be confused with the commands. It returns BadPasswordError if the
+// passphrase is wrong.
+func (g *GnuPG) runEditKey(fp fingerprint.Fingerprint, passphrase string, commands string) (stdout string, stderr string, err error) {
+	passphraseReader, err := makePassphrasePipe(passphrase)
+	if err != nil {
+		return "", "", err
 	}
+	defer passphraseReader.Close()
 
-	return
+	stdout, stderr, err = g.runCommand(
+		context.Background(),
+		strings.NewReader(commands),
+		[]*os.File{passphraseReader},
+		"--pinentry-mode", "loopback", // don't use OS password prompt
+		"--passphrase-fd", "3", // the first of the extra files
+		"--command-fd", "0",
+		"--status-fd", "1",
+		"--edit-key", fp.Hex(),
+	)
+	if strings.Contains(stdout, badPassphraseStatus) || strings.Contains(stderr, badPassphrase) {
+		return stdout, stderr, &BadPasswordError{}
+	}
+	return stdout, stderr, err
 }
 
 func (g *GnuPG) prependGlobalArguments(arguments ...string) []string {
-	var globalArguments = []string{
-		"-vv",
+	globalArguments := g.Verbosity.arguments()
+	globalArguments = append(globalArguments,
 		"--keyid-format", "0xlong",
 		"--batch",
 		"--no-tty",
-	}
+	)
 	if g.homeDir != "" {
 		homeDirArgs := []string{"--homedir", g.homeDir}
 		globalArguments = append(globalArguments, homeDirArgs...)
@@ -387,4 +739,7 @@ const (
 	loopbackUnsupported       = `setting pinentry mode 'loopback' failed: Not supported`
 	badPassphrase             = "Bad passphrase"
 	noPassphrase              = "No passphrase given"
+	badPassphraseStatus       = "[GNUPG:] BAD_PASSPHRASE"
+	noSecretKey               = "No secret key"
+	noPublicKey               = "No public key"
 )
@@ -1,13 +1,18 @@
 package gpgwrapper
 
 import (
+	"bytes"
+	"context"
 	"fmt"
 	"io/ioutil"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/fluidkeys/fluidkeys/assert"
+	"github.com/fluidkeys/fluidkeys/exampledata"
 	"github.com/fluidkeys/fluidkeys/fingerprint"
 )
 
@@ -48,6 +53,360 @@ func TestHomeDir(t *testing.T) {
 	})
 }
 
+func TestIsAvailable(t *testing.T) {
+	t.Run("with a binary that doesn't exist", func(t *testing.T) {
+		gpg := GnuPG{fullGpgPath: "/nonexistent/gpg"}
+		assert.Equal(t, false, gpg.IsAvailable())
+	})
+
+	t.Run("with a file that isn't executable", func(t *testing.T) {
+		path := filepath.Join(makeTempGnupgHome(t), "gpg")
+		if err := ioutil.WriteFile(path, []byte("#!/bin/sh\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		gpg := GnuPG{fullGpgPath: path}
+		assert.Equal(t, false, gpg.IsAvailable())
+	})
+
+	t.Run("with an executable that isn't gpg", func(t *testing.T) {
+		// IsAvailable doesn't run the binary, so it doesn't notice
+		gpg := GnuPG{fullGpgPath: makeFakeGpgScript(t, "exit 2")}
+		assert.Equal(t, true, gpg.IsAvailable())
+		assert.Equal(t, false, gpg.IsWorking())
+	})
+
+	t.Run("with the gpg binary from Load", func(t *testing.T) {
+		gpg, err := Load()
+		assertNoError(t, err)
+		assert.Equal(t, true, gpg.IsAvailable())
+	})
+}
+
+func TestVersionCache(t *testing.T) {
+	countFile := filepath.Join(makeTempGnupgHome(t), "count")
+	fakeGpg := makeFakeGpgScript(t,
+		"echo run >> '"+countFile+"'\necho 'gpg (GnuPG) 2.2.4'")
+
+	timesRun := func() int {
+		contents, err := ioutil.ReadFile(countFile)
+		if os.IsNotExist(err) {
+			return 0
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		return strings.Count(string(contents), "run")
+	}
+
+	gpg, err := LoadWithPaths(fakeGpg, makeTempGnupgHome(t))
+	assertNoError(t, err)
+
+	t.Run("only runs gpg once", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			version, err := gpg.Version()
+			assertNoError(t, err)
+			assert.Equal(t, "2.2.4", version)
+		}
+		errs := runConcurrently(5, func() error {
+			_, err := gpg.Version()
+			return err
+		})
+		assert.Equal(t, 0, len(errs))
+		assert.Equal(t, 1, timesRun())
+	})
+
+	t.Run("runs gpg again after ClearVersionCache", func(t *testing.T) {
+		gpg.ClearVersionCache()
+		version, err := gpg.Version()
+		assertNoError(t, err)
+		assert.Equal(t, "2.2.4", version)
+
+		_, err = gpg.Version()
+		assertNoError(t, err)
+		assert.Equal(t, 2, timesRun())
+	})
+}
+
+func TestLoadWithPaths(t *testing.T) {
+	gpgBinary, err := findGpgBinary()
+	assert.ErrorIsNil(t, err)
+
+	t.Run("with a binary path and home directory", func(t *testing.T) {
+		homeDir := makeTempGnupgHome(t)
+		gpg, err := LoadWithPaths(gpgBinary, homeDir)
+		assertNoError(t, err)
+
+		gotDirectory, err := gpg.HomeDir()
+		assertNoError(t, err)
+		assert.Equal(t, homeDir, gotDirectory)
+	})
+
+	t.Run("with no binary path", func(t *testing.T) {
+		gpg, err := LoadWithPaths("", "")
+		assertNoError(t, err)
+		assert.Equal(t, gpgBinary, gpg.fullGpgPath)
+	})
+
+	t.Run("with a binary that doesn't exist", func(t *testing.T) {
+		_, err := LoadWithPaths("/nonexistent/gpg", "")
+		assert.ErrorIsNotNil(t, err)
+	})
+}
+
+func TestRunSeparatesStdoutAndStderr(t *testing.T) {
+	gpg := GnuPG{fullGpgPath: makeFakeGpgScript(t,
+		"echo 'pub:u:2048:1:E162F6D17FEABECC'; echo 'gpg: WARNING: unsafe permissions' >&2")}
+
+	t.Run("run returns only stdout", func(t *testing.T) {
+		stdout, err := gpg.run("--list-keys")
+		assertNoError(t, err)
+		assert.Equal(t, "pub:u:2048:1:E162F6D17FEABECC\n", stdout)
+	})
+
+	t.Run("runWithStdin returns stdout and stderr", func(t *testing.T) {
+		stdout, stderr, err := gpg.runWithStdin("", "--list-keys")
+		assertNoError(t, err)
+		assert.Equal(t, "pub:u:2048:1:E162F6D17FEABECC\n", stdout)
+		assert.Equal(t, "gpg: WARNING: unsafe permissions\n", stderr)
+	})
+
+	t.Run("run includes stderr in the error if gpg fails", func(t *testing.T) {
+		failingGpg := GnuPG{fullGpgPath: makeFakeGpgScript(t,
+			"echo 'partial output'; echo 'gpg: fatal error' >&2; exit 2")}
+
+		_, err := failingGpg.run("--list-keys")
+
+		gpgFailed, ok := err.(*ErrGpgFailed)
+		if !ok {
+			t.Fatalf("expected ErrGpgFailed, got %v", err)
+		}
+		assert.Equal(t, "gpg: fatal error\n", gpgFailed.Stderr)
+	})
+}
+
+func TestVersionContext(t *testing.T) {
+	gpg := makeGpgWithTempHome(t)
+
+	t.Run("with a context that's already cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		start := time.Now()
+		_, err := gpg.VersionContext(ctx)
+
+		if err != context.Canceled {
+			t.Fatalf("expected context.Canceled, got %v", err)
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Fatalf("expected VersionContext to return promptly, took %v", elapsed)
+		}
+	})
+
+}
+
+func TestRunContext(t *testing.T) {
+	// hungGpg stands in for a gpg that never exits, e.g. waiting on pinentry
+	// exec so killing the script kills the sleep, too
+	hungGpg := GnuPG{fullGpgPath: makeFakeGpgScript(t, "exec sleep 30")}
+
+	t.Run("runContext kills gpg when the context times out", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		_, err := hungGpg.runContext(ctx, "--version")
+
+		assert.Equal(t, context.DeadlineExceeded, err)
+		if elapsed := time.Since(start); elapsed > 5*time.Second {
+			t.Fatalf("expected runContext to return promptly, took %v", elapsed)
+		}
+	})
+
+	t.Run("runWithReaderContext kills gpg when the context times out", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		_, _, err := hungGpg.runWithReaderContext(ctx, strings.NewReader(""), "--import")
+
+		assert.Equal(t, context.DeadlineExceeded, err)
+		if elapsed := time.Since(start); elapsed > 5*time.Second {
+			t.Fatalf("expected runWithReaderContext to return promptly, took %v", elapsed)
+		}
+	})
+}
+
+func TestWithMaxConcurrency(t *testing.T) {
+	t.Run("runs one gpg at a time", func(t *testing.T) {
+		// the script fails if another copy is running, since mkdir fails
+		// if the directory already exists
+		lockDir := filepath.Join(makeTempGnupgHome(t), "running")
+		gpg := GnuPG{fullGpgPath: makeFakeGpgScript(t, fmt.Sprintf(
+			"mkdir '%s' || exit 1; sleep 0.05; rmdir '%s'", lockDir, lockDir))}
+
+		limited := gpg.WithMaxConcurrency(1)
+		for _, err := range runConcurrently(10, func() error {
+			_, err := limited.run("--version")
+			return err
+		}) {
+			t.Errorf("expected no errors, got %v", err)
+		}
+	})
+
+	t.Run("waiting for a turn respects the context", func(t *testing.T) {
+		hungGpg := GnuPG{fullGpgPath: makeFakeGpgScript(t, "exec sleep 30")}
+		limited := hungGpg.WithMaxConcurrency(1)
+
+		firstCtx, cancelFirst := context.WithCancel(context.Background())
+		firstDone := make(chan struct{})
+		go func() {
+			limited.runContext(firstCtx, "--version")
+			close(firstDone)
+		}()
+		defer func() {
+			cancelFirst()
+			<-firstDone
+		}()
+		time.Sleep(50 * time.Millisecond) // let the first call start gpg
+
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		_, err := limited.runContext(ctx, "--version")
+		assert.Equal(t, context.DeadlineExceeded, err)
+	})
+
+	t.Run("with no limit", func(t *testing.T) {
+		gpg := makeGpgWithTempHome(t)
+		assert.Equal(t, (chan struct{})(nil), gpg.WithMaxConcurrency(0).running)
+	})
+
+	t.Run("concurrent imports and exports", func(t *testing.T) {
+		tempGpg := makeGpgWithTempHome(t)
+		gpg := tempGpg.WithMaxConcurrency(defaultMaxConcurrency)
+
+		for _, err := range runConcurrently(8, func() error {
+			if _, err := gpg.ImportArmoredKey(exampledata.ExamplePublicKey4); err != nil {
+				return err
+			}
+			_, err := gpg.ExportPublicKey(exampledata.ExampleFingerprint4)
+			return err
+		}) {
+			t.Errorf("expected no errors, got %v", err)
+		}
+	})
+}
+
+func TestVerbosity(t *testing.T) {
+	tests := []struct {
+		verbosity Verbosity
+		expected  []string
+	}{
+		{VerbosityVerbose, []string{"--verbose", "--verbose", "--keyid-format"}},
+		{VerbosityNormal, []string{"--keyid-format"}},
+		{VerbosityQuiet, []string{"--quiet", "--keyid-format"}},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("with verbosity %d", test.verbosity), func(t *testing.T) {
+			runner := &fakeRunner{stdout: "gpg (GnuPG) 2.2.4\n"}
+			gpg := makeGpgWithFakeRunner(runner)
+			gpg.Verbosity = test.verbosity
+
+			_, err := gpg.Version()
+			assertNoError(t, err)
+			assert.Equal(t, test.expected, runner.calls[0][:len(test.expected)])
+		})
+	}
+
+	t.Run("the default is verbose", func(t *testing.T) {
+		assert.Equal(t, VerbosityVerbose, GnuPG{}.Verbosity)
+	})
+
+	t.Run("doesn't affect parsing the key listing", func(t *testing.T) {
+		for _, verbosity := range []Verbosity{VerbosityVerbose, VerbosityNormal, VerbosityQuiet} {
+			gpg := makeGpgWithTempHome(t)
+			gpg.Verbosity = verbosity
+			_, err := gpg.ImportArmoredKey(exampledata.ExamplePublicKey4)
+			assertNoError(t, err)
+
+			keys, err := gpg.ListPublicKeys()
+			assertNoError(t, err)
+			assert.Equal(t, 1, len(keys))
+			assert.Equal(t, exampledata.ExampleFingerprint4, keys[0].Fingerprint)
+		}
+	})
+}
+
+func TestCommandLogger(t *testing.T) {
+	gpg := makeGpgWithTempHome(t)
+
+	var logged [][]string
+	gpg.CommandLogger = func(args []string) {
+		logged = append(logged, args)
+	}
+
+	t.Run("logger receives the full argument vector", func(t *testing.T) {
+		_, err := gpg.Version()
+		assertNoError(t, err)
+
+		expected := [][]string{{
+			gpg.fullGpgPath,
+			"--verbose", "--verbose",
+			"--keyid-format", "0xlong",
+			"--batch",
+			"--no-tty",
+			"--homedir", gpg.homeDir,
+			"--version",
+		}}
+		assert.Equal(t, expected, logged)
+	})
+
+	t.Run("with DryRun, logs the command without running it", func(t *testing.T) {
+		logged = nil
+		failingGpg := GnuPG{
+			fullGpgPath:   makeFakeGpgScript(t, "echo 'should not run' >&2; exit 2"),
+			DryRun:        true,
+			CommandLogger: gpg.CommandLogger,
+		}
+
+		stdout, stderr, err := failingGpg.runWithStdin("", "--import")
+		assertNoError(t, err)
+		assert.Equal(t, "", stdout)
+		assert.Equal(t, "", stderr)
+		assert.Equal(t, 1, len(logged))
+		assert.Equal(t, "--import", logged[0][len(logged[0])-1])
+	})
+}
+
+// runConcurrently calls f from n goroutines at once and returns the errors
+// it returned.
+func runConcurrently(n int, f func() error) []error {
+	results := make(chan error, n)
+	for i := 0; i < n; i++ {
+		go func() { results <- f() }()
+	}
+
+	var errs []error
+	for i := 0; i < n; i++ {
+		if err := <-results; err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// makeFakeGpgScript writes a shell script to run instead of gpg and returns
+// its path.
+func makeFakeGpgScript(t *testing.T, body string) string {
+	t.Helper()
+	path := filepath.Join(makeTempGnupgHome(t), "gpg")
+	script := "#!/bin/sh\n" + body + "\n"
+	if err := ioutil.WriteFile(path, []byte(script), 0700); err != nil {
+		t.Fatalf("failed to write script: %v", err)
+	}
+	return path
+}
+
 func TestRunningGPG(t *testing.T) {
 	gpg := makeGpgWithTempHome(t)
 
@@ -108,13 +467,26 @@ func TestImportPublicKey(t *testing.T) {
 	gpg := makeGpgWithTempHome(t)
 
 	t.Run("with valid public key", func(t *testing.T) {
-		_, err := gpg.ImportArmoredKey(ExamplePublicKey)
+		result, err := gpg.ImportArmoredKey(ExamplePublicKey)
 		assertNoError(t, err)
+		assert.Equal(t, ImportResult{Imported: 1}, result)
+	})
+
+	t.Run("with a public key that's already imported", func(t *testing.T) {
+		result, err := gpg.ImportArmoredKey(ExamplePublicKey)
+		assertNoError(t, err)
+		assert.Equal(t, ImportResult{Unchanged: 1}, result)
 	})
 
 	t.Run("with valid private key", func(t *testing.T) {
-		_, err := gpg.ImportArmoredKey(ExamplePrivateKey)
+		result, err := gpg.ImportArmoredKey(ExamplePrivateKey)
 		assertNoError(t, err)
+		assert.Equal(t, 1, result.SecretImported)
+	})
+
+	t.Run("with something that isn't a key", func(t *testing.T) {
+		_, err := gpg.ImportArmoredKey("not a key")
+		assert.ErrorIsNotNil(t, err)
 	})
 }
 
@@ -132,7 +504,7 @@ func TestListSecretKeys(t *testing.T) {
 		t.Fatalf("expected 1 secret key, got %d: %v", len(secretKeys), secretKeys)
 	}
 
-	expectedKey := SecretKeyListing{
+	expectedKey := KeyListing{
 		Fingerprint: fingerprint.MustParse("C16B 89AC 31CD F3B7 8DA3  3AAE 1D20 FC95 4793 5FC6"),
 		Uids:        []string{"test@example.com"},
 		Created:     time.Date(2018, 8, 22, 12, 8, 23, 0, time.UTC),
@@ -148,21 +520,102 @@ func TestExportPublicKey(t *testing.T) {
 	gpg.ImportArmoredKey(ExamplePrivateKey)
 
 	t.Run("with a valid fingerprint", func(t *testing.T) {
-		_, err := gpg.ExportPublicKey(fingerprint.MustParse("8FBC 0768 76F2 B042 AE2B  A37B 0BBD 7E7E 5B85 C8D3"))
+		armoredKey, err := gpg.ExportPublicKey(fingerprint.MustParse("8FBC 0768 76F2 B042 AE2B  A37B 0BBD 7E7E 5B85 C8D3"))
 
 		if err != nil {
 			t.Errorf("Failed to run ExportPublicKey: %v", err)
 		}
+		if !strings.HasPrefix(armoredKey, publicHeader) {
+			t.Errorf("expected armored key to start with '%s', got '%s'", publicHeader, armoredKey)
+		}
+	})
+
+	t.Run("with a fingerprint that isn't in the keyring", func(t *testing.T) {
+		fp := fingerprint.MustParse("0000 0000 0000 0000 0000 0000 0000 0000 0000 0000")
+		_, err := gpg.ExportPublicKey(fp)
 
+		assert.Equal(t, &ErrKeyNotFound{Fingerprint: fp}, err)
 	})
 
-	t.Run("with an invalid fingerprint", func(t *testing.T) {
-		_, err := gpg.ExportPublicKey(fingerprint.MustParse("0000 0000 0000 0000 0000 0000 0000 0000 0000 0000"))
+	t.Run("with an unset fingerprint", func(t *testing.T) {
+		_, err := gpg.ExportPublicKey(fingerprint.Fingerprint{})
 
-		if err == nil {
-			t.Errorf("ExportPublicKey should have returned an error but didnt")
+		assert.ErrorIsNotNil(t, err)
+	})
+
+	t.Run("ExportPublicKeyBytes armored", func(t *testing.T) {
+		output, err := gpg.ExportPublicKeyBytes(fingerprint.MustParse("8FBC 0768 76F2 B042 AE2B  A37B 0BBD 7E7E 5B85 C8D3"), true)
+		assertNoError(t, err)
+
+		if !bytes.HasPrefix(output, []byte(publicHeader)) {
+			t.Errorf("expected armored key to start with '%s', got '%s'", publicHeader, output)
+		}
+	})
+
+	t.Run("ExportPublicKeyBytes binary", func(t *testing.T) {
+		fp := fingerprint.MustParse("8FBC 0768 76F2 B042 AE2B  A37B 0BBD 7E7E 5B85 C8D3")
+		output, err := gpg.ExportPublicKeyBytes(fp, false)
+		assertNoError(t, err)
+
+		if len(output) == 0 || bytes.HasPrefix(output, []byte(publicHeader)) {
+			t.Fatalf("expected a binary key, got '%s'", output)
+		}
+		// the first byte of an OpenPGP packet always has bit 7 set
+		if output[0]&0x80 == 0 {
+			t.Errorf("expected an OpenPGP packet, got first byte 0x%02x", output[0])
+		}
+
+		armored, err := gpg.ExportPublicKeyBytes(fp, true)
+		assertNoError(t, err)
+		if len(output) >= len(armored) {
+			t.Errorf("expected binary key (%d bytes) to be smaller than armored (%d bytes)", len(output), len(armored))
+		}
+	})
+
+	t.Run("ExportPublicKeyBytes binary with a fingerprint that isn't in the keyring", func(t *testing.T) {
+		fp := fingerprint.MustParse("0000 0000 0000 0000 0000 0000 0000 0000 0000 0000")
+		_, err := gpg.ExportPublicKeyBytes(fp, false)
+
+		assert.Equal(t, &ErrKeyNotFound{Fingerprint: fp}, err)
+	})
+}
+
+func TestExportSecretSubkeys(t *testing.T) {
+	gpg := makeGpgWithTempHome(t)
+	gpg.ImportArmoredKey(ExamplePublicKey)
+	gpg.ImportArmoredKey(ExamplePrivateKey)
+	fp := fingerprint.MustParse("C16B 89AC 31CD F3B7 8DA3  3AAE 1D20 FC95 4793 5FC6")
+
+	t.Run("exports subkeys with a stub for the primary key", func(t *testing.T) {
+		armoredSubkeys, err := gpg.ExportSecretSubkeys(fp, "foo")
+		assertNoError(t, err)
+
+		laptopGpg := makeGpgWithTempHome(t)
+		_, err = laptopGpg.ImportArmoredKey(armoredSubkeys)
+		assertNoError(t, err)
+
+		listing, err := laptopGpg.run("--with-colons", "--list-secret-keys", fp.Hex())
+		assertNoError(t, err)
+
+		for _, line := range strings.Split(listing, "\n") {
+			cols := strings.Split(line, ":")
+			if len(cols) < 15 {
+				continue
+			}
+			// field 15 is "#" if the secret key isn't available
+			if cols[0] == "sec" && cols[14] != "#" {
+				t.Fatalf("expected primary secret key to be a stub, got: %s", line)
+			}
+			if cols[0] == "ssb" && cols[14] == "#" {
+				t.Fatalf("expected secret subkey to be exported, got: %s", line)
+			}
 		}
 	})
+
+	t.Run("with an invalid fingerprint", func(t *testing.T) {
+		_, err := gpg.ExportSecretSubkeys(fingerprint.MustParse("0000 0000 0000 0000 0000 0000 0000 0000 0000 0000"), "foo")
+		assert.ErrorIsNotNil(t, err)
+	})
 }
 
 func TestExportPrivateKey(t *testing.T) {
@@ -171,11 +624,14 @@ func TestExportPrivateKey(t *testing.T) {
 	gpg.ImportArmoredKey(ExamplePrivateKey)
 
 	t.Run("with a valid fingerprint and password", func(t *testing.T) {
-		_, err := gpg.ExportPrivateKey(fingerprint.MustParse("C16B 89AC 31CD F3B7 8DA3  3AAE 1D20 FC95 4793 5FC6"), "foo")
+		armoredKey, err := gpg.ExportPrivateKey(fingerprint.MustParse("C16B 89AC 31CD F3B7 8DA3  3AAE 1D20 FC95 4793 5FC6"), "foo")
 
 		if err != nil {
 			t.Errorf("Failed to run ExportPrivateKey: %v", err)
 		}
+		if !strings.HasPrefix(armoredKey, privateHeader) {
+			t.Errorf("expected armored key to start with '%s', got '%s'", privateHeader, armoredKey)
+		}
 	})
 
 	t.Run("with an invalid password", func(t *testing.T) {
@@ -204,17 +660,52 @@ func TestExportPrivateKey(t *testing.T) {
 		if _, ok := err.(*BadPasswordError); !ok {
 			t.Errorf("ExportPrivateKey should have returned a BadPasswordError but didnt. err: %v, output: %s", err, output)
 		}
+		assert.Equal(t, "", output)
 	})
 
 	t.Run("with an invalid fingerprint", func(t *testing.T) {
-		_, err := gpg.ExportPrivateKey(fingerprint.MustParse("0000 0000 0000 0000 0000 0000 0000 0000 0000 0000"), "bar")
+		fp := fingerprint.MustParse("0000 0000 0000 0000 0000 0000 0000 0000 0000 0000")
+		_, err := gpg.ExportPrivateKey(fp, "bar")
 
-		if err == nil {
-			t.Errorf("ExportPrivateKey should have returned an error but didnt")
+		assert.Equal(t, &ErrKeyNotFound{Fingerprint: fp}, err)
+	})
+
+	t.Run("ExportPrivateKeyBytes armored", func(t *testing.T) {
+		output, err := gpg.ExportPrivateKeyBytes(fingerprint.MustParse("C16B 89AC 31CD F3B7 8DA3  3AAE 1D20 FC95 4793 5FC6"), "foo", true)
+		assertNoError(t, err)
+
+		if !bytes.HasPrefix(output, []byte(privateHeader)) {
+			t.Errorf("expected armored key to start with '%s', got '%s'", privateHeader, output)
+		}
+	})
+
+	t.Run("ExportPrivateKeyBytes binary", func(t *testing.T) {
+		output, err := gpg.ExportPrivateKeyBytes(fingerprint.MustParse("C16B 89AC 31CD F3B7 8DA3  3AAE 1D20 FC95 4793 5FC6"), "foo", false)
+		assertNoError(t, err)
+
+		if len(output) == 0 || bytes.HasPrefix(output, []byte(privateHeader)) {
+			t.Fatalf("expected a binary key, got '%s'", output)
+		}
+		if output[0]&0x80 == 0 {
+			t.Errorf("expected an OpenPGP packet, got first byte 0x%02x", output[0])
 		}
 	})
 }
 
+func TestGetArgsExportPrivateKey(t *testing.T) {
+	fp := fingerprint.MustParse("C16B 89AC 31CD F3B7 8DA3  3AAE 1D20 FC95 4793 5FC6")
+
+	t.Run("armored", func(t *testing.T) {
+		args := getArgsExportPrivateKeyWithoutPinentry(fp, "--export-secret-keys", true)
+		assert.Equal(t, []string{"--passphrase-fd", "0", "--armor", "--export-secret-keys", fp.Hex()}, args)
+	})
+
+	t.Run("binary", func(t *testing.T) {
+		args := getArgsExportPrivateKeyWithPinentry(fp, "--export-secret-keys", false)
+		assert.Equal(t, []string{"--pinentry-mode", "loopback", "--passphrase-fd", "0", "--export-secret-keys", fp.Hex()}, args)
+	})
+}
+
 func TestParseListSecretKeys(t *testing.T) {
 	t.Run("test parsing example colon delimited data", func(t *testing.T) {
 		result, err := parseListSecretKeys(exampleListSecretKeys)
@@ -226,7 +717,7 @@ func TestParseListSecretKeys(t *testing.T) {
 			t.Fatalf("expected 2 secret keys, got %d: %v", len(result), result)
 		}
 
-		expectedFirst := SecretKeyListing{
+		expectedFirst := KeyListing{
 			Fingerprint: fingerprint.MustParse("A999 B749 8D1A 8DC4 73E5  3C92 309F 635D AD1B 5517"),
 			Created:     time.Date(2014, 10, 31, 21, 34, 34, 0, time.UTC), // 31 October 2014 21:34:34
 			Uids: []string{
@@ -235,7 +726,7 @@ func TestParseListSecretKeys(t *testing.T) {
 			},
 		}
 
-		expectedSecond := SecretKeyListing{
+		expectedSecond := KeyListing{
 			Fingerprint: fingerprint.MustParse("B79F 0840 DEF1 2EBB A72F  F72D 7327 A44C 2157 A758"),
 			Created:     time.Date(2018, 9, 4, 16, 15, 46, 0, time.UTC), // Tue Sep  4 17:15:46 BST 2018
 			Uids:        []string{"<paul@fluidkeys.com>"},
@@ -248,6 +739,25 @@ func TestParseListSecretKeys(t *testing.T) {
 		assertEqual(t, expectedSecond, gotSecond)
 	})
 
+	t.Run("parser includes the same fields as ListPublicKeys", func(t *testing.T) {
+		result, err := parseListSecretKeys(exampleListSecretKeys)
+		assertNoError(t, err)
+
+		got := result[0]
+		assert.Equal(t, uint64(0x309F635DAD1B5517), got.KeyId)
+		assert.Equal(t, "rsa4096", got.Algorithm)
+		assert.Equal(t, "scESC", got.Capabilities)
+		assert.AssertEqualTimes(t, time.Date(2018, 11, 12, 8, 54, 5, 0, time.UTC), *got.Expires)
+
+		if len(got.Subkeys) != 2 {
+			t.Fatalf("expected 2 subkeys, got %d: %v", len(got.Subkeys), got.Subkeys)
+		}
+		assert.Equal(t, fingerprint.MustParse("58B67D78347ACEAD63C0B185627B1B4E8E532C34"), got.Subkeys[0].Fingerprint)
+		assert.Equal(t, "e", got.Subkeys[0].Capabilities)
+		assert.Equal(t, fingerprint.MustParse("CF2954DA9D72255C217CF92A0AC6AD63E8E8A9B0"), got.Subkeys[1].Fingerprint)
+		assert.Equal(t, "s", got.Subkeys[1].Capabilities)
+	})
+
 	t.Run("parser ignores keys with invalid creation time", func(t *testing.T) {
 		result, err := parseListSecretKeys(exampleListSecretKeysInvalidCreationTime)
 		if err != nil {
@@ -351,7 +861,43 @@ func TestUnquoteColons(t *testing.T) {
 	}
 }
 
-func assertEqual(t *testing.T, expected SecretKeyListing, got SecretKeyListing) {
+func TestNormalizeFingerprint(t *testing.T) {
+	const canonical = "A999B7498D1A8DC473E53C92309F635DAD1B5517"
+
+	var validInputs = []string{
+		"A999B7498D1A8DC473E53C92309F635DAD1B5517",
+		"A999 B749 8D1A 8DC4 73E5  3C92 309F 635D AD1B 5517",
+		"0xA999B7498D1A8DC473E53C92309F635DAD1B5517",
+		"a999b7498d1a8dc473e53c92309f635dad1b5517",
+		"  A999B7498D1A8DC473E53C92309F635DAD1B5517\n",
+	}
+
+	for _, input := range validInputs {
+		t.Run(fmt.Sprintf("NormalizeFingerprint(%q)", input), func(t *testing.T) {
+			got, err := NormalizeFingerprint(input)
+			assertNoError(t, err)
+			assert.Equal(t, canonical, got)
+		})
+	}
+
+	t.Run("rejects short key IDs", func(t *testing.T) {
+		for _, keyId := range []string{"AD1B5517", "0x309F635DAD1B5517"} {
+			_, err := NormalizeFingerprint(keyId)
+			if err == nil || !strings.Contains(err.Error(), "full 40 character fingerprint") {
+				t.Errorf("expected error suggesting the full fingerprint for %s, got %v", keyId, err)
+			}
+		}
+	})
+
+	t.Run("rejects invalid input", func(t *testing.T) {
+		for _, input := range []string{"", "not a fingerprint", "A999B7498D1A8DC473E53C92309F635DAD1B551"} {
+			_, err := NormalizeFingerprint(input)
+			assert.ErrorIsNotNil(t, err)
+		}
+	})
+}
+
+func assertEqual(t *testing.T, expected KeyListing, got KeyListing) {
 	t.Helper()
 	if expected.Fingerprint != got.Fingerprint {
 		t.Fatalf("fingerprints don't match, expected '%s', got '%s'",
@@ -0,0 +1,281 @@
+// Copyright 2018 Paul Furley and Ian Drysdale
+//
+// This file is part of Fluidkeys Client which makes it simple to use OpenPGP.
+//
+// Fluidkeys Client is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Fluidkeys Client is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with Fluidkeys Client.  If not, see <https://www.gnu.org/licenses/>.
+
+package gpgwrapper
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/fluidkeys/crypto/openpgp/armor"
+	"github.com/fluidkeys/crypto/openpgp/packet"
+)
+
+// ImportResult summarises what GnuPG did when importing keys.
+type ImportResult struct {
+	// Imported is the number of new keys added to the keyring.
+	Imported int
+
+	// Unchanged is the number of keys which were already in the keyring.
+	Unchanged int
+
+	// SecretImported is the number of secret keys added to the keyring.
+	SecretImported int
+}
+
+// ImportFromReader imports keys read from r, for example os.Stdin when
+// being piped into (`curl ... | fk import`). The data is streamed to GnuPG
+// rather than read into memory first, so it's suitable for large keyring
+// dumps.
+func (g *GnuPG) ImportFromReader(r io.Reader) (ImportResult, error) {
+	stdout, stderr, err := g.runWithReader(r, "--status-fd", "1", "--import")
+	if err != nil {
+		return ImportResult{}, fmt.Errorf("problem importing keys, %v: %s", err, stderr)
+	}
+
+	result, err := importResult(stdout, stderr)
+	if err != nil {
+		return result, fmt.Errorf("problem importing keys, %v", err)
+	}
+	return result, nil
+}
+
+// ImportFromFile imports the keys in the file at path, streaming it to
+// GnuPG like ImportFromReader. If the file can't be opened it returns an
+// *ErrFileAccess.
+func (g *GnuPG) ImportFromFile(path string) (ImportResult, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return ImportResult{}, &ErrFileAccess{Path: path, Err: err}
+	}
+	defer f.Close()
+
+	return g.ImportFromReader(f)
+}
+
+// importResult reads what GnuPG imported from the output of
+// `gpg --status-fd 1 --import`, falling back to the summary on stderr if
+// there's no status line. GnuPG can exit successfully having imported
+// nothing (e.g. for a corrupt key), so an ImportResult with no keys counted
+// is returned as an error.
+func importResult(stdout string, stderr string) (ImportResult, error) {
+	result, err := parseImportResult(stdout)
+	if err != nil {
+		result, err = parseImportSummary(stderr)
+		if err != nil {
+			return ImportResult{}, err
+		}
+	}
+
+	if result.Imported == 0 && result.Unchanged == 0 && result.SecretImported == 0 {
+		return result, fmt.Errorf("GnuPG didn't import anything: %s", stderr)
+	}
+	return result, nil
+}
+
+// parseImportResult finds the IMPORT_RES line in the output of
+// `gpg --status-fd 1 --import` and returns its counts. For the format, see
+// https://github.com/gpg/gnupg/blob/master/doc/DETAILS#import_res
+func parseImportResult(statusOutput string) (ImportResult, error) {
+	for _, line := range strings.Split(statusOutput, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 13 || fields[0] != statusPrefix || fields[1] != "IMPORT_RES" {
+			continue
+		}
+
+		counts := fields[2:]
+		var result ImportResult
+		var err error
+
+		if result.Imported, err = strconv.Atoi(counts[2]); err != nil {
+			return ImportResult{}, fmt.Errorf("error parsing imported count: %v", err)
+		}
+		if result.Unchanged, err = strconv.Atoi(counts[4]); err != nil {
+			return ImportResult{}, fmt.Errorf("error parsing unchanged count: %v", err)
+		}
+		if result.SecretImported, err = strconv.Atoi(counts[10]); err != nil {
+			return ImportResult{}, fmt.Errorf("error parsing secret imported count: %v", err)
+		}
+		return result, nil
+	}
+
+	return ImportResult{}, fmt.Errorf("GnuPG didn't report an import result, is the input a valid key?")
+}
+
+// parseImportSummary reads the human-readable summary GnuPG writes to stderr
+// after importing, for example:
+//
+//     gpg: Total number processed: 2
+//     gpg:               imported: 1
+//     gpg:              unchanged: 1
+//     gpg:   secret keys imported: 1
+func parseImportSummary(stderr string) (ImportResult, error) {
+	var result ImportResult
+	sawTotal := false
+
+	for _, line := range strings.Split(stderr, "\n") {
+		line = strings.TrimSpace(strings.TrimPrefix(line, "gpg:"))
+		colon := strings.LastIndex(line, ":")
+		if colon == -1 {
+			continue
+		}
+		count, err := strconv.Atoi(strings.TrimSpace(line[colon+1:]))
+		if err != nil {
+			continue
+		}
+
+		switch line[:colon] {
+		case "Total number processed":
+			sawTotal = true
+		case "imported":
+			result.Imported = count
+		case "unchanged", "not changed":
+			result.Unchanged = count
+		case "secret keys imported":
+			result.SecretImported = count
+		}
+	}
+
+	if !sawTotal {
+		return ImportResult{}, fmt.Errorf("GnuPG didn't report an import summary, is the input a valid key?")
+	}
+	return result, nil
+}
+
+const statusPrefix = "[GNUPG:]"
+
+// ImportLimits guard against keys which have been bloated to make GnuPG hang,
+// as in the 2019 keyserver flooding attack where keys were given hundreds of
+// thousands of junk certifications. Zero means no limit.
+type ImportLimits struct {
+	// MaxArmoredBytes is the largest armored input to accept.
+	MaxArmoredBytes int
+
+	// MaxSignatures is the largest number of signature packets to accept
+	// across all the keys in the input.
+	MaxSignatures int
+
+	// ImportClean asks GnuPG to drop unusable signatures (e.g. from keys
+	// not in the keyring) as it imports.
+	ImportClean bool
+}
+
+// DefaultImportLimits are generous enough for well-connected keys but reject
+// flooded ones.
+var DefaultImportLimits = ImportLimits{
+	MaxArmoredBytes: 1024 * 1024,
+	MaxSignatures:   5000,
+	ImportClean:     true,
+}
+
+// ImportWithLimits imports the given armored key(s) after checking them
+// against the limits, without running GnuPG if they're exceeded. In that
+// case it returns an *ErrKeyTooLarge.
+func (g *GnuPG) ImportWithLimits(armored string, limits ImportLimits) (ImportResult, error) {
+	if limits.MaxArmoredBytes > 0 && len(armored) > limits.MaxArmoredBytes {
+		return ImportResult{}, &ErrKeyTooLarge{Limit: "bytes", Got: len(armored), Max: limits.MaxArmoredBytes}
+	}
+
+	cleaned, err := CleanArmoredBlock(armored)
+	if err != nil {
+		return ImportResult{}, fmt.Errorf("problem importing keys, %v", err)
+	}
+
+	if limits.MaxSignatures > 0 {
+		count, err := countSignaturePackets(cleaned)
+		if err != nil {
+			return ImportResult{}, fmt.Errorf("problem importing keys, %v", err)
+		}
+		if count > limits.MaxSignatures {
+			return ImportResult{}, &ErrKeyTooLarge{Limit: "signatures", Got: count, Max: limits.MaxSignatures}
+		}
+	}
+
+	args := []string{"--status-fd", "1"}
+	if limits.ImportClean {
+		args = append(args, "--import-options", "import-clean")
+	}
+	args = append(args, "--import")
+
+	stdout, stderr, err := g.runWithStdin(cleaned, args...)
+	if err != nil {
+		return ImportResult{}, fmt.Errorf("problem importing keys, %v: %s", err, stderr)
+	}
+
+	result, err := importResult(stdout, stderr)
+	if err != nil {
+		return result, fmt.Errorf("problem importing keys, %v", err)
+	}
+	return result, nil
+}
+
+// countSignaturePackets returns the number of signature packets in the
+// armored blocks, as returned by CleanArmoredBlock. Packets are read without
+// being parsed so it's fast even for huge keys.
+func countSignaturePackets(armoredBlocks string) (int, error) {
+	count := 0
+
+	for _, armoredBlock := range splitArmoredBlocks(armoredBlocks) {
+		block, err := armor.Decode(strings.NewReader(armoredBlock))
+		if err != nil {
+			return 0, err
+		}
+
+		packets := packet.NewOpaqueReader(block.Body)
+		for {
+			p, err := packets.Next()
+			if err == io.EOF {
+				break
+			} else if err != nil {
+				return 0, err
+			}
+			if p.Tag == signaturePacketTag {
+				count++
+			}
+		}
+	}
+	return count, nil
+}
+
+// splitArmoredBlocks splits the output of CleanArmoredBlock into the
+// individual blocks, since armor.Decode only reads the first.
+func splitArmoredBlocks(armoredBlocks string) []string {
+	var blocks []string
+	var current []string
+
+	for _, line := range strings.Split(armoredBlocks, "\n") {
+		if strings.HasPrefix(line, "-----BEGIN PGP ") {
+			current = []string{}
+		}
+		if current == nil {
+			continue
+		}
+		current = append(current, line)
+		if strings.HasPrefix(line, "-----END PGP ") {
+			blocks = append(blocks, strings.Join(current, "\n"))
+			current = nil
+		}
+	}
+	return blocks
+}
+
+// signaturePacketTag is the OpenPGP packet tag for signatures, see
+// https://tools.ietf.org/html/rfc4880#section-4.3
+const signaturePacketTag = 2
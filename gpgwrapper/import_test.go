@@ -0,0 +1,251 @@
+package gpgwrapper
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fluidkeys/fluidkeys/assert"
+	"github.com/fluidkeys/fluidkeys/exampledata"
+)
+
+func TestImportFromReader(t *testing.T) {
+	gpg := makeGpgWithTempHome(t)
+
+	t.Run("with a new public key", func(t *testing.T) {
+		result, err := gpg.ImportFromReader(strings.NewReader(exampledata.ExamplePublicKey4))
+		assertNoError(t, err)
+		assert.Equal(t, ImportResult{Imported: 1}, result)
+	})
+
+	t.Run("with a public key that's already imported", func(t *testing.T) {
+		result, err := gpg.ImportFromReader(strings.NewReader(exampledata.ExamplePublicKey4))
+		assertNoError(t, err)
+		assert.Equal(t, ImportResult{Unchanged: 1}, result)
+	})
+
+	t.Run("with something that isn't a key", func(t *testing.T) {
+		_, err := gpg.ImportFromReader(strings.NewReader("not a key"))
+		assert.ErrorIsNotNil(t, err)
+	})
+}
+
+func TestImportAndExportFiles(t *testing.T) {
+	gpg := makeGpgWithTempHome(t)
+	dir := makeTempGnupgHome(t)
+
+	keyFile := filepath.Join(dir, "key.asc")
+	if err := ioutil.WriteFile(keyFile, []byte(exampledata.ExamplePublicKey4), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("round trip through files", func(t *testing.T) {
+		result, err := gpg.ImportFromFile(keyFile)
+		assertNoError(t, err)
+		assert.Equal(t, ImportResult{Imported: 1}, result)
+
+		exportedFile := filepath.Join(dir, "exported.asc")
+		assertNoError(t, gpg.ExportPublicKeyToFile(exampledata.ExampleFingerprint4, exportedFile))
+
+		exported, err := ioutil.ReadFile(exportedFile)
+		assertNoError(t, err)
+		assert.Equal(t, true, strings.HasPrefix(string(exported), publicHeader))
+
+		otherGpg := makeGpgWithTempHome(t)
+		result, err = otherGpg.ImportFromFile(exportedFile)
+		assertNoError(t, err)
+		assert.Equal(t, ImportResult{Imported: 1}, result)
+	})
+
+	t.Run("importing a file that doesn't exist", func(t *testing.T) {
+		_, err := gpg.ImportFromFile(filepath.Join(dir, "missing.asc"))
+
+		fileErr, ok := err.(*ErrFileAccess)
+		assert.Equal(t, true, ok)
+		assert.Equal(t, true, os.IsNotExist(fileErr.Err))
+	})
+
+	t.Run("importing a file without permission", func(t *testing.T) {
+		if os.Geteuid() == 0 {
+			t.Skip("root can read any file")
+		}
+		unreadable := filepath.Join(dir, "unreadable.asc")
+		if err := ioutil.WriteFile(unreadable, []byte(exampledata.ExamplePublicKey4), 0000); err != nil {
+			t.Fatal(err)
+		}
+
+		_, err := gpg.ImportFromFile(unreadable)
+		fileErr, ok := err.(*ErrFileAccess)
+		assert.Equal(t, true, ok)
+		assert.Equal(t, true, os.IsPermission(fileErr.Err))
+	})
+
+	t.Run("exporting to a directory that doesn't exist", func(t *testing.T) {
+		err := gpg.ExportPublicKeyToFile(exampledata.ExampleFingerprint4, filepath.Join(dir, "missing", "key.asc"))
+
+		_, ok := err.(*ErrFileAccess)
+		assert.Equal(t, true, ok)
+	})
+
+	t.Run("exporting a key that isn't in the keyring", func(t *testing.T) {
+		path := filepath.Join(dir, "not-found.asc")
+		err := gpg.ExportPublicKeyToFile(exampledata.ExampleFingerprint2, path)
+		_, ok := err.(*ErrKeyNotFound)
+		assert.Equal(t, true, ok)
+
+		_, err = os.Stat(path)
+		assert.Equal(t, true, os.IsNotExist(err))
+	})
+
+	t.Run("failed export leaves an existing file alone", func(t *testing.T) {
+		path := filepath.Join(dir, "existing.asc")
+		if err := ioutil.WriteFile(path, []byte("existing contents"), 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		err := gpg.ExportPublicKeyToFile(exampledata.ExampleFingerprint2, path)
+		_, ok := err.(*ErrKeyNotFound)
+		assert.Equal(t, true, ok)
+
+		contents, err := ioutil.ReadFile(path)
+		assertNoError(t, err)
+		assert.Equal(t, "existing contents", string(contents))
+
+		files, err := ioutil.ReadDir(dir)
+		assertNoError(t, err)
+		for _, file := range files {
+			if strings.HasPrefix(file.Name(), ".existing.asc.") {
+				t.Errorf("temporary file %s wasn't removed", file.Name())
+			}
+		}
+	})
+
+	t.Run("successful export replaces an existing file", func(t *testing.T) {
+		path := filepath.Join(dir, "replaced.asc")
+		if err := ioutil.WriteFile(path, []byte("existing contents"), 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		assertNoError(t, gpg.ExportPublicKeyToFile(exampledata.ExampleFingerprint4, path))
+
+		contents, err := ioutil.ReadFile(path)
+		assertNoError(t, err)
+		assert.Equal(t, true, strings.HasPrefix(string(contents), publicHeader))
+	})
+}
+
+func TestParseImportResult(t *testing.T) {
+	t.Run("with a secret key imported", func(t *testing.T) {
+		statusOutput := "[GNUPG:] IMPORT_OK 17 BB3C44BF188D56E635F4A092F73D2F0533D7F9D6\n" +
+			"[GNUPG:] IMPORT_RES 1 0 0 0 1 0 0 0 0 1 1 0 0 0 0\n"
+
+		result, err := parseImportResult(statusOutput)
+		assertNoError(t, err)
+		assert.Equal(t, ImportResult{Unchanged: 1, SecretImported: 1}, result)
+	})
+
+	t.Run("with no IMPORT_RES line", func(t *testing.T) {
+		_, err := parseImportResult("[GNUPG:] NODATA 1\n")
+		assert.ErrorIsNotNil(t, err)
+	})
+}
+
+func TestParseImportSummary(t *testing.T) {
+	t.Run("with keys imported and unchanged", func(t *testing.T) {
+		stderr := "gpg: key 0x5EEB67F1D6CF1A0A: public key imported\n" +
+			"gpg: Total number processed: 2\n" +
+			"gpg:               imported: 1\n" +
+			"gpg:              unchanged: 1\n" +
+			"gpg:   secret keys imported: 1\n"
+
+		result, err := parseImportSummary(stderr)
+		assertNoError(t, err)
+		assert.Equal(t, ImportResult{Imported: 1, Unchanged: 1, SecretImported: 1}, result)
+	})
+
+	t.Run("with no summary", func(t *testing.T) {
+		_, err := parseImportSummary("gpg: no valid OpenPGP data found.\n")
+		assert.ErrorIsNotNil(t, err)
+	})
+}
+
+func TestImportResultFallbacks(t *testing.T) {
+	summary := "gpg: Total number processed: 1\n" +
+		"gpg:               imported: 1\n"
+	nothingImported := "[GNUPG:] IMPORT_RES 0 0 0 0 0 0 0 0 0 0 0 0 0 0 0\n"
+
+	imports := []struct {
+		name       string
+		importFunc func(*GnuPG) (ImportResult, error)
+	}{
+		{"ImportArmoredKey", func(g *GnuPG) (ImportResult, error) {
+			return g.ImportArmoredKey(exampledata.ExamplePublicKey4)
+		}},
+		{"ImportFromReader", func(g *GnuPG) (ImportResult, error) {
+			return g.ImportFromReader(strings.NewReader(exampledata.ExamplePublicKey4))
+		}},
+		{"ImportWithLimits", func(g *GnuPG) (ImportResult, error) {
+			return g.ImportWithLimits(exampledata.ExamplePublicKey4, DefaultImportLimits)
+		}},
+	}
+
+	for _, test := range imports {
+		t.Run(test.name+" falls back to the summary on stderr", func(t *testing.T) {
+			gpg := makeGpgWithFakeRunner(&fakeRunner{stderr: summary})
+
+			result, err := test.importFunc(gpg)
+			assertNoError(t, err)
+			assert.Equal(t, ImportResult{Imported: 1}, result)
+		})
+
+		t.Run(test.name+" returns an error if nothing was imported", func(t *testing.T) {
+			gpg := makeGpgWithFakeRunner(&fakeRunner{stdout: nothingImported})
+
+			_, err := test.importFunc(gpg)
+			assert.ErrorIsNotNil(t, err)
+		})
+	}
+}
+
+func TestImportWithLimits(t *testing.T) {
+	t.Run("within the default limits", func(t *testing.T) {
+		gpg := makeGpgWithTempHome(t)
+		result, err := gpg.ImportWithLimits(exampledata.ExamplePublicKey4, DefaultImportLimits)
+		assertNoError(t, err)
+		assert.Equal(t, ImportResult{Imported: 1}, result)
+	})
+
+	t.Run("with too many bytes", func(t *testing.T) {
+		gpg := makeGpgWithTempHome(t)
+		_, err := gpg.ImportWithLimits(exampledata.ExamplePublicKey4, ImportLimits{MaxArmoredBytes: 100})
+
+		tooLarge, ok := err.(*ErrKeyTooLarge)
+		if !ok {
+			t.Fatalf("expected ErrKeyTooLarge, got %v", err)
+		}
+		assert.Equal(t, "bytes", tooLarge.Limit)
+	})
+
+	t.Run("with too many signatures", func(t *testing.T) {
+		gpg := makeGpgWithTempHome(t)
+		_, err := gpg.ImportWithLimits(exampledata.ExamplePublicKey4, ImportLimits{MaxSignatures: 1})
+		assert.Equal(t, &ErrKeyTooLarge{Limit: "signatures", Got: 2, Max: 1}, err)
+
+		listing, err := gpg.run("--with-colons", "--list-keys")
+		assertNoError(t, err)
+		if strings.Contains(listing, exampledata.ExampleFingerprint4.Hex()) {
+			t.Fatalf("expected key not to be imported")
+		}
+	})
+}
+
+func TestCountSignaturePackets(t *testing.T) {
+	cleaned, err := CleanArmoredBlock(exampledata.ExamplePublicKey4 + exampledata.ExamplePublicKey5)
+	assertNoError(t, err)
+
+	count, err := countSignaturePackets(cleaned)
+	assertNoError(t, err)
+	assert.Equal(t, 5, count) // 2 on key 4, 3 on key 5 (including the direct key signature)
+}
@@ -0,0 +1,87 @@
+// Copyright 2018 Paul Furley and Ian Drysdale
+//
+// This file is part of Fluidkeys Client which makes it simple to use OpenPGP.
+//
+// Fluidkeys Client is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Fluidkeys Client is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with Fluidkeys Client.  If not, see <https://www.gnu.org/licenses/>.
+
+package gpgwrapper
+
+import (
+	"sort"
+
+	"github.com/fluidkeys/fluidkeys/fingerprint"
+)
+
+// FindKeyIdCollisions returns any short (32-bit) or long (64-bit) key IDs
+// shared by more than one key or subkey in the keyring, mapped to the
+// fingerprints that share them. Key IDs are formatted like GnuPG's
+// `--keyid-format 0xlong`, for example "0xD350C30C" or "0x343CC240D350C30C".
+//
+// Short key IDs are trivial to collide on purpose, so any collision means
+// keys on this keyring must be referred to by their full fingerprint.
+// If there are no collisions, it returns an empty map.
+func (g *GnuPG) FindKeyIdCollisions() (map[string][]fingerprint.Fingerprint, error) {
+	keys, err := g.listPublicKeys()
+	if err != nil {
+		return nil, err
+	}
+	return findKeyIdCollisions(keyFingerprints(keys)), nil
+}
+
+func findKeyIdCollisions(fingerprints []fingerprint.Fingerprint) map[string][]fingerprint.Fingerprint {
+	byKeyId := make(map[string][]fingerprint.Fingerprint)
+
+	for _, fp := range fingerprints {
+		for _, keyId := range []string{shortKeyId(fp), longKeyId(fp)} {
+			if !fingerprint.Contains(byKeyId[keyId], fp) {
+				byKeyId[keyId] = append(byKeyId[keyId], fp)
+			}
+		}
+	}
+
+	collisions := make(map[string][]fingerprint.Fingerprint)
+	for keyId, fingerprints := range byKeyId {
+		if len(fingerprints) > 1 {
+			sort.Slice(fingerprints, func(i, j int) bool {
+				return fingerprints[i].Hex() < fingerprints[j].Hex()
+			})
+			collisions[keyId] = fingerprints
+		}
+	}
+	return collisions
+}
+
+// keyFingerprints returns the fingerprint of every primary key and subkey
+// in the listing.
+func keyFingerprints(keys []KeyListing) []fingerprint.Fingerprint {
+	var fingerprints []fingerprint.Fingerprint
+
+	for _, key := range keys {
+		fingerprints = append(fingerprints, key.Fingerprint)
+		for _, subkey := range key.Subkeys {
+			if subkey.Fingerprint.IsSet() {
+				fingerprints = append(fingerprints, subkey.Fingerprint)
+			}
+		}
+	}
+	return fingerprints
+}
+
+func shortKeyId(fp fingerprint.Fingerprint) string {
+	return "0x" + fp.Hex()[32:]
+}
+
+func longKeyId(fp fingerprint.Fingerprint) string {
+	return "0x" + fp.Hex()[24:]
+}
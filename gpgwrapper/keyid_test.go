@@ -0,0 +1,71 @@
+package gpgwrapper
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/fluidkeys/fluidkeys/assert"
+	"github.com/fluidkeys/fluidkeys/exampledata"
+	"github.com/fluidkeys/fluidkeys/fingerprint"
+)
+
+func TestFindKeyIdCollisions(t *testing.T) {
+	gpg := makeGpgWithTempHome(t)
+
+	t.Run("with an empty keyring", func(t *testing.T) {
+		collisions, err := gpg.FindKeyIdCollisions()
+		assertNoError(t, err)
+		assert.Equal(t, map[string][]fingerprint.Fingerprint{}, collisions)
+	})
+
+	t.Run("with no collisions", func(t *testing.T) {
+		_, err := gpg.ImportArmoredKey(exampledata.ExamplePublicKey4)
+		assertNoError(t, err)
+		_, err = gpg.ImportArmoredKey(exampleAlicePublicKey)
+		assertNoError(t, err)
+
+		collisions, err := gpg.FindKeyIdCollisions()
+		assertNoError(t, err)
+		assert.Equal(t, map[string][]fingerprint.Fingerprint{}, collisions)
+	})
+}
+
+func TestFindKeyIdCollisionsHelper(t *testing.T) {
+	a := fingerprint.MustParse("AAAAAAAAAAAAAAAAAAAAAAAA11111111D350C30C")
+	b := fingerprint.MustParse("BBBBBBBBBBBBBBBBBBBBBBBB22222222D350C30C")
+	c := fingerprint.MustParse("CCCCCCCCCCCCCCCCCCCCCCCC22222222D350C30C")
+	d := fingerprint.MustParse("DDDDDDDDDDDDDDDDDDDDDDDDDDDDDDDDDDDDDDDD")
+
+	t.Run("groups short and long key id collisions", func(t *testing.T) {
+		expected := map[string][]fingerprint.Fingerprint{
+			"0xD350C30C":         []fingerprint.Fingerprint{a, b, c},
+			"0x22222222D350C30C": []fingerprint.Fingerprint{b, c},
+		}
+		assert.Equal(t, expected, findKeyIdCollisions([]fingerprint.Fingerprint{c, d, a, b}))
+	})
+
+	t.Run("ignores a key listed twice", func(t *testing.T) {
+		assert.Equal(t,
+			map[string][]fingerprint.Fingerprint{},
+			findKeyIdCollisions([]fingerprint.Fingerprint{a, a, d}),
+		)
+	})
+}
+
+func TestKeyFingerprints(t *testing.T) {
+	output := "tru::1:1792143441:0:3:1:5\n" +
+		"pub:-:1024:1:F73D2F0533D7F9D6:1543864399:::-:::scESC::::::::0:\n" +
+		"fpr:::::::::BB3C44BF188D56E635F4A092F73D2F0533D7F9D6:\n" +
+		"uid:-::::1543864399::E5E624A29B306779F2D0604D3714C1932FB1CF57::test4@example.com::::::::::0:\n" +
+		"sub:-:1024:1:CE7881186F55FA9E:1543864399::::::e:::::::\n" +
+		"fpr:::::::::09D408F6DD1525735F1F54ABCE7881186F55FA9E:\n"
+
+	keys, err := parseColonDelimitedKeys(strings.NewReader(output))
+	assertNoError(t, err)
+
+	got := keyFingerprints(keys)
+	assert.Equal(t, []fingerprint.Fingerprint{
+		fingerprint.MustParse("BB3C44BF188D56E635F4A092F73D2F0533D7F9D6"),
+		fingerprint.MustParse("09D408F6DD1525735F1F54ABCE7881186F55FA9E"),
+	}, got)
+}
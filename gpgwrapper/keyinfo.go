@@ -0,0 +1,133 @@
+// Copyright 2018 Paul Furley and Ian Drysdale
+//
+// This file is part of Fluidkeys Client which makes it simple to use OpenPGP.
+//
+// Fluidkeys Client is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Fluidkeys Client is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with Fluidkeys Client.  If not, see <https://www.gnu.org/licenses/>.
+
+package gpgwrapper
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/fluidkeys/fluidkeys/fingerprint"
+)
+
+// KeyInfo describes a key as GnuPG sees it, for displaying in a UI or
+// printing as JSON.
+type KeyInfo struct {
+	Fingerprint  string     `json:"fingerprint"`
+	Created      time.Time  `json:"created"`
+	Expires      *time.Time `json:"expires,omitempty"`
+	Algorithm    string     `json:"algorithm"`    // e.g. "rsa4096" or "ed25519"
+	Capabilities string     `json:"capabilities"` // e.g. "scESC", see DETAILS field 12
+	Revoked      bool       `json:"revoked"`
+	Expired      bool       `json:"expired"`
+	BitStrength  int        `json:"bit_strength"` // see EstimatedBitStrength
+
+	Uids               []UidInfo    `json:"uids"`
+	Subkeys            []SubkeyInfo `json:"subkeys"`
+	DesignatedRevokers []string     `json:"designated_revokers"`
+}
+
+// UidInfo is a user ID on a key and GnuPG's calculated validity for it, e.g.
+// "ultimate", "full" or "unknown".
+type UidInfo struct {
+	Uid      string `json:"uid"`
+	Validity string `json:"validity"`
+}
+
+// SubkeyInfo describes one subkey of a KeyInfo.
+type SubkeyInfo struct {
+	Fingerprint  string     `json:"fingerprint"`
+	Created      time.Time  `json:"created"`
+	Expires      *time.Time `json:"expires,omitempty"`
+	Algorithm    string     `json:"algorithm"`
+	Capabilities string     `json:"capabilities"`
+	Revoked      bool       `json:"revoked"`
+	Expired      bool       `json:"expired"`
+}
+
+// KeyInfoJSON returns a JSON document describing the key with the given
+// fingerprint: its creation and expiry times, algorithms, capabilities,
+// user IDs (with validity), subkeys, revocation state and designated
+// revokers. Only the public key needs to be in the keyring.
+func (g *GnuPG) KeyInfoJSON(fp fingerprint.Fingerprint) ([]byte, error) {
+	key, err := g.getKeyListing(fp, "--list-keys")
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := newKeyInfo(*key)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(info)
+}
+
+// newKeyInfo builds a KeyInfo from the listing of a single key.
+func newKeyInfo(key KeyListing) (*KeyInfo, error) {
+	strength, err := estimatedBitStrength(key)
+	if err != nil {
+		return nil, err
+	}
+
+	info := KeyInfo{
+		Fingerprint:  key.Fingerprint.Hex(),
+		Created:      key.Created,
+		Expires:      key.Expires,
+		Algorithm:    key.Algorithm,
+		Capabilities: key.Capabilities,
+		Revoked:      key.Revoked,
+		Expired:      key.Expired,
+		BitStrength:  strength,
+
+		Uids:               []UidInfo{},
+		Subkeys:            []SubkeyInfo{},
+		DesignatedRevokers: []string{},
+	}
+
+	for i, uid := range key.Uids {
+		validity := "unknown"
+		if i < len(key.UidValidities) {
+			validity = key.UidValidities[i]
+		}
+		info.Uids = append(info.Uids, UidInfo{Uid: uid, Validity: validity})
+	}
+
+	for _, subkey := range key.Subkeys {
+		subkeyInfo := SubkeyInfo{
+			Created:      subkey.Created,
+			Expires:      subkey.Expires,
+			Algorithm:    subkey.Algorithm,
+			Capabilities: subkey.Capabilities,
+			Revoked:      subkey.Revoked,
+			Expired:      subkey.Expired,
+		}
+		if subkey.Fingerprint.IsSet() {
+			subkeyInfo.Fingerprint = subkey.Fingerprint.Hex()
+		}
+		info.Subkeys = append(info.Subkeys, subkeyInfo)
+	}
+
+	for _, revoker := range key.DesignatedRevokers {
+		if !containsString(info.DesignatedRevokers, revoker.Hex()) {
+			info.DesignatedRevokers = append(info.DesignatedRevokers, revoker.Hex())
+		}
+	}
+	sort.Strings(info.DesignatedRevokers)
+
+	return &info, nil
+}
@@ -0,0 +1,89 @@
+package gpgwrapper
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fluidkeys/fluidkeys/assert"
+	"github.com/fluidkeys/fluidkeys/exampledata"
+)
+
+func TestKeyInfoJSON(t *testing.T) {
+	gpg := makeGpgWithTempHome(t)
+	_, err := gpg.ImportArmoredKey(exampledata.ExamplePublicKey5)
+	assertNoError(t, err)
+
+	t.Run("with a public key", func(t *testing.T) {
+		output, err := gpg.KeyInfoJSON(exampledata.ExampleFingerprint5)
+		assertNoError(t, err)
+
+		var got KeyInfo
+		if err := json.Unmarshal(output, &got); err != nil {
+			t.Fatalf("failed to unmarshal JSON: %v\n%s", err, output)
+		}
+
+		assert.Equal(t, "6F3D9EA26411B777EF3EA76EE162F6D17FEABECC", got.Fingerprint)
+		assert.Equal(t, "rsa2048", got.Algorithm)
+		assert.Equal(t, false, got.Revoked)
+		assert.Equal(t, []UidInfo{{Uid: "Test Revoker Fixture <test5@example.com>", Validity: "unknown"}}, got.Uids)
+		assert.Equal(t, 1, len(got.Subkeys))
+		assert.Equal(t, "DBED352A5033E57C2FA7EF369769C9E8732F89A4", got.Subkeys[0].Fingerprint)
+		assert.Equal(t, []string{exampledata.ExampleFingerprint4.Hex()}, got.DesignatedRevokers)
+	})
+
+	t.Run("with a key that isn't in the keyring", func(t *testing.T) {
+		_, err := gpg.KeyInfoJSON(exampledata.ExampleFingerprint4)
+		assert.ErrorIsNotNil(t, err)
+	})
+}
+
+func TestNewKeyInfo(t *testing.T) {
+	colons := "tru::1:1792144295:2524651200:3:1:5\n" +
+		"pub:u:2048:1:E162F6D17FEABECC:1792144292:2524651200::u:::scESC::::::23::0:\n" +
+		"rvk:::1::::::BB3C44BF188D56E635F4A092F73D2F0533D7F9D6:80:\n" +
+		"fpr:::::::::6F3D9EA26411B777EF3EA76EE162F6D17FEABECC:\n" +
+		"uid:u::::1792144292::6E9DF1CC7B8B3198B6276144D1BC6EE0E471531A::Test\\x3a Fixture <test5@example.com>::::::::::0:\n" +
+		"sub:e:2048:1:9769C9E8732F89A4:1792144295:1792144296:::::e::::::23:\n" +
+		"fpr:::::::::DBED352A5033E57C2FA7EF369769C9E8732F89A4:\n" +
+		"sub:u:256:18:1111111111111111:1792144297::::::e:::::cv25519:\n" +
+		"fpr:::::::::0000000000000000000000001111111111111111:\n"
+
+	keys, err := parseColonDelimitedKeys(strings.NewReader(colons))
+	assertNoError(t, err)
+
+	got, err := newKeyInfo(keys[0])
+	assertNoError(t, err)
+
+	expires := time.Unix(2524651200, 0).UTC()
+	subkeyExpires := time.Unix(1792144296, 0).UTC()
+
+	expected := &KeyInfo{
+		Fingerprint:  "6F3D9EA26411B777EF3EA76EE162F6D17FEABECC",
+		Created:      time.Unix(1792144292, 0).UTC(),
+		Expires:      &expires,
+		Algorithm:    "rsa2048",
+		Capabilities: "scESC",
+		BitStrength:  112,
+		Uids:         []UidInfo{{Uid: "Test: Fixture <test5@example.com>", Validity: "ultimate"}},
+		Subkeys: []SubkeyInfo{
+			{
+				Fingerprint:  "DBED352A5033E57C2FA7EF369769C9E8732F89A4",
+				Created:      time.Unix(1792144295, 0).UTC(),
+				Expires:      &subkeyExpires,
+				Algorithm:    "rsa2048",
+				Capabilities: "e",
+				Expired:      true,
+			},
+			{
+				Fingerprint:  "0000000000000000000000001111111111111111",
+				Created:      time.Unix(1792144297, 0).UTC(),
+				Algorithm:    "cv25519",
+				Capabilities: "e",
+			},
+		},
+		DesignatedRevokers: []string{"BB3C44BF188D56E635F4A092F73D2F0533D7F9D6"},
+	}
+	assert.Equal(t, expected, got)
+}
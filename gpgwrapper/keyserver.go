@@ -0,0 +1,157 @@
+// Copyright 2018 Paul Furley and Ian Drysdale
+//
+// This file is part of Fluidkeys Client which makes it simple to use OpenPGP.
+//
+// Fluidkeys Client is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Fluidkeys Client is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with Fluidkeys Client.  If not, see <https://www.gnu.org/licenses/>.
+
+package gpgwrapper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/fluidkeys/fluidkeys/fingerprint"
+)
+
+// ErrKeyserverUnavailable is returned when GnuPG couldn't talk to the
+// keyserver, for example because of a DNS or connection failure.
+type ErrKeyserverUnavailable struct {
+	// Reason is GnuPG's explanation, e.g. "Connection refused"
+	Reason string
+}
+
+func (e *ErrKeyserverUnavailable) Error() string {
+	return "keyserver unavailable: " + e.Reason
+}
+
+// ErrKeyNotFoundOnKeyserver is returned when the keyserver was reached but
+// doesn't have the requested key.
+var ErrKeyNotFoundOnKeyserver = errors.New("key not found on keyserver")
+
+// ErrKeyserverRejectedKey is returned when the keyserver was reached but
+// refused an uploaded key, for example because it has no self-signature.
+type ErrKeyserverRejectedKey struct {
+	// Reason is GnuPG's explanation, e.g. "Server indicated a failure"
+	Reason string
+}
+
+func (e *ErrKeyserverRejectedKey) Error() string {
+	return "keyserver rejected key: " + e.Reason
+}
+
+// FetchFromKeyserver fetches the key with the given fingerprint from the
+// keyserver (e.g. "hkps://keys.openpgp.org"), imports it into the keyring
+// and returns it ascii-armored.
+//
+// Keyservers can be slow, so gpg is killed if ctx is done first.
+func (g *GnuPG) FetchFromKeyserver(ctx context.Context, fp fingerprint.Fingerprint, keyserverURL string) (string, error) {
+	if keyserverURL == "" {
+		return "", fmt.Errorf("no keyserver given")
+	}
+
+	_, stderr, err := g.runWithReaderContext(ctx, nil,
+		"--keyserver", keyserverURL,
+		"--recv-keys", fp.Hex(),
+	)
+	if ctx.Err() != nil {
+		return "", ctx.Err()
+	} else if err != nil {
+		if unavailable := findKeyserverUnavailable(stderr); unavailable != nil {
+			return "", unavailable
+		} else if strings.Contains(stderr, keyserverServerFailure) {
+			return "", &ErrKeyserverUnavailable{Reason: keyserverServerFailure}
+		} else if strings.Contains(stderr, keyserverNoData) {
+			return "", ErrKeyNotFoundOnKeyserver
+		}
+		return "", fmt.Errorf("keyserver error: %v", err)
+	}
+
+	return g.ExportPublicKey(fp)
+}
+
+// SendToKeyserver uploads the key with the given fingerprint from the
+// keyring to the keyserver (e.g. "hkps://keys.openpgp.org").
+//
+// It returns ErrKeyserverUnavailable if the keyserver couldn't be reached,
+// or ErrKeyserverRejectedKey if it refused the key.
+// Keyservers can be slow, so gpg is killed if ctx is done first.
+func (g *GnuPG) SendToKeyserver(ctx context.Context, fp fingerprint.Fingerprint, keyserverURL string) error {
+	if keyserverURL == "" {
+		return fmt.Errorf("no keyserver given")
+	}
+
+	_, stderr, err := g.runWithReaderContext(ctx, nil,
+		"--keyserver", keyserverURL,
+		"--send-keys", fp.Hex(),
+	)
+	if ctx.Err() != nil {
+		return ctx.Err()
+	} else if err != nil {
+		if unavailable := findKeyserverUnavailable(stderr); unavailable != nil {
+			return unavailable
+		} else if reason := findKeyserverSendFailure(stderr); reason != "" {
+			return &ErrKeyserverRejectedKey{Reason: reason}
+		}
+		return fmt.Errorf("keyserver error: %v", err)
+	}
+	return nil
+}
+
+// findKeyserverUnavailable returns ErrKeyserverUnavailable with GnuPG's
+// reason if stderr shows the keyserver couldn't be reached, otherwise nil.
+func findKeyserverUnavailable(stderr string) error {
+	for _, message := range keyserverUnavailableMessages {
+		if strings.Contains(stderr, message) {
+			return &ErrKeyserverUnavailable{Reason: message}
+		}
+	}
+	return nil
+}
+
+// findKeyserverSendFailure returns the reason from a line like
+// gpg: keyserver send failed: Server indicated a failure
+// or an empty string if there isn't one.
+func findKeyserverSendFailure(stderr string) string {
+	for _, line := range strings.Split(stderr, "\n") {
+		if index := strings.Index(line, keyserverSendFailed); index != -1 {
+			return strings.TrimSpace(line[index+len(keyserverSendFailed):])
+		}
+	}
+	return ""
+}
+
+const (
+	// keyserverNoData is how dirmngr reports that the keyserver answered
+	// but didn't have the key
+	keyserverNoData = "keyserver receive failed: No data"
+
+	// keyserverServerFailure is how dirmngr reports an error response
+	// from the keyserver
+	keyserverServerFailure = "Server indicated a failure"
+
+	keyserverSendFailed = "keyserver send failed:"
+)
+
+// keyserverUnavailableMessages are the reasons GnuPG gives for not reaching
+// a keyserver
+var keyserverUnavailableMessages = []string{
+	"No keyserver available",
+	"Connection refused",
+	"Connection timed out",
+	"Network is unreachable",
+	"No route to host",
+	"No name", // DNS lookup failed
+}
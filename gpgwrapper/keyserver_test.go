@@ -0,0 +1,106 @@
+package gpgwrapper
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fluidkeys/fluidkeys/assert"
+	"github.com/fluidkeys/fluidkeys/exampledata"
+)
+
+func TestFetchFromKeyserver(t *testing.T) {
+	t.Run("with a key on the keyserver", func(t *testing.T) {
+		keyFilename := filepath.Join(makeTempGnupgHome(t), "key.asc")
+		err := ioutil.WriteFile(keyFilename, []byte(exampledata.ExamplePublicKey4), 0600)
+		assertNoError(t, err)
+
+		gpg := GnuPG{fullGpgPath: makeFakeGpgScript(t, `case "$*" in
+  *--recv-keys*) echo 'gpg: key 0xF73D2F0533D7F9D6: public key imported' >&2;;
+  *--export*) cat `+keyFilename+`;;
+esac`)}
+
+		got, err := gpg.FetchFromKeyserver(context.Background(), exampledata.ExampleFingerprint4, "hkps://keys.example.com")
+		assertNoError(t, err)
+		assert.Equal(t, exampledata.ExamplePublicKey4, got)
+	})
+
+	t.Run("with a key that isn't on the keyserver", func(t *testing.T) {
+		gpg := GnuPG{fullGpgPath: makeFakeGpgScript(t,
+			"echo 'gpg: keyserver receive failed: No data' >&2; exit 2")}
+
+		_, err := gpg.FetchFromKeyserver(context.Background(), exampledata.ExampleFingerprint4, "hkps://keys.example.com")
+		if err != ErrKeyNotFoundOnKeyserver {
+			t.Fatalf("expected ErrKeyNotFoundOnKeyserver, got %v", err)
+		}
+	})
+
+	for _, message := range []string{"Connection refused", "Server indicated a failure"} {
+		t.Run("with an unreachable keyserver: "+message, func(t *testing.T) {
+			gpg := GnuPG{fullGpgPath: makeFakeGpgScript(t,
+				"echo 'gpg: keyserver receive failed: "+message+"' >&2; exit 2")}
+
+			_, err := gpg.FetchFromKeyserver(context.Background(), exampledata.ExampleFingerprint4, "hkps://keys.example.com")
+			if _, ok := err.(*ErrKeyserverUnavailable); !ok {
+				t.Fatalf("expected ErrKeyserverUnavailable, got %v", err)
+			}
+		})
+	}
+
+	t.Run("with a keyserver that doesn't answer in time", func(t *testing.T) {
+		gpg := GnuPG{fullGpgPath: makeFakeGpgScript(t, "exec sleep 30")}
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		_, err := gpg.FetchFromKeyserver(ctx, exampledata.ExampleFingerprint4, "hkps://keys.example.com")
+		assert.Equal(t, context.DeadlineExceeded, err)
+	})
+
+	t.Run("with no keyserver", func(t *testing.T) {
+		gpg := makeGpgWithTempHome(t)
+		_, err := gpg.FetchFromKeyserver(context.Background(), exampledata.ExampleFingerprint4, "")
+		assert.ErrorIsNotNil(t, err)
+	})
+}
+
+func TestSendToKeyserver(t *testing.T) {
+	t.Run("when the keyserver accepts the key", func(t *testing.T) {
+		gpg := GnuPG{fullGpgPath: makeFakeGpgScript(t,
+			`echo 'gpg: sending key 0xF73D2F0533D7F9D6 to hkps://keys.example.com' >&2`)}
+
+		err := gpg.SendToKeyserver(context.Background(), exampledata.ExampleFingerprint4, "hkps://keys.example.com")
+		assertNoError(t, err)
+	})
+
+	t.Run("when the keyserver rejects the key", func(t *testing.T) {
+		gpg := GnuPG{fullGpgPath: makeFakeGpgScript(t,
+			"echo 'gpg: keyserver send failed: Server indicated a failure' >&2; exit 2")}
+
+		err := gpg.SendToKeyserver(context.Background(), exampledata.ExampleFingerprint4, "hkps://keys.example.com")
+		if _, ok := err.(*ErrKeyserverRejectedKey); !ok {
+			t.Fatalf("expected ErrKeyserverRejectedKey, got %v", err)
+		}
+		assert.Equal(t, "keyserver rejected key: Server indicated a failure", err.Error())
+	})
+
+	t.Run("with an unreachable keyserver", func(t *testing.T) {
+		gpg := GnuPG{fullGpgPath: makeFakeGpgScript(t,
+			"echo 'gpg: keyserver send failed: No name' >&2; exit 2")}
+
+		err := gpg.SendToKeyserver(context.Background(), exampledata.ExampleFingerprint4, "hkps://keys.example.com")
+		if _, ok := err.(*ErrKeyserverUnavailable); !ok {
+			t.Fatalf("expected ErrKeyserverUnavailable, got %v", err)
+		}
+	})
+
+	t.Run("with a keyserver that doesn't answer in time", func(t *testing.T) {
+		gpg := GnuPG{fullGpgPath: makeFakeGpgScript(t, "exec sleep 30")}
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		err := gpg.SendToKeyserver(ctx, exampledata.ExampleFingerprint4, "hkps://keys.example.com")
+		assert.Equal(t, context.DeadlineExceeded, err)
+	})
+}
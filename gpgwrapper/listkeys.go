@@ -0,0 +1,216 @@
+// Copyright 2018 Paul Furley and Ian Drysdale
+//
+// This file is part of Fluidkeys Client which makes it simple to use OpenPGP.
+//
+// Fluidkeys Client is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Fluidkeys Client is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with Fluidkeys Client.  If not, see <https://www.gnu.org/licenses/>.
+
+package gpgwrapper
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fluidkeys/fluidkeys/fingerprint"
+)
+
+// KeyListing is a key parsed from GnuPG's colon-delimited key listing,
+// including its subkeys and every user ID.
+type KeyListing struct {
+	Fingerprint  fingerprint.Fingerprint
+	KeyId        uint64
+	Created      time.Time
+	Expires      *time.Time // nil if the key doesn't expire
+	Algorithm    string     // e.g. "rsa4096" or "ed25519"
+	Capabilities string     // e.g. "scESC", see DETAILS field 12
+	Revoked      bool
+	Expired      bool
+
+	// Uids is a list of UTF-8 user ID strings, in the order GnuPG lists them
+	Uids []string
+
+	// UidValidities is GnuPG's calculated validity of each of Uids, in the
+	// same order, e.g. "ultimate", "full" or "unknown".
+	UidValidities []string
+
+	Subkeys []SubkeyListing
+
+	// DesignatedRevokers are the keys allowed to revoke this key, from the
+	// rvk records.
+	DesignatedRevokers []fingerprint.Fingerprint
+
+	// Signatures are on the primary key, its user IDs and its subkeys, in
+	// the order GnuPG lists them. They're only listed by --list-sigs and
+	// --check-sigs.
+	Signatures []SignatureListing
+}
+
+// SubkeyListing is one subkey of a KeyListing.
+type SubkeyListing struct {
+	Fingerprint  fingerprint.Fingerprint
+	KeyId        uint64
+	Created      time.Time
+	Expires      *time.Time
+	Algorithm    string
+	Capabilities string
+	Revoked      bool
+	Expired      bool
+}
+
+// SignatureListing is a sig or rev record of a KeyListing.
+type SignatureListing struct {
+	IssuerKeyId uint64
+
+	// IssuerFingerprint isn't set if GnuPG didn't list it.
+	IssuerFingerprint fingerprint.Fingerprint
+
+	// Revocation is true for a rev record, which revokes an earlier
+	// signature by the same issuer.
+	Revocation bool
+
+	// Good is true if --check-sigs verified the signature.
+	Good bool
+
+	// Uid is the user ID the signature is on, or empty for a signature
+	// directly on the primary key or on a subkey.
+	Uid string
+
+	// SubkeyId is the key ID of the subkey the signature is on, or 0.
+	SubkeyId uint64
+
+	// Subpackets are only listed with `--list-options show-sig-subpackets`.
+	Subpackets []SignatureSubpacket
+}
+
+// SignatureSubpacket is an spk record of a SignatureListing.
+type SignatureSubpacket struct {
+	Type int
+
+	// Data is percent-escaped, as GnuPG lists it.
+	Data string
+}
+
+// ListPublicKeys returns every key in the public keyring, including revoked
+// and expired keys.
+func (g *GnuPG) ListPublicKeys() ([]KeyListing, error) {
+	return g.listPublicKeys()
+}
+
+// GetFingerprintForEmail returns the fingerprint of the key in the public
+// keyring with a user ID for the given email address. It returns
+// ErrKeyNotFound if there's no such key and ErrMultipleKeysFound if there's
+// more than one, including revoked and expired keys.
+func (g *GnuPG) GetFingerprintForEmail(email string) (fingerprint.Fingerprint, error) {
+	if email == "" || strings.ContainsAny(email, "<>\r\n") {
+		return fingerprint.Fingerprint{}, fmt.Errorf("invalid email address: '%s'", email)
+	}
+
+	// "<email>" makes GnuPG match the email exactly, rather than any user
+	// ID containing it
+	keys, err := g.listPublicKeys("<" + email + ">")
+	if _, ok := err.(*ErrKeyNotFound); ok {
+		return fingerprint.Fingerprint{}, &ErrKeyNotFound{}
+	} else if err != nil {
+		return fingerprint.Fingerprint{}, err
+	}
+
+	switch len(keys) {
+	case 0:
+		return fingerprint.Fingerprint{}, &ErrKeyNotFound{}
+	case 1:
+		return keys[0].Fingerprint, nil
+	}
+
+	multipleErr := &ErrMultipleKeysFound{Email: email}
+	for _, key := range keys {
+		multipleErr.Fingerprints = append(multipleErr.Fingerprints, key.Fingerprint)
+	}
+	return fingerprint.Fingerprint{}, multipleErr
+}
+
+// GetPrimaryKeyExpiry returns when GnuPG thinks the primary key with the
+// given fingerprint expires, from the pub record of `--with-colons`. It
+// returns false if the key doesn't expire, and ErrKeyNotFound if it isn't in
+// the keyring.
+//
+// Unlike status.Expiry, this is GnuPG's view of the key in the keyring,
+// rather than a calculation from a parsed key.
+func (g *GnuPG) GetPrimaryKeyExpiry(fp fingerprint.Fingerprint) (time.Time, bool, error) {
+	if !fp.IsSet() {
+		return time.Time{}, false, fmt.Errorf("can't get expiry: fingerprint isn't set")
+	}
+
+	key, err := g.getKeyListing(fp, "--list-keys")
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	if key.Expires == nil {
+		return time.Time{}, false, nil
+	}
+	return *key.Expires, true, nil
+}
+
+// listPublicKeys lists the keys matching any of the given GnuPG search
+// patterns, or every key if there are none.
+func (g *GnuPG) listPublicKeys(patterns ...string) ([]KeyListing, error) {
+	listing, err := g.listKeysWithColons([]string{"--list-keys"}, patterns...)
+	if err != nil {
+		return nil, err
+	}
+	return listing.keys, nil
+}
+
+// getKeyListing returns the primary key with the given fingerprint from the
+// listing made by args, for example `--list-sigs`. It returns
+// ErrKeyNotFound if the key isn't in the keyring.
+func (g *GnuPG) getKeyListing(fp fingerprint.Fingerprint, args ...string) (*KeyListing, error) {
+	listing, err := g.listKeysWithColons(args, fp.Hex())
+	if _, ok := err.(*ErrKeyNotFound); ok {
+		return nil, &ErrKeyNotFound{Fingerprint: fp}
+	} else if err != nil {
+		return nil, err
+	}
+
+	// a fingerprint pattern can also match a subkey, so check it's the
+	// primary key
+	for i := range listing.keys {
+		if listing.keys[i].Fingerprint == fp {
+			return &listing.keys[i], nil
+		}
+	}
+	return nil, &ErrKeyNotFound{Fingerprint: fp}
+}
+
+// listKeysWithColons runs gpg with args, which give the listing command and
+// its options (e.g. `--check-sigs`), and parses the colon-delimited listing
+// of the keys matching any of the patterns, or every key if there are none.
+func (g *GnuPG) listKeysWithColons(args []string, patterns ...string) (*colonListing, error) {
+	fullArgs := []string{
+		"--with-colons",
+		"--fixed-list-mode",
+		"--with-fingerprint",
+		"--with-fingerprint", // twice to include subkey fingerprints
+	}
+	fullArgs = append(fullArgs, args...)
+	fullArgs = append(fullArgs, patterns...)
+
+	outString, err := g.run(fullArgs...)
+	if _, ok := err.(*ErrKeyNotFound); ok {
+		return nil, err
+	} else if err != nil {
+		return nil, fmt.Errorf("error running 'gpg %s': %v", strings.Join(fullArgs, " "), err)
+	}
+
+	return parseColonListing(strings.NewReader(outString))
+}
@@ -0,0 +1,255 @@
+package gpgwrapper
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fluidkeys/fluidkeys/assert"
+	"github.com/fluidkeys/fluidkeys/exampledata"
+	"github.com/fluidkeys/fluidkeys/fingerprint"
+)
+
+func TestListPublicKeys(t *testing.T) {
+	gpg := makeGpgWithTempHome(t)
+	_, err := gpg.ImportArmoredKey(exampledata.ExamplePublicKey4)
+	assertNoError(t, err)
+	_, err = gpg.ImportArmoredKey(exampledata.ExamplePublicKey5)
+	assertNoError(t, err)
+
+	keys, err := gpg.ListPublicKeys()
+	assertNoError(t, err)
+
+	if len(keys) != 2 {
+		t.Fatalf("expected 2 keys, got %d: %v", len(keys), keys)
+	}
+	assert.Equal(t, exampledata.ExampleFingerprint4, keys[0].Fingerprint)
+	assert.Equal(t, []string{"test4@example.com"}, keys[0].Uids)
+	assert.Equal(t, exampledata.ExampleFingerprint5, keys[1].Fingerprint)
+	assert.Equal(t, uint64(0xE162F6D17FEABECC), keys[1].KeyId)
+	assert.Equal(t, "rsa2048", keys[1].Algorithm)
+	assert.Equal(t, 1, len(keys[1].Subkeys))
+	assert.Equal(t, uint64(0x9769C9E8732F89A4), keys[1].Subkeys[0].KeyId)
+}
+
+func TestGetFingerprintForEmail(t *testing.T) {
+	gpg := makeGpgWithTempHome(t)
+
+	t.Run("with no matching key", func(t *testing.T) {
+		_, err := gpg.GetFingerprintForEmail("test4@example.com")
+		if _, ok := err.(*ErrKeyNotFound); !ok {
+			t.Fatalf("expected ErrKeyNotFound, got %v", err)
+		}
+	})
+
+	_, err := gpg.ImportArmoredKey(exampledata.ExamplePublicKey4)
+	assertNoError(t, err)
+
+	t.Run("with one matching key", func(t *testing.T) {
+		got, err := gpg.GetFingerprintForEmail("test4@example.com")
+		assertNoError(t, err)
+		assert.Equal(t, exampledata.ExampleFingerprint4, got)
+	})
+
+	t.Run("doesn't match part of an email", func(t *testing.T) {
+		_, err := gpg.GetFingerprintForEmail("est4@example.com")
+		if _, ok := err.(*ErrKeyNotFound); !ok {
+			t.Fatalf("expected ErrKeyNotFound, got %v", err)
+		}
+	})
+
+	t.Run("with two keys for the same email", func(t *testing.T) {
+		var expected []fingerprint.Fingerprint
+		for i := 0; i < 2; i++ {
+			fp, err := gpg.GenerateKey(GenerateKeyParams{
+				Name:       "Jane",
+				Email:      "jane@example.com",
+				Passphrase: "test",
+				KeyType:    KeyTypeEd25519,
+			})
+			assertNoError(t, err)
+			expected = append(expected, fp)
+		}
+
+		_, err := gpg.GetFingerprintForEmail("jane@example.com")
+
+		multipleErr, ok := err.(*ErrMultipleKeysFound)
+		if !ok {
+			t.Fatalf("expected ErrMultipleKeysFound, got %v", err)
+		}
+		assert.Equal(t, expected, multipleErr.Fingerprints)
+	})
+
+	t.Run("with an invalid email", func(t *testing.T) {
+		_, err := gpg.GetFingerprintForEmail("jane@example.com>\n")
+		assert.ErrorIsNotNil(t, err)
+	})
+}
+
+func TestGetPrimaryKeyExpiry(t *testing.T) {
+	gpg := makeGpgWithTempHome(t)
+	_, err := gpg.ImportArmoredKey(exampledata.ExamplePublicKey4)
+	assertNoError(t, err)
+	_, err = gpg.ImportArmoredKey(exampledata.ExamplePublicKey14)
+	assertNoError(t, err)
+
+	t.Run("with a key that expires", func(t *testing.T) {
+		expiry, hasExpiry, err := gpg.GetPrimaryKeyExpiry(exampledata.ExampleFingerprint14)
+		assertNoError(t, err)
+		assert.Equal(t, true, hasExpiry)
+		assert.AssertEqualTimes(t, time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC), expiry)
+	})
+
+	t.Run("with a key that doesn't expire", func(t *testing.T) {
+		_, hasExpiry, err := gpg.GetPrimaryKeyExpiry(exampledata.ExampleFingerprint4)
+		assertNoError(t, err)
+		assert.Equal(t, false, hasExpiry)
+	})
+
+	t.Run("with a key that isn't in the keyring", func(t *testing.T) {
+		_, _, err := gpg.GetPrimaryKeyExpiry(exampledata.ExampleFingerprint2)
+		if notFound, ok := err.(*ErrKeyNotFound); !ok || notFound.Fingerprint != exampledata.ExampleFingerprint2 {
+			t.Fatalf("expected ErrKeyNotFound, got %v", err)
+		}
+	})
+
+	t.Run("with a subkey fingerprint", func(t *testing.T) {
+		_, _, err := gpg.GetPrimaryKeyExpiry(exampleSubkeyFingerprint4)
+		if _, ok := err.(*ErrKeyNotFound); !ok {
+			t.Fatalf("expected ErrKeyNotFound, got %v", err)
+		}
+	})
+}
+
+func TestParseColonDelimitedKeys(t *testing.T) {
+	t.Run("with multiple keys, subkeys and user IDs", func(t *testing.T) {
+		colons := "sec:u:2048:1:E162F6D17FEABECC:1792144292:2524651200::u:::scESC::::::23::0:\n" +
+			"fpr:::::::::6F3D9EA26411B777EF3EA76EE162F6D17FEABECC:\n" +
+			"grp:::::::::2A8C00B6A3C8C5A8A4BFF3E1C1B1A1F5E0D0C0B0:\n" +
+			"uid:u::::1792144292::6E9DF1CC7B8B3198B6276144D1BC6EE0E471531A::Test\\x3a Fixture <test5@example.com>::::::::::0:\n" +
+			"uid:u::::1792144293::1E9DF1CC7B8B3198B6276144D1BC6EE0E471531A::Other <other@example.com>::::::::::0:\n" +
+			"ssb:e:2048:1:9769C9E8732F89A4:1792144295:1792144296:::::e::::::23:\n" +
+			"fpr:::::::::DBED352A5033E57C2FA7EF369769C9E8732F89A4:\n" +
+			"ssb:u:256:18:1111111111111111:1792144297::::::e:::::cv25519:\n" +
+			"fpr:::::::::0000000000000000000000001111111111111111:\n" +
+			"sec:r:1024:1:F73D2F0533D7F9D6:1543864399:::-:::sc::::::::0:\n" +
+			"fpr:::::::::BB3C44BF188D56E635F4A092F73D2F0533D7F9D6:\n" +
+			"uid:r::::1543864399::E5E624A29B306779F2D0604D3714C1932FB1CF57::test4@example.com::::::::::0:\n"
+
+		got, err := parseColonDelimitedKeys(strings.NewReader(colons))
+		assertNoError(t, err)
+
+		expires := time.Unix(2524651200, 0).UTC()
+		subkeyExpires := time.Unix(1792144296, 0).UTC()
+
+		expected := []KeyListing{
+			{
+				Fingerprint:   fingerprint.MustParse("6F3D9EA26411B777EF3EA76EE162F6D17FEABECC"),
+				KeyId:         0xE162F6D17FEABECC,
+				Created:       time.Unix(1792144292, 0).UTC(),
+				Expires:       &expires,
+				Algorithm:     "rsa2048",
+				Capabilities:  "scESC",
+				Uids:          []string{"Test: Fixture <test5@example.com>", "Other <other@example.com>"},
+				UidValidities: []string{"ultimate", "ultimate"},
+				Subkeys: []SubkeyListing{
+					{
+						Fingerprint:  fingerprint.MustParse("DBED352A5033E57C2FA7EF369769C9E8732F89A4"),
+						KeyId:        0x9769C9E8732F89A4,
+						Created:      time.Unix(1792144295, 0).UTC(),
+						Expires:      &subkeyExpires,
+						Algorithm:    "rsa2048",
+						Capabilities: "e",
+						Expired:      true,
+					},
+					{
+						Fingerprint:  fingerprint.MustParse("0000000000000000000000001111111111111111"),
+						KeyId:        0x1111111111111111,
+						Created:      time.Unix(1792144297, 0).UTC(),
+						Algorithm:    "cv25519",
+						Capabilities: "e",
+					},
+				},
+			},
+			{
+				Fingerprint:   fingerprint.MustParse("BB3C44BF188D56E635F4A092F73D2F0533D7F9D6"),
+				KeyId:         0xF73D2F0533D7F9D6,
+				Created:       time.Unix(1543864399, 0).UTC(),
+				Algorithm:     "rsa1024",
+				Capabilities:  "sc",
+				Revoked:       true,
+				Uids:          []string{"test4@example.com"},
+				UidValidities: []string{"revoked"},
+			},
+		}
+		assert.Equal(t, expected, got)
+	})
+
+	t.Run("with revokers, signatures and subpackets", func(t *testing.T) {
+		colons := "pub:u:2048:1:E162F6D17FEABECC:1792144292:2524608000::u:::scSC::::::23::0:\n" +
+			"rvk:::1::::::BB3C44BF188D56E635F4A092F73D2F0533D7F9D6:80:\n" +
+			"fpr:::::::::6F3D9EA26411B777EF3EA76EE162F6D17FEABECC:\n" +
+			"uid:u::::1792144292::6E9DF1CC7B8B3198B6276144D1BC6EE0E471531A::test5@example.com::::::::::0:\n" +
+			"sig:!::1:E162F6D17FEABECC:1792144292::::test5@example.com:13x::6F3D9EA26411B777EF3EA76EE162F6D17FEABECC:::10:\n" +
+			"spk:27:1:1:%03\n" +
+			"spk:20:1:32:%80%00%00%00%00%10%00%08team@example.complatform\n" +
+			"rev:?::1:1111111111111111:1792144293::::someone else:30x:::::10:\n" +
+			"sub:u:2048:1:9769C9E8732F89A4:1792144295::::::e::::::23:\n" +
+			"fpr:::::::::DBED352A5033E57C2FA7EF369769C9E8732F89A4:\n" +
+			"sig:::1:E162F6D17FEABECC:1792144295::::test5@example.com:18x:::::10:\n"
+
+		keys, err := parseColonDelimitedKeys(strings.NewReader(colons))
+		assertNoError(t, err)
+		if len(keys) != 1 {
+			t.Fatalf("expected 1 key, got %d: %v", len(keys), keys)
+		}
+
+		assert.Equal(t, []fingerprint.Fingerprint{exampledata.ExampleFingerprint4}, keys[0].DesignatedRevokers)
+
+		expected := []SignatureListing{
+			{
+				IssuerKeyId:       0xE162F6D17FEABECC,
+				IssuerFingerprint: fingerprint.MustParse("6F3D9EA26411B777EF3EA76EE162F6D17FEABECC"),
+				Good:              true,
+				Uid:               "test5@example.com",
+				Subpackets: []SignatureSubpacket{
+					{Type: 27, Data: "%03"},
+					{Type: 20, Data: "%80%00%00%00%00%10%00%08team@example.complatform"},
+				},
+			},
+			{
+				IssuerKeyId: 0x1111111111111111,
+				Revocation:  true,
+				Uid:         "test5@example.com",
+			},
+			{
+				IssuerKeyId: 0xE162F6D17FEABECC,
+				SubkeyId:    0x9769C9E8732F89A4,
+			},
+		}
+		assert.Equal(t, expected, keys[0].Signatures)
+	})
+
+	t.Run("with no keys", func(t *testing.T) {
+		got, err := parseColonDelimitedKeys(strings.NewReader("tru::1:1792146455:0:3:1:5\n"))
+		assertNoError(t, err)
+		assert.Equal(t, 0, len(got))
+	})
+
+	t.Run("with a truncated pub record", func(t *testing.T) {
+		_, err := parseColonDelimitedKeys(strings.NewReader("pub:u:2048:1:E162F6D17FEABECC\n"))
+		assert.ErrorIsNotNil(t, err)
+	})
+
+	t.Run("with an invalid timestamp", func(t *testing.T) {
+		_, err := parseColonDelimitedKeys(strings.NewReader(
+			"pub:-:1024:1:F73D2F0533D7F9D6:foo:::-:::scESC::::::::0:\n"))
+		assert.ErrorIsNotNil(t, err)
+	})
+
+	t.Run("without fingerprints", func(t *testing.T) {
+		_, err := parseColonDelimitedKeys(strings.NewReader(
+			"pub:-:1024:1:F73D2F0533D7F9D6:1543864399:::-:::scESC::::::::0:\n"))
+		assert.ErrorIsNotNil(t, err)
+	})
+}
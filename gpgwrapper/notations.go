@@ -0,0 +1,105 @@
+// Copyright 2018 Paul Furley and Ian Drysdale
+//
+// This file is part of Fluidkeys Client which makes it simple to use OpenPGP.
+//
+// Fluidkeys Client is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Fluidkeys Client is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with Fluidkeys Client.  If not, see <https://www.gnu.org/licenses/>.
+
+package gpgwrapper
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net/url"
+
+	"github.com/fluidkeys/fluidkeys/fingerprint"
+)
+
+// SignatureNotations returns the notations (RFC 4880 section 5.2.3.16) on
+// the key's self signatures, keyed by name, for example:
+//
+//	{"team@example.com": ["platform"]}
+//
+// Organisations can use these to store metadata such as identity proofs or
+// policy tags. Only human-readable notations are returned.
+func (g *GnuPG) SignatureNotations(fp fingerprint.Fingerprint) (map[string][]string, error) {
+	key, err := g.getKeyListing(fp, "--list-options", "show-sig-subpackets", "--list-sigs")
+	if err != nil {
+		return nil, err
+	}
+
+	return selfSignatureNotations(*key)
+}
+
+// selfSignatureNotations takes a key listed by `gpg --list-sigs
+// --list-options show-sig-subpackets` and returns the notations from the
+// subpackets of its self signatures.
+func selfSignatureNotations(key KeyListing) (map[string][]string, error) {
+	notations := make(map[string][]string)
+
+	for _, signature := range key.Signatures {
+		if signature.Revocation || signature.IssuerKeyId != key.KeyId {
+			continue
+		}
+		for _, subpacket := range signature.Subpackets {
+			if subpacket.Type != notationSubpacket {
+				continue
+			}
+			name, value, humanReadable, err := parseNotationSubpacket(subpacket.Data)
+			if err != nil {
+				return nil, err
+			}
+			if humanReadable && !containsString(notations[name], value) {
+				notations[name] = append(notations[name], value)
+			}
+		}
+	}
+	return notations, nil
+}
+
+// parseNotationSubpacket decodes the percent-escaped data of a notation
+// subpacket: 4 bytes of flags, 2 bytes of name length, 2 bytes of value
+// length, then the name and value.
+func parseNotationSubpacket(escapedData string) (name string, value string, humanReadable bool, err error) {
+	unescaped, err := url.PathUnescape(escapedData)
+	if err != nil {
+		return "", "", false, fmt.Errorf("invalid notation subpacket '%s': %v", escapedData, err)
+	}
+	data := []byte(unescaped)
+	if len(data) < 8 {
+		return "", "", false, fmt.Errorf("notation subpacket too short: '%s'", escapedData)
+	}
+
+	nameLength := int(binary.BigEndian.Uint16(data[4:6]))
+	valueLength := int(binary.BigEndian.Uint16(data[6:8]))
+	if len(data) != 8+nameLength+valueLength {
+		return "", "", false, fmt.Errorf("notation subpacket has wrong length: '%s'", escapedData)
+	}
+
+	humanReadable = data[0]&0x80 != 0
+	name = string(data[8 : 8+nameLength])
+	value = string(data[8+nameLength:])
+	return name, value, humanReadable, nil
+}
+
+func containsString(haystack []string, needle string) bool {
+	for _, s := range haystack {
+		if s == needle {
+			return true
+		}
+	}
+	return false
+}
+
+// notationSubpacket is the signature subpacket type for notation data
+const notationSubpacket = 20
@@ -0,0 +1,56 @@
+package gpgwrapper
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/fluidkeys/fluidkeys/assert"
+	"github.com/fluidkeys/fluidkeys/fingerprint"
+)
+
+func TestSignatureNotations(t *testing.T) {
+	gpg := makeGpgWithTempHome(t)
+
+	_, err := gpg.run(
+		"--passphrase", "", "--pinentry-mode", "loopback",
+		"--cert-notation", "team@example.com=platform",
+		"--quick-gen-key", "notations@example.com", "ed25519", "sign,cert", "never",
+	)
+	assertNoError(t, err)
+
+	keys, err := gpg.listPublicKeys("notations@example.com")
+	assertNoError(t, err)
+
+	got, err := gpg.SignatureNotations(keys[0].Fingerprint)
+	assertNoError(t, err)
+	assert.Equal(t, map[string][]string{"team@example.com": []string{"platform"}}, got)
+
+	t.Run("with a key that isn't in the keyring", func(t *testing.T) {
+		_, err := gpg.SignatureNotations(fingerprint.MustParse("0000 0000 0000 0000 0000 0000 0000 0000 0000 0000"))
+		assert.ErrorIsNotNil(t, err)
+	})
+}
+
+func TestSelfSignatureNotations(t *testing.T) {
+	colons := "pub:u:2048:1:060FA38F71F89678:1792144973:2524651200::u:::scSC::::::23::0:\n" +
+		"fpr:::::::::681DDC3322432BC916485B3C060FA38F71F89678:\n" +
+		"uid:u::::1792144973::D45198F78332562C726E7A8DA1B030D19336128F::n <n@example.com>::::::::::0:\n" +
+		"sig:::1:060FA38F71F89678:1792144973::::n <n@example.com>:13x::681DDC3322432BC916485B3C060FA38F71F89678:::10:\n" +
+		"spk:27:1:1:%03\n" +
+		"spk:20:1:30:%80%00%00%00%00%10%00%06role@example.comoncall\n" +
+		"spk:20:1:32:%80%00%00%00%00%10%00%08team@example.complatform\n" +
+		"sig:::1:1111111111111111:1792144973::::someone else:10x:::::10:\n" +
+		"spk:20:1:29:%80%00%00%00%00%10%00%05team@example.comother\n"
+
+	keys, err := parseColonDelimitedKeys(strings.NewReader(colons))
+	assertNoError(t, err)
+
+	got, err := selfSignatureNotations(keys[0])
+	assertNoError(t, err)
+
+	expected := map[string][]string{
+		"role@example.com": []string{"oncall"},
+		"team@example.com": []string{"platform"},
+	}
+	assert.Equal(t, expected, got)
+}
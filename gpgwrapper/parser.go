@@ -18,7 +18,9 @@
 package gpgwrapper
 
 import (
+	"bufio"
 	"fmt"
+	"io"
 	"strconv"
 	"strings"
 	"time"
@@ -27,11 +29,11 @@ import (
 )
 
 // Parse the output of --with-colons --list-secret keys and return only valid
-// keys (not revoked, not expired) as []SecretKeyListing
+// keys (not revoked, not expired) as []KeyListing
 // For the format of the colon-delimited string, see:
 // https://github.com/gpg/gnupg/blob/master/doc/DETAILS
 
-func parseListSecretKeys(colonDelimitedString string) ([]SecretKeyListing, error) {
+func parseListSecretKeys(colonDelimitedString string) ([]KeyListing, error) {
 	parser := listSecretKeysParser{}
 
 	for _, line := range strings.Split(colonDelimitedString, "\n") {
@@ -50,8 +52,9 @@ func parseListSecretKeys(colonDelimitedString string) ([]SecretKeyListing, error
 // partial key is checked for validity and added to Keys.
 
 type listSecretKeysParser struct {
-	partialKey *SecretKeyListing
-	keys       []SecretKeyListing
+	partialKey    *KeyListing
+	partialSubkey *SubkeyListing
+	keys          []KeyListing
 }
 
 // Adds a line to the parser, which builds up its internal Keys field.
@@ -64,6 +67,10 @@ func (p *listSecretKeysParser) PushLine(cols []string) {
 		p.handleSecretPrimaryKeyLine(cols)
 		return
 
+	case "ssb":
+		p.handleSecretSubkeyLine(cols)
+		return
+
 	case "fpr":
 		p.handleFingerprintLine(cols)
 		return
@@ -77,7 +84,7 @@ func (p *listSecretKeysParser) PushLine(cols []string) {
 // Keys() returns the list of keys that have been accumulated so far.
 // It should be called when all lines have been pushed with `PushLine`, else
 // keys may be missing, or, worse, they may have missing UIDs.
-func (p *listSecretKeysParser) Keys() []SecretKeyListing {
+func (p *listSecretKeysParser) Keys() []KeyListing {
 	p.end()
 	return p.keys
 }
@@ -97,18 +104,33 @@ func (p *listSecretKeysParser) handleSecretPrimaryKeyLine(cols []string) {
 		return
 	}
 
-	createdTime, err := parseTimestamp(cols[5])
+	primaryKey, err := parseSubkeyListing(cols, strings.Join(cols, ":"))
 
 	if err != nil {
-		// Primary keys should always have a valid created time. Ignore
-		// this broken key.
+		// Primary keys should always have a valid created time (and key
+		// ID, algorithm...). Ignore this broken key.
 		return
 	}
 
-	p.partialKey = &SecretKeyListing{
-		Created: *createdTime,
+	listing := newKeyListing(*primaryKey)
+	p.partialKey = &listing
+}
+
+func (p *listSecretKeysParser) handleSecretSubkeyLine(cols []string) {
+	p.partialSubkey = nil
+
+	if p.partialKey == nil {
+		return
 	}
 
+	subkey, err := parseSubkeyListing(cols, strings.Join(cols, ":"))
+	if err != nil {
+		// Ignore the broken subkey, but keep the rest of the key.
+		return
+	}
+
+	p.partialKey.Subkeys = append(p.partialKey.Subkeys, *subkey)
+	p.partialSubkey = &p.partialKey.Subkeys[len(p.partialKey.Subkeys)-1]
 }
 
 func (p *listSecretKeysParser) handleFingerprintLine(cols []string) {
@@ -119,18 +141,18 @@ func (p *listSecretKeysParser) handleFingerprintLine(cols []string) {
 		return
 	}
 
-	if p.partialKey.Fingerprint.IsSet() {
-		// We've already got a fingerprint for this key, so this is
-		// probably a fingerprint for a subkey, which we're not
-		// interested in
-		return
-	}
 	fingerprint, err := fingerprint.Parse(cols[9])
 	if err != nil {
 		return
 	}
 
-	p.partialKey.Fingerprint = fingerprint
+	if p.partialSubkey != nil {
+		if !p.partialSubkey.Fingerprint.IsSet() {
+			p.partialSubkey.Fingerprint = fingerprint
+		}
+	} else if !p.partialKey.Fingerprint.IsSet() {
+		p.partialKey.Fingerprint = fingerprint
+	}
 }
 
 func (p *listSecretKeysParser) handleUidLine(cols []string) {
@@ -157,6 +179,287 @@ func (p *listSecretKeysParser) addPartialKeyToList() {
 		p.keys = append(p.keys, *p.partialKey)
 	}
 	p.partialKey = nil
+	p.partialSubkey = nil
+}
+
+// colonListing is everything read from a colon-delimited key listing.
+type colonListing struct {
+	keys []KeyListing
+
+	// trustDb is from the tru record, or nil if there wasn't one.
+	trustDb *trustDbRecord
+}
+
+// parseColonDelimitedKeys reads the output of `gpg --with-colons --list-keys`
+// (or --list-secret-keys) and returns a KeyListing for each pub (or sec)
+// record, with the records that follow it.
+func parseColonDelimitedKeys(reader io.Reader) ([]KeyListing, error) {
+	listing, err := parseColonListing(reader)
+	if err != nil {
+		return nil, err
+	}
+	return listing.keys, nil
+}
+
+// parseColonListing reads the colon-delimited output of --list-keys,
+// --list-secret-keys, --list-sigs or --check-sigs. Each pub (or sec) record
+// starts a KeyListing, and the records that follow it are added to it:
+//
+// * sub or ssb: a subkey
+// * fpr: the fingerprint of the primary key or subkey before it
+// * uid: a user ID
+// * rvk: a designated revoker
+// * sig or rev: a signature on the primary key, user ID or subkey before it
+// * spk: a subpacket of the signature before it
+//
+// The tru record describes the trust database rather than a key.
+func parseColonListing(reader io.Reader) (*colonListing, error) {
+	listing := colonListing{}
+	var currentKey *KeyListing
+	var currentSubkey *SubkeyListing
+	var currentUid string
+	var currentSignature *SignatureListing
+
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		line := scanner.Text()
+		cols := strings.Split(line, ":")
+
+		if cols[0] != "spk" {
+			currentSignature = nil
+		}
+
+		switch cols[0] {
+		case "tru":
+			record, err := parseTrustDbRecord(cols, line)
+			if err != nil {
+				return nil, err
+			}
+			listing.trustDb = record
+
+		case "pub", "sec":
+			component, err := parseSubkeyListing(cols, line)
+			if err != nil {
+				return nil, err
+			}
+			listing.keys = append(listing.keys, newKeyListing(*component))
+			currentKey = &listing.keys[len(listing.keys)-1]
+			currentSubkey = nil
+			currentUid = ""
+
+		case "sub", "ssb":
+			if currentKey == nil {
+				return nil, fmt.Errorf("%s record before any primary key: '%s'", cols[0], line)
+			}
+			component, err := parseSubkeyListing(cols, line)
+			if err != nil {
+				return nil, err
+			}
+			currentKey.Subkeys = append(currentKey.Subkeys, *component)
+			currentSubkey = &currentKey.Subkeys[len(currentKey.Subkeys)-1]
+			currentUid = ""
+
+		case "fpr":
+			if currentKey == nil {
+				continue
+			}
+			if len(cols) < 10 {
+				return nil, fmt.Errorf("fpr record has too few fields: '%s'", line)
+			}
+			fp, err := fingerprint.Parse(cols[9])
+			if err != nil {
+				return nil, err
+			}
+			if currentSubkey != nil {
+				if !currentSubkey.Fingerprint.IsSet() {
+					currentSubkey.Fingerprint = fp
+				}
+			} else if !currentKey.Fingerprint.IsSet() {
+				currentKey.Fingerprint = fp
+			}
+
+		case "uid":
+			if currentKey == nil {
+				continue
+			}
+			if len(cols) < 10 {
+				return nil, fmt.Errorf("uid record has too few fields: '%s'", line)
+			}
+			currentUid = unquoteColons(cols[9])
+			currentKey.Uids = append(currentKey.Uids, currentUid)
+			currentKey.UidValidities = append(currentKey.UidValidities, validityName(cols[1]))
+			currentSubkey = nil
+
+		case "rvk":
+			if currentKey == nil {
+				continue
+			}
+			if len(cols) < 10 {
+				return nil, fmt.Errorf("rvk record has too few fields: '%s'", line)
+			}
+			revoker, err := fingerprint.Parse(cols[9])
+			if err != nil {
+				return nil, err
+			}
+			currentKey.DesignatedRevokers = append(currentKey.DesignatedRevokers, revoker)
+
+		case "sig", "rev":
+			if currentKey == nil {
+				continue
+			}
+			signature, err := parseSignatureListing(cols, line)
+			if err != nil {
+				return nil, err
+			}
+			signature.Uid = currentUid
+			if currentSubkey != nil {
+				signature.SubkeyId = currentSubkey.KeyId
+			}
+			currentKey.Signatures = append(currentKey.Signatures, *signature)
+			currentSignature = &currentKey.Signatures[len(currentKey.Signatures)-1]
+
+		case "spk":
+			if currentSignature == nil {
+				continue
+			}
+			if len(cols) < 5 {
+				return nil, fmt.Errorf("spk record has too few fields: '%s'", line)
+			}
+			subpacketType, err := strconv.Atoi(cols[1])
+			if err != nil {
+				return nil, fmt.Errorf("error parsing subpacket type '%s': %v", cols[1], err)
+			}
+			currentSignature.Subpackets = append(currentSignature.Subpackets, SignatureSubpacket{
+				Type: subpacketType,
+				Data: cols[4],
+			})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading key listing: %v", err)
+	}
+
+	for _, key := range listing.keys {
+		if !key.Fingerprint.IsSet() {
+			return nil, fmt.Errorf("no fingerprint for key 0x%016X, was --with-fingerprint used?", key.KeyId)
+		}
+	}
+	return &listing, nil
+}
+
+// newKeyListing returns a KeyListing for the primary key parsed from a pub or
+// sec record, without its fingerprint, user IDs or subkeys.
+func newKeyListing(primaryKey SubkeyListing) KeyListing {
+	return KeyListing{
+		Fingerprint:  primaryKey.Fingerprint,
+		KeyId:        primaryKey.KeyId,
+		Created:      primaryKey.Created,
+		Expires:      primaryKey.Expires,
+		Algorithm:    primaryKey.Algorithm,
+		Capabilities: primaryKey.Capabilities,
+		Revoked:      primaryKey.Revoked,
+		Expired:      primaryKey.Expired,
+	}
+}
+
+// parseSubkeyListing reads the fields common to pub, sec, sub and ssb
+// records.
+func parseSubkeyListing(cols []string, line string) (*SubkeyListing, error) {
+	if len(cols) < 17 {
+		return nil, fmt.Errorf("%s record has too few fields: '%s'", cols[0], line)
+	}
+	keyId, err := strconv.ParseUint(cols[4], 16, 64)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing key ID '%s': %v", cols[4], err)
+	}
+	created, err := parseTimestamp(cols[5])
+	if err != nil {
+		return nil, err
+	}
+
+	subkey := SubkeyListing{
+		KeyId:        keyId,
+		Created:      *created,
+		Algorithm:    algorithmName(cols[3], cols[2], cols[16]),
+		Capabilities: cols[11],
+		Revoked:      cols[1] == "r",
+		Expired:      cols[1] == "e",
+	}
+
+	if cols[6] != "" {
+		expires, err := parseTimestamp(cols[6])
+		if err != nil {
+			return nil, err
+		}
+		subkey.Expires = expires
+	}
+	return &subkey, nil
+}
+
+// parseSignatureListing reads a sig or rev record.
+func parseSignatureListing(cols []string, line string) (*SignatureListing, error) {
+	if len(cols) < 5 {
+		return nil, fmt.Errorf("%s record has too few fields: '%s'", cols[0], line)
+	}
+	issuerKeyId, err := strconv.ParseUint(cols[4], 16, 64)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing key ID '%s': %v", cols[4], err)
+	}
+
+	signature := SignatureListing{
+		IssuerKeyId: issuerKeyId,
+		Revocation:  cols[0] == "rev",
+		Good:        cols[1] == "!",
+	}
+
+	if len(cols) > 12 && cols[12] != "" {
+		issuer, err := fingerprint.Parse(cols[12])
+		if err != nil {
+			return nil, err
+		}
+		signature.IssuerFingerprint = issuer
+	}
+	return &signature, nil
+}
+
+// algorithmName returns a name like "rsa4096" or "ed25519" from the
+// algorithm number, key length and curve name fields of a pub or sub record.
+func algorithmName(algorithm string, keyLength string, curve string) string {
+	switch algorithm {
+	case "1", "2", "3":
+		return "rsa" + keyLength
+	case "16":
+		return "elg" + keyLength
+	case "17":
+		return "dsa" + keyLength
+	case "18", "19", "22":
+		return curve
+	}
+	return "unknown" + algorithm
+}
+
+// validityName returns a readable name for the validity field of a colon
+// listing record.
+func validityName(validity string) string {
+	switch validity {
+	case "i":
+		return "invalid"
+	case "d":
+		return "disabled"
+	case "r":
+		return "revoked"
+	case "e":
+		return "expired"
+	case "n":
+		return "never"
+	case "m":
+		return "marginal"
+	case "f":
+		return "full"
+	case "u":
+		return "ultimate"
+	}
+	return "unknown" // o, -, q or empty
 }
 
 func parseTimestamp(utcTimestamp string) (*time.Time, error) {
@@ -0,0 +1,86 @@
+// Copyright 2018 Paul Furley and Ian Drysdale
+//
+// This file is part of Fluidkeys Client which makes it simple to use OpenPGP.
+//
+// Fluidkeys Client is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Fluidkeys Client is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with Fluidkeys Client.  If not, see <https://www.gnu.org/licenses/>.
+
+package gpgwrapper
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/fluidkeys/fluidkeys/fingerprint"
+)
+
+// ErrRevokerNotConfirmed is returned by AddDesignatedRevoker if the caller
+// hasn't confirmed that designating a revoker can't be undone.
+var ErrRevokerNotConfirmed = errors.New("designating a revoker can't be undone and wasn't confirmed")
+
+// AddDesignatedRevoker designates the key revokerFingerprint as allowed to
+// revoke the key fingerprint, for example so an organisation can revoke the
+// key of someone who has left. Both keys must be in the keyring, and the
+// secret key for fingerprint must be unlocked with the given password.
+//
+// This is irreversible: once published, the revoker can revoke the key
+// forever, and there is no way to withdraw the designation. To make sure
+// that's intended, confirmIrreversible must be true, otherwise
+// ErrRevokerNotConfirmed is returned and the key is left unchanged.
+func (g *GnuPG) AddDesignatedRevoker(
+	fingerprint fingerprint.Fingerprint, revokerFingerprint fingerprint.Fingerprint,
+	password string, confirmIrreversible bool) error {
+
+	if !confirmIrreversible {
+		return ErrRevokerNotConfirmed
+	}
+
+	commands := strings.Join([]string{
+		"addrevoker",
+		revokerFingerprint.Hex(),
+		"y", // confirm "appointing a key as a designated revoker cannot be undone"
+		"save",
+	}, "\n") + "\n"
+
+	_, stderr, err := g.runEditKey(fingerprint, password, commands)
+	if _, ok := err.(*BadPasswordError); ok {
+		return err
+	} else if err != nil {
+		return fmt.Errorf("error adding designated revoker: %v: %s", err, stderr)
+	}
+
+	revokers, err := g.designatedRevokers(fingerprint)
+	if err != nil {
+		return err
+	}
+	if !revokers[revokerFingerprint] {
+		return fmt.Errorf("gpg didn't add %s as a designated revoker: %s", revokerFingerprint, stderr)
+	}
+	return nil
+}
+
+// designatedRevokers returns the fingerprints of the keys allowed to revoke
+// the given key.
+func (g *GnuPG) designatedRevokers(fp fingerprint.Fingerprint) (map[fingerprint.Fingerprint]bool, error) {
+	key, err := g.getKeyListing(fp, "--list-keys")
+	if err != nil {
+		return nil, err
+	}
+
+	revokers := make(map[fingerprint.Fingerprint]bool)
+	for _, revoker := range key.DesignatedRevokers {
+		revokers[revoker] = true
+	}
+	return revokers, nil
+}
@@ -0,0 +1,45 @@
+package gpgwrapper
+
+import (
+	"testing"
+
+	"github.com/fluidkeys/fluidkeys/assert"
+	"github.com/fluidkeys/fluidkeys/exampledata"
+	"github.com/fluidkeys/fluidkeys/fingerprint"
+)
+
+func TestAddDesignatedRevoker(t *testing.T) {
+	gpg := makeGpgWithTempHome(t)
+	_, err := gpg.ImportArmoredKey(ExamplePrivateKey)
+	assertNoError(t, err)
+	_, err = gpg.ImportArmoredKey(exampledata.ExamplePublicKey4)
+	assertNoError(t, err)
+
+	fp := fingerprint.MustParse("C16B 89AC 31CD F3B7 8DA3  3AAE 1D20 FC95 4793 5FC6")
+	revoker := exampledata.ExampleFingerprint4
+
+	t.Run("without confirmation", func(t *testing.T) {
+		err := gpg.AddDesignatedRevoker(fp, revoker, "foo", false)
+		assert.Equal(t, ErrRevokerNotConfirmed, err)
+
+		revokers, err := gpg.designatedRevokers(fp)
+		assertNoError(t, err)
+		assert.Equal(t, map[fingerprint.Fingerprint]bool{}, revokers)
+	})
+
+	t.Run("with the wrong password", func(t *testing.T) {
+		err := gpg.AddDesignatedRevoker(fp, revoker, "wrong password", true)
+		if _, ok := err.(*BadPasswordError); !ok {
+			t.Fatalf("expected BadPasswordError, got %v", err)
+		}
+	})
+
+	t.Run("with confirmation and the right password", func(t *testing.T) {
+		err := gpg.AddDesignatedRevoker(fp, revoker, "foo", true)
+		assertNoError(t, err)
+
+		revokers, err := gpg.designatedRevokers(fp)
+		assertNoError(t, err)
+		assert.Equal(t, map[fingerprint.Fingerprint]bool{revoker: true}, revokers)
+	})
+}
@@ -0,0 +1,54 @@
+// Copyright 2018 Paul Furley and Ian Drysdale
+//
+// This file is part of Fluidkeys Client which makes it simple to use OpenPGP.
+//
+// Fluidkeys Client is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Fluidkeys Client is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with Fluidkeys Client.  If not, see <https://www.gnu.org/licenses/>.
+package gpgwrapper
+
+import (
+	"context"
+	"io"
+	"os"
+	"os/exec"
+)
+
+// commandRunner runs gpg for GnuPG. It's an interface so that tests can use
+// a fake which returns canned output instead of needing a real gpg.
+type commandRunner interface {
+	run(ctx context.Context, c command) error
+}
+
+// command is a single gpg invocation for a commandRunner. The runner writes
+// gpg's output to stdout and stderr.
+type command struct {
+	path       string
+	args       []string
+	stdin      io.Reader
+	extraFiles []*os.File
+	stdout     io.Writer
+	stderr     io.Writer
+}
+
+// execRunner is the commandRunner which actually runs gpg. If the process
+// can't be started or exits non-zero, it returns the error from exec.
+type execRunner struct{}
+
+func (execRunner) run(ctx context.Context, c command) error {
+	cmd := exec.CommandContext(ctx, c.path, c.args...)
+	cmd.Stdin = c.stdin
+	cmd.ExtraFiles = c.extraFiles
+	cmd.Stdout = c.stdout
+	cmd.Stderr = c.stderr
+	return cmd.Run()
+}
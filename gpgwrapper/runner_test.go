@@ -0,0 +1,134 @@
+package gpgwrapper
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/fluidkeys/fluidkeys/assert"
+	"github.com/fluidkeys/fluidkeys/exampledata"
+)
+
+// fakeRunner is a commandRunner which returns canned output instead of
+// running gpg, and records the arguments it was called with.
+type fakeRunner struct {
+	stdout   string
+	stderr   string
+	exitCode int
+
+	mu    sync.Mutex
+	calls [][]string
+	stdin []string
+}
+
+func (f *fakeRunner) run(ctx context.Context, c command) error {
+	var stdin string
+	if c.stdin != nil {
+		b, err := ioutil.ReadAll(c.stdin)
+		if err != nil {
+			return err
+		}
+		stdin = string(b)
+	}
+
+	f.mu.Lock()
+	f.calls = append(f.calls, c.args)
+	f.stdin = append(f.stdin, stdin)
+	f.mu.Unlock()
+
+	fmt.Fprint(c.stdout, f.stdout)
+	fmt.Fprint(c.stderr, f.stderr)
+
+	if f.exitCode != 0 {
+		return &fakeExitError{exitCode: f.exitCode}
+	}
+	return nil
+}
+
+// fakeExitError is like the *exec.ExitError returned when gpg exits
+// non-zero.
+type fakeExitError struct {
+	exitCode int
+}
+
+func (e *fakeExitError) Error() string { return fmt.Sprintf("exit status %d", e.exitCode) }
+func (e *fakeExitError) ExitCode() int { return e.exitCode }
+
+func makeGpgWithFakeRunner(runner *fakeRunner) *GnuPG {
+	return &GnuPG{fullGpgPath: "/fake/gpg", runner: runner}
+}
+
+func TestFakeRunner(t *testing.T) {
+	t.Run("Version parses canned output", func(t *testing.T) {
+		runner := &fakeRunner{stdout: "gpg (GnuPG) 2.2.4\nlibgcrypt 1.8.1\n"}
+		gpg := makeGpgWithFakeRunner(runner)
+
+		version, err := gpg.Version()
+		assertNoError(t, err)
+		assert.Equal(t, "2.2.4", version)
+
+		assert.Equal(t, 1, len(runner.calls))
+		args := runner.calls[0]
+		assert.Equal(t, "--version", args[len(args)-1])
+	})
+
+	t.Run("run returns the exit code when gpg fails", func(t *testing.T) {
+		runner := &fakeRunner{stderr: "gpg: something went wrong", exitCode: 2}
+		gpg := makeGpgWithFakeRunner(runner)
+
+		_, err := gpg.run("--version")
+
+		gpgErr, ok := err.(*ErrGpgFailed)
+		assert.Equal(t, true, ok)
+		assert.Equal(t, 2, gpgErr.ExitCode)
+		assert.Equal(t, "gpg: something went wrong", gpgErr.Stderr)
+	})
+
+	t.Run("ImportArmoredKey sends the key and parses the status output", func(t *testing.T) {
+		runner := &fakeRunner{
+			stdout: "[GNUPG:] IMPORT_OK 1 BB3C44BF188D56E635F4A092F73D2F0533D7F9D6\n" +
+				"[GNUPG:] IMPORT_RES 1 0 1 0 0 0 0 0 0 0 0 0 0 0 0\n",
+		}
+		gpg := makeGpgWithFakeRunner(runner)
+
+		result, err := gpg.ImportArmoredKey(exampledata.ExamplePublicKey4)
+		assertNoError(t, err)
+		assert.Equal(t, ImportResult{Imported: 1}, result)
+
+		assert.Equal(t, 1, len(runner.stdin))
+		assert.Equal(t, true, strings.Contains(runner.stdin[0], publicHeader))
+	})
+
+	t.Run("ImportArmoredKey when gpg fails", func(t *testing.T) {
+		runner := &fakeRunner{stderr: "gpg: keydb_search failed: Resource temporarily unavailable", exitCode: 2}
+		gpg := makeGpgWithFakeRunner(runner)
+
+		_, err := gpg.ImportArmoredKey(exampledata.ExamplePublicKey4)
+		assert.ErrorIsNotNil(t, err)
+		assert.Equal(t, true, strings.Contains(err.Error(), "Resource temporarily unavailable"))
+	})
+
+	t.Run("runEditKey sends only the commands on stdin", func(t *testing.T) {
+		runner := &fakeRunner{}
+		gpg := makeGpgWithFakeRunner(runner)
+
+		_, _, err := gpg.runEditKey(exampledata.ExampleFingerprint4, "secret\npassphrase", "save\n")
+		assertNoError(t, err)
+
+		assert.Equal(t, []string{"save\n"}, runner.stdin)
+		assert.Equal(t, true, strings.Contains(strings.Join(runner.calls[0], " "), "--passphrase-fd 3"))
+	})
+
+	t.Run("runEditKey with the wrong passphrase", func(t *testing.T) {
+		runner := &fakeRunner{stdout: "[GNUPG:] BAD_PASSPHRASE 1D20FC9547935FC6\n", exitCode: 2}
+		gpg := makeGpgWithFakeRunner(runner)
+
+		_, _, err := gpg.runEditKey(exampledata.ExampleFingerprint4, "wrong", "save\n")
+		if _, ok := err.(*BadPasswordError); !ok {
+			t.Fatalf("expected BadPasswordError, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,74 @@
+// Copyright 2018 Paul Furley and Ian Drysdale
+//
+// This file is part of Fluidkeys Client which makes it simple to use OpenPGP.
+//
+// Fluidkeys Client is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Fluidkeys Client is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with Fluidkeys Client.  If not, see <https://www.gnu.org/licenses/>.
+
+package gpgwrapper
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fluidkeys/fluidkeys/fingerprint"
+)
+
+// SignDetached makes a detached signature over data with the secret key
+// with the given fingerprint (or its signing subkey), unlocked with
+// passphrase. The signature is ascii-armored if armor is true, otherwise
+// it's the raw signature packet.
+//
+// The passphrase is passed to gpg over a pipe, so it never appears in the
+// process's argument list. It returns ErrBadPassphrase if the passphrase is
+// wrong, or ErrNoSecretKey if the secret key isn't in the keyring.
+func (g *GnuPG) SignDetached(data string, signer fingerprint.Fingerprint, passphrase string, armor bool) (string, error) {
+	passphraseReader, err := makePassphrasePipe(passphrase)
+	if err != nil {
+		return "", err
+	}
+	defer passphraseReader.Close()
+
+	stdout, stderr, err := g.runCommand(
+		context.Background(),
+		strings.NewReader(data),
+		[]*os.File{passphraseReader},
+		getArgsSignDetached(signer, armor)...,
+	)
+	if isSecretKeyError(err) {
+		return "", err
+	} else if err != nil {
+		return "", fmt.Errorf("error signing: %v: %s", err, stderr)
+	}
+
+	if armor && !strings.Contains(stdout, signatureHeader) {
+		return "", fmt.Errorf("GnuPG didn't output a signature: %s", stderr)
+	}
+	return stdout, nil
+}
+
+func getArgsSignDetached(signer fingerprint.Fingerprint, armor bool) []string {
+	args := []string{
+		"--pinentry-mode", "loopback", // don't use OS password prompt
+		"--passphrase-fd", "3", // the first of the extra files
+		"--local-user", signer.Hex(), // GnuPG picks a signing subkey if there is one
+	}
+	if armor {
+		args = append(args, "--armor")
+	}
+	return append(args, "--detach-sign")
+}
+
+const signatureHeader = "-----BEGIN PGP SIGNATURE-----"
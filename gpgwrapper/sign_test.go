@@ -0,0 +1,52 @@
+package gpgwrapper
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/fluidkeys/fluidkeys/assert"
+	"github.com/fluidkeys/fluidkeys/exampledata"
+)
+
+func TestSignDetached(t *testing.T) {
+	gpg := makeGpgWithTempHome(t)
+	_, err := gpg.ImportArmoredKey(exampledata.ExamplePublicKey4)
+	assertNoError(t, err)
+	_, err = gpg.ImportArmoredKey(exampledata.ExamplePrivateKey4)
+	assertNoError(t, err)
+
+	// gpg-agent caches the passphrase once it's been used, so try the
+	// wrong one first
+	t.Run("with the wrong passphrase", func(t *testing.T) {
+		_, err := gpg.SignDetached("hello", exampledata.ExampleFingerprint4, "wrong passphrase", true)
+		if _, ok := err.(*BadPasswordError); !ok {
+			t.Fatalf("expected ErrBadPassphrase, got %v", err)
+		}
+	})
+
+	for _, armor := range []bool{true, false} {
+		name := "armored"
+		if !armor {
+			name = "binary"
+		}
+
+		t.Run(name+" signature verifies", func(t *testing.T) {
+			signature, err := gpg.SignDetached("hello", exampledata.ExampleFingerprint4, "test4", armor)
+			assertNoError(t, err)
+			assert.Equal(t, armor, strings.HasPrefix(signature, signatureHeader))
+
+			result, err := gpg.VerifyWithArmoredKey("hello", signature, exampledata.ExamplePublicKey4)
+			assertNoError(t, err)
+			assert.Equal(t, true, result.Valid)
+			assert.Equal(t, exampledata.ExampleFingerprint4, result.SignerFingerprint)
+		})
+	}
+
+	t.Run("without the secret key", func(t *testing.T) {
+		otherGpg := makeGpgWithTempHome(t)
+		_, err := otherGpg.SignDetached("hello", exampledata.ExampleFingerprint4, "test4", true)
+		if err != ErrNoSecretKey {
+			t.Fatalf("expected ErrNoSecretKey, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,112 @@
+// Copyright 2018 Paul Furley and Ian Drysdale
+//
+// This file is part of Fluidkeys Client which makes it simple to use OpenPGP.
+//
+// Fluidkeys Client is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Fluidkeys Client is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with Fluidkeys Client.  If not, see <https://www.gnu.org/licenses/>.
+
+package gpgwrapper
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/fluidkeys/fluidkeys/fingerprint"
+	"github.com/fluidkeys/fluidkeys/policy"
+)
+
+// EstimatedBitStrength returns the approximate security of the key in bits,
+// expressed as the size of a symmetric key that would be equally hard to
+// break. This gives one number for comparing RSA and elliptic curve keys.
+//
+// The strength of the whole key is that of its weakest component: the
+// primary key or any subkey which hasn't expired or been revoked.
+//
+// RSA, DSA and ElGamal use the NIST SP 800-57 equivalences, see
+// policy.FiniteFieldBitStrength.
+//
+// Elliptic curve keys use policy.EllipticCurveBitStrength: 128 for ed25519,
+// cv25519, nistp256 and 256-bit brainpool/secp256k1 curves, 192 for
+// nistp384, 224 for ed448 and cv448 and 256 for nistp521 and
+// brainpoolP512r1.
+func (g *GnuPG) EstimatedBitStrength(fp fingerprint.Fingerprint) (int, error) {
+	key, err := g.getKeyListing(fp, "--list-keys")
+	if err != nil {
+		return 0, err
+	}
+
+	return estimatedBitStrength(*key)
+}
+
+// estimatedBitStrength returns the strength of the weakest usable component
+// of the key.
+func estimatedBitStrength(key KeyListing) (int, error) {
+	weakest, err := algorithmBitStrength(key.Algorithm)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, subkey := range key.Subkeys {
+		if subkey.Expired || subkey.Revoked {
+			continue // expired or revoked subkeys can't be used
+		}
+
+		strength, err := algorithmBitStrength(subkey.Algorithm)
+		if err != nil {
+			return 0, err
+		}
+		if strength < weakest {
+			weakest = strength
+		}
+	}
+	return weakest, nil
+}
+
+// algorithmBitStrength returns the strength of a single primary key or
+// subkey given its algorithm name, e.g. "rsa4096" or "ed25519" (see
+// algorithmName).
+func algorithmBitStrength(algorithm string) (int, error) {
+	for _, prefix := range []string{"rsa", "elg", "dsa"} {
+		if !strings.HasPrefix(algorithm, prefix) {
+			continue
+		}
+		bits, err := strconv.Atoi(strings.TrimPrefix(algorithm, prefix))
+		if err != nil {
+			return 0, fmt.Errorf("invalid key length in '%s': %v", algorithm, err)
+		}
+		return policy.FiniteFieldBitStrength(bits), nil
+	}
+
+	if curveBits, ok := curveSizes[algorithm]; ok {
+		return policy.EllipticCurveBitStrength(curveBits), nil
+	}
+	return 0, fmt.Errorf("unknown public key algorithm '%s'", algorithm)
+}
+
+// curveSizes maps GnuPG's curve names (field 17 of a pub or sub record, which
+// algorithmName uses for elliptic curve keys) to the size of the curve in
+// bits.
+var curveSizes = map[string]int{
+	"ed25519":         256,
+	"cv25519":         256,
+	"nistp256":        256,
+	"brainpoolP256r1": 256,
+	"secp256k1":       256,
+	"nistp384":        384,
+	"brainpoolP384r1": 384,
+	"ed448":           448,
+	"cv448":           448,
+	"nistp521":        521,
+	"brainpoolP512r1": 512,
+}
@@ -0,0 +1,75 @@
+package gpgwrapper
+
+import (
+	"testing"
+
+	"github.com/fluidkeys/fluidkeys/assert"
+	"github.com/fluidkeys/fluidkeys/exampledata"
+)
+
+func TestEstimatedBitStrength(t *testing.T) {
+	gpg := makeGpgWithTempHome(t)
+	_, err := gpg.ImportArmoredKey(exampledata.ExamplePublicKey4)
+	assertNoError(t, err)
+	_, err = gpg.ImportArmoredKey(exampleAlicePublicKey)
+	assertNoError(t, err)
+
+	t.Run("with a 1024 bit RSA key", func(t *testing.T) {
+		strength, err := gpg.EstimatedBitStrength(exampledata.ExampleFingerprint4)
+		assertNoError(t, err)
+		assert.Equal(t, 80, strength)
+	})
+
+	t.Run("with an ed25519 key", func(t *testing.T) {
+		strength, err := gpg.EstimatedBitStrength(exampleAliceFingerprint)
+		assertNoError(t, err)
+		assert.Equal(t, 128, strength)
+	})
+
+	t.Run("with a key that isn't in the keyring", func(t *testing.T) {
+		_, err := gpg.EstimatedBitStrength(exampledata.ExampleFingerprint2)
+		assert.ErrorIsNotNil(t, err)
+	})
+}
+
+func TestEstimatedBitStrengthOfListing(t *testing.T) {
+	var tests = []struct {
+		name             string
+		key              KeyListing
+		expectedStrength int
+	}{
+		{
+			"RSA 4096 primary with RSA 2048 subkey returns the subkey",
+			KeyListing{Algorithm: "rsa4096", Subkeys: []SubkeyListing{{Algorithm: "rsa2048"}}},
+			112,
+		},
+		{
+			"RSA 3072 primary with expired RSA 1024 subkey ignores the subkey",
+			KeyListing{Algorithm: "rsa3072", Subkeys: []SubkeyListing{{Algorithm: "rsa1024", Expired: true}}},
+			128,
+		},
+		{
+			"nistp384 primary with cv25519 subkey",
+			KeyListing{Algorithm: "nistp384", Subkeys: []SubkeyListing{{Algorithm: "cv25519"}}},
+			128,
+		},
+		{
+			"RSA 768 primary is broken",
+			KeyListing{Algorithm: "rsa768"},
+			0,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			strength, err := estimatedBitStrength(test.key)
+			assertNoError(t, err)
+			assert.Equal(t, test.expectedStrength, strength)
+		})
+	}
+
+	t.Run("with an unknown curve", func(t *testing.T) {
+		_, err := estimatedBitStrength(KeyListing{Algorithm: "foo"})
+		assert.ErrorIsNotNil(t, err)
+	})
+}
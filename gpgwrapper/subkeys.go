@@ -0,0 +1,172 @@
+// Copyright 2018 Paul Furley and Ian Drysdale
+//
+// This file is part of Fluidkeys Client which makes it simple to use OpenPGP.
+//
+// Fluidkeys Client is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Fluidkeys Client is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with Fluidkeys Client.  If not, see <https://www.gnu.org/licenses/>.
+
+package gpgwrapper
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fluidkeys/fluidkeys/fingerprint"
+	"github.com/fluidkeys/fluidkeys/policy"
+)
+
+// AddEncryptionSubkey adds a new RSA encryption subkey (of
+// policy.EncryptionSubkeyRsaKeyBits) to the key with the given fingerprint,
+// expiring at expiry, and returns the new subkey's fingerprint. If expiry is
+// zero, the subkey doesn't expire.
+//
+// The secret key is unlocked with passphrase, passed to gpg over a pipe. It
+// returns ErrBadPassphrase if the passphrase is wrong, or ErrNoSecretKey if
+// the keyring only has the public key.
+func (g *GnuPG) AddEncryptionSubkey(fp fingerprint.Fingerprint, passphrase string, expiry time.Time) (fingerprint.Fingerprint, error) {
+	passphraseReader, err := makePassphrasePipe(passphrase)
+	if err != nil {
+		return fingerprint.Fingerprint{}, err
+	}
+	defer passphraseReader.Close()
+
+	stdout, stderr, err := g.runCommand(
+		context.Background(),
+		nil,
+		[]*os.File{passphraseReader},
+		getArgsAddEncryptionSubkey(fp, expiry)...,
+	)
+	if isSecretKeyError(err) {
+		return fingerprint.Fingerprint{}, err
+	} else if err != nil {
+		return fingerprint.Fingerprint{}, fmt.Errorf("error adding subkey: %v: %s", err, stderr)
+	}
+
+	return parseKeyCreatedStatus(stdout)
+}
+
+// RevocationReason is the reason code given when revoking a subkey, as
+// listed by GnuPG's revkey prompt.
+type RevocationReason string
+
+const (
+	// RevocationReasonNone gives no reason
+	RevocationReasonNone RevocationReason = "0"
+
+	// RevocationReasonCompromised says the subkey's secret key has been
+	// compromised
+	RevocationReasonCompromised RevocationReason = "1"
+
+	// RevocationReasonSuperseded says the subkey has been replaced, for
+	// example by rotation
+	RevocationReasonSuperseded RevocationReason = "2"
+
+	// RevocationReasonNoLongerUsed says the subkey is no longer used
+	RevocationReasonNoLongerUsed RevocationReason = "3"
+)
+
+// RevokeSubkey revokes the given subkey of the key with the given fingerprint,
+// giving reason as the reason code, for example RevocationReasonSuperseded
+// once a new encryption subkey has been added.
+//
+// It scripts the `--edit-key` prompt. It returns ErrSubkeyNotFound if the key
+// doesn't have the subkey and BadPasswordError if the passphrase is wrong.
+func (g *GnuPG) RevokeSubkey(fp fingerprint.Fingerprint, subkey fingerprint.Fingerprint, passphrase string, reason RevocationReason) error {
+	switch reason {
+	case RevocationReasonNone, RevocationReasonCompromised, RevocationReasonSuperseded, RevocationReasonNoLongerUsed:
+	default:
+		return fmt.Errorf("invalid revocation reason '%s'", reason)
+	}
+
+	keys, err := g.SubkeyCreationTimes(fp)
+	if err != nil {
+		return err
+	}
+	if _, ok := keys[subkey]; !ok || subkey == fp {
+		return &ErrSubkeyNotFound{Fingerprint: fp, SubkeyFingerprint: subkey}
+	}
+
+	_, stderr, err := g.runEditKey(fp, passphrase, getEditKeyRevokeSubkeyCommands(subkey, reason))
+	if _, ok := err.(*BadPasswordError); ok {
+		return err
+	} else if err != nil {
+		return fmt.Errorf("error revoking subkey: %v: %s", err, stderr)
+	}
+	return nil
+}
+
+// getEditKeyRevokeSubkeyCommands returns the edit-key commands to select the
+// subkey, revoke it with the given reason and no description, then save.
+func getEditKeyRevokeSubkeyCommands(subkey fingerprint.Fingerprint, reason RevocationReason) string {
+	commands := []string{
+		"key " + subkey.Hex(), // select the subkey
+		"revkey",
+		"y", // really revoke this subkey?
+		string(reason),
+		"",  // an empty line ends the description
+		"y", // is this okay?
+		"save",
+	}
+	return strings.Join(commands, "\n") + "\n"
+}
+
+func getArgsAddEncryptionSubkey(fp fingerprint.Fingerprint, expiry time.Time) []string {
+	expiryArg := "0" // never expires
+	if !expiry.IsZero() {
+		expiryArg = expiry.UTC().Format(isoTimestampFormat)
+	}
+
+	return []string{
+		"--status-fd", "1",
+		"--pinentry-mode", "loopback", // don't use OS password prompt
+		"--passphrase-fd", "3", // the first of the extra files
+		"--quick-add-key",
+		fp.Hex(),
+		"rsa" + strconv.Itoa(policy.EncryptionSubkeyRsaKeyBits),
+		"encrypt",
+		expiryArg,
+	}
+}
+
+// SubkeyCreationTimes returns the creation time of the primary key and of
+// every subkey (including expired and revoked ones), keyed by fingerprint.
+//
+// It reads GnuPG's colon listing rather than loading the whole key, so it's
+// cheap enough to run across a whole keyring when auditing rotation.
+func (g *GnuPG) SubkeyCreationTimes(fp fingerprint.Fingerprint) (map[fingerprint.Fingerprint]time.Time, error) {
+	key, err := g.getKeyListing(fp, "--list-keys")
+	if err != nil {
+		return nil, err
+	}
+
+	return keyCreationTimes(*key), nil
+}
+
+// keyCreationTimes returns the creation time of the primary key and each
+// subkey in the listing, keyed by fingerprint.
+func keyCreationTimes(key KeyListing) map[fingerprint.Fingerprint]time.Time {
+	creationTimes := map[fingerprint.Fingerprint]time.Time{
+		key.Fingerprint: key.Created,
+	}
+
+	for _, subkey := range key.Subkeys {
+		if subkey.Fingerprint.IsSet() {
+			creationTimes[subkey.Fingerprint] = subkey.Created
+		}
+	}
+	return creationTimes
+}
@@ -0,0 +1,168 @@
+package gpgwrapper
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fluidkeys/fluidkeys/assert"
+	"github.com/fluidkeys/fluidkeys/exampledata"
+	"github.com/fluidkeys/fluidkeys/fingerprint"
+)
+
+func TestAddEncryptionSubkey(t *testing.T) {
+	gpg := makeGpgWithTempHome(t)
+	fp, err := gpg.GenerateKey(GenerateKeyParams{
+		Email:      "jane@example.com",
+		Passphrase: "test",
+		KeyType:    KeyTypeEd25519,
+	})
+	assertNoError(t, err)
+
+	expiry := time.Date(2038, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("with the right passphrase", func(t *testing.T) {
+		subkeyFp, err := gpg.AddEncryptionSubkey(fp, "test", expiry)
+		assertNoError(t, err)
+
+		secretKeys, err := gpg.ListSecretKeys()
+		assertNoError(t, err)
+		assert.Equal(t, 1, len(secretKeys))
+		assert.Equal(t, fp, secretKeys[0].Fingerprint)
+
+		keys, err := gpg.ListPublicKeys()
+		assertNoError(t, err)
+
+		var added *SubkeyListing
+		for i, subkey := range keys[0].Subkeys {
+			if subkey.Fingerprint == subkeyFp {
+				added = &keys[0].Subkeys[i]
+			}
+		}
+		if added == nil {
+			t.Fatalf("subkey %v not found in %v", subkeyFp, keys[0].Subkeys)
+		}
+		assert.Equal(t, "e", added.Capabilities)
+		assert.Equal(t, "rsa2048", added.Algorithm)
+		assert.Equal(t, expiry, *added.Expires)
+	})
+
+	t.Run("with the wrong passphrase", func(t *testing.T) {
+		_, err := gpg.AddEncryptionSubkey(fp, "wrong", expiry)
+		if _, ok := err.(*BadPasswordError); !ok {
+			t.Fatalf("expected ErrBadPassphrase, got %v", err)
+		}
+	})
+}
+
+func TestRevokeSubkey(t *testing.T) {
+	gpg := makeGpgWithTempHome(t)
+	fp, err := gpg.GenerateKey(GenerateKeyParams{
+		Email:      "jane@example.com",
+		Passphrase: "test",
+		KeyType:    KeyTypeEd25519,
+	})
+	assertNoError(t, err)
+
+	keys, err := gpg.ListPublicKeys()
+	assertNoError(t, err)
+	subkeyFp := keys[0].Subkeys[0].Fingerprint
+
+	t.Run("with the wrong passphrase", func(t *testing.T) {
+		err := gpg.RevokeSubkey(fp, subkeyFp, "wrong", RevocationReasonSuperseded)
+		if _, ok := err.(*BadPasswordError); !ok {
+			t.Fatalf("expected ErrBadPassphrase, got %v", err)
+		}
+	})
+
+	t.Run("with a subkey that isn't on the key", func(t *testing.T) {
+		err := gpg.RevokeSubkey(fp, exampledata.ExampleFingerprint4, "test", RevocationReasonSuperseded)
+		if _, ok := err.(*ErrSubkeyNotFound); !ok {
+			t.Fatalf("expected ErrSubkeyNotFound, got %v", err)
+		}
+	})
+
+	t.Run("with the primary key's fingerprint", func(t *testing.T) {
+		err := gpg.RevokeSubkey(fp, fp, "test", RevocationReasonSuperseded)
+		if _, ok := err.(*ErrSubkeyNotFound); !ok {
+			t.Fatalf("expected ErrSubkeyNotFound, got %v", err)
+		}
+	})
+
+	t.Run("with an invalid reason", func(t *testing.T) {
+		err := gpg.RevokeSubkey(fp, subkeyFp, "test", RevocationReason("9"))
+		assert.ErrorIsNotNil(t, err)
+	})
+
+	t.Run("with the right passphrase", func(t *testing.T) {
+		err := gpg.RevokeSubkey(fp, subkeyFp, "test", RevocationReasonSuperseded)
+		assertNoError(t, err)
+
+		colons, err := gpg.run("--with-colons", "--list-keys", fp.Hex())
+		assertNoError(t, err)
+		if !strings.Contains(colons, "\nsub:r:") {
+			t.Fatalf("expected a revoked subkey in:\n%s", colons)
+		}
+
+		keys, err := gpg.ListPublicKeys()
+		assertNoError(t, err)
+		assert.Equal(t, true, keys[0].Subkeys[0].Revoked)
+	})
+}
+
+func TestGetArgsAddEncryptionSubkey(t *testing.T) {
+	t.Run("with an expiry", func(t *testing.T) {
+		args := getArgsAddEncryptionSubkey(exampledata.ExampleFingerprint4, time.Date(2038, 1, 1, 12, 0, 0, 0, time.UTC))
+		assert.Equal(t, []string{"--quick-add-key", exampledata.ExampleFingerprint4.Hex(), "rsa2048", "encrypt", "20380101T120000"}, args[6:])
+	})
+
+	t.Run("without an expiry", func(t *testing.T) {
+		args := getArgsAddEncryptionSubkey(exampledata.ExampleFingerprint4, time.Time{})
+		assert.Equal(t, "0", args[len(args)-1])
+	})
+}
+
+func TestSubkeyCreationTimes(t *testing.T) {
+	gpg := makeGpgWithTempHome(t)
+	_, err := gpg.ImportArmoredKey(exampledata.ExamplePublicKey4)
+	assertNoError(t, err)
+
+	creationTimes, err := gpg.SubkeyCreationTimes(exampledata.ExampleFingerprint4)
+	assertNoError(t, err)
+
+	expected := map[fingerprint.Fingerprint]time.Time{
+		exampledata.ExampleFingerprint4:                                   time.Unix(1543864399, 0).UTC(),
+		fingerprint.MustParse("09D408F6DD1525735F1F54ABCE7881186F55FA9E"): time.Unix(1543864399, 0).UTC(),
+	}
+	assert.Equal(t, expected, creationTimes)
+
+	t.Run("with a key that isn't in the keyring", func(t *testing.T) {
+		_, err := gpg.SubkeyCreationTimes(exampledata.ExampleFingerprint2)
+		assert.ErrorIsNotNil(t, err)
+	})
+}
+
+func TestKeyCreationTimes(t *testing.T) {
+	t.Run("with a primary key and two subkeys", func(t *testing.T) {
+		output := "tru::1:1792143441:0:3:1:5\n" +
+			"pub:-:1024:1:F73D2F0533D7F9D6:1543864399:::-:::scESC::::::::0:\n" +
+			"fpr:::::::::BB3C44BF188D56E635F4A092F73D2F0533D7F9D6:\n" +
+			"uid:-::::1543864399::E5E624A29B306779F2D0604D3714C1932FB1CF57::test4@example.com::::::::::0:\n" +
+			"sub:e:1024:1:CE7881186F55FA9E:1543864399:1546300800:::::e:::::::\n" +
+			"fpr:::::::::09D408F6DD1525735F1F54ABCE7881186F55FA9E:\n" +
+			"sub:-:1024:1:AAAABBBBCCCCDDDD:1546300800::::::e:::::::\n" +
+			"fpr:::::::::111122223333444455556666AAAABBBBCCCCDDDD:\n"
+
+		keys, err := parseColonDelimitedKeys(strings.NewReader(output))
+		assertNoError(t, err)
+
+		got := keyCreationTimes(keys[0])
+
+		expected := map[fingerprint.Fingerprint]time.Time{
+			fingerprint.MustParse("BB3C44BF188D56E635F4A092F73D2F0533D7F9D6"): time.Unix(1543864399, 0).UTC(),
+			fingerprint.MustParse("09D408F6DD1525735F1F54ABCE7881186F55FA9E"): time.Unix(1543864399, 0).UTC(),
+			fingerprint.MustParse("111122223333444455556666AAAABBBBCCCCDDDD"): time.Unix(1546300800, 0).UTC(),
+		}
+		assert.Equal(t, expected, got)
+	})
+}
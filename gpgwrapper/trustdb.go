@@ -0,0 +1,121 @@
+// Copyright 2018 Paul Furley and Ian Drysdale
+//
+// This file is part of Fluidkeys Client which makes it simple to use OpenPGP.
+//
+// Fluidkeys Client is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Fluidkeys Client is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with Fluidkeys Client.  If not, see <https://www.gnu.org/licenses/>.
+
+package gpgwrapper
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ErrNoTrustDb is returned if GnuPG hasn't created a trust database yet,
+// which happens the first time it's used.
+var ErrNoTrustDb = errors.New("GnuPG has no trust database yet")
+
+// TrustDbLastUpdated returns when GnuPG last built its trust database, which
+// it uses to calculate the validity of keys.
+func (g *GnuPG) TrustDbLastUpdated() (time.Time, error) {
+	record, err := g.getTrustDbRecord()
+	if err != nil {
+		return time.Time{}, err
+	}
+	return record.lastUpdated, nil
+}
+
+// TrustDbCheckDue returns true if the trust database is out of date, either
+// because GnuPG has marked it as stale or because its next scheduled check
+// is before `now`. If so, key validity may be wrong until
+// `gpg --check-trustdb` is run.
+func (g *GnuPG) TrustDbCheckDue(now time.Time) (bool, error) {
+	record, err := g.getTrustDbRecord()
+	if err != nil {
+		return false, err
+	}
+	if record.stale {
+		return true, nil
+	}
+	return record.nextCheck != nil && record.nextCheck.Before(now), nil
+}
+
+func (g *GnuPG) getTrustDbRecord() (*trustDbRecord, error) {
+	homeDir, err := g.HomeDir()
+	if err != nil {
+		return nil, err
+	}
+
+	// listing keys would create an empty trustdb, so check first
+	if _, err := os.Stat(filepath.Join(homeDir, trustDbFilename)); os.IsNotExist(err) {
+		return nil, ErrNoTrustDb
+	}
+
+	listing, err := g.listKeysWithColons([]string{
+		"--no-auto-check-trustdb", // otherwise gpg may update it first
+		"--list-keys",
+	})
+	if err != nil {
+		return nil, err
+	}
+	if listing.trustDb == nil {
+		return nil, ErrNoTrustDb
+	}
+	return listing.trustDb, nil
+}
+
+// trustDbRecord is the information in a `tru` record of the colon listing.
+type trustDbRecord struct {
+	// stale is true if GnuPG considers the trustdb out of date, or built
+	// for a different trust model.
+	stale bool
+
+	lastUpdated time.Time
+
+	// nextCheck is when the trustdb should be checked next, or nil if it
+	// doesn't need checking.
+	nextCheck *time.Time
+}
+
+// parseTrustDbRecord reads the `tru` record of a colon listing:
+// tru:<staleness reason>:<trust model>:<date created>:<date expires>:...
+func parseTrustDbRecord(cols []string, line string) (*trustDbRecord, error) {
+	if len(cols) < 5 {
+		return nil, fmt.Errorf("tru record has too few fields: '%s'", line)
+	}
+
+	lastUpdated, err := parseTimestamp(cols[3])
+	if err != nil {
+		return nil, err
+	}
+
+	record := trustDbRecord{
+		stale:       cols[1] != "", // "o" (old) or "t" (different trust model)
+		lastUpdated: *lastUpdated,
+	}
+
+	if cols[4] != "" && cols[4] != "0" {
+		nextCheck, err := parseTimestamp(cols[4])
+		if err != nil {
+			return nil, err
+		}
+		record.nextCheck = nextCheck
+	}
+	return &record, nil
+}
+
+const trustDbFilename = "trustdb.gpg"
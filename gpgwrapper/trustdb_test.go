@@ -0,0 +1,81 @@
+package gpgwrapper
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fluidkeys/fluidkeys/assert"
+	"github.com/fluidkeys/fluidkeys/exampledata"
+)
+
+func TestTrustDbLastUpdated(t *testing.T) {
+	gpg := makeGpgWithTempHome(t)
+
+	t.Run("before GnuPG has created a trustdb", func(t *testing.T) {
+		_, err := gpg.TrustDbLastUpdated()
+		assert.Equal(t, ErrNoTrustDb, err)
+
+		_, err = gpg.TrustDbCheckDue(time.Now())
+		assert.Equal(t, ErrNoTrustDb, err)
+	})
+
+	t.Run("after importing a key", func(t *testing.T) {
+		before := time.Now().Add(-time.Minute)
+		_, err := gpg.ImportArmoredKey(exampledata.ExamplePublicKey4)
+		assertNoError(t, err)
+
+		lastUpdated, err := gpg.TrustDbLastUpdated()
+		assertNoError(t, err)
+		if lastUpdated.Before(before) || lastUpdated.After(time.Now()) {
+			t.Fatalf("expected trustdb to have been updated just now, got %v", lastUpdated)
+		}
+
+		checkDue, err := gpg.TrustDbCheckDue(time.Now())
+		assertNoError(t, err)
+		assert.Equal(t, false, checkDue)
+	})
+}
+
+func TestParseTrustDbRecord(t *testing.T) {
+	now := time.Unix(1540000000, 0).UTC()
+
+	parseRecord := func(t *testing.T, colons string) *trustDbRecord {
+		t.Helper()
+		listing, err := parseColonListing(strings.NewReader(colons))
+		assertNoError(t, err)
+		return listing.trustDb
+	}
+
+	t.Run("with an up to date trustdb", func(t *testing.T) {
+		record := parseRecord(t, "tru::1:1530000000:0:3:1:5\n"+
+			"pub:-:1024:1:F73D2F0533D7F9D6:1543864399:::-:::scESC::::::::0:\n"+
+			"fpr:::::::::BB3C44BF188D56E635F4A092F73D2F0533D7F9D6:\n")
+
+		assert.Equal(t, false, record.stale)
+		assert.AssertEqualTimes(t, time.Unix(1530000000, 0).UTC(), record.lastUpdated)
+		assert.Equal(t, (*time.Time)(nil), record.nextCheck)
+	})
+
+	t.Run("with a stale trustdb", func(t *testing.T) {
+		record := parseRecord(t, "tru:o:1:1530000000:0:3:1:5\n")
+		assert.Equal(t, true, record.stale)
+	})
+
+	t.Run("with a next check date", func(t *testing.T) {
+		record := parseRecord(t, "tru::1:1530000000:1535000000:3:1:5\n")
+		assert.AssertEqualTimes(t, time.Unix(1535000000, 0).UTC(), *record.nextCheck)
+		assert.Equal(t, true, record.nextCheck.Before(now))
+	})
+
+	t.Run("with no tru record", func(t *testing.T) {
+		record := parseRecord(t, "pub:-:1024:1:F73D2F0533D7F9D6:1543864399:::-:::scESC::::::::0:\n"+
+			"fpr:::::::::BB3C44BF188D56E635F4A092F73D2F0533D7F9D6:\n")
+		assert.Equal(t, (*trustDbRecord)(nil), record)
+	})
+
+	t.Run("with a truncated tru record", func(t *testing.T) {
+		_, err := parseColonListing(strings.NewReader("tru::1:1530000000\n"))
+		assert.ErrorIsNotNil(t, err)
+	})
+}
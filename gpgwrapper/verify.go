@@ -0,0 +1,179 @@
+// Copyright 2018 Paul Furley and Ian Drysdale
+//
+// This file is part of Fluidkeys Client which makes it simple to use OpenPGP.
+//
+// Fluidkeys Client is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Fluidkeys Client is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with Fluidkeys Client.  If not, see <https://www.gnu.org/licenses/>.
+
+package gpgwrapper
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fluidkeys/fluidkeys/fingerprint"
+)
+
+// VerificationResult describes the outcome of checking a signature.
+type VerificationResult struct {
+	// Valid is true if the signature is a good signature over the data.
+	Valid bool
+
+	// SignerFingerprint is the fingerprint of the primary key that made the
+	// signature, even if it was made by a signing subkey. It's only set if
+	// Valid is true.
+	SignerFingerprint fingerprint.Fingerprint
+
+	// SignatureTime is when the signature was (apparently) made. It's only
+	// set if Valid is true.
+	SignatureTime time.Time
+}
+
+// VerifyDetached checks a detached signature (ascii-armored or binary) over
+// data using the keys in the keyring.
+//
+// A signature that doesn't match returns Valid=false and no error. If the
+// signing key isn't in the keyring, it returns ErrUnknownSigner.
+func (g *GnuPG) VerifyDetached(data string, signature string) (VerificationResult, error) {
+	tempDir, err := ioutil.TempDir("", "fluidkeys.verify.")
+	if err != nil {
+		return VerificationResult{}, fmt.Errorf("failed to make temporary directory: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	return g.verifyDetached(data, signature, tempDir)
+}
+
+// VerifyWithArmoredKey checks a detached signature over signedData using
+// only the given ascii-armored public key.
+//
+// The key is imported into a temporary GnuPG home directory which is
+// deleted afterwards, so the user's keyring never sees it. This means an
+// untrusted key can be used to check a signature without being added to the
+// keyring.
+//
+// A signature that doesn't match returns Valid=false and no error. An error
+// is returned if the signature couldn't be checked at all, for example if it
+// wasn't made by the given key.
+func (g *GnuPG) VerifyWithArmoredKey(signedData string, signature string, armoredKey string) (VerificationResult, error) {
+	tempHomeDir, err := ioutil.TempDir("", "fluidkeys.verify.")
+	if err != nil {
+		return VerificationResult{}, fmt.Errorf("failed to make temporary GnuPG home: %v", err)
+	}
+	defer os.RemoveAll(tempHomeDir)
+
+	// copy g so the temporary home still gets its CommandLogger, DryRun
+	// and concurrency limit
+	tempGpg := *g
+	tempGpg.homeDir = tempHomeDir
+
+	if _, err := tempGpg.ImportArmoredKey(armoredKey); err != nil {
+		return VerificationResult{}, fmt.Errorf("failed to import key: %v", err)
+	}
+
+	result, err := tempGpg.verifyDetached(signedData, signature, tempHomeDir)
+	if _, ok := err.(*ErrUnknownSigner); ok {
+		return VerificationResult{}, fmt.Errorf("signature wasn't made by the given key")
+	}
+	return result, err
+}
+
+// verifyDetached writes the signature to a file in tempDir and checks it
+// against data, which is sent to gpg on stdin.
+func (g *GnuPG) verifyDetached(data string, signature string, tempDir string) (VerificationResult, error) {
+	signatureFilename := filepath.Join(tempDir, "signature.sig")
+	if err := ioutil.WriteFile(signatureFilename, []byte(signature), 0600); err != nil {
+		return VerificationResult{}, fmt.Errorf("failed to write signature: %v", err)
+	}
+
+	// gpg exits non-zero for a bad signature, so rely on the status output
+	// rather than the error to decide what happened.
+	stdout, stderr, _ := g.runWithStdin(data,
+		"--status-fd", "1",
+		"--verify", signatureFilename, "-",
+	)
+
+	result, err := parseVerifyStatus(stdout)
+	if _, ok := err.(*ErrUnknownSigner); ok {
+		return VerificationResult{}, err
+	} else if err != nil {
+		return VerificationResult{}, fmt.Errorf("%v: %s", err, stderr)
+	}
+	return result, nil
+}
+
+// parseVerifyStatus reads the status output of `gpg --status-fd 1 --verify`
+// for a single signature.
+// For the format see https://github.com/gpg/gnupg/blob/master/doc/DETAILS
+func parseVerifyStatus(statusOutput string) (VerificationResult, error) {
+	for _, line := range strings.Split(statusOutput, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || fields[0] != statusPrefix {
+			continue
+		}
+
+		switch fields[1] {
+		case "VALIDSIG":
+			return parseValidSig(fields[2:])
+
+		case "BADSIG":
+			return VerificationResult{Valid: false}, nil
+
+		case "NO_PUBKEY":
+			if len(fields) < 3 {
+				return VerificationResult{}, fmt.Errorf("NO_PUBKEY has too few fields: '%s'", line)
+			}
+			keyId, err := strconv.ParseUint(fields[2], 16, 64)
+			if err != nil {
+				return VerificationResult{}, fmt.Errorf("error parsing key ID '%s': %v", fields[2], err)
+			}
+			return VerificationResult{}, &ErrUnknownSigner{KeyId: keyId}
+		}
+	}
+	return VerificationResult{}, fmt.Errorf("failed to check signature")
+}
+
+// parseValidSig parses the arguments of a VALIDSIG status line:
+// <fingerprint> <sig_creation_date> <sig-timestamp> <expire-timestamp>
+// <sig-version> <reserved> <pubkey-algo> <hash-algo> <sig-class>
+// [ <primary-key-fpr> ]
+func parseValidSig(args []string) (VerificationResult, error) {
+	if len(args) < 3 {
+		return VerificationResult{}, fmt.Errorf("VALIDSIG has too few fields: %v", args)
+	}
+
+	signerFingerprint := args[0]
+	if len(args) >= 10 {
+		signerFingerprint = args[9]
+	}
+	fp, err := fingerprint.Parse(signerFingerprint)
+	if err != nil {
+		return VerificationResult{}, err
+	}
+
+	signatureTime, err := parseTimestamp(args[2])
+	if err != nil {
+		return VerificationResult{}, err
+	}
+
+	return VerificationResult{
+		Valid:             true,
+		SignerFingerprint: fp,
+		SignatureTime:     *signatureTime,
+	}, nil
+}
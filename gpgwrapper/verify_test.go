@@ -0,0 +1,137 @@
+package gpgwrapper
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/fluidkeys/fluidkeys/assert"
+)
+
+func TestVerifyWithArmoredKey(t *testing.T) {
+	gpg := makeGpgWithTempHome(t)
+
+	t.Run("with a good signature", func(t *testing.T) {
+		result, err := gpg.VerifyWithArmoredKey(exampleSignedData, exampleAliceSignature, exampleAlicePublicKey)
+		assertNoError(t, err)
+
+		assert.Equal(t, true, result.Valid)
+		assert.Equal(t, exampleAliceFingerprint, result.SignerFingerprint)
+		assert.AssertEqualTimes(t, time.Unix(1792143531, 0).UTC(), result.SignatureTime)
+	})
+
+	t.Run("with modified data", func(t *testing.T) {
+		result, err := gpg.VerifyWithArmoredKey("hello wXrld", exampleAliceSignature, exampleAlicePublicKey)
+		assertNoError(t, err)
+		assert.Equal(t, false, result.Valid)
+	})
+
+	t.Run("with a different key", func(t *testing.T) {
+		_, err := gpg.VerifyWithArmoredKey(exampleSignedData, exampleAliceSignature, exampleBobPublicKey)
+		assert.ErrorIsNotNil(t, err)
+	})
+
+	t.Run("with an invalid key", func(t *testing.T) {
+		_, err := gpg.VerifyWithArmoredKey(exampleSignedData, exampleAliceSignature, "not a key")
+		assert.ErrorIsNotNil(t, err)
+	})
+
+	t.Run("doesn't import the key into the keyring", func(t *testing.T) {
+		_, err := gpg.SubkeyCreationTimes(exampleAliceFingerprint)
+		assert.ErrorIsNotNil(t, err)
+	})
+
+	t.Run("removes the temporary directory", func(t *testing.T) {
+		tempDir := makeTempGnupgHome(t)
+		originalTmpDir := os.Getenv("TMPDIR")
+		os.Setenv("TMPDIR", tempDir)
+		defer os.Setenv("TMPDIR", originalTmpDir)
+
+		_, err := gpg.VerifyWithArmoredKey(exampleSignedData, exampleAliceSignature, exampleBobPublicKey)
+		assert.ErrorIsNotNil(t, err)
+
+		files, err := ioutil.ReadDir(tempDir)
+		assertNoError(t, err)
+		assert.Equal(t, 0, len(files))
+	})
+}
+
+func TestVerifyDetached(t *testing.T) {
+	gpg := makeGpgWithTempHome(t)
+	_, err := gpg.ImportArmoredKey(exampleAlicePublicKey)
+	assertNoError(t, err)
+
+	t.Run("with a good signature", func(t *testing.T) {
+		result, err := gpg.VerifyDetached(exampleSignedData, exampleAliceSignature)
+		assertNoError(t, err)
+
+		assert.Equal(t, true, result.Valid)
+		assert.Equal(t, exampleAliceFingerprint, result.SignerFingerprint)
+		assert.AssertEqualTimes(t, time.Unix(1792143531, 0).UTC(), result.SignatureTime)
+	})
+
+	t.Run("with a bad signature", func(t *testing.T) {
+		result, err := gpg.VerifyDetached("hello wXrld", exampleAliceSignature)
+		assertNoError(t, err)
+		assert.Equal(t, false, result.Valid)
+	})
+
+	t.Run("with a signature from an unknown key", func(t *testing.T) {
+		otherGpg := makeGpgWithTempHome(t)
+		_, err := otherGpg.VerifyDetached(exampleSignedData, exampleAliceSignature)
+
+		unknownSigner, ok := err.(*ErrUnknownSigner)
+		if !ok {
+			t.Fatalf("expected ErrUnknownSigner, got %v", err)
+		}
+		assert.Equal(t, uint64(0x765354F03E8A421D), unknownSigner.KeyId)
+	})
+
+	t.Run("with something that isn't a signature", func(t *testing.T) {
+		_, err := gpg.VerifyDetached(exampleSignedData, "not a signature")
+		assert.ErrorIsNotNil(t, err)
+	})
+}
+
+func TestParseVerifyStatus(t *testing.T) {
+	t.Run("with VALIDSIG from a subkey", func(t *testing.T) {
+		status := "[GNUPG:] NEWSIG\n" +
+			"[GNUPG:] GOODSIG 765354F03E8A421D alice@example.com\n" +
+			"[GNUPG:] VALIDSIG 09D408F6DD1525735F1F54ABCE7881186F55FA9E 2026-10-16 1792143531 0 4 0 22 8 00 8AADA686735E9B66FBD190AC765354F03E8A421D\n"
+
+		result, err := parseVerifyStatus(status)
+		assertNoError(t, err)
+		assert.Equal(t, exampleAliceFingerprint, result.SignerFingerprint)
+	})
+
+	t.Run("with BADSIG", func(t *testing.T) {
+		result, err := parseVerifyStatus("[GNUPG:] NEWSIG\n" +
+			"[GNUPG:] BADSIG 765354F03E8A421D alice@example.com\n")
+		assertNoError(t, err)
+		assert.Equal(t, false, result.Valid)
+	})
+
+	t.Run("with NO_PUBKEY", func(t *testing.T) {
+		_, err := parseVerifyStatus("[GNUPG:] ERRSIG 765354F03E8A421D 22 8 00 1792143531 9 -\n" +
+			"[GNUPG:] NO_PUBKEY 765354F03E8A421D\n")
+		assert.Equal(t, &ErrUnknownSigner{KeyId: 0x765354F03E8A421D}, err)
+	})
+
+	t.Run("with no signature", func(t *testing.T) {
+		_, err := parseVerifyStatus("[GNUPG:] NODATA 1\n")
+		assert.ErrorIsNotNil(t, err)
+	})
+}
+
+const exampleSignedData = "hello world"
+
+// exampleAliceSignature is a detached signature over exampleSignedData made
+// by exampleAlicePublicKey
+const exampleAliceSignature = `-----BEGIN PGP SIGNATURE-----
+
+iIgEABYIADAWIQSKraaGc16bZvvRkKx2U1TwPopCHQUCatHwqxIcYWxpY2VAZXhh
+bXBsZS5jb20ACgkQdlNU8D6KQh0RJwD+K7ehk4XLvOfwjuj3Oa4iYnlvxkiN/r/F
+qNYVkggyMRwA/ig1ZOa5p7p579Z9si9ZrO2gM4xcyf6B5i5aNx9rInwP
+=imBK
+-----END PGP SIGNATURE-----`
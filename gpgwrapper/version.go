@@ -0,0 +1,94 @@
+// Copyright 2018 Paul Furley and Ian Drysdale
+//
+// This file is part of Fluidkeys Client which makes it simple to use OpenPGP.
+//
+// Fluidkeys Client is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Fluidkeys Client is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with Fluidkeys Client.  If not, see <https://www.gnu.org/licenses/>.
+
+package gpgwrapper
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// minimumCompatibleVersion is the oldest GnuPG that Fluidkeys works with:
+// 2.1 added `--pinentry-mode loopback`, which is needed to pass passwords
+// without a TTY.
+const minimumCompatibleVersion = "2.1.0"
+
+// IsCompatible returns true if the installed GnuPG is new enough for
+// Fluidkeys to use.
+func (g *GnuPG) IsCompatible() (bool, error) {
+	return g.MeetsMinimumVersion(minimumCompatibleVersion)
+}
+
+// MeetsMinimumVersion returns true if the installed GnuPG's version is at
+// least minimum, e.g. "2.1.22". Versions are compared component by
+// component, so 2.10.0 is newer than 2.9.0.
+func (g *GnuPG) MeetsMinimumVersion(minimum string) (bool, error) {
+	version, err := g.Version()
+	if err != nil {
+		return false, err
+	}
+
+	comparison, err := compareVersions(version, minimum)
+	if err != nil {
+		return false, err
+	}
+	return comparison >= 0, nil
+}
+
+// compareVersions compares two dotted version strings like "2.1.11",
+// returning -1 if a is older than b, 0 if they're the same or 1 if a is
+// newer. Missing components count as 0, so "2.1" is the same as "2.1.0".
+func compareVersions(a string, b string) (int, error) {
+	aComponents, err := parseVersionComponents(a)
+	if err != nil {
+		return 0, err
+	}
+	bComponents, err := parseVersionComponents(b)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := 0; i < len(aComponents) || i < len(bComponents); i++ {
+		aComponent, bComponent := 0, 0
+		if i < len(aComponents) {
+			aComponent = aComponents[i]
+		}
+		if i < len(bComponents) {
+			bComponent = bComponents[i]
+		}
+
+		if aComponent < bComponent {
+			return -1, nil
+		} else if aComponent > bComponent {
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
+
+func parseVersionComponents(version string) ([]int, error) {
+	var components []int
+	for _, component := range strings.Split(version, ".") {
+		number, err := strconv.Atoi(component)
+		if err != nil || number < 0 {
+			return nil, fmt.Errorf("invalid version '%s'", version)
+		}
+		components = append(components, number)
+	}
+	return components, nil
+}
@@ -0,0 +1,67 @@
+package gpgwrapper
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/fluidkeys/fluidkeys/assert"
+)
+
+func TestCompareVersions(t *testing.T) {
+	var tests = []struct {
+		a        string
+		b        string
+		expected int
+	}{
+		{"2.1.0", "2.1.0", 0},
+		{"2.1", "2.1.0", 0},
+		{"2.0.30", "2.1.0", -1},
+		{"2.2.4", "2.1.22", 1},
+		{"2.1.0", "2.10.0", -1},
+		{"2.10.0", "2.9.0", 1},
+		{"2.1.9", "2.1.11", -1},
+		{"3", "2.99.99", 1},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%s vs %s", test.a, test.b), func(t *testing.T) {
+			got, err := compareVersions(test.a, test.b)
+			assertNoError(t, err)
+			assert.Equal(t, test.expected, got)
+		})
+	}
+
+	for _, invalid := range []string{"", "2.x.1", "2..1", "-1.0"} {
+		t.Run(fmt.Sprintf("with invalid version '%s'", invalid), func(t *testing.T) {
+			_, err := compareVersions(invalid, "2.1.0")
+			assert.ErrorIsNotNil(t, err)
+		})
+	}
+}
+
+func TestMeetsMinimumVersion(t *testing.T) {
+	gpg := GnuPG{fullGpgPath: makeFakeGpgScript(t, "echo 'gpg (GnuPG) 2.0.30'")}
+
+	t.Run("with an older minimum", func(t *testing.T) {
+		got, err := gpg.MeetsMinimumVersion("2.0.9")
+		assertNoError(t, err)
+		assert.Equal(t, true, got)
+	})
+
+	t.Run("with a newer minimum", func(t *testing.T) {
+		got, err := gpg.MeetsMinimumVersion("2.1.0")
+		assertNoError(t, err)
+		assert.Equal(t, false, got)
+	})
+
+	t.Run("IsCompatible is false for 2.0", func(t *testing.T) {
+		got, err := gpg.IsCompatible()
+		assertNoError(t, err)
+		assert.Equal(t, false, got)
+	})
+
+	t.Run("with an invalid minimum", func(t *testing.T) {
+		_, err := gpg.MeetsMinimumVersion("foo")
+		assert.ErrorIsNotNil(t, err)
+	})
+}
@@ -211,13 +211,14 @@ func makePrimaryInstruction(keysWithWarnings []KeyWithWarnings) string {
 		warnings = append(warnings, keyWithWarnings.Warnings...)
 	}
 	var output string
-	if len(warnings) > 0 {
+	if status.KeyNeedsAttention(warnings) {
 		if warningsSliceContainsType(warnings, status.PrimaryKeyOverdueForRotation) ||
 			warningsSliceContainsType(warnings, status.SubkeyOverdueForRotation) {
 			output = "Prevent your key(s) from becoming unusable by running:\n"
 		}
 		if warningsSliceContainsType(warnings, status.PrimaryKeyExpired) ||
-			warningsSliceContainsType(warnings, status.NoValidEncryptionSubkey) {
+			warningsSliceContainsType(warnings, status.NoValidEncryptionSubkey) ||
+			warningsSliceContainsType(warnings, status.EncryptionSubkeyRevoked) {
 			output = "Make your key(s) usable again by running:\n"
 		} else { // These aren't urgent issues
 			output = "Fix these issues by running:\n"
@@ -240,20 +241,19 @@ func warningsSliceContainsType(haystack []status.KeyWarning, needle status.Warni
 }
 
 // keyStatus takes a key and slice of warnings and returns a slice of coloured
-// strings for printing in the table. If no warnings, the status is reported as
-// Good
+// strings for printing in the table. If no warnings need attention, the status
+// is reported as Good, followed by any informational warnings.
 func keyStatus(key pgpkey.PgpKey, keyWarnings []status.KeyWarning) []string {
 	keyWarningLines := []string{}
-	if len(keyWarnings) > 0 {
-		for _, keyWarning := range keyWarnings {
-			keyWarningLines = append(
-				keyWarningLines,
-				keyWarning.String(),
-			)
-		}
-	} else {
+	if !status.KeyNeedsAttention(keyWarnings) {
 		keyWarningLines = append(keyWarningLines, colour.Success("Good ✔"))
 	}
+	for _, keyWarning := range keyWarnings {
+		keyWarningLines = append(
+			keyWarningLines,
+			keyWarning.String(),
+		)
+	}
 	return keyWarningLines
 }
 
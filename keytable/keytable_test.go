@@ -138,10 +138,21 @@ func TestKeyStatus(t *testing.T) {
 		t.Fatalf("failed to load example PgpKey: %v", err)
 	}
 
-	want := []string{colour.Success("Good ✔")}
-	got := keyStatus(*pgpKey, []status.KeyWarning{})
+	t.Run("with no warnings", func(t *testing.T) {
+		want := []string{colour.Success("Good ✔")}
+		got := keyStatus(*pgpKey, []status.KeyWarning{})
 
-	assert.AssertEqualSliceOfStrings(t, want, got)
+		assert.AssertEqualSliceOfStrings(t, want, got)
+	})
+
+	t.Run("with only informational warnings", func(t *testing.T) {
+		want := []string{colour.Success("Good ✔"), "Key does not support uncompressed data"}
+		got := keyStatus(*pgpKey, []status.KeyWarning{
+			status.KeyWarning{Type: status.MissingUncompressedPreference},
+		})
+
+		assert.AssertEqualSliceOfStrings(t, want, got)
+	})
 }
 
 // AssertEqualCells compares two string slices and calls t.Fatalf
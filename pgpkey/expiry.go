@@ -19,6 +19,7 @@ package pgpkey
 
 import (
 	"github.com/fluidkeys/crypto/openpgp"
+	"github.com/fluidkeys/crypto/openpgp/packet"
 	"time"
 )
 
@@ -27,8 +28,15 @@ import (
 //
 // From https://tools.ietf.org/html/rfc4880#section-5.2.3.6
 // "If this is not present or has a value of zero, the key never expires."
+//
+// If the creation time isn't plausible (see IsPlausibleCreationTime) there's
+// no meaningful expiry, so it returns false rather than a time near 1970
+// which would make the key look long expired.
 func CalculateExpiry(creationTime time.Time, lifetimeSecs *uint32) (bool, *time.Time) {
-	//
+	if !IsPlausibleCreationTime(creationTime) {
+		return false, nil
+	}
+
 	if lifetimeSecs == nil {
 		return false, nil
 	}
@@ -41,11 +49,63 @@ func CalculateExpiry(creationTime time.Time, lifetimeSecs *uint32) (bool, *time.
 	return true, &expiry
 }
 
+// IsPlausibleCreationTime returns false for creation times which can't be
+// real, for example the zero timestamp of a malformed key packet. OpenPGP
+// keys can't have been created before PGP was first released in 1991.
+func IsPlausibleCreationTime(creationTime time.Time) bool {
+	return !creationTime.Before(earliestPlausibleCreationTime)
+}
+
+var earliestPlausibleCreationTime = time.Date(1991, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// IsCreatedInFuture returns true if creationTime is after now, allowing for
+// a little clock skew between the computer which made the key and this one.
+func IsCreatedInFuture(creationTime time.Time, now time.Time) bool {
+	return creationTime.After(now.Add(creationTimeTolerance))
+}
+
+const creationTimeTolerance = time.Hour
+
+// ExpiryFromSignature returns when a key (or subkey) created at creationTime
+// stops working according to sig, its self signature or binding signature.
+//
+// That's the earlier of:
+//
+//   - the key expiration time (KeyLifetimeSecs, counted from creationTime)
+//   - the signature expiration time (SigLifetimeSecs, counted from the
+//     signature's own creation time). Once the signature has expired it no
+//     longer binds the key, even if the key lifetime hasn't run out.
+//
+// It returns false if neither is set.
+func ExpiryFromSignature(creationTime time.Time, sig *packet.Signature) (bool, *time.Time) {
+	if !IsPlausibleCreationTime(creationTime) {
+		return false, nil
+	}
+
+	hasKeyExpiry, keyExpiry := CalculateExpiry(creationTime, sig.KeyLifetimeSecs)
+	hasSigExpiry, sigExpiry := CalculateExpiry(sig.CreationTime, sig.SigLifetimeSecs)
+
+	switch {
+	case hasKeyExpiry && hasSigExpiry:
+		if sigExpiry.Before(*keyExpiry) {
+			return true, sigExpiry
+		}
+		return true, keyExpiry
+
+	case hasKeyExpiry:
+		return true, keyExpiry
+
+	case hasSigExpiry:
+		return true, sigExpiry
+	}
+	return false, nil
+}
+
 // SubkeyExpiry returns true and a time if the subkey has an expiry time set,
-// or false if it has no expiry.
+// or false if it has no expiry. See ExpiryFromSignature.
 func SubkeyExpiry(subkey openpgp.Subkey) (bool, *time.Time) {
-	return CalculateExpiry(
+	return ExpiryFromSignature(
 		subkey.PublicKey.CreationTime, // not to be confused with the time of the *signature*
-		subkey.Sig.KeyLifetimeSecs,
+		subkey.Sig,
 	)
 }
@@ -3,6 +3,8 @@ package pgpkey
 import (
 	"testing"
 	"time"
+
+	"github.com/fluidkeys/crypto/openpgp/packet"
 )
 
 var (
@@ -27,6 +29,14 @@ func TestCalculateExpiry(t *testing.T) {
 		}
 	})
 
+	t.Run("with zero creation time", func(t *testing.T) {
+		var lifetimeSecs uint32 = 3600
+		hasExpiry, expiryTime := CalculateExpiry(time.Unix(0, 0), &lifetimeSecs)
+		if hasExpiry || expiryTime != nil {
+			t.Fatalf("expected hasExpiry=false for zero creation time, got %v", expiryTime)
+		}
+	})
+
 	t.Run("with valid lifetimeSecs", func(t *testing.T) {
 		var lifetimeSecs uint32 = 3600
 		hasExpiry, expiryTime := CalculateExpiry(createdTime, &lifetimeSecs)
@@ -41,3 +51,71 @@ func TestCalculateExpiry(t *testing.T) {
 		}
 	})
 }
+
+func TestExpiryFromSignature(t *testing.T) {
+	createdTime := feb1st
+	oneDay := uint32(24 * 60 * 60)
+	tenDays := uint32(10 * 24 * 60 * 60)
+
+	var tests = []struct {
+		name              string
+		sig               packet.Signature
+		expectedHasExpiry bool
+		expectedExpiry    time.Time
+	}{
+		{
+			"with neither lifetime",
+			packet.Signature{CreationTime: createdTime},
+			false,
+			time.Time{},
+		},
+		{
+			"with only a key lifetime",
+			packet.Signature{CreationTime: createdTime, KeyLifetimeSecs: &tenDays},
+			true,
+			createdTime.Add(10 * 24 * time.Hour),
+		},
+		{
+			"with only a signature lifetime",
+			packet.Signature{CreationTime: createdTime, SigLifetimeSecs: &tenDays},
+			true,
+			createdTime.Add(10 * 24 * time.Hour),
+		},
+		{
+			"with a signature expiring before the key",
+			packet.Signature{
+				CreationTime:    createdTime.Add(24 * time.Hour), // counted from the signature
+				KeyLifetimeSecs: &tenDays,
+				SigLifetimeSecs: &oneDay,
+			},
+			true,
+			createdTime.Add(2 * 24 * time.Hour),
+		},
+		{
+			"with the key expiring before the signature",
+			packet.Signature{CreationTime: createdTime, KeyLifetimeSecs: &oneDay, SigLifetimeSecs: &tenDays},
+			true,
+			createdTime.Add(24 * time.Hour),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			hasExpiry, expiry := ExpiryFromSignature(createdTime, &test.sig)
+			if hasExpiry != test.expectedHasExpiry {
+				t.Fatalf("expected hasExpiry=%v, got %v", test.expectedHasExpiry, hasExpiry)
+			}
+			if hasExpiry && !expiry.Equal(test.expectedExpiry) {
+				t.Fatalf("expected expiry %v, got %v", test.expectedExpiry, *expiry)
+			}
+		})
+	}
+
+	t.Run("with zero creation time", func(t *testing.T) {
+		sig := packet.Signature{CreationTime: createdTime, SigLifetimeSecs: &tenDays}
+		hasExpiry, expiry := ExpiryFromSignature(time.Unix(0, 0), &sig)
+		if hasExpiry || expiry != nil {
+			t.Fatalf("expected hasExpiry=false for zero creation time, got %v", expiry)
+		}
+	})
+}
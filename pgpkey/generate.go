@@ -96,7 +96,7 @@ func generateMakePrimaryKey(creationTime time.Time, config *packet.Config) (key
 		Subkeys:    make([]openpgp.Subkey, 0),
 	}
 
-	key = &PgpKey{e}
+	key = &PgpKey{Entity: e}
 	return
 }
 
@@ -42,6 +42,16 @@ import (
 
 type PgpKey struct {
 	openpgp.Entity
+
+	// DirectSignatures are signatures made by the primary key directly over
+	// itself, for example to designate revocation keys, which
+	// openpgp.ReadEntity discards.
+	DirectSignatures []*packet.Signature
+
+	// revokedSubkeyBindings holds the newest binding signature of each
+	// revoked subkey, indexed by key ID, since openpgp.ReadEntity replaces
+	// it with the revocation. See SubkeyBindingSignature.
+	revokedSubkeyBindings map[uint64]*packet.Signature
 }
 
 type IncorrectPassword struct {
@@ -71,7 +81,8 @@ func LoadFromArmoredPublicKey(armoredPublicKey string) (*PgpKey, error) {
 	}
 	entity := entityList[0]
 
-	pgpKey := PgpKey{*entity}
+	pgpKey := PgpKey{Entity: *entity}
+	pgpKey.readSignatures(armoredPublicKey)
 	return &pgpKey, nil
 }
 
@@ -107,7 +118,8 @@ func LoadFromArmoredEncryptedPrivateKey(armoredPrivateKey string, password strin
 		}
 	}
 
-	pgpKey := PgpKey{*entity}
+	pgpKey := PgpKey{Entity: *entity}
+	pgpKey.readSignatures(armoredPrivateKey)
 	return &pgpKey, nil
 }
 
@@ -119,7 +131,7 @@ func (key *PgpKey) Armor() (string, error) {
 	if err != nil {
 		return "", err
 	}
-	err = key.Serialize(armor)
+	err = key.serializeWithDirectSignatures(armor, key.Serialize)
 	if err != nil {
 		return "", fmt.Errorf("error calling key.Serialize(..): %v", err)
 	}
@@ -148,7 +160,9 @@ func (key *PgpKey) ArmorPrivate(passwordToEncryptWith string) (string, error) {
 	}
 	config := packet.Config{SerializePrivatePassword: passwordToEncryptWith}
 
-	err = key.SerializePrivate(armor, &config)
+	err = key.serializeWithDirectSignatures(armor, func(w io.Writer) error {
+		return key.SerializePrivate(w, &config)
+	})
 	if err != nil {
 		return "", fmt.Errorf("error calling key.SerializePrivate: %v", err)
 	}
@@ -237,6 +251,81 @@ func (key *PgpKey) SetPreferredCompressionAlgorithms(algos []compression.Compres
 	return key.RefreshUserIdSelfSignatures(now)
 }
 
+// AdvertisesCipher returns true if every user ID on the key lists the given
+// symmetric cipher in its preferences, meaning that others can safely use it
+// to encrypt to this key.
+func (key *PgpKey) AdvertisesCipher(cipher symmetric.SymmetricAlgorithm) bool {
+	selfSigs := key.getIdentitySelfSignatures()
+	if len(selfSigs) == 0 {
+		return false
+	}
+
+	for _, selfSig := range selfSigs {
+		if !containsCipher(selfSig.PreferredSymmetric, cipher) {
+			return false
+		}
+	}
+	return true
+}
+
+// DesignatedRevokers returns the fingerprints of keys allowed to revoke this
+// key, read from the revocation key subpackets of the direct key signatures
+// and user ID self signatures.
+func (key *PgpKey) DesignatedRevokers() []fingerprint.Fingerprint {
+	signatures := append([]*packet.Signature{}, key.DirectSignatures...)
+	signatures = append(signatures, key.getIdentitySelfSignatures()...)
+
+	revokers := []fingerprint.Fingerprint{}
+	for _, sig := range signatures {
+		for _, revoker := range revocationKeys(sig) {
+			if !fingerprint.Contains(revokers, revoker) {
+				revokers = append(revokers, revoker)
+			}
+		}
+	}
+	return revokers
+}
+
+// IsUserIdRevoked returns true if the user ID with the given name has a
+// revocation signature from the primary key made after its self signature.
+// Revocations from other keys, or which don't verify, are ignored.
+func (key *PgpKey) IsUserIdRevoked(name string) bool {
+	identity, ok := key.Identities[name]
+	if !ok {
+		return false
+	}
+
+	for _, sig := range identity.Signatures {
+		if sig.SigType != sigTypeCertificationRevocation {
+			continue
+		}
+		if sig.IssuerKeyId == nil || *sig.IssuerKeyId != key.PrimaryKey.KeyId {
+			continue
+		}
+		if identity.SelfSignature != nil && sig.CreationTime.Before(identity.SelfSignature.CreationTime) {
+			continue // the user ID was certified again after being revoked
+		}
+		if key.PrimaryKey.VerifyUserIdSignature(name, key.PrimaryKey, sig) == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// sigTypeCertificationRevocation is the type of a signature revoking a user
+// ID certification, see RFC 4880, section 5.2.1. The packet package doesn't
+// define it.
+const sigTypeCertificationRevocation packet.SignatureType = 0x30
+
+func containsCipher(ciphers []symmetric.SymmetricAlgorithm, cipher symmetric.SymmetricAlgorithm) bool {
+	for _, c := range ciphers {
+		if c == cipher {
+			return true
+		}
+	}
+	return false
+}
+
 func (key *PgpKey) getIdentitySelfSignatures() []*packet.Signature {
 	var selfSigs []*packet.Signature
 	for name, _ := range key.Identities {
@@ -363,6 +452,19 @@ func (key *PgpKey) Emails(allowUnbracketed bool) []string {
 	return sortedEmails
 }
 
+// PrimaryIdentity returns the identity which comes first in the order used by
+// Emails, preferring the one flagged as the primary user id. It returns nil
+// if the key has no identities.
+func (key *PgpKey) PrimaryIdentity() *openpgp.Identity {
+	var primary *openpgp.Identity
+	for _, identity := range key.Identities {
+		if primary == nil || identityLess(*identity, *primary) {
+			primary = identity
+		}
+	}
+	return primary
+}
+
 func getEmail(identity *openpgp.Identity, allowUnbracketed bool) (string, bool) {
 	if email := identity.UserId.Email; emailutils.RoughlyValidateEmail(email) {
 		return identity.UserId.Email, true
@@ -387,6 +489,7 @@ func (key *PgpKey) UpdateExpiryForAllUserIds(validUntil time.Time, now time.Time
 
 	for _, selfSig := range key.getIdentitySelfSignatures() {
 		selfSig.KeyLifetimeSecs = &keyLifetimeSeconds
+		selfSig.SigLifetimeSecs = nil // otherwise it could cut the new expiry short
 	}
 
 	return key.RefreshUserIdSelfSignatures(now)
@@ -400,7 +503,24 @@ func (key *PgpKey) UpdateExpiryForAllUserIds(validUntil time.Time, now time.Time
 // * has the latest CreationTime (e.g. most recent)
 
 func (key *PgpKey) EncryptionSubkey(now time.Time) *openpgp.Subkey {
-	subkeys := key.validEncryptionSubkeys(now)
+	subkeys := key.ValidEncryptionSubkeys(now)
+
+	if len(subkeys) == 0 {
+		return nil
+	}
+
+	sort.Sort(sort.Reverse(BySubkeyCreated(subkeys)))
+	return &subkeys[0]
+}
+
+// SigningSubkey returns either nil or a single openpgp.Subkey which:
+//
+// * has the valid flag set
+// * has the sign capability flag
+// * has a valid, in-date signature
+// * has the latest CreationTime (e.g. most recent)
+func (key *PgpKey) SigningSubkey(now time.Time) *openpgp.Subkey {
+	subkeys := key.validSigningSubkeys(now)
 
 	if len(subkeys) == 0 {
 		return nil
@@ -496,6 +616,7 @@ func (key *PgpKey) UpdateSubkeyValidUntil(subkeyId uint64, validUntil time.Time,
 	subkey.Sig.Hash = config.Hash()
 	subkey.Sig.CreationTime = now // essential that this sig is the most recent
 	subkey.Sig.KeyLifetimeSecs = &keyLifetimeSeconds
+	subkey.Sig.SigLifetimeSecs = nil // otherwise it could cut the new expiry short
 
 	err = subkey.Sig.SignKey(subkey.PublicKey, key.PrivateKey, &config)
 	if err != nil {
@@ -505,7 +626,9 @@ func (key *PgpKey) UpdateSubkeyValidUntil(subkeyId uint64, validUntil time.Time,
 	return nil
 }
 
-func (key *PgpKey) validEncryptionSubkeys(now time.Time) []openpgp.Subkey {
+// ValidEncryptionSubkeys returns every subkey which could be used for
+// encryption at `now`: see EncryptionSubkey for the conditions.
+func (key *PgpKey) ValidEncryptionSubkeys(now time.Time) []openpgp.Subkey {
 	var subkeys []openpgp.Subkey
 
 	for _, subkey := range key.Subkeys {
@@ -516,6 +639,17 @@ func (key *PgpKey) validEncryptionSubkeys(now time.Time) []openpgp.Subkey {
 	return subkeys
 }
 
+func (key *PgpKey) validSigningSubkeys(now time.Time) []openpgp.Subkey {
+	var subkeys []openpgp.Subkey
+
+	for _, subkey := range key.Subkeys {
+		if isSigningSubkeyValid(subkey, now) {
+			subkeys = append(subkeys, subkey)
+		}
+	}
+	return subkeys
+}
+
 // ensureGotDecryptedPrivateKey returns an error if the primary key's private
 // key is not present, or hasn't been decrypted
 func (key *PgpKey) ensureGotDecryptedPrivateKey() error {
@@ -546,6 +680,17 @@ func isEncryptionSubkeyValid(subkey openpgp.Subkey, now time.Time) bool {
 	return valid
 }
 
+func isSigningSubkeyValid(subkey openpgp.Subkey, now time.Time) bool {
+	isRevoked := subkey.Sig.SigType == packet.SigTypeSubkeyRevocation
+	createdInThePast := !subkey.PublicKey.CreationTime.After(now)
+	canSign := subkey.PublicKey.PubKeyAlgo.CanSign() && subkey.Sig.FlagSign
+
+	hasExpiry, expiry := SubkeyExpiry(subkey)
+	inDate := !hasExpiry || now.Before(*expiry)
+
+	return !isRevoked && createdInThePast && subkey.Sig.FlagsValid && canSign && inDate
+}
+
 func slugify(textToSlugify string) (slugified string) {
 	var re = regexp.MustCompile(`[^a-zA-Z0-9]+`)
 	slugified = re.ReplaceAllString(textToSlugify, `-`)
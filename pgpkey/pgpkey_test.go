@@ -17,6 +17,7 @@ import (
 	"github.com/fluidkeys/fluidkeys/assert"
 	"github.com/fluidkeys/fluidkeys/exampledata"
 	"github.com/fluidkeys/fluidkeys/fingerprint"
+	"github.com/fluidkeys/fluidkeys/openpgpdefs/symmetric"
 	"github.com/fluidkeys/fluidkeys/policy"
 )
 
@@ -163,6 +164,27 @@ func TestEmailsMethod(t *testing.T) {
 	})
 }
 
+func TestPrimaryIdentity(t *testing.T) {
+	t.Run("returns the identity flagged as primary", func(t *testing.T) {
+		pgpKey, err := LoadFromArmoredPublicKey(exampledata.ExamplePublicKey3)
+		assert.ErrorIsNil(t, err)
+
+		setIsPrimary(false, pgpKey.Identities, "<test3@example.com>")
+		setIsPrimary(true, pgpKey.Identities, "Example Name <another@example.com>")
+		setIsPrimary(false, pgpKey.Identities, "unbracketedemail@example.com")
+
+		assertEqual(t, "Example Name <another@example.com>", pgpKey.PrimaryIdentity().Name)
+	})
+
+	t.Run("returns nil for a key without identities", func(t *testing.T) {
+		pgpKey, err := LoadFromArmoredPublicKey(exampledata.ExamplePublicKey3)
+		assert.ErrorIsNil(t, err)
+		pgpKey.Identities = map[string]*openpgp.Identity{}
+
+		assert.Equal(t, true, pgpKey.PrimaryIdentity() == nil)
+	})
+}
+
 func TestFingerprintMethod(t *testing.T) {
 	pgpKey := loadExamplePgpKey(t)
 
@@ -530,7 +552,7 @@ func TestEncryptionSubkey(t *testing.T) {
 		})
 	}
 
-	t.Run("validEncryptionSubkeys filters valid keys", func(t *testing.T) {
+	t.Run("ValidEncryptionSubkeys filters valid keys", func(t *testing.T) {
 		var expectedSubkeys []openpgp.Subkey // we don't know these until they've been generated
 
 		for i, subkeyConfig := range subkeyTests {
@@ -539,7 +561,7 @@ func TestEncryptionSubkey(t *testing.T) {
 			}
 		}
 
-		gotSubkeys := pgpKey.validEncryptionSubkeys(now)
+		gotSubkeys := pgpKey.ValidEncryptionSubkeys(now)
 		if len(gotSubkeys) != len(expectedSubkeys) {
 			t.Logf("gpKey.subkeys: %v", pgpKey.Subkeys)
 			t.Fatalf("expected %d valid subkeys, got %d: %v", len(expectedSubkeys), len(gotSubkeys), gotSubkeys)
@@ -575,8 +597,8 @@ func TestEncryptionSubkey(t *testing.T) {
 		stashedSubkeys := pgpKey.Subkeys
 		pgpKey.Subkeys = []openpgp.Subkey{} // delete all the subkeys so there aren't any valid ones
 
-		t.Run("validEncryptionSubkeys() returns empty", func(t *testing.T) {
-			gotKeys := pgpKey.validEncryptionSubkeys(now)
+		t.Run("ValidEncryptionSubkeys() returns empty", func(t *testing.T) {
+			gotKeys := pgpKey.ValidEncryptionSubkeys(now)
 			if len(gotKeys) != 0 {
 				t.Fatalf("expected empty slice, got %v", gotKeys)
 			}
@@ -674,6 +696,57 @@ func makeKeyWithSubkeys(t *testing.T, subkeyConfigs []subkeyConfig, now time.Tim
 	return pgpKey, nil
 }
 
+func TestEncryptionSubkeySkipsRevokedSubkeys(t *testing.T) {
+	key, err := LoadFromArmoredPublicKey(exampledata.ExamplePublicKey10)
+	if err != nil {
+		t.Fatalf("failed to load example key: %v", err)
+	}
+	now := time.Date(2018, 11, 15, 0, 0, 0, 0, time.UTC)
+
+	got := key.EncryptionSubkey(now)
+	if got == nil {
+		t.Fatalf("expected an encryption subkey, got nil")
+	}
+	// 0xF291DE5ADD97892E is newer but revoked
+	assert.Equal(t, uint64(0x0A5B7B748CBF7498), got.PublicKey.KeyId)
+}
+
+func TestSigningSubkey(t *testing.T) {
+	now := time.Date(2018, 11, 15, 0, 0, 0, 0, time.UTC)
+
+	t.Run("with only an encryption subkey", func(t *testing.T) {
+		key, err := LoadFromArmoredPublicKey(exampledata.ExamplePublicKey8)
+		if err != nil {
+			t.Fatalf("failed to load example key: %v", err)
+		}
+		if got := key.SigningSubkey(now); got != nil {
+			t.Fatalf("expected nil, got subkey 0x%X", got.PublicKey.KeyId)
+		}
+	})
+
+	t.Run("returns the newest signing subkey", func(t *testing.T) {
+		key, err := LoadFromArmoredPublicKey(exampledata.ExamplePublicKey9)
+		if err != nil {
+			t.Fatalf("failed to load example key: %v", err)
+		}
+		got := key.SigningSubkey(now)
+		if got == nil {
+			t.Fatalf("expected a signing subkey, got nil")
+		}
+		assert.Equal(t, uint64(0xD976E89E7D3A2633), got.PublicKey.KeyId)
+	})
+
+	t.Run("ignores expired signing subkeys", func(t *testing.T) {
+		key, err := LoadFromArmoredPublicKey(exampledata.ExamplePublicKey9)
+		if err != nil {
+			t.Fatalf("failed to load example key: %v", err)
+		}
+		if got := key.SigningSubkey(time.Date(2030, 6, 1, 0, 0, 0, 0, time.UTC)); got != nil {
+			t.Fatalf("expected nil, got subkey 0x%X", got.PublicKey.KeyId)
+		}
+	})
+}
+
 func TestCreateNewEncryptionSubkey(t *testing.T) {
 
 	pgpKey, err := LoadFromArmoredEncryptedPrivateKey(exampledata.ExamplePrivateKey3, "test3")
@@ -825,6 +898,9 @@ func TestUpdateSubkeyValidUntil(t *testing.T) {
 	subkey := pgpKey.EncryptionSubkey(now)
 	assertSubkeyValidity(*subkey, true, now, t)
 
+	oneDay := uint32(24 * 60 * 60)
+	subkey.Sig.SigLifetimeSecs = &oneDay
+
 	err = pgpKey.UpdateSubkeyValidUntil(subkey.PublicKey.KeyId, validUntil, now)
 	if err != nil {
 		t.Fatalf("Error updating subkey expiry to now: " + err.Error())
@@ -834,6 +910,12 @@ func TestUpdateSubkeyValidUntil(t *testing.T) {
 		assert.Equal(t, now, subkey.Sig.CreationTime)
 	})
 
+	t.Run("new subkey binding signature doesn't expire", func(t *testing.T) {
+		if subkey.Sig.SigLifetimeSecs != nil {
+			t.Fatalf("expected SigLifetimeSecs to be cleared, got %d", *subkey.Sig.SigLifetimeSecs)
+		}
+	})
+
 	t.Run("subkey expiry time is now `validUntil`", func(t *testing.T) {
 		hasExpiry, expiry := SubkeyExpiry(*subkey)
 		if hasExpiry != true {
@@ -937,6 +1019,73 @@ func TestSetPreferredMethods(t *testing.T) {
 
 }
 
+func TestAdvertisesCipher(t *testing.T) {
+	now := time.Date(2018, 6, 15, 0, 0, 0, 0, time.UTC)
+
+	key, err := LoadFromArmoredEncryptedPrivateKey(exampledata.ExamplePrivateKey3, "test3")
+	if err != nil {
+		t.Fatalf("failed to load example key")
+	}
+
+	err = key.SetPreferredSymmetricAlgorithms([]uint8{symmetric.AES256, symmetric.CAST5}, now)
+	if err != nil {
+		t.Fatalf("failed to set preferences: %v", err)
+	}
+
+	t.Run("with a cipher in the preferences", func(t *testing.T) {
+		assert.Equal(t, true, key.AdvertisesCipher(symmetric.AES256))
+	})
+
+	t.Run("with a cipher missing from the preferences", func(t *testing.T) {
+		assert.Equal(t, false, key.AdvertisesCipher(symmetric.AES128))
+	})
+}
+
+func TestDesignatedRevokers(t *testing.T) {
+	t.Run("with a key designating a revoker", func(t *testing.T) {
+		key, err := LoadFromArmoredPublicKey(exampledata.ExamplePublicKey5)
+		if err != nil {
+			t.Fatalf("failed to load example key: %v", err)
+		}
+		assert.Equal(t, []fingerprint.Fingerprint{exampledata.ExampleFingerprint4}, key.DesignatedRevokers())
+	})
+
+	t.Run("designated revokers survive re-armoring", func(t *testing.T) {
+		key, err := LoadFromArmoredPublicKey(exampledata.ExamplePublicKey5)
+		if err != nil {
+			t.Fatalf("failed to load example key: %v", err)
+		}
+		armored, err := key.Armor()
+		if err != nil {
+			t.Fatalf("failed to armor key: %v", err)
+		}
+		reloaded, err := LoadFromArmoredPublicKey(armored)
+		if err != nil {
+			t.Fatalf("failed to reload key: %v", err)
+		}
+		assert.Equal(t, []fingerprint.Fingerprint{exampledata.ExampleFingerprint4}, reloaded.DesignatedRevokers())
+	})
+
+	t.Run("with a key without a designated revoker", func(t *testing.T) {
+		key, err := LoadFromArmoredPublicKey(exampledata.ExamplePublicKey4)
+		if err != nil {
+			t.Fatalf("failed to load example key: %v", err)
+		}
+		assert.Equal(t, []fingerprint.Fingerprint{}, key.DesignatedRevokers())
+	})
+}
+
+func TestIsUserIdRevoked(t *testing.T) {
+	key, err := LoadFromArmoredPublicKey(exampledata.ExamplePublicKey11)
+	if err != nil {
+		t.Fatalf("failed to load example key: %v", err)
+	}
+
+	assert.Equal(t, true, key.IsUserIdRevoked("Old <old11@example.com>"))
+	assert.Equal(t, false, key.IsUserIdRevoked("New <new11@example.com>"))
+	assert.Equal(t, false, key.IsUserIdRevoked("Missing <missing@example.com>"))
+}
+
 func TestRefreshUserIdSelfSignatures(t *testing.T) {
 	now := time.Date(2018, 6, 15, 0, 0, 0, 0, time.UTC)
 	key, err := LoadFromArmoredEncryptedPrivateKey(exampledata.ExamplePrivateKey3, "test3")
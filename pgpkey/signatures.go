@@ -0,0 +1,237 @@
+// Copyright 2018 Paul Furley and Ian Drysdale
+//
+// This file is part of Fluidkeys Client which makes it simple to use OpenPGP.
+//
+// Fluidkeys Client is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Fluidkeys Client is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with Fluidkeys Client.  If not, see <https://www.gnu.org/licenses/>.
+
+package pgpkey
+
+import (
+	"bytes"
+	"io"
+	"strings"
+
+	"github.com/fluidkeys/crypto/openpgp"
+	"github.com/fluidkeys/crypto/openpgp/armor"
+	"github.com/fluidkeys/crypto/openpgp/packet"
+	"github.com/fluidkeys/fluidkeys/fingerprint"
+)
+
+// packet.Signature only parses the subpackets it knows about, so these are
+// read from the raw hashed subpackets instead.
+const (
+	revocationKeySubpacket = 12
+	keyFlagsSubpacket      = 27
+
+	// criticalSubpacket is set in the type of a subpacket which must be
+	// understood to use the signature.
+	criticalSubpacket = 0x80
+
+	// keyFlagAuthenticate marks a key for authentication, e.g. SSH. See
+	// RFC 4880, section 5.2.3.21.
+	keyFlagAuthenticate = 0x20
+)
+
+// SubkeyBindingSignature returns the signature which says what the subkey is
+// for. This is subkey.Sig, except for a revoked subkey, where subkey.Sig is
+// the revocation (which has no key flags) and this is the newest binding
+// signature instead.
+func (key *PgpKey) SubkeyBindingSignature(subkey openpgp.Subkey) *packet.Signature {
+	if subkey.Sig.SigType == packet.SigTypeSubkeyRevocation {
+		if bindingSig, ok := key.revokedSubkeyBindings[subkey.PublicKey.KeyId]; ok {
+			return bindingSig
+		}
+	}
+	return subkey.Sig
+}
+
+// readSignatures reads the signatures which openpgp.ReadEntity doesn't keep
+// from the armored key: direct key signatures (see DirectSignatures) and the
+// binding signatures of revoked subkeys.
+//
+// ReadEntity has already accepted the key, so this is best effort: anything
+// it can't read is ignored.
+func (key *PgpKey) readSignatures(armoredKey string) {
+	block, err := armor.Decode(strings.NewReader(armoredKey))
+	if err != nil {
+		return
+	}
+
+	bindings := make(map[uint64]*packet.Signature)
+	var currentSubkey *packet.PublicKey
+	afterPrimaryKey := false // direct signatures come straight after it
+
+	packets := packet.NewReader(block.Body)
+	for {
+		p, err := packets.Next()
+		if err != nil {
+			break // io.EOF, or a packet ReadEntity would have rejected
+		}
+
+		switch pkt := p.(type) {
+		case *packet.PublicKey:
+			currentSubkey = subkeyOrNil(pkt)
+			afterPrimaryKey = !pkt.IsSubkey
+		case *packet.PrivateKey:
+			currentSubkey = subkeyOrNil(&pkt.PublicKey)
+			afterPrimaryKey = !pkt.IsSubkey
+		case *packet.UserId, *packet.UserAttribute:
+			currentSubkey = nil
+			afterPrimaryKey = false
+		case *packet.Signature:
+			if afterPrimaryKey && pkt.SigType == packet.SigTypeDirectSignature {
+				key.addDirectSignature(pkt)
+				continue
+			}
+			if currentSubkey == nil || pkt.SigType != packet.SigTypeSubkeyBinding {
+				continue
+			}
+			existing, ok := bindings[currentSubkey.KeyId]
+			if ok && !pkt.CreationTime.After(existing.CreationTime) {
+				continue
+			}
+			if key.PrimaryKey.VerifyKeySignature(currentSubkey, pkt) == nil {
+				bindings[currentSubkey.KeyId] = pkt
+			}
+		}
+	}
+
+	for _, subkey := range key.Subkeys {
+		if subkey.Sig.SigType != packet.SigTypeSubkeyRevocation {
+			continue
+		}
+		if bindingSig, ok := bindings[subkey.PublicKey.KeyId]; ok {
+			if key.revokedSubkeyBindings == nil {
+				key.revokedSubkeyBindings = make(map[uint64]*packet.Signature)
+			}
+			key.revokedSubkeyBindings[subkey.PublicKey.KeyId] = bindingSig
+		}
+	}
+}
+
+// addDirectSignature adds the signature to DirectSignatures if it was made by
+// the primary key over itself.
+func (key *PgpKey) addDirectSignature(sig *packet.Signature) {
+	if sig.IssuerKeyId == nil || *sig.IssuerKeyId != key.PrimaryKey.KeyId {
+		return
+	}
+	// direct signatures are made over the primary key alone, so they're
+	// hashed in the same way as key revocations
+	if key.PrimaryKey.VerifyRevocationSignature(sig) == nil {
+		key.DirectSignatures = append(key.DirectSignatures, sig)
+	}
+}
+
+func subkeyOrNil(publicKey *packet.PublicKey) *packet.PublicKey {
+	if publicKey.IsSubkey {
+		return publicKey
+	}
+	return nil
+}
+
+// serializeWithDirectSignatures writes the key to w using serialize (the
+// entity's Serialize or SerializePrivate), adding the DirectSignatures after
+// the primary key, since openpgp.Entity doesn't know about them.
+func (key *PgpKey) serializeWithDirectSignatures(w io.Writer, serialize func(io.Writer) error) error {
+	if len(key.DirectSignatures) == 0 {
+		return serialize(w)
+	}
+
+	buf := new(bytes.Buffer)
+	if err := serialize(buf); err != nil {
+		return err
+	}
+
+	packets := packet.NewOpaqueReader(buf)
+	primaryKey, err := packets.Next()
+	if err != nil {
+		return err
+	}
+	if err := primaryKey.Serialize(w); err != nil {
+		return err
+	}
+	for _, sig := range key.DirectSignatures {
+		if err := sig.Serialize(w); err != nil {
+			return err
+		}
+	}
+
+	for {
+		p, err := packets.Next()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if err := p.Serialize(w); err != nil {
+			return err
+		}
+	}
+}
+
+// revocationKeys returns the fingerprints of the keys designated by the
+// signature as allowed to revoke its key (see RFC 4880, section 5.2.3.15).
+// Malformed revocation key subpackets are skipped.
+func revocationKeys(sig *packet.Signature) []fingerprint.Fingerprint {
+	var revokers []fingerprint.Fingerprint
+	for _, contents := range hashedSubpackets(sig, revocationKeySubpacket) {
+		// one octet of class (which always has 0x80 set), one of public
+		// key algorithm and a 20 octet fingerprint
+		if len(contents) != 22 || contents[0]&0x80 == 0 {
+			continue
+		}
+		var fp [20]byte
+		copy(fp[:], contents[2:])
+		revokers = append(revokers, fingerprint.FromBytes(fp))
+	}
+	return revokers
+}
+
+// IsFlaggedForAuthentication returns true if the signature has a key flags
+// subpacket marking the key for authentication.
+func IsFlaggedForAuthentication(sig *packet.Signature) bool {
+	for _, flags := range hashedSubpackets(sig, keyFlagsSubpacket) {
+		if len(flags) > 0 {
+			return flags[0]&keyFlagAuthenticate != 0
+		}
+	}
+	return false
+}
+
+// hashedSubpackets returns the contents of the signature's hashed
+// subpackets of the given type, whether or not they're marked critical.
+//
+// They're read from the HashSuffix of a version 4 signature, which is the
+// version, signature type, public key and hash algorithms, a two byte
+// length, the subpackets and a six byte trailer (see RFC 4880, section
+// 5.2.4). If a subpacket is malformed, only the ones before it are read.
+func hashedSubpackets(sig *packet.Signature, subType uint8) [][]byte {
+	if sig == nil || len(sig.HashSuffix) < 12 || sig.HashSuffix[0] != 4 {
+		return nil
+	}
+	length := int(sig.HashSuffix[4])<<8 | int(sig.HashSuffix[5])
+	if 6+length+6 > len(sig.HashSuffix) {
+		return nil
+	}
+
+	subpackets, _ := packet.OpaqueSubpackets(sig.HashSuffix[6 : 6+length])
+
+	var contents [][]byte
+	for _, subpacket := range subpackets {
+		if subpacket.SubType&^criticalSubpacket == subType {
+			contents = append(contents, subpacket.Contents)
+		}
+	}
+	return contents
+}
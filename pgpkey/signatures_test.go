@@ -0,0 +1,116 @@
+package pgpkey
+
+import (
+	"testing"
+
+	"github.com/fluidkeys/crypto/openpgp/packet"
+	"github.com/fluidkeys/fluidkeys/assert"
+	"github.com/fluidkeys/fluidkeys/exampledata"
+	"github.com/fluidkeys/fluidkeys/fingerprint"
+)
+
+func TestIsFlaggedForAuthentication(t *testing.T) {
+	t.Run("with an authentication subkey", func(t *testing.T) {
+		key, err := LoadFromArmoredPublicKey(exampledata.ExamplePublicKey18)
+		if err != nil {
+			t.Fatalf("failed to load example key: %v", err)
+		}
+		assert.Equal(t, true, IsFlaggedForAuthentication(key.Subkeys[0].Sig))
+
+		for _, identity := range key.Identities {
+			assert.Equal(t, false, IsFlaggedForAuthentication(identity.SelfSignature))
+		}
+	})
+
+	t.Run("with an encryption subkey", func(t *testing.T) {
+		key, err := LoadFromArmoredPublicKey(exampledata.ExamplePublicKey4)
+		if err != nil {
+			t.Fatalf("failed to load example key: %v", err)
+		}
+		assert.Equal(t, false, IsFlaggedForAuthentication(key.Subkeys[0].Sig))
+	})
+
+	t.Run("with a critical key flags subpacket", func(t *testing.T) {
+		sig := makeSignatureWithSubpackets([]byte{2, keyFlagsSubpacket | criticalSubpacket, keyFlagAuthenticate})
+		assert.Equal(t, true, IsFlaggedForAuthentication(sig))
+	})
+
+	t.Run("with a truncated key flags subpacket", func(t *testing.T) {
+		sig := makeSignatureWithSubpackets([]byte{5, keyFlagsSubpacket, keyFlagAuthenticate})
+		assert.Equal(t, false, IsFlaggedForAuthentication(sig))
+	})
+}
+
+// makeSignatureWithSubpackets returns a signature with the given raw hashed
+// subpackets in its HashSuffix.
+func makeSignatureWithSubpackets(subpackets []byte) *packet.Signature {
+	length := len(subpackets)
+	hashSuffix := []byte{4, 0x13, 1, 8, byte(length >> 8), byte(length)}
+	hashSuffix = append(hashSuffix, subpackets...)
+	hashSuffix = append(hashSuffix, 4, 0xff, 0, 0, byte((6+length)>>8), byte(6+length))
+	return &packet.Signature{HashSuffix: hashSuffix}
+}
+
+func TestSubkeyBindingSignature(t *testing.T) {
+	key, err := LoadFromArmoredPublicKey(exampledata.ExamplePublicKey10)
+	if err != nil {
+		t.Fatalf("failed to load example key: %v", err)
+	}
+
+	t.Run("with a subkey that isn't revoked", func(t *testing.T) {
+		subkey, err := key.Subkey(0x0A5B7B748CBF7498)
+		assert.ErrorIsNil(t, err)
+		assert.Equal(t, subkey.Sig, key.SubkeyBindingSignature(*subkey))
+	})
+
+	t.Run("with a revoked subkey", func(t *testing.T) {
+		subkey, err := key.Subkey(0xF291DE5ADD97892E)
+		assert.ErrorIsNil(t, err)
+		assert.Equal(t, true, subkey.Sig.SigType == packet.SigTypeSubkeyRevocation)
+
+		bindingSig := key.SubkeyBindingSignature(*subkey)
+		assert.Equal(t, true, bindingSig.SigType == packet.SigTypeSubkeyBinding)
+		assert.Equal(t, true, bindingSig.FlagEncryptCommunications)
+	})
+}
+
+func TestRevocationKeys(t *testing.T) {
+	revoker := exampledata.ExampleFingerprint4
+	revokerBytes := revoker.Bytes()
+	revocationKey := append([]byte{23, revocationKeySubpacket, 0x80, 1}, revokerBytes[:]...)
+
+	t.Run("with a revocation key subpacket", func(t *testing.T) {
+		sig := makeSignatureWithSubpackets(revocationKey)
+		assert.Equal(t, []fingerprint.Fingerprint{revoker}, revocationKeys(sig))
+	})
+
+	t.Run("skips a revocation key subpacket with a bad length", func(t *testing.T) {
+		malformed := []byte{6, revocationKeySubpacket, 0x80, 1, 0xAA, 0xBB, 0xCC}
+		sig := makeSignatureWithSubpackets(append(malformed, revocationKey...))
+		assert.Equal(t, []fingerprint.Fingerprint{revoker}, revocationKeys(sig))
+	})
+
+	t.Run("skips a revocation key subpacket without the class bit", func(t *testing.T) {
+		withoutClass := append([]byte{23, revocationKeySubpacket, 0x00, 1}, revokerBytes[:]...)
+		sig := makeSignatureWithSubpackets(withoutClass)
+		assert.Equal(t, 0, len(revocationKeys(sig)))
+	})
+}
+
+func TestDirectSignatures(t *testing.T) {
+	t.Run("loads the direct signature designating a revoker", func(t *testing.T) {
+		key, err := LoadFromArmoredPublicKey(exampledata.ExamplePublicKey5)
+		if err != nil {
+			t.Fatalf("failed to load example key: %v", err)
+		}
+		assert.Equal(t, 1, len(key.DirectSignatures))
+	})
+
+	t.Run("with a key without direct signatures", func(t *testing.T) {
+		key, err := LoadFromArmoredPublicKey(exampledata.ExamplePublicKey4)
+		if err != nil {
+			t.Fatalf("failed to load example key: %v", err)
+		}
+		assert.Equal(t, 0, len(key.DirectSignatures))
+	})
+}
@@ -60,6 +60,12 @@ var (
 		[]uint8{symmetric.AES256, symmetric.AES192, symmetric.AES128},
 	}
 
+	// StrongSymmetricCipher is the cipher that every recipient of a message
+	// must advertise before we'll consider the message strongly encrypted.
+	// If any recipient doesn't list it, clients fall back to a cipher
+	// everyone supports, which may be as weak as TripleDES.
+	StrongSymmetricCipher symmetric.SymmetricAlgorithm = symmetric.AES256
+
 	// SupportedSymmetricKeyAlgorithms defines what algorithms we can
 	// technically decrypt (but doesn't mean they're encouraged.)
 	SupportedSymmetricKeyAlgorithms = []uint8{
@@ -156,14 +162,14 @@ const (
 // for example, if today is 15th September, nextExpiryTime would return
 // 1st October + 30 days
 func NextExpiryTime(now time.Time) time.Time {
-	return firstOfNextMonth(now).Add(thirtyDays).In(time.UTC)
+	return DefaultPolicy().NextExpiryTime(now)
 }
 
 // NextRotation returns 30 days before the earliest expiry time on
 // the key.
 // If the key doesn't expire, it returns nil.
 func NextRotation(expiry time.Time) time.Time {
-	return expiry.Add(-thirtyDays)
+	return DefaultPolicy().NextRotation(expiry)
 }
 
 // IsExpiryTooLong returns true if the expiry is too far in the future.
@@ -173,15 +179,13 @@ func NextRotation(expiry time.Time) time.Time {
 // We use `NextExpiryTime` such that when we set an expiry date it's *exactly*
 // on the cusp of being too long, and can only get shorter after that point.
 func IsExpiryTooLong(expiry time.Time, now time.Time) bool {
-	latestAcceptableExpiry := NextExpiryTime(now)
-	return expiry.After(latestAcceptableExpiry)
+	return DefaultPolicy().IsExpiryTooLong(expiry, now)
 }
 
 // IsOverdueForRotation returns true if `now` is more than 10 days after
 // nextRotation
 func IsOverdueForRotation(nextRotation time.Time, now time.Time) bool {
-	overdueTime := nextRotation.Add(tenDays)
-	return overdueTime.Before(now)
+	return DefaultPolicy().IsOverdueForRotation(nextRotation, now)
 }
 
 // IsDueForRotation returns true if `now` is any time after the key's next
@@ -190,18 +194,315 @@ func IsDueForRotation(nextRotation time.Time, now time.Time) bool {
 	return nextRotation.Before(now)
 }
 
+// firstOfNextMonth returns midnight UTC on the 1st of the month after
+// `today`'s month (in today's own timezone). time.Date normalises month 13 to
+// January of the following year.
 func firstOfNextMonth(today time.Time) time.Time {
-	firstOfThisMonth := beginningOfMonth(today)
-	return beginningOfMonth(firstOfThisMonth.Add(fortyFiveDays))
+	y, m, _ := today.Date()
+	return time.Date(y, m+1, 1, 0, 0, 0, 0, time.UTC)
+}
+
+func days(n int) time.Duration {
+	return time.Duration(n) * time.Hour * 24
+}
+
+const (
+	thirtyDays time.Duration = time.Duration(time.Hour * 24 * 30)
+)
+
+// Policy lets an organisation adjust how strictly keys are checked. The zero
+// value gives Fluidkeys' default behaviour.
+type Policy struct {
+	// NoExpiryTreatment controls what happens when the primary key or
+	// encryption subkey never expires.
+	NoExpiryTreatment NoExpiryTreatment
+
+	// RequireDesignatedRevoker raises a warning for keys which don't
+	// designate another key (for example the organisation's) as allowed to
+	// revoke them. Off by default.
+	RequireDesignatedRevoker bool
+
+	// Calendar, if set, says which days the key's owner can be expected to
+	// act on it, so that rotations due on a weekend or holiday can be
+	// scheduled for the business day before. Nil disables this.
+	Calendar Calendar
+
+	// Role is what the key is meant to be used for. If set, keys which
+	// can't be used for that role get a warning saying so.
+	Role KeyRole
+
+	// MinimumBitStrength is the weakest the primary key or any subkey may
+	// be, as a symmetric-equivalent strength (see FiniteFieldBitStrength),
+	// e.g. 128 requires RSA keys of at least 3072 bits. 0 means no minimum.
+	MinimumBitStrength int
+
+	// RotateDaysBeforeExpiry is how many days before it expires a key
+	// becomes due for rotation. 0 means the default of 30 days.
+	RotateDaysBeforeExpiry int
+
+	// OverdueGraceDays is how many days after it becomes due for rotation
+	// a key is overdue. 0 means the default of 10 days.
+	OverdueGraceDays int
+
+	// MaxExpiryDuration is how far after the 1st of next month a key may
+	// expire before its expiry is too long. It's also how far after the 1st
+	// of next month NextExpiryTime sets expiries. 0 means the default of 30
+	// days.
+	MaxExpiryDuration time.Duration
+
+	// ExpiringSoonDays is how many days before it expires a key (or
+	// encryption subkey) gets a warning that it's about to expire, whatever
+	// the rotation settings. 0 means the default of 7 days.
+	ExpiringSoonDays int
+
+	// DayRounding says how a part day is counted when saying how many days
+	// until (or since) a key expires. The default is to round down.
+	DayRounding DayRounding
+
+	// MaxExpiryGapDays is how many days apart the primary key and the
+	// encryption subkey may expire before the key gets a warning. 0 means
+	// the default of 30 days.
+	MaxExpiryGapDays int
+}
+
+// DefaultPolicy returns the policy Fluidkeys uses unless told otherwise, with
+// the rotation settings filled in explicitly. It behaves the same as the zero
+// Policy.
+func DefaultPolicy() Policy {
+	return Policy{
+		RotateDaysBeforeExpiry: 30,
+		OverdueGraceDays:       10,
+		MaxExpiryDuration:      thirtyDays,
+		ExpiringSoonDays:       7,
+		MaxExpiryGapDays:       30,
+	}
+}
+
+// NextExpiryTime returns the expiry time in UTC to set on a key created or
+// extended at `now`: MaxExpiryDuration after the 1st of the next month.
+func (p Policy) NextExpiryTime(now time.Time) time.Time {
+	return firstOfNextMonth(now).Add(p.maxExpiryDuration()).In(time.UTC)
+}
+
+// NextRotation returns RotateDaysBeforeExpiry days before the given expiry.
+func (p Policy) NextRotation(expiry time.Time) time.Time {
+	return expiry.Add(-days(p.rotateDaysBeforeExpiry()))
+}
+
+// IsExpiryTooLong returns true if the expiry is later than NextExpiryTime
+// would set it at `now`.
+func (p Policy) IsExpiryTooLong(expiry time.Time, now time.Time) bool {
+	latestAcceptableExpiry := p.NextExpiryTime(now)
+	return expiry.After(latestAcceptableExpiry)
+}
+
+// IsOverdueForRotation returns true if `now` is more than OverdueGraceDays
+// days after nextRotation.
+func (p Policy) IsOverdueForRotation(nextRotation time.Time, now time.Time) bool {
+	return p.OverdueRotation(nextRotation).Before(now)
+}
+
+// OverdueRotation returns OverdueGraceDays days after nextRotation, after
+// which the key is overdue for rotation.
+func (p Policy) OverdueRotation(nextRotation time.Time) time.Time {
+	return nextRotation.Add(days(p.overdueGraceDays()))
+}
+
+// IsExpiringSoon returns true if the expiry is no more than ExpiringSoonDays
+// days after `now`. It doesn't check whether the expiry has already passed.
+func (p Policy) IsExpiringSoon(expiry time.Time, now time.Time) bool {
+	return !expiry.After(now.Add(days(p.expiringSoonDays())))
+}
+
+// IsExpiryGapTooLarge returns true if the primary key and encryption subkey
+// expiries are more than MaxExpiryGapDays days apart, whichever is first.
+func (p Policy) IsExpiryGapTooLarge(primaryExpiry time.Time, subkeyExpiry time.Time) bool {
+	gap := primaryExpiry.Sub(subkeyExpiry)
+	if gap < 0 {
+		gap = -gap
+	}
+	return gap > days(p.maxExpiryGapDays())
+}
+
+func (p Policy) rotateDaysBeforeExpiry() int {
+	if p.RotateDaysBeforeExpiry == 0 {
+		return 30
+	}
+	return p.RotateDaysBeforeExpiry
+}
+
+func (p Policy) overdueGraceDays() int {
+	if p.OverdueGraceDays == 0 {
+		return 10
+	}
+	return p.OverdueGraceDays
+}
+
+func (p Policy) expiringSoonDays() int {
+	if p.ExpiringSoonDays == 0 {
+		return 7
+	}
+	return p.ExpiringSoonDays
+}
+
+func (p Policy) maxExpiryGapDays() int {
+	if p.MaxExpiryGapDays == 0 {
+		return 30
+	}
+	return p.MaxExpiryGapDays
 }
 
-func beginningOfMonth(now time.Time) time.Time {
-	y, m, _ := now.Date()
-	return time.Date(y, m, 1, 0, 0, 0, 0, now.Location()).In(time.UTC)
+func (p Policy) maxExpiryDuration() time.Duration {
+	if p.MaxExpiryDuration == 0 {
+		return thirtyDays
+	}
+	return p.MaxExpiryDuration
 }
 
+// KeyRole is the intended use of a key.
+type KeyRole int
+
 const (
-	tenDays       time.Duration = time.Duration(time.Hour * 24 * 10)
-	thirtyDays    time.Duration = time.Duration(time.Hour * 24 * 30)
-	fortyFiveDays time.Duration = time.Duration(time.Hour * 24 * 45)
+	// RoleUnspecified doesn't check the key against a role. This is the
+	// default.
+	RoleUnspecified KeyRole = 0
+
+	// RoleEncryption is for keys used to receive encrypted messages.
+	RoleEncryption KeyRole = 1
+
+	// RoleSigning is for keys used to sign messages or code.
+	RoleSigning KeyRole = 2
+
+	// RoleAuthentication is for keys used to log in, e.g. over SSH.
+	RoleAuthentication KeyRole = 3
 )
+
+// String returns the use the role describes, e.g. "encryption".
+func (r KeyRole) String() string {
+	switch r {
+	case RoleEncryption:
+		return "encryption"
+	case RoleSigning:
+		return "signing"
+	case RoleAuthentication:
+		return "authentication"
+	}
+	return "unspecified"
+}
+
+// NoExpiryTreatment says how keys without an expiry date should be treated.
+type NoExpiryTreatment int
+
+const (
+	// NoExpiryWarn raises the usual warning for a key which never expires.
+	// This is the default.
+	NoExpiryWarn NoExpiryTreatment = 0
+
+	// NoExpiryIgnore accepts keys which never expire, for example for
+	// users who deliberately keep a never-expiring key offline.
+	NoExpiryIgnore NoExpiryTreatment = 1
+
+	// NoExpiryCritical treats a key which never expires as critical.
+	NoExpiryCritical NoExpiryTreatment = 2
+)
+
+// DayRounding says how to turn a duration into a number of days.
+type DayRounding int
+
+const (
+	// DayRoundingFloor counts whole days only, so 47 hours is 1 day. This
+	// is the default.
+	DayRoundingFloor DayRounding = 0
+
+	// DayRoundingNearest rounds to the nearest day, so 47 hours is 2 days
+	// but 25 hours is 1 day.
+	DayRoundingNearest DayRounding = 1
+
+	// DayRoundingCeil counts any part day as a whole day, so 25 hours is 2
+	// days.
+	DayRoundingCeil DayRounding = 2
+)
+
+// Calendar says which days are business days, when someone can be expected
+// to rotate their key.
+type Calendar interface {
+	IsBusinessDay(day time.Time) bool
+}
+
+// WeekdayCalendar is a Calendar where Monday to Friday are business days,
+// except for the given holidays.
+type WeekdayCalendar struct {
+	// Holidays are non-business days. Only the date (in the holiday's
+	// location) is used.
+	Holidays []time.Time
+}
+
+// IsBusinessDay returns true if day is a weekday and not a holiday.
+func (c WeekdayCalendar) IsBusinessDay(day time.Time) bool {
+	switch day.Weekday() {
+	case time.Saturday, time.Sunday:
+		return false
+	}
+
+	y, m, d := day.Date()
+	for _, holiday := range c.Holidays {
+		hy, hm, hd := holiday.In(day.Location()).Date()
+		if y == hy && m == hm && d == hd {
+			return false
+		}
+	}
+	return true
+}
+
+// PreviousBusinessDay returns the latest business day on or before day,
+// according to the calendar. If the calendar has no business days in the
+// preceding year, day is returned unchanged.
+func PreviousBusinessDay(calendar Calendar, day time.Time) time.Time {
+	for i := 0; i < 366; i++ {
+		candidate := day.AddDate(0, 0, -i)
+		if calendar.IsBusinessDay(candidate) {
+			return candidate
+		}
+	}
+	return day
+}
+
+// FiniteFieldBitStrength returns the approximate security in bits of an RSA,
+// DSA or ElGamal key of the given size, using the NIST SP 800-57
+// equivalences:
+//
+//	key size   strength
+//	1024       80
+//	2048       112
+//	3072       128
+//	7680       192
+//	15360      256
+//
+// Sizes in between round down to the next size in the table, and keys
+// smaller than 1024 bits are given a strength of 0 as they're considered
+// broken.
+func FiniteFieldBitStrength(bits int) int {
+	switch {
+	case bits >= 15360:
+		return 256
+	case bits >= 7680:
+		return 192
+	case bits >= 3072:
+		return 128
+	case bits >= 2048:
+		return 112
+	case bits >= 1024:
+		return 80
+	}
+	return 0
+}
+
+// EllipticCurveBitStrength returns the approximate security in bits of an
+// elliptic curve key over a curve of the given size: half the size, capped
+// at 256 (so nistp521 is 256, like 15360-bit RSA).
+func EllipticCurveBitStrength(curveBits int) int {
+	if strength := curveBits / 2; strength < 256 {
+		return strength
+	}
+	return 256
+}
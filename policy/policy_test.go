@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"testing"
 	"time"
+
+	"github.com/fluidkeys/fluidkeys/assert"
 )
 
 var (
@@ -44,17 +46,33 @@ func TestFirstOfNextMonth(t *testing.T) {
 			march1st,
 		},
 		{
-			time.Date(2020, 2, 29, 23, 59, 59, 0, time.UTC), // end of Feb, leap year
+			time.Date(2020, 2, 1, 0, 0, 0, 0, time.UTC), // start of Feb, leap year
 			march1stLeapYear,
 		},
 		{
 			time.Date(2020, 2, 29, 23, 59, 59, 0, time.UTC), // end of Feb, leap year
 			march1stLeapYear,
 		},
+		{
+			time.Date(2018, 1, 31, 0, 0, 0, 0, time.UTC), // 31 day month to a short month
+			feb1st,
+		},
+		{
+			time.Date(2018, 12, 1, 0, 0, 0, 0, time.UTC), // start of Dec
+			time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			time.Date(2018, 12, 31, 23, 59, 59, 0, time.UTC), // end of Dec
+			time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
 		{
 			time.Date(2018, 2, 15, 12, 0, 0, 0, anotherTimezone), // non-UTC
 			time.Date(2018, 3, 1, 0, 0, 0, 0, time.UTC),          // should convert timezone
 		},
+		{
+			time.Date(2018, 3, 1, 2, 0, 0, 0, anotherTimezone), // still Feb in UTC
+			time.Date(2018, 4, 1, 0, 0, 0, 0, time.UTC),        // uses the month in today's timezone
+		},
 	}
 
 	for _, test := range tests {
@@ -106,6 +124,14 @@ func TestNextExpiryTime(t *testing.T) {
 			time.Date(2018, 2, 15, 18, 0, 0, 0, anotherTimezone), // non-UTC
 			time.Date(2018, 3, 31, 0, 0, 0, 0, time.UTC),         // should convert to UTC
 		},
+		{
+			time.Date(2018, 8, 31, 23, 59, 59, 0, time.UTC), // end of a 31 day month
+			time.Date(2018, 10, 1, 0, 0, 0, 0, time.UTC),    // 1st Sept + 30 days
+		},
+		{
+			time.Date(2018, 12, 31, 23, 59, 59, 0, time.UTC), // end of the year
+			time.Date(2019, 1, 31, 0, 0, 0, 0, time.UTC),     // 1st Jan + 30 days
+		},
 	}
 
 	for _, test := range tests {
@@ -166,3 +192,126 @@ func TestDueOverdueFunctions(t *testing.T) {
 	})
 
 }
+
+func TestPolicyRotationSettings(t *testing.T) {
+	now := time.Date(2018, 6, 15, 0, 0, 0, 0, time.UTC)
+	expiry := time.Date(2018, 9, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("DefaultPolicy matches the zero Policy", func(t *testing.T) {
+		assert.Equal(t, Policy{}.NextRotation(expiry), DefaultPolicy().NextRotation(expiry))
+		assert.Equal(t, Policy{}.NextExpiryTime(now), DefaultPolicy().NextExpiryTime(now))
+		assert.Equal(t, NextExpiryTime(now), DefaultPolicy().NextExpiryTime(now))
+		assert.Equal(t, Policy{}.IsExpiringSoon(expiry, now), DefaultPolicy().IsExpiringSoon(expiry, now))
+	})
+
+	t.Run("IsExpiringSoon with the default of 7 days", func(t *testing.T) {
+		p := Policy{}
+		assert.Equal(t, true, p.IsExpiringSoon(now.Add(time.Duration(7*24)*time.Hour), now))
+		assert.Equal(t, false, p.IsExpiringSoon(now.Add(time.Duration(7*24)*time.Hour+time.Second), now))
+	})
+
+	t.Run("IsExpiringSoon uses ExpiringSoonDays", func(t *testing.T) {
+		p := Policy{ExpiringSoonDays: 2}
+		assert.Equal(t, true, p.IsExpiringSoon(now.Add(time.Duration(2*24)*time.Hour), now))
+		assert.Equal(t, false, p.IsExpiringSoon(now.Add(time.Duration(3*24)*time.Hour), now))
+	})
+
+	t.Run("IsExpiryGapTooLarge with the default of 30 days", func(t *testing.T) {
+		thirtyDaysLater := expiry.Add(time.Duration(30*24) * time.Hour)
+		assert.Equal(t, false, Policy{}.IsExpiryGapTooLarge(expiry, expiry))
+		assert.Equal(t, false, Policy{}.IsExpiryGapTooLarge(expiry, thirtyDaysLater))
+		assert.Equal(t, true, Policy{}.IsExpiryGapTooLarge(expiry, thirtyDaysLater.Add(time.Second)))
+		assert.Equal(t, true, Policy{}.IsExpiryGapTooLarge(thirtyDaysLater.Add(time.Second), expiry))
+		assert.Equal(t, false, DefaultPolicy().IsExpiryGapTooLarge(expiry, thirtyDaysLater))
+	})
+
+	t.Run("IsExpiryGapTooLarge uses MaxExpiryGapDays", func(t *testing.T) {
+		p := Policy{MaxExpiryGapDays: 2}
+		assert.Equal(t, false, p.IsExpiryGapTooLarge(expiry, expiry.Add(time.Duration(2*24)*time.Hour)))
+		assert.Equal(t, true, p.IsExpiryGapTooLarge(expiry, expiry.Add(time.Duration(3*24)*time.Hour)))
+	})
+
+	p := Policy{
+		RotateDaysBeforeExpiry: 14,
+		OverdueGraceDays:       2,
+		MaxExpiryDuration:      time.Duration(7*24) * time.Hour,
+	}
+
+	t.Run("NextRotation uses RotateDaysBeforeExpiry", func(t *testing.T) {
+		assert.Equal(t, time.Date(2018, 8, 18, 0, 0, 0, 0, time.UTC), p.NextRotation(expiry))
+	})
+
+	t.Run("IsOverdueForRotation uses OverdueGraceDays", func(t *testing.T) {
+		assert.Equal(t, false, p.IsOverdueForRotation(now.Add(time.Duration(-2*24)*time.Hour), now))
+		assert.Equal(t, true, p.IsOverdueForRotation(now.Add(time.Duration(-3*24)*time.Hour), now))
+		assert.Equal(t, time.Date(2018, 8, 20, 0, 0, 0, 0, time.UTC), p.OverdueRotation(p.NextRotation(expiry)))
+	})
+
+	t.Run("NextExpiryTime and IsExpiryTooLong use MaxExpiryDuration", func(t *testing.T) {
+		july8th := time.Date(2018, 7, 8, 0, 0, 0, 0, time.UTC)
+		assert.Equal(t, july8th, p.NextExpiryTime(now))
+		assert.Equal(t, false, p.IsExpiryTooLong(july8th, now))
+		assert.Equal(t, true, p.IsExpiryTooLong(july8th.Add(time.Second), now))
+	})
+}
+
+func TestWeekdayCalendar(t *testing.T) {
+	friday := time.Date(2018, 11, 2, 12, 0, 0, 0, time.UTC)
+	saturday := friday.AddDate(0, 0, 1)
+	sunday := friday.AddDate(0, 0, 2)
+	monday := friday.AddDate(0, 0, 3)
+
+	calendar := WeekdayCalendar{Holidays: []time.Time{
+		time.Date(2018, 11, 5, 0, 0, 0, 0, time.UTC), // monday
+	}}
+
+	var tests = []struct {
+		day      time.Time
+		expected bool
+	}{
+		{friday, true},
+		{saturday, false},
+		{sunday, false},
+		{monday, false},
+		{monday.AddDate(0, 0, 1), true},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("IsBusinessDay(%s)", test.day.Weekday()), func(t *testing.T) {
+			if got := calendar.IsBusinessDay(test.day); got != test.expected {
+				t.Errorf("expected IsBusinessDay(%v) to return %v, got %v", test.day, test.expected, got)
+			}
+		})
+	}
+
+	t.Run("PreviousBusinessDay skips the weekend and holiday", func(t *testing.T) {
+		if got := PreviousBusinessDay(calendar, monday); !got.Equal(friday) {
+			t.Errorf("expected PreviousBusinessDay(%v) to return %v, got %v", monday, friday, got)
+		}
+	})
+
+	t.Run("PreviousBusinessDay on a business day", func(t *testing.T) {
+		if got := PreviousBusinessDay(calendar, friday); !got.Equal(friday) {
+			t.Errorf("expected PreviousBusinessDay(%v) to return %v, got %v", friday, friday, got)
+		}
+	})
+}
+
+func TestEllipticCurveBitStrength(t *testing.T) {
+	var tests = []struct {
+		curveBits int
+		expected  int
+	}{
+		{256, 128},
+		{384, 192},
+		{448, 224},
+		{512, 256},
+		{521, 256},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("EllipticCurveBitStrength(%d)", test.curveBits), func(t *testing.T) {
+			assert.Equal(t, test.expected, EllipticCurveBitStrength(test.curveBits))
+		})
+	}
+}
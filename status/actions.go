@@ -22,16 +22,39 @@ import (
 	"sort"
 	"time"
 
+	"github.com/fluidkeys/fluidkeys/config"
+	"github.com/fluidkeys/fluidkeys/pgpkey"
 	"github.com/fluidkeys/fluidkeys/policy"
 )
 
+// GetKeyActions returns the actions which would fix the problems
+// GetKeyWarnings finds with the key, for example extending the primary key's
+// expiry or rotating the encryption subkey. Warnings which need the same fix
+// share a single action.
+func GetKeyActions(key pgpkey.PgpKey, config *config.Config) []KeyAction {
+	return GetKeyActionsAt(key, config, time.Now())
+}
+
+// GetKeyActionsAt is like GetKeyActions, but checks the key as of `now`
+// rather than the current time.
+func GetKeyActionsAt(key pgpkey.PgpKey, config *config.Config, now time.Time) []KeyAction {
+	return MakeActionsFromWarnings(GetKeyWarningsAt(key, config, now), now)
+}
+
 // MakeActionsFromWarnings returns a list of actions that can be performed on
 // the key to fix the warning.
 // Call `KeyAction.Enact(key)` to actually carry out the action.
 func MakeActionsFromWarnings(warnings []KeyWarning, now time.Time) []KeyAction {
+	return MakeActionsFromWarningsWithPolicy(warnings, policy.Policy{}, now)
+}
+
+// MakeActionsFromWarningsWithPolicy is like MakeActionsFromWarnings, but new
+// expiry dates are set according to the given policy (rather than the
+// default), so they match the warnings from GetKeyWarningsWithPolicy.
+func MakeActionsFromWarningsWithPolicy(warnings []KeyWarning, p policy.Policy, now time.Time) []KeyAction {
 	var actions []KeyAction
 	for _, warning := range warnings {
-		actions = append(actions, makeActionsFromSingleWarning(warning, now)...)
+		actions = append(actions, makeActionsFromSingleWarning(warning, p, now)...)
 	}
 	return deduplicateAndOrder(actions)
 }
@@ -55,12 +78,20 @@ func deduplicateAndOrder(actions []KeyAction) []KeyAction {
 // getUniqueStringForAction returns a string that can be used to disambiguate
 // between two actions, for example:
 // "SetPreferredCompressionAlgorithms{NewPreferences: []uint8{0x01}}"
+//
+// ModifyPrimaryKeyExpiry is identified by its ValidUntil alone: the previous
+// expiry only changes how the action is described, and warnings such as
+// PrimaryKeyOverdueForRotation and PrimaryKeyLongExpiry may carry it in
+// different pointers.
 func getUniqueStringForAction(action KeyAction) string {
+	if modifyExpiry, ok := action.(ModifyPrimaryKeyExpiry); ok {
+		return fmt.Sprintf("ModifyPrimaryKeyExpiry{ValidUntil: %s}", modifyExpiry.ValidUntil)
+	}
 	return fmt.Sprintf("%#v", action)
 }
 
-func makeActionsFromSingleWarning(warning KeyWarning, now time.Time) []KeyAction {
-	nextExpiry := policy.NextExpiryTime(now)
+func makeActionsFromSingleWarning(warning KeyWarning, p policy.Policy, now time.Time) []KeyAction {
+	nextExpiry := p.NextExpiryTime(now)
 
 	switch warning.Type {
 	case PrimaryKeyDueForRotation, PrimaryKeyOverdueForRotation, PrimaryKeyNoExpiry, PrimaryKeyLongExpiry, PrimaryKeyExpired:
@@ -75,7 +106,27 @@ func makeActionsFromSingleWarning(warning KeyWarning, now time.Time) []KeyAction
 			ExpireSubkey{SubkeyId: warning.SubkeyId},
 		}
 
-	case NoValidEncryptionSubkey:
+	case ExpiringSoon:
+		if warning.SubkeyId == 0 {
+			return []KeyAction{
+				ModifyPrimaryKeyExpiry{ValidUntil: nextExpiry, PreviouslyValidUntil: warning.CurrentValidUntil},
+			}
+		}
+		return []KeyAction{
+			CreateNewEncryptionSubkey{ValidUntil: nextExpiry},
+			ExpireSubkey{SubkeyId: warning.SubkeyId},
+		}
+
+	case MismatchedExpiryDates:
+		// line both up with the next expiry
+		return []KeyAction{
+			ModifyPrimaryKeyExpiry{ValidUntil: nextExpiry, PreviouslyValidUntil: warning.CurrentValidUntil},
+			CreateNewEncryptionSubkey{ValidUntil: nextExpiry},
+			ExpireSubkey{SubkeyId: warning.SubkeyId},
+		}
+
+	case NoValidEncryptionSubkey, KeyCannotEncrypt, EncryptionBrokenSigningIntact, EncryptionSubkeyRevoked,
+		PrimaryKeyUsedForEncryption, SubkeyCombinedEncryptAndSign:
 		return []KeyAction{
 			CreateNewEncryptionSubkey{ValidUntil: nextExpiry},
 		}
@@ -109,6 +160,18 @@ func makeActionsFromSingleWarning(warning KeyWarning, now time.Time) []KeyAction
 			RefreshUserIdSelfSignatures{},
 		}
 
+	case SelfSigHashBelowPreferences:
+		if warning.SubkeyId == 0 {
+			return []KeyAction{
+				RefreshUserIdSelfSignatures{},
+			}
+		}
+		return []KeyAction{
+			RefreshSubkeyBindingSignature{
+				SubkeyId: warning.SubkeyId,
+			},
+		}
+
 	case WeakSubkeyBindingSignatureHash:
 		return []KeyAction{
 			RefreshSubkeyBindingSignature{
@@ -116,6 +179,75 @@ func makeActionsFromSingleWarning(warning KeyWarning, now time.Time) []KeyAction
 			},
 		}
 
+	case PrimaryKeyCannotSign:
+		// the primary key's algorithm can't be changed, so the only
+		// remedy is to create a whole new key.
+		return []KeyAction{}
+
+	case KeyFromVulnerablePeriod:
+		if warning.SubkeyId == 0 {
+			return []KeyAction{} // the primary key must be replaced
+		}
+		if warning.Detail != policy.RoleEncryption.String() {
+			// Fluidkeys only creates encryption subkeys, so other subkeys
+			// have to be replaced with other tools.
+			return []KeyAction{}
+		}
+		return []KeyAction{
+			CreateNewEncryptionSubkey{ValidUntil: nextExpiry},
+			ExpireSubkey{SubkeyId: warning.SubkeyId},
+		}
+
+	case RotationDueOnNonBusinessDay:
+		return []KeyAction{} // nothing to do until the key is due for rotation
+
+	case KeyBelowMinimumStrength:
+		// new subkeys are generated with EncryptionSubkeyRsaKeyBits, which
+		// may not meet the policy either, so leave it to the owner.
+		return []KeyAction{}
+
+	case NoValidSigningSubkey,
+		SigningSubkeyDueForRotation,
+		SigningSubkeyOverdueForRotation,
+		SigningSubkeyNoExpiry:
+		// Fluidkeys only creates encryption subkeys, so signing subkeys
+		// have to be rotated with other tools.
+		return []KeyAction{}
+
+	case PrimaryKeyNoValidUserId, PrimaryKeyNoUserId:
+		// Fluidkeys can't add user IDs, so the owner needs to add one
+		// with other tools.
+		return []KeyAction{}
+
+	case PrimaryKeyWeakCipher:
+		// the primary key can't be replaced, so the owner needs a new key.
+		return []KeyAction{}
+
+	case ExpiryDrivenBySecondaryUid:
+		// whether the user ID is stale is up to the owner, so leave the
+		// rotation actions to the PrimaryKey* warnings.
+		return []KeyAction{}
+
+	case RoleCapabilityMismatch:
+		// the key was made for something else, so the owner needs a
+		// different key rather than changes to this one.
+		return []KeyAction{}
+
+	case MissingDesignatedRevoker:
+		// the revoker is chosen by the organisation, so this can't be
+		// fixed automatically.
+		return []KeyAction{}
+
+	case PrimaryKeyRevoked:
+		// a revoked key can't be brought back, so the owner needs a new
+		// key.
+		return []KeyAction{}
+
+	case KeyCreatedInFuture:
+		// either this computer's clock is wrong or the key was made with
+		// a bad one. Changing the expiry wouldn't help either way.
+		return []KeyAction{}
+
 	default: // don't know how to remedy this KeyWarning
 		// TODO: log that we don't know how to remedy this type of
 		// KeyWarning
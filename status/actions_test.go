@@ -5,6 +5,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/fluidkeys/fluidkeys/exampledata"
+	"github.com/fluidkeys/fluidkeys/pgpkey"
 	"github.com/fluidkeys/fluidkeys/policy"
 )
 
@@ -66,6 +68,154 @@ func TestMakeActionsFromSingleWarning(t *testing.T) {
 				CreateNewEncryptionSubkey{ValidUntil: nextExpiry},
 			},
 		},
+		{
+			KeyCannotEncrypt,
+			0,
+			[]KeyAction{
+				CreateNewEncryptionSubkey{ValidUntil: nextExpiry},
+			},
+		},
+		{
+			EncryptionBrokenSigningIntact,
+			0,
+			[]KeyAction{
+				CreateNewEncryptionSubkey{ValidUntil: nextExpiry},
+			},
+		},
+		{
+			EncryptionSubkeyRevoked,
+			0,
+			[]KeyAction{
+				CreateNewEncryptionSubkey{ValidUntil: nextExpiry},
+			},
+		},
+		{
+			PrimaryKeyUsedForEncryption,
+			0,
+			[]KeyAction{
+				CreateNewEncryptionSubkey{ValidUntil: nextExpiry},
+			},
+		},
+		{
+			SubkeyCombinedEncryptAndSign,
+			0xABCD,
+			[]KeyAction{
+				CreateNewEncryptionSubkey{ValidUntil: nextExpiry},
+			},
+		},
+		{
+			ExpiringSoon,
+			0,
+			[]KeyAction{
+				ModifyPrimaryKeyExpiry{ValidUntil: nextExpiry},
+			},
+		},
+		{
+			ExpiringSoon,
+			9999,
+			[]KeyAction{
+				CreateNewEncryptionSubkey{ValidUntil: nextExpiry},
+				ExpireSubkey{SubkeyId: 9999},
+			},
+		},
+		{
+			MismatchedExpiryDates,
+			9999,
+			[]KeyAction{
+				ModifyPrimaryKeyExpiry{ValidUntil: nextExpiry},
+				CreateNewEncryptionSubkey{ValidUntil: nextExpiry},
+				ExpireSubkey{SubkeyId: 9999},
+			},
+		},
+		{
+			PrimaryKeyCannotSign,
+			0,
+			[]KeyAction{},
+		},
+		{
+			KeyFromVulnerablePeriod,
+			0,
+			[]KeyAction{},
+		},
+		{
+			MissingDesignatedRevoker,
+			0,
+			[]KeyAction{},
+		},
+		{
+			RoleCapabilityMismatch,
+			0,
+			[]KeyAction{},
+		},
+		{
+			KeyBelowMinimumStrength,
+			9999,
+			[]KeyAction{},
+		},
+		{
+			ExpiryDrivenBySecondaryUid,
+			0,
+			[]KeyAction{},
+		},
+		{
+			PrimaryKeyWeakCipher,
+			0,
+			[]KeyAction{},
+		},
+		{
+			PrimaryKeyNoValidUserId,
+			0,
+			[]KeyAction{},
+		},
+		{
+			PrimaryKeyNoUserId,
+			0,
+			[]KeyAction{},
+		},
+		{
+			KeyCreatedInFuture,
+			0,
+			[]KeyAction{},
+		},
+		{
+			PrimaryKeyRevoked,
+			0,
+			[]KeyAction{},
+		},
+		{
+			NoValidSigningSubkey,
+			0,
+			[]KeyAction{},
+		},
+		{
+			SigningSubkeyOverdueForRotation,
+			9999,
+			[]KeyAction{},
+		},
+		{
+			RotationDueOnNonBusinessDay,
+			9999,
+			[]KeyAction{},
+		},
+		{
+			SelfSigHashBelowPreferences,
+			0,
+			[]KeyAction{
+				RefreshUserIdSelfSignatures{},
+			},
+		},
+		{
+			SelfSigHashBelowPreferences,
+			9999,
+			[]KeyAction{
+				RefreshSubkeyBindingSignature{SubkeyId: 9999},
+			},
+		},
+		{
+			KeyFromVulnerablePeriod,
+			9999,
+			[]KeyAction{}, // not an encryption subkey, see TestVulnerableEncryptionSubkeyActions
+		},
 		{
 			SubkeyDueForRotation,
 			9999,
@@ -205,12 +355,23 @@ func TestMakeActionsFromSingleWarning(t *testing.T) {
 		}
 
 		t.Run(fmt.Sprintf("%s subkey=%v", warning, test.subkeyID), func(t *testing.T) {
-			gotActions := makeActionsFromSingleWarning(warning, now)
+			gotActions := makeActionsFromSingleWarning(warning, policy.Policy{}, now)
 			assertActionsEqual(t, test.expectedActions, gotActions)
 		})
 	}
 }
 
+func TestVulnerableEncryptionSubkeyActions(t *testing.T) {
+	now := time.Date(2018, 6, 15, 0, 0, 0, 0, time.UTC)
+	warning := KeyWarning{Type: KeyFromVulnerablePeriod, SubkeyId: 9999, Detail: "encryption"}
+
+	expected := []KeyAction{
+		CreateNewEncryptionSubkey{ValidUntil: time.Date(2018, 7, 31, 0, 0, 0, 0, time.UTC)},
+		ExpireSubkey{SubkeyId: 9999},
+	}
+	assertActionsEqual(t, expected, makeActionsFromSingleWarning(warning, policy.Policy{}, now))
+}
+
 func TestDeduplicateAndOrder(t *testing.T) {
 
 	t.Run("should de-duplicate identical actions", func(t *testing.T) {
@@ -243,6 +404,23 @@ func TestDeduplicateAndOrder(t *testing.T) {
 		assertActionsEqual(t, expectedActions, gotActions)
 	})
 
+	t.Run("de-duplicates primary key expiry actions with different previous expiries", func(t *testing.T) {
+		validUntil := time.Date(2018, 7, 31, 0, 0, 0, 0, time.UTC)
+		previously := time.Date(2018, 7, 1, 0, 0, 0, 0, time.UTC)
+		samePreviously := previously
+
+		inputActions := []KeyAction{
+			ModifyPrimaryKeyExpiry{ValidUntil: validUntil, PreviouslyValidUntil: &previously},
+			ModifyPrimaryKeyExpiry{ValidUntil: validUntil, PreviouslyValidUntil: &samePreviously},
+		}
+
+		expectedActions := []KeyAction{
+			ModifyPrimaryKeyExpiry{ValidUntil: validUntil, PreviouslyValidUntil: &previously},
+		}
+		gotActions := deduplicateAndOrder(inputActions)
+		assertActionsEqual(t, expectedActions, gotActions)
+	})
+
 	t.Run("doesn't de-duplicate actions for different subkeys", func(t *testing.T) {
 		inputActions := []KeyAction{
 			ExpireSubkey{SubkeyId: 1234},
@@ -305,6 +483,116 @@ func TestMakeActionsFromWarnings(t *testing.T) {
 	assertActionsEqual(t, expectedActions, gotActions)
 }
 
+func TestMakeActionsFromWarningsWithPolicy(t *testing.T) {
+	warnings := []KeyWarning{
+		KeyWarning{Type: PrimaryKeyLongExpiry},
+		KeyWarning{Type: SubkeyDueForRotation, SubkeyId: 0x1111},
+	}
+	p := policy.Policy{MaxExpiryDuration: 60 * 24 * time.Hour}
+
+	now := time.Date(2018, 6, 15, 0, 0, 0, 0, time.UTC)
+	expectedActions := []KeyAction{
+		ModifyPrimaryKeyExpiry{ValidUntil: time.Date(2018, 8, 30, 0, 0, 0, 0, time.UTC)},
+		CreateNewEncryptionSubkey{ValidUntil: time.Date(2018, 8, 30, 0, 0, 0, 0, time.UTC)},
+		ExpireSubkey{SubkeyId: 0x1111},
+	}
+	gotActions := MakeActionsFromWarningsWithPolicy(warnings, p, now)
+	assertActionsEqual(t, expectedActions, gotActions)
+}
+
+func TestMakeActionsFromWarningCombinations(t *testing.T) {
+	now := time.Date(2018, 6, 15, 0, 0, 0, 0, time.UTC)
+	nextExpiry := time.Date(2018, 7, 31, 0, 0, 0, 0, time.UTC)
+	currentExpiry := time.Date(2018, 6, 20, 0, 0, 0, 0, time.UTC)
+	longExpiry := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	var tests = []struct {
+		name            string
+		warnings        []KeyWarning
+		expectedActions []KeyAction
+	}{
+		{
+			"primary key overdue and long expiry collapse to one expiry change",
+			[]KeyWarning{
+				KeyWarning{Type: PrimaryKeyOverdueForRotation, CurrentValidUntil: &currentExpiry},
+				KeyWarning{Type: PrimaryKeyLongExpiry, CurrentValidUntil: &longExpiry},
+			},
+			[]KeyAction{
+				ModifyPrimaryKeyExpiry{ValidUntil: nextExpiry, PreviouslyValidUntil: &currentExpiry},
+			},
+		},
+		{
+			"subkey overdue and long expiry collapse to one rotation",
+			[]KeyWarning{
+				KeyWarning{Type: SubkeyOverdueForRotation, SubkeyId: 0x1111},
+				KeyWarning{Type: SubkeyLongExpiry, SubkeyId: 0x1111},
+			},
+			[]KeyAction{
+				CreateNewEncryptionSubkey{ValidUntil: nextExpiry},
+				ExpireSubkey{SubkeyId: 0x1111},
+			},
+		},
+		{
+			"missing and revoked encryption subkey need one new subkey",
+			[]KeyWarning{
+				KeyWarning{Type: NoValidEncryptionSubkey},
+				KeyWarning{Type: EncryptionSubkeyRevoked, SubkeyId: 0x1111},
+			},
+			[]KeyAction{
+				CreateNewEncryptionSubkey{ValidUntil: nextExpiry},
+			},
+		},
+		{
+			"warnings without a fix give no actions",
+			[]KeyWarning{
+				KeyWarning{Type: PrimaryKeyCannotSign},
+				KeyWarning{Type: RotationDueOnNonBusinessDay},
+			},
+			nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assertActionsEqual(t, test.expectedActions, MakeActionsFromWarnings(test.warnings, now))
+		})
+	}
+}
+
+func TestGetKeyActions(t *testing.T) {
+	key, err := pgpkey.LoadFromArmoredPublicKey(exampledata.ExamplePublicKey4)
+	if err != nil {
+		t.Fatalf("failed to load example key: %v", err)
+	}
+
+	// key 4's primary key and encryption subkey never expire
+	now := time.Date(2019, 6, 15, 0, 0, 0, 0, time.UTC)
+	nextExpiry := time.Date(2019, 7, 31, 0, 0, 0, 0, time.UTC)
+	subkeyId := key.EncryptionSubkey(now).PublicKey.KeyId
+
+	got := GetKeyActionsAt(*key, nil, now)
+
+	expected := []KeyAction{
+		ModifyPrimaryKeyExpiry{ValidUntil: nextExpiry},
+		CreateNewEncryptionSubkey{ValidUntil: nextExpiry},
+		ExpireSubkey{SubkeyId: subkeyId},
+	}
+	for _, expectedAction := range expected {
+		if !containsAction(got, expectedAction) {
+			t.Errorf("expected %v in %v", expectedAction, got)
+		}
+	}
+}
+
+func containsAction(actions []KeyAction, action KeyAction) bool {
+	for _, a := range actions {
+		if actionsEqual(a, action) {
+			return true
+		}
+	}
+	return false
+}
+
 func assertActionsEqual(t *testing.T, expected []KeyAction, got []KeyAction) {
 	t.Helper()
 	if len(expected) != len(got) {
@@ -0,0 +1,109 @@
+// Copyright 2018 Paul Furley and Ian Drysdale
+//
+// This file is part of Fluidkeys Client which makes it simple to use OpenPGP.
+//
+// Fluidkeys Client is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Fluidkeys Client is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with Fluidkeys Client.  If not, see <https://www.gnu.org/licenses/>.
+
+package status
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/fluidkeys/fluidkeys/config"
+	"github.com/fluidkeys/fluidkeys/pgpkey"
+	"github.com/fluidkeys/fluidkeys/policy"
+)
+
+// BatchOptions controls how GetAllKeyWarningsWithOptions checks a keyring.
+type BatchOptions struct {
+	// Config, if set, is used to check Fluidkeys' configuration for each
+	// key. If nil, warnings about the configuration are skipped.
+	Config *config.Config
+
+	// Policy is what the keys are checked against. The zero Policy is the
+	// default.
+	Policy policy.Policy
+
+	// Now is the time the keys are checked as of. The zero time means the
+	// current time.
+	Now time.Time
+
+	// Workers is how many keys are checked at once. 0 or 1 checks them one
+	// at a time.
+	Workers int
+
+	// Sorted puts each key's warnings in ByWarningOrder, so the same keys
+	// always give the same output, for example when comparing reports.
+	// Otherwise the order can depend on map iteration inside the key.
+	Sorted bool
+}
+
+// GetAllKeyWarnings returns the warnings for each of the keys, keyed by
+// uppercase hex fingerprint (see fingerprint.Hex). Warnings about Fluidkeys'
+// configuration are skipped: use GetAllKeyWarningsWithOptions to include them.
+func GetAllKeyWarnings(keys []pgpkey.PgpKey) map[string][]KeyWarning {
+	return GetAllKeyWarningsWithOptions(keys, BatchOptions{})
+}
+
+// GetAllKeyWarningsWithOptions is like GetAllKeyWarnings, but checks the keys
+// according to the given options.
+func GetAllKeyWarningsWithOptions(keys []pgpkey.PgpKey, options BatchOptions) map[string][]KeyWarning {
+	now := options.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	results := make([][]KeyWarning, len(keys))
+
+	checkKey := func(i int) {
+		warnings := getKeyWarnings(keys[i], options.Config, options.Policy, now)
+		if options.Sorted {
+			sort.Stable(ByWarningOrder(warnings))
+		}
+		results[i] = warnings
+	}
+
+	if options.Workers <= 1 {
+		for i := range keys {
+			checkKey(i)
+		}
+	} else {
+		indexes := make(chan int)
+		var wg sync.WaitGroup
+
+		for w := 0; w < options.Workers; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := range indexes {
+					checkKey(i)
+				}
+			}()
+		}
+
+		for i := range keys {
+			indexes <- i
+		}
+		close(indexes)
+		wg.Wait()
+	}
+
+	warningsByFingerprint := make(map[string][]KeyWarning, len(keys))
+	for i, key := range keys {
+		warningsByFingerprint[key.Fingerprint().Hex()] = results[i]
+	}
+	return warningsByFingerprint
+}
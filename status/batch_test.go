@@ -0,0 +1,98 @@
+package status
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/fluidkeys/fluidkeys/assert"
+	"github.com/fluidkeys/fluidkeys/exampledata"
+	"github.com/fluidkeys/fluidkeys/pgpkey"
+	"github.com/fluidkeys/fluidkeys/policy"
+)
+
+func TestGetAllKeyWarnings(t *testing.T) {
+	now := time.Date(2018, 11, 1, 0, 0, 0, 0, time.UTC)
+
+	var keys []pgpkey.PgpKey
+	for _, armored := range []string{
+		exampledata.ExamplePublicKey2,
+		exampledata.ExamplePublicKey3,
+		exampledata.ExamplePublicKey4,
+		exampledata.ExamplePublicKey12,
+		exampledata.ExamplePublicKey13,
+	} {
+		key, err := pgpkey.LoadFromArmoredPublicKey(armored)
+		if err != nil {
+			t.Fatalf("failed to load example key: %v", err)
+		}
+		keys = append(keys, *key)
+	}
+
+	t.Run("returns each key's warnings keyed by fingerprint", func(t *testing.T) {
+		got := GetAllKeyWarningsWithOptions(keys, BatchOptions{Now: now, Sorted: true})
+
+		assert.Equal(t, len(keys), len(got))
+		for _, key := range keys {
+			// sort both, since a key with several user IDs can give the
+			// same warnings in a different order
+			expected := GetKeyWarningsWithPolicy(key, nil, policy.Policy{}, now)
+			sort.Stable(ByWarningOrder(expected))
+			assert.Equal(t, expected, got[key.Fingerprint().Hex()])
+		}
+	})
+
+	t.Run("healthy and problematic keys", func(t *testing.T) {
+		got := GetAllKeyWarningsWithOptions(keys, BatchOptions{Now: now})
+
+		assert.Equal(t, false, HasWarning(got[exampledata.ExampleFingerprint4.Hex()], PrimaryKeyUsedForEncryption))
+		assert.Equal(t, true, HasWarning(got[exampledata.ExampleFingerprint12.Hex()], PrimaryKeyUsedForEncryption))
+	})
+
+	t.Run("with a worker pool gives the same warnings", func(t *testing.T) {
+		expected := GetAllKeyWarningsWithOptions(keys, BatchOptions{Now: now, Sorted: true})
+
+		for _, workers := range []int{2, 3, 10} {
+			t.Run(fmt.Sprintf("%d workers", workers), func(t *testing.T) {
+				got := GetAllKeyWarningsWithOptions(keys, BatchOptions{Now: now, Sorted: true, Workers: workers})
+				assert.Equal(t, expected, got)
+			})
+		}
+	})
+
+	t.Run("Sorted puts warnings in ByWarningOrder", func(t *testing.T) {
+		got := GetAllKeyWarningsWithOptions(keys, BatchOptions{Now: now, Sorted: true})
+
+		for fingerprint, warnings := range got {
+			if !sort.IsSorted(ByWarningOrder(warnings)) {
+				t.Errorf("warnings for %s aren't sorted: %v", fingerprint, warnings)
+			}
+		}
+	})
+
+	t.Run("with no keys", func(t *testing.T) {
+		assert.Equal(t, map[string][]KeyWarning{}, GetAllKeyWarnings([]pgpkey.PgpKey{}))
+	})
+}
+
+func TestByWarningOrder(t *testing.T) {
+	warnings := []KeyWarning{
+		KeyWarning{Type: WeakPreferredHashAlgorithms, Detail: "SHA1"},
+		KeyWarning{Type: SubkeyDueForRotation, SubkeyId: 0xB},
+		KeyWarning{Type: WeakPreferredHashAlgorithms, Detail: "MD5"},
+		KeyWarning{Type: PrimaryKeyExpired},
+		KeyWarning{Type: SubkeyDueForRotation, SubkeyId: 0xA},
+	}
+
+	expected := []KeyWarning{
+		KeyWarning{Type: PrimaryKeyExpired},
+		KeyWarning{Type: SubkeyDueForRotation, SubkeyId: 0xA},
+		KeyWarning{Type: SubkeyDueForRotation, SubkeyId: 0xB},
+		KeyWarning{Type: WeakPreferredHashAlgorithms, Detail: "MD5"},
+		KeyWarning{Type: WeakPreferredHashAlgorithms, Detail: "SHA1"},
+	}
+
+	sort.Sort(ByWarningOrder(warnings))
+	assert.Equal(t, expected, warnings)
+}
@@ -0,0 +1,99 @@
+// Copyright 2018 Paul Furley and Ian Drysdale
+//
+// This file is part of Fluidkeys Client which makes it simple to use OpenPGP.
+//
+// Fluidkeys Client is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Fluidkeys Client is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with Fluidkeys Client.  If not, see <https://www.gnu.org/licenses/>.
+
+package status
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	"github.com/fluidkeys/fluidkeys/colour"
+)
+
+// WarningsToCSV writes the warnings for each key (keyed by fingerprint) as
+// CSV, one row per warning, for loading into a spreadsheet.
+//
+// The first row is a header. Keys are written in fingerprint order, and each
+// key's warnings in the order given. The columns are:
+//
+// fingerprint, type, severity, message, subkey_id, days_until_expiry,
+// days_since_expiry
+//
+// subkey_id and the day counts are left empty if they don't apply to the
+// warning.
+func WarningsToCSV(reports map[string][]KeyWarning, w io.Writer) error {
+	csvWriter := csv.NewWriter(w)
+
+	if err := csvWriter.Write(csvHeader); err != nil {
+		return fmt.Errorf("error writing CSV header: %v", err)
+	}
+
+	var fingerprints []string
+	for fingerprint := range reports {
+		fingerprints = append(fingerprints, fingerprint)
+	}
+	sort.Strings(fingerprints)
+
+	for _, fingerprint := range fingerprints {
+		for _, warning := range reports[fingerprint] {
+			if err := csvWriter.Write(makeCSVRow(fingerprint, warning)); err != nil {
+				return fmt.Errorf("error writing CSV row: %v", err)
+			}
+		}
+	}
+
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
+
+func makeCSVRow(fingerprint string, warning KeyWarning) []string {
+	var subkeyId, daysUntilExpiry, daysSinceExpiry string
+
+	if warning.SubkeyId != 0 {
+		subkeyId = fmt.Sprintf("0x%X", warning.SubkeyId)
+	}
+
+	if hasDaysUntilExpiry(warning.Type) {
+		daysUntilExpiry = strconv.FormatUint(uint64(warning.DaysUntilExpiry), 10)
+	}
+	if hasDaysSinceExpiry(warning) {
+		daysSinceExpiry = strconv.FormatUint(uint64(warning.DaysSinceExpiry), 10)
+	}
+
+	return []string{
+		fingerprint,
+		warning.Type.Name(),
+		warning.Severity().String(),
+		colour.StripAllColourCodes(warning.String()),
+		subkeyId,
+		daysUntilExpiry,
+		daysSinceExpiry,
+	}
+}
+
+var csvHeader = []string{
+	"fingerprint",
+	"type",
+	"severity",
+	"message",
+	"subkey_id",
+	"days_until_expiry",
+	"days_since_expiry",
+}
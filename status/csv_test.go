@@ -0,0 +1,48 @@
+package status
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/fluidkeys/fluidkeys/assert"
+)
+
+func TestWarningsToCSV(t *testing.T) {
+	header := "fingerprint,type,severity,message,subkey_id,days_until_expiry,days_since_expiry\n"
+
+	t.Run("with warnings for multiple keys", func(t *testing.T) {
+		subkeyExpiry := time.Date(2018, 6, 1, 0, 0, 0, 0, time.UTC)
+		reports := map[string][]KeyWarning{
+			"BB3C44BF188D56E635F4A092F73D2F0533D7F9D6": []KeyWarning{
+				KeyWarning{Type: SubkeyOverdueForRotation, SubkeyId: 0xCE7881186F55FA9E, DaysUntilExpiry: 5},
+				KeyWarning{Type: WeakPreferredHashAlgorithms, Detail: "SHA1, MD5"},
+			},
+			"7C18DE4DE47813568B243AC8719BD63EF03BDC20": []KeyWarning{
+				KeyWarning{Type: PrimaryKeyExpired, DaysSinceExpiry: 3},
+				KeyWarning{Type: NoValidEncryptionSubkey, DaysSinceExpiry: 3, CurrentValidUntil: &subkeyExpiry},
+				KeyWarning{Type: NoValidEncryptionSubkey},
+			},
+			"5C78E71F6FEFB55829654CC5343CC240D350C30C": []KeyWarning{},
+		}
+
+		expected := header +
+			"7C18DE4DE47813568B243AC8719BD63EF03BDC20,PrimaryKeyExpired,critical,Primary key expired 3 days ago,,,3\n" +
+			"7C18DE4DE47813568B243AC8719BD63EF03BDC20,NoValidEncryptionSubkey,critical,Encryption subkey expired 3 days ago,,,3\n" +
+			"7C18DE4DE47813568B243AC8719BD63EF03BDC20,NoValidEncryptionSubkey,critical,Missing encryption subkey,,,\n" +
+			"BB3C44BF188D56E635F4A092F73D2F0533D7F9D6,SubkeyOverdueForRotation,high,Encryption subkey 0xCE7881186F55FA9E needs rotating now (expires in 5 days),0xCE7881186F55FA9E,5,\n" +
+			"BB3C44BF188D56E635F4A092F73D2F0533D7F9D6,WeakPreferredHashAlgorithms,medium,\"Hash preferences could be stronger (currently: SHA1, MD5)\",,,\n"
+
+		var buf bytes.Buffer
+		err := WarningsToCSV(reports, &buf)
+		assert.ErrorIsNil(t, err)
+		assert.Equal(t, expected, buf.String())
+	})
+
+	t.Run("with no keys writes just the header", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := WarningsToCSV(map[string][]KeyWarning{}, &buf)
+		assert.ErrorIsNil(t, err)
+		assert.Equal(t, header, buf.String())
+	})
+}
@@ -0,0 +1,117 @@
+// Copyright 2018 Paul Furley and Ian Drysdale
+//
+// This file is part of Fluidkeys Client which makes it simple to use OpenPGP.
+//
+// Fluidkeys Client is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Fluidkeys Client is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with Fluidkeys Client.  If not, see <https://www.gnu.org/licenses/>.
+
+package status
+
+import (
+	"sort"
+	"time"
+
+	"github.com/fluidkeys/fluidkeys/pgpkey"
+	"github.com/fluidkeys/fluidkeys/policy"
+)
+
+// KeyEventKind says what happens to a key on a KeyEvent's date.
+type KeyEventKind int
+
+const (
+	// EventDueForRotation is when the key (or subkey) should be rotated.
+	EventDueForRotation KeyEventKind = 1
+
+	// EventOverdueForRotation is when the key becomes overdue for rotation,
+	// having not been rotated once it was due.
+	EventOverdueForRotation KeyEventKind = 2
+
+	// EventExpiry is when the key stops working.
+	EventExpiry KeyEventKind = 3
+)
+
+// String returns a short description of the event, e.g. "expires".
+func (k KeyEventKind) String() string {
+	switch k {
+	case EventDueForRotation:
+		return "due for rotation"
+	case EventOverdueForRotation:
+		return "overdue for rotation"
+	case EventExpiry:
+		return "expires"
+	}
+	return "unknown"
+}
+
+// KeyEvent is something which will happen to a key at a known time.
+type KeyEvent struct {
+	Date time.Time
+	Kind KeyEventKind
+
+	// SubkeyId is the subkey the event is for, or 0 for the primary key.
+	SubkeyId uint64
+}
+
+// UpcomingEvents returns the rotation and expiry events after `now` for the
+// primary key and the encryption subkey, according to the default policy,
+// with the earliest first. Keys (or subkeys) which never expire have no
+// events.
+func UpcomingEvents(key pgpkey.PgpKey, now time.Time) []KeyEvent {
+	return UpcomingEventsWithPolicy(key, policy.Policy{}, now)
+}
+
+// UpcomingEventsWithPolicy is like UpcomingEvents, but the rotation dates
+// come from the given policy (rather than the default).
+func UpcomingEventsWithPolicy(key pgpkey.PgpKey, p policy.Policy, now time.Time) []KeyEvent {
+	events := []KeyEvent{}
+
+	if isPlausibleCreationTime(key.PrimaryKey.CreationTime, now) {
+		if hasExpiry, expiry := getEarliestUidExpiry(key); hasExpiry {
+			events = append(events, makeEvents(*expiry, 0, p, now)...)
+		}
+	}
+
+	encryptionSubkey := getBestEncryptionSubkey(key, now)
+	if encryptionSubkey != nil && isPlausibleCreationTime(encryptionSubkey.PublicKey.CreationTime, now) {
+		if hasExpiry, expiry := pgpkey.SubkeyExpiry(*encryptionSubkey); hasExpiry {
+			events = append(events, makeEvents(*expiry, encryptionSubkey.PublicKey.KeyId, p, now)...)
+		}
+	}
+
+	sort.Stable(ByEventDate(events))
+	return events
+}
+
+// makeEvents returns the events after `now` for a key or subkey which
+// expires at `expiry`.
+func makeEvents(expiry time.Time, subkeyId uint64, p policy.Policy, now time.Time) []KeyEvent {
+	nextRotation := p.NextRotation(expiry)
+
+	var events []KeyEvent
+	for _, event := range []KeyEvent{
+		KeyEvent{Date: nextRotation, Kind: EventDueForRotation, SubkeyId: subkeyId},
+		KeyEvent{Date: p.OverdueRotation(nextRotation), Kind: EventOverdueForRotation, SubkeyId: subkeyId},
+		KeyEvent{Date: expiry, Kind: EventExpiry, SubkeyId: subkeyId},
+	} {
+		if event.Date.After(now) {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+// isPlausibleCreationTime returns false if the expiry can't be worked out
+// from the creation time, see InvalidCreationTime and KeyCreatedInFuture.
+func isPlausibleCreationTime(creationTime time.Time, now time.Time) bool {
+	return pgpkey.IsPlausibleCreationTime(creationTime) && !pgpkey.IsCreatedInFuture(creationTime, now)
+}
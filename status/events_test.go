@@ -0,0 +1,93 @@
+package status
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fluidkeys/fluidkeys/assert"
+	"github.com/fluidkeys/fluidkeys/exampledata"
+	"github.com/fluidkeys/fluidkeys/pgpkey"
+	"github.com/fluidkeys/fluidkeys/policy"
+)
+
+func TestUpcomingEvents(t *testing.T) {
+	// key 14's primary key expires 2030-01-01, its encryption subkey
+	// 0xCEB2953975E4E846 2019-06-01
+	key, err := pgpkey.LoadFromArmoredPublicKey(exampledata.ExamplePublicKey14)
+	if err != nil {
+		t.Fatalf("failed to load example key: %v", err)
+	}
+	const subkeyId = 0xCEB2953975E4E846
+
+	t.Run("returns every event in date order", func(t *testing.T) {
+		now := time.Date(2018, 12, 1, 0, 0, 0, 0, time.UTC)
+		expected := []KeyEvent{
+			KeyEvent{Date: date(2019, 5, 2), Kind: EventDueForRotation, SubkeyId: subkeyId},
+			KeyEvent{Date: date(2019, 5, 12), Kind: EventOverdueForRotation, SubkeyId: subkeyId},
+			KeyEvent{Date: date(2019, 6, 1), Kind: EventExpiry, SubkeyId: subkeyId},
+			KeyEvent{Date: date(2029, 12, 2), Kind: EventDueForRotation},
+			KeyEvent{Date: date(2029, 12, 12), Kind: EventOverdueForRotation},
+			KeyEvent{Date: date(2030, 1, 1), Kind: EventExpiry},
+		}
+		assertEqualEvents(t, expected, UpcomingEvents(*key, now))
+	})
+
+	t.Run("leaves out events which have already happened", func(t *testing.T) {
+		now := time.Date(2019, 5, 5, 0, 0, 0, 0, time.UTC)
+		expected := []KeyEvent{
+			KeyEvent{Date: date(2019, 5, 12), Kind: EventOverdueForRotation, SubkeyId: subkeyId},
+			KeyEvent{Date: date(2019, 6, 1), Kind: EventExpiry, SubkeyId: subkeyId},
+			KeyEvent{Date: date(2029, 12, 2), Kind: EventDueForRotation},
+			KeyEvent{Date: date(2029, 12, 12), Kind: EventOverdueForRotation},
+			KeyEvent{Date: date(2030, 1, 1), Kind: EventExpiry},
+		}
+		assertEqualEvents(t, expected, UpcomingEvents(*key, now))
+	})
+
+	t.Run("with a key created in the future", func(t *testing.T) {
+		futureKey, err := pgpkey.LoadFromArmoredPublicKey(exampledata.ExamplePublicKey15)
+		if err != nil {
+			t.Fatalf("failed to load example key: %v", err)
+		}
+		now := time.Date(2018, 12, 1, 0, 0, 0, 0, time.UTC)
+		assertEqualEvents(t, []KeyEvent{}, UpcomingEvents(*futureKey, now))
+	})
+
+	t.Run("with a custom rotation policy", func(t *testing.T) {
+		p := policy.Policy{RotateDaysBeforeExpiry: 10, OverdueGraceDays: 5}
+		now := time.Date(2018, 12, 1, 0, 0, 0, 0, time.UTC)
+		expected := []KeyEvent{
+			KeyEvent{Date: date(2019, 5, 22), Kind: EventDueForRotation, SubkeyId: subkeyId},
+			KeyEvent{Date: date(2019, 5, 27), Kind: EventOverdueForRotation, SubkeyId: subkeyId},
+			KeyEvent{Date: date(2019, 6, 1), Kind: EventExpiry, SubkeyId: subkeyId},
+			KeyEvent{Date: date(2029, 12, 22), Kind: EventDueForRotation},
+			KeyEvent{Date: date(2029, 12, 27), Kind: EventOverdueForRotation},
+			KeyEvent{Date: date(2030, 1, 1), Kind: EventExpiry},
+		}
+		assertEqualEvents(t, expected, UpcomingEventsWithPolicy(*key, p, now))
+	})
+}
+
+func TestKeyEventKindString(t *testing.T) {
+	assert.Equal(t, "due for rotation", EventDueForRotation.String())
+	assert.Equal(t, "overdue for rotation", EventOverdueForRotation.String())
+	assert.Equal(t, "expires", EventExpiry.String())
+	assert.Equal(t, "unknown", KeyEventKind(0).String())
+}
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func assertEqualEvents(t *testing.T, expected []KeyEvent, got []KeyEvent) {
+	t.Helper()
+	if len(expected) != len(got) {
+		t.Fatalf("expected %d events, got %d: %v", len(expected), len(got), got)
+	}
+	for i := range expected {
+		if !expected[i].Date.Equal(got[i].Date) || expected[i].Kind != got[i].Kind ||
+			expected[i].SubkeyId != got[i].SubkeyId {
+			t.Errorf("event %d: expected %v, got %v", i, expected[i], got[i])
+		}
+	}
+}
@@ -0,0 +1,153 @@
+// Copyright 2018 Paul Furley and Ian Drysdale
+//
+// This file is part of Fluidkeys Client which makes it simple to use OpenPGP.
+//
+// Fluidkeys Client is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Fluidkeys Client is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with Fluidkeys Client.  If not, see <https://www.gnu.org/licenses/>.
+
+package status
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fluidkeys/fluidkeys/colour"
+)
+
+// jsonKeyWarning is the JSON representation of a KeyWarning. Its fields are
+// part of a stable schema for scripts, so don't rename or remove them.
+type jsonKeyWarning struct {
+	Type              string     `json:"type"`     // e.g. "SubkeyOverdueForRotation"
+	Severity          string     `json:"severity"` // e.g. "high"
+	Message           string     `json:"message"`
+	SubkeyId          string     `json:"subkey_id,omitempty"` // e.g. "0xCE7881186F55FA9E"
+	UserId            string     `json:"user_id,omitempty"`
+	Detail            string     `json:"detail,omitempty"`
+	DaysUntilExpiry   *uint      `json:"days_until_expiry,omitempty"`
+	DaysSinceExpiry   *uint      `json:"days_since_expiry,omitempty"`
+	CurrentValidUntil *time.Time `json:"current_valid_until,omitempty"`
+	SubkeyValidUntil  *time.Time `json:"subkey_valid_until,omitempty"`
+	Escalated         bool       `json:"escalated,omitempty"`
+}
+
+// WarningsToJSON returns the warnings as an indented JSON array, see
+// KeyWarning.MarshalJSON.
+func WarningsToJSON(warnings []KeyWarning) ([]byte, error) {
+	if warnings == nil {
+		warnings = []KeyWarning{} // `[]` rather than `null`
+	}
+	return marshalWithoutEscapingHTML(warnings, "  ")
+}
+
+// MarshalJSON returns the warning as a JSON object, with the type as its
+// name (e.g. "SubkeyOverdueForRotation") rather than a number, and the
+// subkey ID in hex. days_until_expiry and days_since_expiry are only present
+// for warning types where they apply.
+func (w KeyWarning) MarshalJSON() ([]byte, error) {
+	output := jsonKeyWarning{
+		Type:              w.Type.Name(),
+		Severity:          w.Severity().String(),
+		Message:           colour.StripAllColourCodes(w.String()),
+		UserId:            w.UserId,
+		Detail:            w.Detail,
+		CurrentValidUntil: w.CurrentValidUntil,
+		SubkeyValidUntil:  w.SubkeyValidUntil,
+		Escalated:         w.Escalated,
+	}
+
+	if w.SubkeyId != 0 {
+		output.SubkeyId = fmt.Sprintf("0x%X", w.SubkeyId)
+	}
+	if hasDaysUntilExpiry(w.Type) {
+		days := w.DaysUntilExpiry
+		output.DaysUntilExpiry = &days
+	}
+	if hasDaysSinceExpiry(w) {
+		days := w.DaysSinceExpiry
+		output.DaysSinceExpiry = &days
+	}
+	return marshalWithoutEscapingHTML(output, "")
+}
+
+// UnmarshalJSON reads a warning written by MarshalJSON. The severity and
+// message are ignored since they're derived from the other fields.
+func (w *KeyWarning) UnmarshalJSON(data []byte) error {
+	var input jsonKeyWarning
+	if err := json.Unmarshal(data, &input); err != nil {
+		return err
+	}
+
+	warningType, ok := warningTypeFromName(input.Type)
+	if !ok {
+		return fmt.Errorf("unknown warning type %q", input.Type)
+	}
+
+	var subkeyId uint64
+	if input.SubkeyId != "" {
+		var err error
+		subkeyId, err = strconv.ParseUint(strings.TrimPrefix(input.SubkeyId, "0x"), 16, 64)
+		if err != nil {
+			return fmt.Errorf("invalid subkey_id %q: %v", input.SubkeyId, err)
+		}
+	}
+
+	*w = KeyWarning{
+		Type:              warningType,
+		SubkeyId:          subkeyId,
+		UserId:            input.UserId,
+		Detail:            input.Detail,
+		CurrentValidUntil: input.CurrentValidUntil,
+		SubkeyValidUntil:  input.SubkeyValidUntil,
+		Escalated:         input.Escalated,
+	}
+	if input.DaysUntilExpiry != nil {
+		w.DaysUntilExpiry = *input.DaysUntilExpiry
+	}
+	if input.DaysSinceExpiry != nil {
+		w.DaysSinceExpiry = *input.DaysSinceExpiry
+	}
+	return nil
+}
+
+// marshalWithoutEscapingHTML is like json.Marshal (or json.MarshalIndent if
+// indent isn't empty), but leaves characters such as `<` alone, since user
+// IDs are usually of the form "Name <email@example.com>".
+func marshalWithoutEscapingHTML(v interface{}, indent string) ([]byte, error) {
+	buf := bytes.Buffer{}
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", indent)
+
+	if err := encoder.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// warningTypeFromName returns the WarningType whose Name is `name`. It relies
+// on the types being numbered consecutively from 1.
+func warningTypeFromName(name string) (WarningType, bool) {
+	for t := WarningType(1); ; t++ {
+		typeName := t.Name()
+		if typeName == fmt.Sprintf("WarningType(%d)", int(t)) {
+			return UnsetType, false // past the last type
+		}
+		if typeName == name {
+			return t, true
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package status
+
+import (
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fluidkeys/fluidkeys/assert"
+)
+
+var updateGolden = flag.Bool("update", false, "update the golden files in testdata")
+
+func TestWarningsToJSON(t *testing.T) {
+	expiry := time.Date(2018, 10, 15, 12, 0, 0, 0, time.UTC)
+	subkeyExpiry := time.Date(2018, 6, 1, 0, 0, 0, 0, time.UTC)
+	warnings := []KeyWarning{
+		KeyWarning{Type: PrimaryKeyExpired, DaysSinceExpiry: 3, CurrentValidUntil: &expiry},
+		KeyWarning{Type: SubkeyOverdueForRotation, SubkeyId: 0xCE7881186F55FA9E, DaysUntilExpiry: 0},
+		KeyWarning{Type: WeakSelfSignatureHash, UserId: "<test@example.com>", Detail: "SHA1"},
+		KeyWarning{Type: PrimaryKeyNoExpiry, Escalated: true},
+		KeyWarning{Type: MismatchedExpiryDates, SubkeyId: 0xCE7881186F55FA9E, CurrentValidUntil: &expiry, SubkeyValidUntil: &subkeyExpiry},
+		KeyWarning{Type: NoValidEncryptionSubkey, SubkeyId: 0xCE7881186F55FA9E, DaysSinceExpiry: 3, CurrentValidUntil: &subkeyExpiry},
+		KeyWarning{Type: ExpiringSoon, DaysUntilExpiry: 5},
+	}
+
+	t.Run("matches golden file", func(t *testing.T) {
+		got, err := WarningsToJSON(warnings)
+		assert.ErrorIsNil(t, err)
+
+		goldenFilename := filepath.Join("testdata", "warnings.golden.json")
+		if *updateGolden {
+			if err := ioutil.WriteFile(goldenFilename, got, 0644); err != nil {
+				t.Fatalf("failed to update golden file: %v", err)
+			}
+		}
+
+		expected, err := ioutil.ReadFile(goldenFilename)
+		if err != nil {
+			t.Fatalf("failed to read golden file: %v", err)
+		}
+		assert.Equal(t, string(expected), string(got))
+	})
+
+	t.Run("round trips", func(t *testing.T) {
+		encoded, err := WarningsToJSON(warnings)
+		assert.ErrorIsNil(t, err)
+
+		var decoded []KeyWarning
+		assert.ErrorIsNil(t, json.Unmarshal(encoded, &decoded))
+		assert.Equal(t, warnings, decoded)
+	})
+
+	t.Run("no warnings gives an empty array", func(t *testing.T) {
+		got, err := WarningsToJSON(nil)
+		assert.ErrorIsNil(t, err)
+		assert.Equal(t, "[]", string(got))
+	})
+}
+
+func TestKeyWarningJSONRoundTripsEveryType(t *testing.T) {
+	for warningType := WarningType(1); warningType <= lastWarningType; warningType++ {
+		t.Run(warningType.Name(), func(t *testing.T) {
+			warning := KeyWarning{Type: warningType, SubkeyId: 0xABCD}
+
+			encoded, err := json.Marshal(warning)
+			assert.ErrorIsNil(t, err)
+
+			var decoded KeyWarning
+			assert.ErrorIsNil(t, json.Unmarshal(encoded, &decoded))
+			assert.Equal(t, warning, decoded)
+		})
+	}
+}
+
+func TestKeyWarningUnmarshalJSONErrors(t *testing.T) {
+	var tests = []struct {
+		name  string
+		input string
+	}{
+		{"unknown type", `{"type": "NotAWarning"}`},
+		{"invalid subkey id", `{"type": "SubkeyDueForRotation", "subkey_id": "0xZZZZ"}`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var warning KeyWarning
+			if err := json.Unmarshal([]byte(test.input), &warning); err == nil {
+				t.Fatalf("expected an error, got %v", warning)
+			}
+		})
+	}
+}
@@ -18,10 +18,12 @@
 package status
 
 import (
+	"crypto/sha256"
 	"fmt"
 	"time"
 
 	"github.com/fluidkeys/fluidkeys/colour"
+	"github.com/fluidkeys/fluidkeys/fingerprint"
 )
 
 type WarningType int
@@ -60,16 +62,327 @@ const (
 	ConfigMaintainAutomaticallyNotSet         = 22
 	ConfigPublishToAPINotSet                  = 23
 	ConfigMaintainAutomaticallyButDontPublish = 24
+
+	KeyCannotEncrypt = 25
+
+	InvalidCreationTime = 26
+
+	PrimaryKeyCannotSign = 27
+
+	KeyFromVulnerablePeriod = 28
+
+	EncryptionBrokenSigningIntact = 29
+
+	MissingDesignatedRevoker = 30
+
+	RotationDueOnNonBusinessDay = 31
+
+	SelfSigHashBelowPreferences = 32
+
+	RoleCapabilityMismatch = 33
+
+	KeyBelowMinimumStrength = 34
+
+	ExpiryDrivenBySecondaryUid = 35
+
+	PrimaryKeyWeakCipher = 36
+
+	NoValidSigningSubkey            = 37
+	SigningSubkeyDueForRotation     = 38
+	SigningSubkeyOverdueForRotation = 39
+	SigningSubkeyNoExpiry           = 40
+
+	EncryptionSubkeyRevoked = 41
+
+	PrimaryKeyNoValidUserId = 42
+
+	PrimaryKeyUsedForEncryption = 43
+
+	ExpiringSoon = 44
+
+	PrimaryKeyNoUserId = 45
+
+	SubkeyCombinedEncryptAndSign = 46
+
+	MismatchedExpiryDates = 47
+
+	KeyCreatedInFuture = 48
+
+	PrimaryKeyRevoked = 49
+
+	// lastWarningType is the highest WarningType, see AllWarningTypes.
+	lastWarningType = PrimaryKeyRevoked
+)
+
+// Severity indicates how urgently a KeyWarning needs dealing with. A higher
+// value is more severe.
+type Severity int
+
+const (
+	// SeverityInfo is for purely informational advisories which never
+	// count towards a key needing attention. See KeyNeedsAttention.
+	SeverityInfo     Severity = 0
+	SeverityLow      Severity = 1
+	SeverityMedium   Severity = 2
+	SeverityHigh     Severity = 3
+	SeverityCritical Severity = 4
+)
+
+// String returns the name of the severity, for example "high"
+func (s Severity) String() string {
+	switch s {
+	case SeverityInfo:
+		return "info"
+	case SeverityLow:
+		return "low"
+	case SeverityMedium:
+		return "medium"
+	case SeverityHigh:
+		return "high"
+	case SeverityCritical:
+		return "critical"
+	}
+	return fmt.Sprintf("Severity(%d)", int(s))
+}
+
+// Name returns the name of the warning type as it appears in the code, for
+// example "SubkeyOverdueForRotation", for use in logs and machine-readable
+// output.
+func (t WarningType) Name() string {
+	switch t {
+	case UnsetType:
+		return "UnsetType"
+	case PrimaryKeyDueForRotation:
+		return "PrimaryKeyDueForRotation"
+	case PrimaryKeyOverdueForRotation:
+		return "PrimaryKeyOverdueForRotation"
+	case PrimaryKeyExpired:
+		return "PrimaryKeyExpired"
+	case PrimaryKeyNoExpiry:
+		return "PrimaryKeyNoExpiry"
+	case PrimaryKeyLongExpiry:
+		return "PrimaryKeyLongExpiry"
+	case NoValidEncryptionSubkey:
+		return "NoValidEncryptionSubkey"
+	case SubkeyDueForRotation:
+		return "SubkeyDueForRotation"
+	case SubkeyOverdueForRotation:
+		return "SubkeyOverdueForRotation"
+	case SubkeyNoExpiry:
+		return "SubkeyNoExpiry"
+	case SubkeyLongExpiry:
+		return "SubkeyLongExpiry"
+	case MissingPreferredSymmetricAlgorithms:
+		return "MissingPreferredSymmetricAlgorithms"
+	case WeakPreferredSymmetricAlgorithms:
+		return "WeakPreferredSymmetricAlgorithms"
+	case UnsupportedPreferredSymmetricAlgorithm:
+		return "UnsupportedPreferredSymmetricAlgorithm"
+	case MissingPreferredHashAlgorithms:
+		return "MissingPreferredHashAlgorithms"
+	case WeakPreferredHashAlgorithms:
+		return "WeakPreferredHashAlgorithms"
+	case UnsupportedPreferredHashAlgorithm:
+		return "UnsupportedPreferredHashAlgorithm"
+	case MissingPreferredCompressionAlgorithms:
+		return "MissingPreferredCompressionAlgorithms"
+	case UnsupportedPreferredCompressionAlgorithm:
+		return "UnsupportedPreferredCompressionAlgorithm"
+	case MissingUncompressedPreference:
+		return "MissingUncompressedPreference"
+	case WeakSelfSignatureHash:
+		return "WeakSelfSignatureHash"
+	case WeakSubkeyBindingSignatureHash:
+		return "WeakSubkeyBindingSignatureHash"
+	case ConfigMaintainAutomaticallyNotSet:
+		return "ConfigMaintainAutomaticallyNotSet"
+	case ConfigPublishToAPINotSet:
+		return "ConfigPublishToAPINotSet"
+	case ConfigMaintainAutomaticallyButDontPublish:
+		return "ConfigMaintainAutomaticallyButDontPublish"
+	case KeyCannotEncrypt:
+		return "KeyCannotEncrypt"
+	case InvalidCreationTime:
+		return "InvalidCreationTime"
+	case PrimaryKeyCannotSign:
+		return "PrimaryKeyCannotSign"
+	case KeyFromVulnerablePeriod:
+		return "KeyFromVulnerablePeriod"
+	case EncryptionBrokenSigningIntact:
+		return "EncryptionBrokenSigningIntact"
+	case MissingDesignatedRevoker:
+		return "MissingDesignatedRevoker"
+	case RotationDueOnNonBusinessDay:
+		return "RotationDueOnNonBusinessDay"
+	case SelfSigHashBelowPreferences:
+		return "SelfSigHashBelowPreferences"
+	case RoleCapabilityMismatch:
+		return "RoleCapabilityMismatch"
+	case KeyBelowMinimumStrength:
+		return "KeyBelowMinimumStrength"
+	case ExpiryDrivenBySecondaryUid:
+		return "ExpiryDrivenBySecondaryUid"
+	case PrimaryKeyWeakCipher:
+		return "PrimaryKeyWeakCipher"
+	case NoValidSigningSubkey:
+		return "NoValidSigningSubkey"
+	case SigningSubkeyDueForRotation:
+		return "SigningSubkeyDueForRotation"
+	case SigningSubkeyOverdueForRotation:
+		return "SigningSubkeyOverdueForRotation"
+	case SigningSubkeyNoExpiry:
+		return "SigningSubkeyNoExpiry"
+	case EncryptionSubkeyRevoked:
+		return "EncryptionSubkeyRevoked"
+	case PrimaryKeyNoValidUserId:
+		return "PrimaryKeyNoValidUserId"
+	case PrimaryKeyUsedForEncryption:
+		return "PrimaryKeyUsedForEncryption"
+	case ExpiringSoon:
+		return "ExpiringSoon"
+	case PrimaryKeyNoUserId:
+		return "PrimaryKeyNoUserId"
+	case SubkeyCombinedEncryptAndSign:
+		return "SubkeyCombinedEncryptAndSign"
+	case MismatchedExpiryDates:
+		return "MismatchedExpiryDates"
+	case KeyCreatedInFuture:
+		return "KeyCreatedInFuture"
+	case PrimaryKeyRevoked:
+		return "PrimaryKeyRevoked"
+	}
+	return fmt.Sprintf("WarningType(%d)", int(t))
+}
+
+// AllWarningTypes returns every WarningType except UnsetType, in order, for
+// example to count warnings of each type for metrics.
+func AllWarningTypes() []WarningType {
+	var types []WarningType
+	for t := WarningType(1); t <= lastWarningType; t++ {
+		types = append(types, t)
+	}
+	return types
+}
+
+// WarningCategory groups warning types by what they're about, for example
+// to count warnings by category on a dashboard.
+type WarningCategory int
+
+const (
+	UnsetCategory WarningCategory = 0
+
+	// CategoryExpiry is for keys which have expired, are about to, or
+	// have the wrong expiry set.
+	CategoryExpiry WarningCategory = 1
+
+	// CategoryRotation is for keys or subkeys which are due for rotation.
+	CategoryRotation WarningCategory = 2
+
+	// CategoryCipher is for weak algorithms, hashes and preferences.
+	CategoryCipher WarningCategory = 3
+
+	// CategoryStructure is for problems with how the key is put together,
+	// such as missing subkeys, user IDs or capabilities, and with
+	// Fluidkeys' configuration for the key.
+	CategoryStructure WarningCategory = 4
 )
 
+// String returns the name of the category, for example "rotation"
+func (c WarningCategory) String() string {
+	switch c {
+	case CategoryExpiry:
+		return "expiry"
+	case CategoryRotation:
+		return "rotation"
+	case CategoryCipher:
+		return "cipher"
+	case CategoryStructure:
+		return "structure"
+	}
+	return "unset"
+}
+
+// Category returns which WarningCategory the warning type belongs to, or
+// UnsetCategory for UnsetType and unknown types.
+func (t WarningType) Category() WarningCategory {
+	switch t {
+	case PrimaryKeyExpired,
+		PrimaryKeyNoExpiry,
+		PrimaryKeyLongExpiry,
+		SubkeyNoExpiry,
+		SubkeyLongExpiry,
+		EncryptionBrokenSigningIntact,
+		ExpiryDrivenBySecondaryUid,
+		SigningSubkeyNoExpiry,
+		ExpiringSoon,
+		MismatchedExpiryDates:
+		return CategoryExpiry
+
+	case PrimaryKeyDueForRotation,
+		PrimaryKeyOverdueForRotation,
+		SubkeyDueForRotation,
+		SubkeyOverdueForRotation,
+		RotationDueOnNonBusinessDay,
+		SigningSubkeyDueForRotation,
+		SigningSubkeyOverdueForRotation:
+		return CategoryRotation
+
+	case MissingPreferredSymmetricAlgorithms,
+		WeakPreferredSymmetricAlgorithms,
+		UnsupportedPreferredSymmetricAlgorithm,
+		MissingPreferredHashAlgorithms,
+		WeakPreferredHashAlgorithms,
+		UnsupportedPreferredHashAlgorithm,
+		MissingPreferredCompressionAlgorithms,
+		UnsupportedPreferredCompressionAlgorithm,
+		MissingUncompressedPreference,
+		WeakSelfSignatureHash,
+		WeakSubkeyBindingSignatureHash,
+		KeyFromVulnerablePeriod,
+		SelfSigHashBelowPreferences,
+		KeyBelowMinimumStrength,
+		PrimaryKeyWeakCipher:
+		return CategoryCipher
+
+	case NoValidEncryptionSubkey,
+		ConfigMaintainAutomaticallyNotSet,
+		ConfigPublishToAPINotSet,
+		ConfigMaintainAutomaticallyButDontPublish,
+		KeyCannotEncrypt,
+		InvalidCreationTime,
+		KeyCreatedInFuture,
+		PrimaryKeyCannotSign,
+		MissingDesignatedRevoker,
+		RoleCapabilityMismatch,
+		NoValidSigningSubkey,
+		EncryptionSubkeyRevoked,
+		PrimaryKeyNoValidUserId,
+		PrimaryKeyUsedForEncryption,
+		PrimaryKeyNoUserId,
+		SubkeyCombinedEncryptAndSign,
+		PrimaryKeyRevoked:
+		return CategoryStructure
+	}
+	return UnsetCategory
+}
+
 type KeyWarning struct {
 	Type WarningType
 
 	SubkeyId          uint64
+	UserId            string
 	DaysUntilExpiry   uint
 	DaysSinceExpiry   uint
 	CurrentValidUntil *time.Time
 	Detail            string
+
+	// SubkeyValidUntil is the encryption subkey's expiry, for warnings
+	// which compare it with the primary key's (in CurrentValidUntil).
+	SubkeyValidUntil *time.Time
+
+	// Escalated is true if a policy treats this warning as critical,
+	// whatever its type. See policy.Policy.
+	Escalated bool
 }
 
 func (w KeyWarning) String() string {
@@ -93,12 +406,19 @@ func (w KeyWarning) String() string {
 		return "Primary key expires too far in the future"
 
 	case NoValidEncryptionSubkey:
+		if w.CurrentValidUntil != nil {
+			return colour.Danger("Encryption subkey " + relativeExpiryDate(w.DaysSinceExpiry))
+		}
 		return colour.Danger("Missing encryption subkey")
 
 	case SubkeyDueForRotation:
 		return "Encryption subkey needs rotating"
 
 	case SubkeyOverdueForRotation:
+		if w.SubkeyId != 0 {
+			return colour.Danger(fmt.Sprintf("Encryption subkey 0x%X needs rotating now (%s)",
+				w.SubkeyId, countdownUntilExpiry(w.DaysUntilExpiry)))
+		}
 		return colour.Danger("Encryption subkey needs rotating now (" + countdownUntilExpiry(w.DaysUntilExpiry) + ")")
 
 	case SubkeyNoExpiry:
@@ -135,9 +455,15 @@ func (w KeyWarning) String() string {
 		return fmt.Sprintf("Fluidkeys doesn't support %s compression", w.Detail)
 
 	case WeakSelfSignatureHash:
+		if w.UserId != "" {
+			return fmt.Sprintf("Weak hash %s used for self signature on %s", w.Detail, w.UserId)
+		}
 		return fmt.Sprintf("Weak hash %s used for self signature", w.Detail)
 
 	case WeakSubkeyBindingSignatureHash:
+		if w.SubkeyId != 0 {
+			return fmt.Sprintf("Weak hash %s used for binding signature of subkey 0x%X", w.Detail, w.SubkeyId)
+		}
 		return fmt.Sprintf("Weak hash %s used for subkey binding signature", w.Detail)
 
 	case ConfigMaintainAutomaticallyNotSet:
@@ -148,11 +474,243 @@ func (w KeyWarning) String() string {
 
 	case ConfigMaintainAutomaticallyButDontPublish:
 		return "Key maintained automatically but not uploaded, unable to receive secrets"
+
+	case KeyCannotEncrypt:
+		return colour.Danger("Key can't be used for encryption")
+
+	case InvalidCreationTime:
+		if w.SubkeyId != 0 {
+			return fmt.Sprintf("Subkey 0x%X has an invalid creation time", w.SubkeyId)
+		}
+		return "Primary key has an invalid creation time"
+
+	case KeyCreatedInFuture:
+		if w.SubkeyId != 0 {
+			return fmt.Sprintf("Subkey 0x%X was created in the future", w.SubkeyId)
+		}
+		return "Primary key was created in the future"
+
+	case PrimaryKeyCannotSign:
+		return colour.Danger("Primary key can't sign, create a new key")
+
+	case KeyFromVulnerablePeriod:
+		if w.SubkeyId != 0 {
+			return colour.Danger(fmt.Sprintf(
+				"Subkey 0x%X was generated by vulnerable software, see %s", w.SubkeyId, rocaURL))
+		}
+		return colour.Danger("Primary key was generated by vulnerable software, see " + rocaURL)
+
+	case EncryptionBrokenSigningIntact:
+		return colour.Danger("Encryption subkey has expired (signing still works)")
+
+	case MissingDesignatedRevoker:
+		return "Key doesn't designate a revoker, required by policy"
+
+	case RotationDueOnNonBusinessDay:
+		if w.SubkeyId != 0 {
+			return "Encryption subkey rotation falls on a non-working day, rotate by " + w.Detail
+		}
+		return "Primary key rotation falls on a non-working day, rotate by " + w.Detail
+
+	case SelfSigHashBelowPreferences:
+		if w.SubkeyId != 0 {
+			return fmt.Sprintf("Subkey binding signature uses %s, re-sign to match hash preferences", w.Detail)
+		}
+		return fmt.Sprintf("Self signature uses %s, re-sign to match hash preferences", w.Detail)
+
+	case RoleCapabilityMismatch:
+		return colour.Danger("Key isn't suited for " + w.Detail)
+
+	case KeyBelowMinimumStrength:
+		if w.SubkeyId != 0 {
+			return fmt.Sprintf("Subkey 0x%X is too weak (%s)", w.SubkeyId, w.Detail)
+		}
+		return fmt.Sprintf("Primary key is too weak (%s)", w.Detail)
+
+	case ExpiryDrivenBySecondaryUid:
+		if w.CurrentValidUntil == nil {
+			return fmt.Sprintf("Key expiry is set by user ID %s, consider revoking it", w.Detail)
+		}
+		return fmt.Sprintf("Key expiry is set by user ID %s (expires %s), consider revoking it",
+			w.Detail, w.CurrentValidUntil.Format("2 January 2006"))
+
+	case PrimaryKeyWeakCipher:
+		return colour.Danger(fmt.Sprintf("Primary key is %s, which is considered weak", w.Detail))
+
+	case NoValidSigningSubkey:
+		return colour.Danger("Missing signing subkey")
+
+	case SigningSubkeyDueForRotation:
+		return "Signing subkey needs rotating"
+
+	case SigningSubkeyOverdueForRotation:
+		return colour.Danger("Signing subkey needs rotating now (" + countdownUntilExpiry(w.DaysUntilExpiry) + ")")
+
+	case SigningSubkeyNoExpiry:
+		return "Signing subkey never expires"
+
+	case EncryptionSubkeyRevoked:
+		return colour.Danger("Encryption subkey has been revoked")
+
+	case PrimaryKeyNoValidUserId:
+		return colour.Danger("All user IDs have been revoked")
+
+	case PrimaryKeyUsedForEncryption:
+		return "Primary key is used for encryption, add an encryption subkey"
+
+	case ExpiringSoon:
+		if w.SubkeyId != 0 {
+			return colour.Danger("Encryption subkey " + countdownUntilExpiry(w.DaysUntilExpiry))
+		}
+		return colour.Danger("Primary key " + countdownUntilExpiry(w.DaysUntilExpiry))
+
+	case PrimaryKeyNoUserId:
+		return colour.Danger("Key has no user ID")
+
+	case PrimaryKeyRevoked:
+		if w.Detail != "" {
+			return colour.Danger("Key has been revoked: " + w.Detail)
+		}
+		return colour.Danger("Key has been revoked")
+
+	case SubkeyCombinedEncryptAndSign:
+		return "Encryption subkey is also used for signing"
+
+	case MismatchedExpiryDates:
+		if w.CurrentValidUntil == nil || w.SubkeyValidUntil == nil {
+			return "Primary key and encryption subkey expire far apart"
+		}
+		return fmt.Sprintf("Primary key expires %s but encryption subkey expires %s",
+			w.CurrentValidUntil.Format("2 January 2006"), w.SubkeyValidUntil.Format("2 January 2006"))
 	}
 
-	return fmt.Sprintf("KeyWarning{Type=%d}", w.Type)
+	return fmt.Sprintf("Unknown key warning (type %d)", w.Type)
 }
 
+// ID returns an identifier for the warning on the key with the given
+// fingerprint, for example "a3f0c2d1e4b5f6a7". The same problem on the same
+// key (or subkey, or user ID) gets the same ID every time the key is
+// checked, so it can be used to remember that a warning has been
+// acknowledged.
+//
+// The ID doesn't depend on the current time, so for example a
+// SubkeyOverdueForRotation warning keeps its ID as the expiry approaches.
+func (w KeyWarning) ID(fp fingerprint.Fingerprint) string {
+	identity := fmt.Sprintf("%s:%s:%X", fp.Hex(), w.Type.Name(), w.SubkeyId)
+	if w.UserId != "" {
+		identity += ":" + w.UserId
+	}
+
+	switch w.Type {
+	case UnsupportedPreferredSymmetricAlgorithm,
+		UnsupportedPreferredHashAlgorithm,
+		UnsupportedPreferredCompressionAlgorithm,
+		WeakSelfSignatureHash,
+		WeakSubkeyBindingSignatureHash:
+		// Detail names the offending algorithm, so each one is a
+		// separate problem. For other types (e.g. weak preferences) it
+		// describes the current state, which can change without the
+		// problem being fixed.
+		identity += ":" + w.Detail
+	}
+
+	hash := sha256.Sum256([]byte(identity))
+	return fmt.Sprintf("%x", hash[:8])
+}
+
+// Severity returns how urgently the warning needs dealing with:
+//
+// * Critical: the key is (or is about to become) unusable
+// * High: the key needs attention soon, or is cryptographically weak
+// * Medium: the key doesn't follow best practice
+// * Low: minor or configuration issues
+// * Info: cosmetic advisories that don't affect how the key works. These are
+//   the compression preference warnings: MissingPreferredCompressionAlgorithms,
+//   UnsupportedPreferredCompressionAlgorithm and MissingUncompressedPreference,
+//   and the RotationDueOnNonBusinessDay scheduling hint
+func (w KeyWarning) Severity() Severity {
+	if w.Escalated {
+		return SeverityCritical
+	}
+
+	switch w.Type {
+	case PrimaryKeyExpired,
+		NoValidEncryptionSubkey,
+		KeyCannotEncrypt,
+		PrimaryKeyCannotSign,
+		KeyFromVulnerablePeriod,
+		EncryptionBrokenSigningIntact,
+		RoleCapabilityMismatch,
+		EncryptionSubkeyRevoked,
+		PrimaryKeyNoValidUserId,
+		PrimaryKeyNoUserId,
+		PrimaryKeyRevoked:
+		return SeverityCritical
+
+	case PrimaryKeyOverdueForRotation,
+		SubkeyOverdueForRotation,
+		InvalidCreationTime,
+		KeyCreatedInFuture,
+		WeakSelfSignatureHash,
+		WeakSubkeyBindingSignatureHash,
+		KeyBelowMinimumStrength,
+		PrimaryKeyWeakCipher,
+		NoValidSigningSubkey,
+		SigningSubkeyOverdueForRotation,
+		ExpiringSoon:
+		return SeverityHigh
+
+	case PrimaryKeyDueForRotation,
+		PrimaryKeyNoExpiry,
+		PrimaryKeyLongExpiry,
+		SubkeyDueForRotation,
+		SubkeyNoExpiry,
+		SubkeyLongExpiry,
+		WeakPreferredSymmetricAlgorithms,
+		WeakPreferredHashAlgorithms,
+		MissingDesignatedRevoker,
+		SigningSubkeyDueForRotation,
+		PrimaryKeyUsedForEncryption,
+		SubkeyCombinedEncryptAndSign,
+		SigningSubkeyNoExpiry:
+		return SeverityMedium
+
+	case MissingPreferredCompressionAlgorithms,
+		UnsupportedPreferredCompressionAlgorithm,
+		MissingUncompressedPreference,
+		RotationDueOnNonBusinessDay:
+		return SeverityInfo
+	}
+
+	return SeverityLow
+}
+
+// hasDaysUntilExpiry returns true if warnings of the given type set
+// DaysUntilExpiry.
+func hasDaysUntilExpiry(t WarningType) bool {
+	switch t {
+	case PrimaryKeyOverdueForRotation, SubkeyOverdueForRotation, SigningSubkeyOverdueForRotation, ExpiringSoon:
+		return true
+	}
+	return false
+}
+
+// hasDaysSinceExpiry returns true if the warning sets DaysSinceExpiry.
+// NoValidEncryptionSubkey only does if there's an expired encryption subkey,
+// shown by CurrentValidUntil.
+func hasDaysSinceExpiry(w KeyWarning) bool {
+	switch w.Type {
+	case PrimaryKeyExpired:
+		return true
+	case NoValidEncryptionSubkey:
+		return w.CurrentValidUntil != nil
+	}
+	return false
+}
+
+// rocaURL explains the ROCA vulnerability (CVE-2017-15361)
+const rocaURL = "https://roca.crocs.fi.muni.cz"
+
 func countdownUntilExpiry(days uint) string {
 	switch days {
 	case 0:
@@ -170,9 +728,7 @@ func relativeExpiryDate(days uint) string {
 		return "expired today"
 	case 1:
 		return "expired yesterday"
-	case 2, 3, 4, 5, 6, 7, 8, 9:
-		return fmt.Sprintf("expired %d days ago", days)
 	default:
-		return "has expired"
+		return fmt.Sprintf("expired %d days ago", days)
 	}
 }
@@ -2,18 +2,30 @@ package status
 
 import (
 	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/fluidkeys/fluidkeys/assert"
 	"github.com/fluidkeys/fluidkeys/colour"
+	"github.com/fluidkeys/fluidkeys/exampledata"
+	"github.com/fluidkeys/fluidkeys/fingerprint"
 )
 
-// TestString tests only the strings with arguments
+// TestString tests the message for every warning type
 func TestString(t *testing.T) {
+	exampleExpiry := time.Date(2018, 10, 15, 12, 0, 0, 0, time.UTC)
+	primaryExpiry := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	subkeyExpiry := time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC)
+
 	var tests = []struct {
 		warning        KeyWarning
 		expectedOutput string
 	}{
+		{
+			KeyWarning{Type: PrimaryKeyDueForRotation},
+			"Primary key needs extending",
+		},
 		{
 			KeyWarning{Type: PrimaryKeyOverdueForRotation, DaysUntilExpiry: 5},
 			colour.Danger("Primary key needs extending now (expires in 5 days)"),
@@ -26,10 +38,58 @@ func TestString(t *testing.T) {
 			KeyWarning{Type: PrimaryKeyOverdueForRotation, DaysUntilExpiry: 0},
 			colour.Danger("Primary key needs extending now (expires today!)"),
 		},
+		{
+			KeyWarning{Type: PrimaryKeyNoExpiry},
+			"Primary key never expires",
+		},
+		{
+			KeyWarning{Type: PrimaryKeyLongExpiry},
+			"Primary key expires too far in the future",
+		},
+		{
+			KeyWarning{Type: NoValidEncryptionSubkey},
+			colour.Danger("Missing encryption subkey"),
+		},
+		{
+			KeyWarning{Type: NoValidEncryptionSubkey, DaysSinceExpiry: 3, CurrentValidUntil: &exampleExpiry},
+			colour.Danger("Encryption subkey expired 3 days ago"),
+		},
+		{
+			KeyWarning{Type: SubkeyDueForRotation},
+			"Encryption subkey needs rotating",
+		},
 		{
 			KeyWarning{Type: SubkeyOverdueForRotation, DaysUntilExpiry: 5},
 			colour.Danger("Encryption subkey needs rotating now (expires in 5 days)"),
 		},
+		{
+			KeyWarning{Type: SubkeyOverdueForRotation, SubkeyId: 0xABCD, DaysUntilExpiry: 1},
+			colour.Danger("Encryption subkey 0xABCD needs rotating now (expires tomorrow!)"),
+		},
+		{
+			KeyWarning{Type: SubkeyNoExpiry},
+			"Encryption subkey never expires",
+		},
+		{
+			KeyWarning{Type: SubkeyLongExpiry},
+			"Encryption subkey expires too far in the future",
+		},
+		{
+			KeyWarning{Type: MissingPreferredSymmetricAlgorithms},
+			"Missing cipher preferences",
+		},
+		{
+			KeyWarning{Type: MissingPreferredHashAlgorithms},
+			"Missing hash preferences",
+		},
+		{
+			KeyWarning{Type: MissingPreferredCompressionAlgorithms},
+			"Missing compression preferences",
+		},
+		{
+			KeyWarning{Type: MissingUncompressedPreference},
+			"Key does not support uncompressed data",
+		},
 		{
 			KeyWarning{Type: PrimaryKeyExpired, DaysSinceExpiry: 0},
 			colour.Danger("Primary key expired today"),
@@ -44,7 +104,11 @@ func TestString(t *testing.T) {
 		},
 		{
 			KeyWarning{Type: PrimaryKeyExpired, DaysSinceExpiry: 10},
-			colour.Danger("Primary key has expired"),
+			colour.Danger("Primary key expired 10 days ago"),
+		},
+		{
+			KeyWarning{Type: NoValidEncryptionSubkey, DaysSinceExpiry: 12, CurrentValidUntil: &exampleExpiry},
+			colour.Danger("Encryption subkey expired 12 days ago"),
 		},
 		{
 			KeyWarning{Type: WeakPreferredSymmetricAlgorithms, Detail: "AES123, DES"},
@@ -70,10 +134,18 @@ func TestString(t *testing.T) {
 			KeyWarning{Type: WeakSelfSignatureHash, Detail: "SHA1"},
 			"Weak hash SHA1 used for self signature",
 		},
+		{
+			KeyWarning{Type: WeakSelfSignatureHash, UserId: "<test@example.com>", Detail: "SHA1"},
+			"Weak hash SHA1 used for self signature on <test@example.com>",
+		},
 		{
 			KeyWarning{Type: WeakSubkeyBindingSignatureHash, Detail: "SHA1"},
 			"Weak hash SHA1 used for subkey binding signature",
 		},
+		{
+			KeyWarning{Type: WeakSubkeyBindingSignatureHash, SubkeyId: 0xABCD, Detail: "MD5"},
+			"Weak hash MD5 used for binding signature of subkey 0xABCD",
+		},
 		{
 			KeyWarning{Type: ConfigMaintainAutomaticallyNotSet},
 			"Key not maintained automatically",
@@ -86,10 +158,158 @@ func TestString(t *testing.T) {
 			KeyWarning{Type: ConfigMaintainAutomaticallyButDontPublish},
 			"Key maintained automatically but not uploaded, unable to receive secrets",
 		},
+		{
+			KeyWarning{Type: KeyCannotEncrypt},
+			colour.Danger("Key can't be used for encryption"),
+		},
+		{
+			KeyWarning{Type: InvalidCreationTime},
+			"Primary key has an invalid creation time",
+		},
+		{
+			KeyWarning{Type: InvalidCreationTime, SubkeyId: 0xABCD},
+			"Subkey 0xABCD has an invalid creation time",
+		},
+		{
+			KeyWarning{Type: PrimaryKeyRevoked},
+			colour.Danger("Key has been revoked"),
+		},
+		{
+			KeyWarning{Type: PrimaryKeyRevoked, Detail: "Key was compromised"},
+			colour.Danger("Key has been revoked: Key was compromised"),
+		},
+		{
+			KeyWarning{Type: KeyCreatedInFuture},
+			"Primary key was created in the future",
+		},
+		{
+			KeyWarning{Type: KeyCreatedInFuture, SubkeyId: 0xABCD},
+			"Subkey 0xABCD was created in the future",
+		},
+		{
+			KeyWarning{Type: PrimaryKeyCannotSign},
+			colour.Danger("Primary key can't sign, create a new key"),
+		},
+		{
+			KeyWarning{Type: KeyFromVulnerablePeriod},
+			colour.Danger("Primary key was generated by vulnerable software, see https://roca.crocs.fi.muni.cz"),
+		},
+		{
+			KeyWarning{Type: KeyFromVulnerablePeriod, SubkeyId: 0xABCD},
+			colour.Danger("Subkey 0xABCD was generated by vulnerable software, see https://roca.crocs.fi.muni.cz"),
+		},
+		{
+			KeyWarning{Type: EncryptionBrokenSigningIntact},
+			colour.Danger("Encryption subkey has expired (signing still works)"),
+		},
+		{
+			KeyWarning{Type: MissingDesignatedRevoker},
+			"Key doesn't designate a revoker, required by policy",
+		},
+		{
+			KeyWarning{Type: RotationDueOnNonBusinessDay, Detail: "Friday 2 November"},
+			"Primary key rotation falls on a non-working day, rotate by Friday 2 November",
+		},
+		{
+			KeyWarning{Type: RotationDueOnNonBusinessDay, SubkeyId: 0xABCD, Detail: "Friday 2 November"},
+			"Encryption subkey rotation falls on a non-working day, rotate by Friday 2 November",
+		},
+		{
+			KeyWarning{Type: SelfSigHashBelowPreferences, Detail: "SHA256 instead of SHA512"},
+			"Self signature uses SHA256 instead of SHA512, re-sign to match hash preferences",
+		},
+		{
+			KeyWarning{Type: SelfSigHashBelowPreferences, SubkeyId: 0xABCD, Detail: "SHA256 instead of SHA512"},
+			"Subkey binding signature uses SHA256 instead of SHA512, re-sign to match hash preferences",
+		},
+		{
+			KeyWarning{Type: RoleCapabilityMismatch, Detail: "encryption, it can only be used for authentication"},
+			colour.Danger("Key isn't suited for encryption, it can only be used for authentication"),
+		},
+		{
+			KeyWarning{Type: KeyBelowMinimumStrength, Detail: "80-bit, policy requires 128-bit"},
+			"Primary key is too weak (80-bit, policy requires 128-bit)",
+		},
+		{
+			KeyWarning{Type: KeyBelowMinimumStrength, SubkeyId: 0xABCD, Detail: "80-bit, policy requires 128-bit"},
+			"Subkey 0xABCD is too weak (80-bit, policy requires 128-bit)",
+		},
+		{
+			KeyWarning{
+				Type:              ExpiryDrivenBySecondaryUid,
+				Detail:            "<old@example.com>",
+				CurrentValidUntil: &exampleExpiry,
+			},
+			"Key expiry is set by user ID <old@example.com> (expires 15 October 2018), consider revoking it",
+		},
+		{
+			KeyWarning{Type: ExpiryDrivenBySecondaryUid, Detail: "<old@example.com>"},
+			"Key expiry is set by user ID <old@example.com>, consider revoking it",
+		},
+		{
+			KeyWarning{Type: PrimaryKeyWeakCipher, Detail: "1024-bit RSA"},
+			colour.Danger("Primary key is 1024-bit RSA, which is considered weak"),
+		},
+		{
+			KeyWarning{Type: NoValidSigningSubkey},
+			colour.Danger("Missing signing subkey"),
+		},
+		{
+			KeyWarning{Type: SigningSubkeyDueForRotation},
+			"Signing subkey needs rotating",
+		},
+		{
+			KeyWarning{Type: SigningSubkeyOverdueForRotation, DaysUntilExpiry: 3},
+			colour.Danger("Signing subkey needs rotating now (expires in 3 days)"),
+		},
+		{
+			KeyWarning{Type: SigningSubkeyNoExpiry},
+			"Signing subkey never expires",
+		},
+		{
+			KeyWarning{Type: EncryptionSubkeyRevoked},
+			colour.Danger("Encryption subkey has been revoked"),
+		},
+		{
+			KeyWarning{Type: PrimaryKeyNoValidUserId},
+			colour.Danger("All user IDs have been revoked"),
+		},
+		{
+			KeyWarning{Type: PrimaryKeyUsedForEncryption},
+			"Primary key is used for encryption, add an encryption subkey",
+		},
+		{
+			KeyWarning{Type: ExpiringSoon, DaysUntilExpiry: 2},
+			colour.Danger("Primary key expires in 2 days"),
+		},
+		{
+			KeyWarning{Type: ExpiringSoon, SubkeyId: 0xABCD, DaysUntilExpiry: 1},
+			colour.Danger("Encryption subkey expires tomorrow!"),
+		},
+		{
+			KeyWarning{Type: PrimaryKeyNoUserId},
+			colour.Danger("Key has no user ID"),
+		},
+		{
+			KeyWarning{Type: SubkeyCombinedEncryptAndSign, SubkeyId: 0xABCD},
+			"Encryption subkey is also used for signing",
+		},
+		{
+			KeyWarning{Type: MismatchedExpiryDates, CurrentValidUntil: &primaryExpiry, SubkeyValidUntil: &subkeyExpiry},
+			"Primary key expires 1 January 2030 but encryption subkey expires 1 June 2019",
+		},
+		{
+			KeyWarning{Type: MismatchedExpiryDates},
+			"Primary key and encryption subkey expire far apart",
+		},
 		{
 			KeyWarning{}, // unspecified type
 			"",
 		},
+		{
+			KeyWarning{Type: 999},
+			"Unknown key warning (type 999)",
+		},
 	}
 
 	for _, test := range tests {
@@ -100,3 +320,156 @@ func TestString(t *testing.T) {
 		})
 	}
 }
+
+func TestSeverity(t *testing.T) {
+	var tests = []struct {
+		warning          KeyWarning
+		expectedSeverity Severity
+	}{
+		{KeyWarning{Type: PrimaryKeyExpired}, SeverityCritical},
+		{KeyWarning{Type: NoValidEncryptionSubkey}, SeverityCritical},
+		{KeyWarning{Type: PrimaryKeyCannotSign}, SeverityCritical},
+		{KeyWarning{Type: KeyFromVulnerablePeriod}, SeverityCritical},
+		{KeyWarning{Type: EncryptionBrokenSigningIntact}, SeverityCritical},
+		{KeyWarning{Type: RoleCapabilityMismatch}, SeverityCritical},
+		{KeyWarning{Type: EncryptionSubkeyRevoked}, SeverityCritical},
+		{KeyWarning{Type: PrimaryKeyNoValidUserId}, SeverityCritical},
+		{KeyWarning{Type: PrimaryKeyNoUserId}, SeverityCritical},
+		{KeyWarning{Type: PrimaryKeyNoExpiry, Escalated: true}, SeverityCritical},
+		{KeyWarning{Type: SubkeyOverdueForRotation}, SeverityHigh},
+		{KeyWarning{Type: WeakSelfSignatureHash}, SeverityHigh},
+		{KeyWarning{Type: KeyBelowMinimumStrength}, SeverityHigh},
+		{KeyWarning{Type: PrimaryKeyWeakCipher}, SeverityHigh},
+		{KeyWarning{Type: NoValidSigningSubkey}, SeverityHigh},
+		{KeyWarning{Type: SigningSubkeyOverdueForRotation}, SeverityHigh},
+		{KeyWarning{Type: ExpiringSoon}, SeverityHigh},
+		{KeyWarning{Type: SigningSubkeyDueForRotation}, SeverityMedium},
+		{KeyWarning{Type: SigningSubkeyNoExpiry}, SeverityMedium},
+		{KeyWarning{Type: PrimaryKeyUsedForEncryption}, SeverityMedium},
+		{KeyWarning{Type: SubkeyCombinedEncryptAndSign}, SeverityMedium},
+		{KeyWarning{Type: MismatchedExpiryDates}, SeverityLow},
+		{KeyWarning{Type: KeyCreatedInFuture}, SeverityHigh},
+		{KeyWarning{Type: PrimaryKeyRevoked}, SeverityCritical},
+		{KeyWarning{Type: PrimaryKeyDueForRotation}, SeverityMedium},
+		{KeyWarning{Type: WeakPreferredHashAlgorithms}, SeverityMedium},
+		{KeyWarning{Type: MissingDesignatedRevoker}, SeverityMedium},
+		{KeyWarning{Type: RotationDueOnNonBusinessDay}, SeverityInfo},
+		{KeyWarning{Type: ConfigPublishToAPINotSet}, SeverityLow},
+		{KeyWarning{Type: SelfSigHashBelowPreferences}, SeverityLow},
+		{KeyWarning{Type: ExpiryDrivenBySecondaryUid}, SeverityLow},
+		{KeyWarning{Type: MissingUncompressedPreference}, SeverityInfo},
+		{KeyWarning{Type: MissingPreferredCompressionAlgorithms}, SeverityInfo},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("for warning type %d", test.warning.Type), func(t *testing.T) {
+			assert.Equal(t, test.expectedSeverity, test.warning.Severity())
+		})
+	}
+}
+
+func TestWarningTypeName(t *testing.T) {
+	assert.Equal(t, "SubkeyOverdueForRotation", WarningType(SubkeyOverdueForRotation).Name())
+	assert.Equal(t, "ConfigPublishToAPINotSet", WarningType(ConfigPublishToAPINotSet).Name())
+	assert.Equal(t, "WarningType(999)", WarningType(999).Name())
+}
+
+func TestAllWarningTypes(t *testing.T) {
+	types := AllWarningTypes()
+
+	assert.Equal(t, WarningType(PrimaryKeyDueForRotation), types[0])
+	assert.Equal(t, WarningType(lastWarningType), types[len(types)-1])
+
+	for _, warningType := range types {
+		t.Run(fmt.Sprintf("WarningType(%d) has a name and category", int(warningType)), func(t *testing.T) {
+			if strings.HasPrefix(warningType.Name(), "WarningType(") {
+				t.Fatalf("no name for WarningType(%d)", int(warningType))
+			}
+			if warningType.Category() == UnsetCategory {
+				t.Fatalf("no category for %s", warningType.Name())
+			}
+		})
+	}
+
+	t.Run("the type after the last one has no name", func(t *testing.T) {
+		assert.Equal(t, fmt.Sprintf("WarningType(%d)", lastWarningType+1), WarningType(lastWarningType+1).Name())
+	})
+}
+
+func TestWarningTypeCategory(t *testing.T) {
+	assert.Equal(t, CategoryExpiry, WarningType(PrimaryKeyExpired).Category())
+	assert.Equal(t, CategoryRotation, WarningType(SubkeyOverdueForRotation).Category())
+	assert.Equal(t, CategoryCipher, WarningType(WeakPreferredHashAlgorithms).Category())
+	assert.Equal(t, CategoryStructure, WarningType(NoValidSigningSubkey).Category())
+	assert.Equal(t, UnsetCategory, WarningType(UnsetType).Category())
+	assert.Equal(t, "rotation", CategoryRotation.String())
+}
+
+func TestSeverityString(t *testing.T) {
+	assert.Equal(t, "info", SeverityInfo.String())
+	assert.Equal(t, "low", SeverityLow.String())
+	assert.Equal(t, "critical", SeverityCritical.String())
+}
+
+func TestKeyWarningID(t *testing.T) {
+	fp := exampledata.ExampleFingerprint4
+	now := time.Date(2018, 9, 24, 18, 0, 0, 0, time.UTC)
+	later := now.Add(24 * time.Hour)
+
+	t.Run("is the same for the same problem found at different times", func(t *testing.T) {
+		first := KeyWarning{Type: SubkeyOverdueForRotation, SubkeyId: 0xABCD, DaysUntilExpiry: 5, CurrentValidUntil: &now}
+		second := KeyWarning{Type: SubkeyOverdueForRotation, SubkeyId: 0xABCD, DaysUntilExpiry: 4, CurrentValidUntil: &later}
+
+		assert.Equal(t, first.ID(fp), second.ID(fp))
+		assert.Equal(t, 16, len(first.ID(fp)))
+	})
+
+	t.Run("is the same when weak preferences change", func(t *testing.T) {
+		first := KeyWarning{Type: WeakPreferredHashAlgorithms, Detail: "SHA1"}
+		second := KeyWarning{Type: WeakPreferredHashAlgorithms, Detail: "SHA1, MD5"}
+
+		assert.Equal(t, first.ID(fp), second.ID(fp))
+	})
+
+	var differentWarnings = []struct {
+		name string
+		a    KeyWarning
+		aFp  fingerprint.Fingerprint
+		b    KeyWarning
+		bFp  fingerprint.Fingerprint
+	}{
+		{
+			"different keys",
+			KeyWarning{Type: PrimaryKeyNoExpiry}, exampledata.ExampleFingerprint4,
+			KeyWarning{Type: PrimaryKeyNoExpiry}, exampledata.ExampleFingerprint2,
+		},
+		{
+			"different types",
+			KeyWarning{Type: PrimaryKeyNoExpiry}, fp,
+			KeyWarning{Type: PrimaryKeyLongExpiry}, fp,
+		},
+		{
+			"different subkeys",
+			KeyWarning{Type: SubkeyNoExpiry, SubkeyId: 1}, fp,
+			KeyWarning{Type: SubkeyNoExpiry, SubkeyId: 2}, fp,
+		},
+		{
+			"different user IDs",
+			KeyWarning{Type: WeakSelfSignatureHash, UserId: "Old <old11@example.com>", Detail: "SHA1"}, fp,
+			KeyWarning{Type: WeakSelfSignatureHash, UserId: "New <new11@example.com>", Detail: "SHA1"}, fp,
+		},
+		{
+			"different unsupported algorithms",
+			KeyWarning{Type: UnsupportedPreferredHashAlgorithm, Detail: "HAVAL"}, fp,
+			KeyWarning{Type: UnsupportedPreferredHashAlgorithm, Detail: "SHA3"}, fp,
+		},
+	}
+
+	for _, test := range differentWarnings {
+		t.Run("is different for "+test.name, func(t *testing.T) {
+			if test.a.ID(test.aFp) == test.b.ID(test.bFp) {
+				t.Fatalf("expected different IDs for %v and %v", test.a, test.b)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright 2018 Paul Furley and Ian Drysdale
+//
+// This file is part of Fluidkeys Client which makes it simple to use OpenPGP.
+//
+// Fluidkeys Client is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Fluidkeys Client is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with Fluidkeys Client.  If not, see <https://www.gnu.org/licenses/>.
+
+package status
+
+import (
+	"time"
+
+	"github.com/fluidkeys/crypto/openpgp"
+	"github.com/fluidkeys/crypto/openpgp/packet"
+	"github.com/fluidkeys/fluidkeys/pgpkey"
+)
+
+// CanEncryptTo answers the question a sender has: can I encrypt a message to
+// this key right now? If not, reason explains why, for example
+// "its encryption subkey has expired".
+//
+// This is deliberately separate from GetKeyWarnings, which advises the
+// *owner* of the key on keeping it healthy (a key which is overdue for
+// rotation can still be encrypted to).
+func CanEncryptTo(key pgpkey.PgpKey, now time.Time) (canEncrypt bool, reason string) {
+	if len(key.Revocations) > 0 {
+		return false, "the key has been revoked"
+	}
+
+	if hasExpiry, expiry := getEarliestUidExpiry(key); hasExpiry && isExpired(*expiry, now) {
+		return false, "the key has expired"
+	}
+
+	if key.EncryptionSubkey(now) != nil {
+		return true, ""
+	}
+
+	if primaryKeyCanEncrypt(key) {
+		return true, ""
+	}
+
+	return false, whyNoEncryptionSubkey(key, now)
+}
+
+// primaryKeyCanEncrypt returns true if the primary key's algorithm supports
+// encryption and a self signature flags it for encryption.
+func primaryKeyCanEncrypt(key pgpkey.PgpKey) bool {
+	if !key.PrimaryKey.PubKeyAlgo.CanEncrypt() {
+		return false
+	}
+
+	for _, selfSig := range getIdentitySelfSignatures(&key) {
+		if selfSig.FlagsValid && (selfSig.FlagEncryptCommunications || selfSig.FlagEncryptStorage) {
+			return true
+		}
+	}
+	return false
+}
+
+// subkeyCanEncrypt returns true if the subkey's algorithm supports encryption
+// and its binding signature flags it for encryption. It doesn't check
+// whether the subkey is currently valid.
+func subkeyCanEncrypt(key pgpkey.PgpKey, subkey openpgp.Subkey) bool {
+	bindingSig := key.SubkeyBindingSignature(subkey)
+	return subkey.PublicKey.PubKeyAlgo.CanEncrypt() &&
+		(bindingSig.FlagEncryptCommunications || bindingSig.FlagEncryptStorage)
+}
+
+// whyNoEncryptionSubkey looks at all the subkeys flagged for encryption to
+// explain why none of them are usable.
+func whyNoEncryptionSubkey(key pgpkey.PgpKey, now time.Time) string {
+	var sawExpired, sawRevoked bool
+
+	for _, subkey := range key.Subkeys {
+		bindingSig := key.SubkeyBindingSignature(subkey)
+		if !bindingSig.FlagEncryptCommunications && !bindingSig.FlagEncryptStorage {
+			continue
+		}
+
+		if subkey.Sig.SigType == packet.SigTypeSubkeyRevocation {
+			sawRevoked = true
+		} else if hasExpiry, expiry := pgpkey.SubkeyExpiry(subkey); hasExpiry && isExpired(*expiry, now) {
+			sawExpired = true
+		}
+	}
+
+	switch {
+	case sawExpired:
+		return "its encryption subkey has expired"
+	case sawRevoked:
+		return "its encryption subkey has been revoked"
+	default:
+		return "it has no encryption subkey"
+	}
+}
@@ -0,0 +1,81 @@
+package status
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fluidkeys/crypto/openpgp/packet"
+	"github.com/fluidkeys/fluidkeys/assert"
+	"github.com/fluidkeys/fluidkeys/exampledata"
+	"github.com/fluidkeys/fluidkeys/pgpkey"
+)
+
+func TestCanEncryptTo(t *testing.T) {
+	now := time.Date(2018, 9, 24, 18, 0, 0, 0, time.UTC)
+
+	loadKey := func(t *testing.T) *pgpkey.PgpKey {
+		t.Helper()
+		key, err := pgpkey.LoadFromArmoredEncryptedPrivateKey(exampledata.ExamplePrivateKey2, "test2")
+		if err != nil {
+			t.Fatalf("failed to load example test data: %v", err)
+		}
+		return key
+	}
+
+	t.Run("with a valid encryption subkey", func(t *testing.T) {
+		key := loadKey(t)
+
+		canEncrypt, reason := CanEncryptTo(*key, now)
+		assert.Equal(t, true, canEncrypt)
+		assert.Equal(t, "", reason)
+	})
+
+	t.Run("with an expired encryption subkey", func(t *testing.T) {
+		key := loadKey(t)
+		subkeyId := key.EncryptionSubkey(now).PublicKey.KeyId
+		assert.ErrorIsNil(t, key.ExpireSubkey(subkeyId, now.Add(-time.Hour)))
+
+		canEncrypt, reason := CanEncryptTo(*key, now)
+		assert.Equal(t, false, canEncrypt)
+		assert.Equal(t, "its encryption subkey has expired", reason)
+	})
+
+	t.Run("with a revoked encryption subkey", func(t *testing.T) {
+		key := loadKey(t)
+		key.EncryptionSubkey(now).Sig.SigType = packet.SigTypeSubkeyRevocation
+
+		canEncrypt, reason := CanEncryptTo(*key, now)
+		assert.Equal(t, false, canEncrypt)
+		assert.Equal(t, "its encryption subkey has been revoked", reason)
+	})
+
+	t.Run("with a subkey revoked by a revocation signature", func(t *testing.T) {
+		key, err := pgpkey.LoadFromArmoredPublicKey(exampledata.ExamplePublicKey10)
+		if err != nil {
+			t.Fatalf("failed to load example key: %v", err)
+		}
+		key.Subkeys = key.Subkeys[1:] // keep only the revoked subkey
+
+		canEncrypt, reason := CanEncryptTo(*key, time.Date(2018, 11, 15, 0, 0, 0, 0, time.UTC))
+		assert.Equal(t, false, canEncrypt)
+		assert.Equal(t, "its encryption subkey has been revoked", reason)
+	})
+
+	t.Run("with no subkeys", func(t *testing.T) {
+		key := loadKey(t)
+		key.Subkeys = nil
+
+		canEncrypt, reason := CanEncryptTo(*key, now)
+		assert.Equal(t, false, canEncrypt)
+		assert.Equal(t, "it has no encryption subkey", reason)
+	})
+
+	t.Run("with an expired primary key", func(t *testing.T) {
+		key := loadKey(t)
+		assert.ErrorIsNil(t, key.UpdateExpiryForAllUserIds(now.Add(-time.Hour), now))
+
+		canEncrypt, reason := CanEncryptTo(*key, now)
+		assert.Equal(t, false, canEncrypt)
+		assert.Equal(t, "the key has expired", reason)
+	})
+}
@@ -0,0 +1,134 @@
+// Copyright 2018 Paul Furley and Ian Drysdale
+//
+// This file is part of Fluidkeys Client which makes it simple to use OpenPGP.
+//
+// Fluidkeys Client is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Fluidkeys Client is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with Fluidkeys Client.  If not, see <https://www.gnu.org/licenses/>.
+
+package status
+
+import (
+	"time"
+
+	"github.com/fluidkeys/fluidkeys/config"
+	"github.com/fluidkeys/fluidkeys/fingerprint"
+	"github.com/fluidkeys/fluidkeys/pgpkey"
+	"github.com/fluidkeys/fluidkeys/policy"
+)
+
+// KeyReport summarises the status of a single key in a keyring.
+type KeyReport struct {
+	Fingerprint fingerprint.Fingerprint
+
+	// Warnings are the cleaned warnings for the key, see
+	// GetKeyWarningsClean.
+	Warnings []KeyWarning
+
+	// NextActionDate is the soonest date the owner must rotate either the
+	// primary key or the encryption subkey. It may be in the past if the
+	// key is already due for rotation.
+	// It's the zero time if the key never expires (see NeverExpires), or
+	// has no rotation date at all.
+	NextActionDate time.Time
+
+	// NeverExpires is true if the primary key has no expiry date, so there's
+	// no date by which the owner must act.
+	NeverExpires bool
+
+	// Severity is how urgently the key as a whole needs attention, see
+	// overallSeverity. It's SeverityInfo if there are no warnings.
+	Severity Severity
+}
+
+// GetKeyringReport returns a KeyReport for each of the given keys, in the
+// same order. Use ByNextActionDate to sort by who needs to act first.
+func GetKeyringReport(keys []pgpkey.PgpKey, config *config.Config, now time.Time) []KeyReport {
+	return GetKeyringReportWithPolicy(keys, config, policy.Policy{}, now)
+}
+
+// GetKeyringReportWithPolicy is like GetKeyringReport, but checks the keys
+// and works out their next action dates using the given policy (rather than
+// the default).
+func GetKeyringReportWithPolicy(keys []pgpkey.PgpKey, config *config.Config, p policy.Policy, now time.Time) []KeyReport {
+	reports := []KeyReport{}
+
+	for _, key := range keys {
+		report := KeyReport{
+			Fingerprint: key.Fingerprint(),
+			Warnings:    cleanWarnings(getKeyWarnings(key, config, p, now)),
+		}
+		report.NextActionDate, report.NeverExpires = getNextActionDate(key, p, now)
+		report.Severity = overallSeverity(report.Warnings)
+		reports = append(reports, report)
+	}
+	return reports
+}
+
+// getNextActionDate returns the earliest rotation date of the primary key
+// and the encryption subkey (see getBestEncryptionSubkey), using the same
+// rotation policy as the rotation warnings.
+// If the primary key never expires it returns the zero time and true.
+func getNextActionDate(key pgpkey.PgpKey, p policy.Policy, now time.Time) (nextActionDate time.Time, neverExpires bool) {
+	if !pgpkey.IsPlausibleCreationTime(key.PrimaryKey.CreationTime) {
+		return time.Time{}, false // expiry can't be calculated
+	}
+
+	hasExpiry, primaryExpiry := getEarliestUidExpiry(key)
+	if !hasExpiry {
+		return time.Time{}, true
+	}
+
+	rotationDates := []time.Time{p.NextRotation(*primaryExpiry)}
+
+	if subkey := getBestEncryptionSubkey(key, now); subkey != nil {
+		if hasExpiry, subkeyExpiry := pgpkey.SubkeyExpiry(*subkey); hasExpiry {
+			rotationDates = append(rotationDates, p.NextRotation(*subkeyExpiry))
+		}
+	}
+
+	return earliest(rotationDates), false
+}
+
+// overallSeverity returns the severity of the most severe warning, except
+// that two or more overdue rotations escalate the key to Critical. For
+// example a key overdue for rotation on both its primary key and encryption
+// subkey has been neglected, and should be dealt with before a key with a
+// single overdue rotation.
+func overallSeverity(warnings []KeyWarning) Severity {
+	most := SeverityInfo
+	overdueCount := 0
+
+	for _, warning := range warnings {
+		if isOverdueForRotation(warning) {
+			overdueCount++
+		}
+		if severity := warning.Severity(); severity > most {
+			most = severity
+		}
+	}
+
+	if overdueCount >= 2 {
+		return SeverityCritical
+	}
+	return most
+}
+
+func isOverdueForRotation(warning KeyWarning) bool {
+	switch warning.Type {
+	case PrimaryKeyOverdueForRotation,
+		SubkeyOverdueForRotation,
+		SigningSubkeyOverdueForRotation:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,139 @@
+package status
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/fluidkeys/fluidkeys/assert"
+	"github.com/fluidkeys/fluidkeys/config"
+	"github.com/fluidkeys/fluidkeys/exampledata"
+	"github.com/fluidkeys/fluidkeys/pgpkey"
+	"github.com/fluidkeys/fluidkeys/policy"
+)
+
+func TestGetKeyringReport(t *testing.T) {
+	now := time.Date(2018, 9, 24, 18, 0, 0, 0, time.UTC)
+
+	key2, err := pgpkey.LoadFromArmoredPublicKey(exampledata.ExamplePublicKey2)
+	assert.ErrorIsNil(t, err)
+	key4, err := pgpkey.LoadFromArmoredPublicKey(exampledata.ExamplePublicKey4)
+	assert.ErrorIsNil(t, err)
+
+	reports := GetKeyringReport([]pgpkey.PgpKey{*key2, *key4}, &config.Config{}, now)
+	assert.Equal(t, 2, len(reports))
+
+	t.Run("for a key with an expiry", func(t *testing.T) {
+		report := reports[0]
+		assert.Equal(t, exampledata.ExampleFingerprint2, report.Fingerprint)
+		assert.Equal(t, false, report.NeverExpires)
+
+		primaryKeyExpiry := time.Unix(2167466389, 0).UTC()
+		assert.AssertEqualTimes(t, primaryKeyExpiry.Add(-30*24*time.Hour), report.NextActionDate)
+	})
+
+	t.Run("for a key that never expires", func(t *testing.T) {
+		report := reports[1]
+		assert.Equal(t, exampledata.ExampleFingerprint4, report.Fingerprint)
+		assert.Equal(t, true, report.NeverExpires)
+		assert.Equal(t, true, report.NextActionDate.IsZero())
+	})
+
+	t.Run("includes clean warnings", func(t *testing.T) {
+		assert.Equal(t, GetKeyWarningsClean(*key4, &config.Config{}, now), reports[1].Warnings)
+	})
+
+	t.Run("with a policy that rotates earlier", func(t *testing.T) {
+		p := policy.Policy{RotateDaysBeforeExpiry: 60}
+		reports := GetKeyringReportWithPolicy([]pgpkey.PgpKey{*key2}, &config.Config{}, p, now)
+		assert.Equal(t, 1, len(reports))
+
+		primaryKeyExpiry := time.Unix(2167466389, 0).UTC()
+		assert.AssertEqualTimes(t, primaryKeyExpiry.Add(-60*24*time.Hour), reports[0].NextActionDate)
+	})
+}
+
+func TestByNextActionDate(t *testing.T) {
+	jan := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+	feb := time.Date(2019, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	reports := []KeyReport{
+		KeyReport{NeverExpires: true},
+		KeyReport{NextActionDate: feb},
+		KeyReport{NextActionDate: jan},
+	}
+	sort.Sort(ByNextActionDate(reports))
+
+	assert.AssertEqualTimes(t, jan, reports[0].NextActionDate)
+	assert.AssertEqualTimes(t, feb, reports[1].NextActionDate)
+	assert.Equal(t, true, reports[2].NeverExpires)
+}
+
+func TestOverallSeverity(t *testing.T) {
+	var tests = []struct {
+		name     string
+		warnings []KeyWarning
+		expected Severity
+	}{
+		{
+			"no warnings",
+			[]KeyWarning{},
+			SeverityInfo,
+		},
+		{
+			"a single high warning",
+			[]KeyWarning{
+				KeyWarning{Type: SubkeyOverdueForRotation},
+				KeyWarning{Type: PrimaryKeyDueForRotation},
+			},
+			SeverityHigh,
+		},
+		{
+			"two high warnings escalate to critical",
+			[]KeyWarning{
+				KeyWarning{Type: PrimaryKeyOverdueForRotation},
+				KeyWarning{Type: SubkeyOverdueForRotation},
+			},
+			SeverityCritical,
+		},
+		{
+			"two unrelated high warnings stay high",
+			[]KeyWarning{
+				KeyWarning{Type: WeakSelfSignatureHash},
+				KeyWarning{Type: ExpiringSoon},
+			},
+			SeverityHigh,
+		},
+		{
+			"the most severe warning wins",
+			[]KeyWarning{
+				KeyWarning{Type: ConfigPublishToAPINotSet},
+				KeyWarning{Type: PrimaryKeyDueForRotation},
+			},
+			SeverityMedium,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, overallSeverity(test.warnings))
+		})
+	}
+}
+
+func TestByReportSeverity(t *testing.T) {
+	jan := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+	feb := time.Date(2019, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	reports := []KeyReport{
+		KeyReport{Severity: SeverityMedium, NextActionDate: jan},
+		KeyReport{Severity: SeverityCritical, NextActionDate: feb},
+		KeyReport{Severity: SeverityCritical, NextActionDate: jan},
+	}
+	sort.Sort(ByReportSeverity(reports))
+
+	assert.Equal(t, SeverityCritical, reports[0].Severity)
+	assert.AssertEqualTimes(t, jan, reports[0].NextActionDate)
+	assert.Equal(t, SeverityCritical, reports[1].Severity)
+	assert.Equal(t, SeverityMedium, reports[2].Severity)
+}
@@ -0,0 +1,107 @@
+// Copyright 2018 Paul Furley and Ian Drysdale
+//
+// This file is part of Fluidkeys Client which makes it simple to use OpenPGP.
+//
+// Fluidkeys Client is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Fluidkeys Client is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with Fluidkeys Client.  If not, see <https://www.gnu.org/licenses/>.
+
+package status
+
+import (
+	"crypto/rsa"
+	"math/big"
+
+	"github.com/fluidkeys/fluidkeys/pgpkey"
+	"github.com/fluidkeys/fluidkeys/policy"
+)
+
+// getVulnerableGenerationWarnings returns KeyFromVulnerablePeriod for the
+// primary key and each subkey with an RSA modulus that was generated by a
+// known-weak implementation. For an encryption subkey the Detail is
+// "encryption", since only those can be replaced by rotating the subkey.
+//
+// Currently this detects ROCA (CVE-2017-15361): RSA keys generated by
+// Infineon chips, including some YubiKey 4s, whose private keys can be
+// recovered from the public modulus.
+// Keys generated during the Debian OpenSSL RNG bug (CVE-2008-0166) can only
+// be detected with a blacklist of every affected modulus, so aren't checked.
+func getVulnerableGenerationWarnings(key pgpkey.PgpKey) []KeyWarning {
+	var warnings []KeyWarning
+
+	if rsaKey, ok := key.PrimaryKey.PublicKey.(*rsa.PublicKey); ok && hasROCAFingerprint(rsaKey.N) {
+		warnings = append(warnings, KeyWarning{Type: KeyFromVulnerablePeriod})
+	}
+
+	for _, subkey := range key.Subkeys {
+		if rsaKey, ok := subkey.PublicKey.PublicKey.(*rsa.PublicKey); ok && hasROCAFingerprint(rsaKey.N) {
+			warning := KeyWarning{
+				Type:     KeyFromVulnerablePeriod,
+				SubkeyId: subkey.PublicKey.KeyId,
+			}
+			if subkeyCanEncrypt(key, subkey) {
+				warning.Detail = policy.RoleEncryption.String()
+			}
+			warnings = append(warnings, warning)
+		}
+	}
+	return warnings
+}
+
+// hasROCAFingerprint returns true if the RSA modulus has the structure of a
+// key generated by the vulnerable Infineon library.
+//
+// Vulnerable primes have the form k*M + (65537^a mod M), where M is the
+// product of small primes, so the modulus taken mod each of those primes is
+// always a power of 65537. A random modulus passes this test with negligible
+// probability. See "The Return of Coppersmith's Attack" (Nemec et al, 2017)
+// and https://github.com/crocs-muni/roca
+func hasROCAFingerprint(modulus *big.Int) bool {
+	if modulus == nil {
+		return false
+	}
+
+	remainder := new(big.Int)
+	for _, prime := range rocaPrimes {
+		remainder.Mod(modulus, big.NewInt(prime))
+		if !isPowerOfGenerator(remainder.Int64(), prime) {
+			return false
+		}
+	}
+	return true
+}
+
+// isPowerOfGenerator returns true if x is in the multiplicative subgroup
+// generated by 65537 modulo the prime.
+func isPowerOfGenerator(x int64, prime int64) bool {
+	generator := rocaGenerator % prime
+	power := int64(1)
+
+	for {
+		if power == x {
+			return true
+		}
+		power = (power * generator) % prime
+		if power == 1 {
+			return false // back to the start of the cycle
+		}
+	}
+}
+
+const rocaGenerator = 65537
+
+// rocaPrimes are the small primes used by the published ROCA detector.
+var rocaPrimes = []int64{
+	3, 5, 7, 11, 13, 17, 19, 23, 29, 31, 37, 41, 43, 47, 53, 59, 61, 67, 71,
+	73, 79, 83, 89, 97, 101, 103, 107, 109, 113, 127, 131, 137, 139, 149,
+	151, 157, 163, 167,
+}
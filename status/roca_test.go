@@ -0,0 +1,91 @@
+package status
+
+import (
+	"crypto/rsa"
+	"math/big"
+	"testing"
+
+	"github.com/fluidkeys/fluidkeys/assert"
+	"github.com/fluidkeys/fluidkeys/exampledata"
+	"github.com/fluidkeys/fluidkeys/pgpkey"
+)
+
+// makeROCAModulus returns a number with the same structure as a modulus
+// generated by the vulnerable Infineon library: 65537^a mod M plus a
+// multiple of M, where M is the product of rocaPrimes.
+func makeROCAModulus() *big.Int {
+	m := big.NewInt(1)
+	for _, prime := range rocaPrimes {
+		m.Mul(m, big.NewInt(prime))
+	}
+
+	n := new(big.Int).Exp(big.NewInt(rocaGenerator), big.NewInt(1234567), m)
+	k := new(big.Int).Lsh(big.NewInt(1), 1800)
+	return n.Add(n, k.Mul(k, m))
+}
+
+func TestHasROCAFingerprint(t *testing.T) {
+	t.Run("with a vulnerable modulus", func(t *testing.T) {
+		assert.Equal(t, true, hasROCAFingerprint(makeROCAModulus()))
+	})
+
+	t.Run("with a normal RSA key", func(t *testing.T) {
+		key, err := pgpkey.LoadFromArmoredPublicKey(exampledata.ExamplePublicKey2)
+		assert.ErrorIsNil(t, err)
+
+		modulus := key.PrimaryKey.PublicKey.(*rsa.PublicKey).N
+		assert.Equal(t, false, hasROCAFingerprint(modulus))
+	})
+
+	t.Run("with a modulus one away from vulnerable", func(t *testing.T) {
+		modulus := makeROCAModulus()
+		modulus.Add(modulus, big.NewInt(1))
+		assert.Equal(t, false, hasROCAFingerprint(modulus))
+	})
+
+	t.Run("with a nil modulus", func(t *testing.T) {
+		assert.Equal(t, false, hasROCAFingerprint(nil))
+	})
+}
+
+func TestGetVulnerableGenerationWarnings(t *testing.T) {
+	t.Run("with a normal key", func(t *testing.T) {
+		key, err := pgpkey.LoadFromArmoredPublicKey(exampledata.ExamplePublicKey2)
+		assert.ErrorIsNil(t, err)
+
+		assertEqualSliceOfKeyWarningTypes(t, []KeyWarning{}, getVulnerableGenerationWarnings(*key))
+	})
+
+	t.Run("with a vulnerable primary key and subkey", func(t *testing.T) {
+		key, err := pgpkey.LoadFromArmoredPublicKey(exampledata.ExamplePublicKey2)
+		assert.ErrorIsNil(t, err)
+
+		key.PrimaryKey.PublicKey.(*rsa.PublicKey).N = makeROCAModulus()
+		key.Subkeys[0].PublicKey.PublicKey.(*rsa.PublicKey).N = makeROCAModulus()
+
+		got := getVulnerableGenerationWarnings(*key)
+		expected := []KeyWarning{
+			KeyWarning{Type: KeyFromVulnerablePeriod},
+			KeyWarning{Type: KeyFromVulnerablePeriod},
+		}
+		assertEqualSliceOfKeyWarningTypes(t, expected, got)
+		assert.Equal(t, uint64(0), got[0].SubkeyId)
+		assert.Equal(t, key.Subkeys[0].PublicKey.KeyId, got[1].SubkeyId)
+		assert.Equal(t, "encryption", got[1].Detail)
+	})
+
+	t.Run("with a vulnerable signing subkey", func(t *testing.T) {
+		key, err := pgpkey.LoadFromArmoredPublicKey(exampledata.ExamplePublicKey2)
+		assert.ErrorIsNil(t, err)
+
+		subkey := key.Subkeys[0]
+		subkey.PublicKey.PublicKey.(*rsa.PublicKey).N = makeROCAModulus()
+		subkey.Sig.FlagEncryptCommunications = false
+		subkey.Sig.FlagEncryptStorage = false
+		subkey.Sig.FlagSign = true
+
+		got := getVulnerableGenerationWarnings(*key)
+		assertEqualSliceOfKeyWarningTypes(t, []KeyWarning{KeyWarning{Type: KeyFromVulnerablePeriod}}, got)
+		assert.Equal(t, "", got[0].Detail)
+	})
+}
@@ -0,0 +1,99 @@
+// Copyright 2018 Paul Furley and Ian Drysdale
+//
+// This file is part of Fluidkeys Client which makes it simple to use OpenPGP.
+//
+// Fluidkeys Client is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Fluidkeys Client is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with Fluidkeys Client.  If not, see <https://www.gnu.org/licenses/>.
+
+package status
+
+import (
+	"strings"
+
+	"github.com/fluidkeys/fluidkeys/pgpkey"
+	"github.com/fluidkeys/fluidkeys/policy"
+)
+
+// getRoleCapabilityWarnings returns RoleCapabilityMismatch if the policy
+// gives the key a role but none of the key's components is flagged for it,
+// for example an authentication-only key being used for encryption.
+//
+// Expiry and revocation aren't considered: they're covered by the other
+// warnings, whereas this says the key was never suited to the role.
+func getRoleCapabilityWarnings(key pgpkey.PgpKey, p policy.Policy) []KeyWarning {
+	if p.Role == policy.RoleUnspecified {
+		return []KeyWarning{}
+	}
+
+	capabilities := keyCapabilities(key)
+	if capabilities[p.Role] {
+		return []KeyWarning{}
+	}
+
+	var suitedFor []string
+	for _, role := range []policy.KeyRole{policy.RoleEncryption, policy.RoleSigning, policy.RoleAuthentication} {
+		if capabilities[role] {
+			suitedFor = append(suitedFor, role.String())
+		}
+	}
+	if len(suitedFor) == 0 {
+		suitedFor = []string{"certification"}
+	}
+
+	return []KeyWarning{KeyWarning{
+		Type:   RoleCapabilityMismatch,
+		Detail: p.Role.String() + ", it can only be used for " + strings.Join(suitedFor, " and "),
+	}}
+}
+
+// isRoleMismatchFor returns true if the RoleCapabilityMismatch warning says
+// the key can't be used for the given role, which starts its Detail.
+func isRoleMismatchFor(warning KeyWarning, role policy.KeyRole) bool {
+	return strings.HasPrefix(warning.Detail, role.String()+",")
+}
+
+// keyCapabilities returns the roles that the primary key or any subkey is
+// flagged (and has a suitable algorithm) for.
+func keyCapabilities(key pgpkey.PgpKey) map[policy.KeyRole]bool {
+	capabilities := make(map[policy.KeyRole]bool)
+
+	if primaryKeyCanEncrypt(key) {
+		capabilities[policy.RoleEncryption] = true
+	}
+	if key.PrimaryKey.PubKeyAlgo.CanSign() {
+		for _, selfSig := range getIdentitySelfSignatures(&key) {
+			if !selfSig.FlagsValid || selfSig.FlagSign {
+				capabilities[policy.RoleSigning] = true
+			}
+			if selfSig.FlagsValid && pgpkey.IsFlaggedForAuthentication(selfSig) {
+				capabilities[policy.RoleAuthentication] = true
+			}
+		}
+	}
+
+	for _, subkey := range key.Subkeys {
+		if subkeyCanEncrypt(key, subkey) {
+			capabilities[policy.RoleEncryption] = true
+		}
+		if !subkey.Sig.FlagsValid || !subkey.PublicKey.PubKeyAlgo.CanSign() {
+			continue
+		}
+		if subkey.Sig.FlagSign {
+			capabilities[policy.RoleSigning] = true
+		}
+		if pgpkey.IsFlaggedForAuthentication(subkey.Sig) {
+			capabilities[policy.RoleAuthentication] = true
+		}
+	}
+	return capabilities
+}
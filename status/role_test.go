@@ -0,0 +1,68 @@
+package status
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fluidkeys/fluidkeys/assert"
+	"github.com/fluidkeys/fluidkeys/exampledata"
+	"github.com/fluidkeys/fluidkeys/pgpkey"
+	"github.com/fluidkeys/fluidkeys/policy"
+)
+
+func TestGetRoleCapabilityWarnings(t *testing.T) {
+	// loadAuthenticationOnlyKey returns key 18, which has its primary key
+	// flagged for certification only and its subkey for authentication only.
+	loadAuthenticationOnlyKey := func(t *testing.T) *pgpkey.PgpKey {
+		t.Helper()
+		key, err := pgpkey.LoadFromArmoredPublicKey(exampledata.ExamplePublicKey18)
+		if err != nil {
+			t.Fatalf("failed to load example key: %v", err)
+		}
+		return key
+	}
+
+	t.Run("without a role", func(t *testing.T) {
+		key := loadAuthenticationOnlyKey(t)
+		assertEqualSliceOfKeyWarningTypes(t, []KeyWarning{}, getRoleCapabilityWarnings(*key, policy.Policy{}))
+	})
+
+	t.Run("authentication key used for authentication", func(t *testing.T) {
+		key := loadAuthenticationOnlyKey(t)
+		p := policy.Policy{Role: policy.RoleAuthentication}
+		assertEqualSliceOfKeyWarningTypes(t, []KeyWarning{}, getRoleCapabilityWarnings(*key, p))
+	})
+
+	t.Run("authentication key used for encryption", func(t *testing.T) {
+		key := loadAuthenticationOnlyKey(t)
+		p := policy.Policy{Role: policy.RoleEncryption}
+
+		expected := []KeyWarning{KeyWarning{
+			Type:   RoleCapabilityMismatch,
+			Detail: "encryption, it can only be used for authentication",
+		}}
+		assert.Equal(t, expected, getRoleCapabilityWarnings(*key, p))
+	})
+
+	t.Run("supersedes the encryption subkey warnings", func(t *testing.T) {
+		key := loadAuthenticationOnlyKey(t)
+		p := policy.Policy{Role: policy.RoleEncryption}
+		now := time.Date(2018, 11, 15, 18, 0, 0, 0, time.UTC)
+
+		for _, warning := range EvaluatePolicies(*key, map[string]policy.Policy{"p": p}, now)["p"] {
+			if warning.Type == NoValidEncryptionSubkey || warning.Type == KeyCannotEncrypt {
+				t.Fatalf("expected %s to be superseded", warning.Type.Name())
+			}
+		}
+	})
+
+	t.Run("a signing mismatch doesn't supersede the encryption warnings", func(t *testing.T) {
+		for _, encryptionWarning := range []WarningType{NoValidEncryptionSubkey, KeyCannotEncrypt, EncryptionSubkeyRevoked} {
+			warnings := []KeyWarning{
+				KeyWarning{Type: RoleCapabilityMismatch, Detail: "signing, it can only be used for authentication"},
+				KeyWarning{Type: encryptionWarning},
+			}
+			assert.Equal(t, warnings, removeSupersededWarnings(warnings))
+		}
+	})
+}
@@ -24,3 +24,73 @@ type ByActionType []KeyAction
 func (a ByActionType) Len() int           { return len(a) }
 func (a ByActionType) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
 func (a ByActionType) Less(i, j int) bool { return a[i].SortOrder() < a[j].SortOrder() }
+
+// BySeverity implements sort.Interface for []KeyWarning, putting the most
+// severe warnings first.
+type BySeverity []KeyWarning
+
+func (a BySeverity) Len() int           { return len(a) }
+func (a BySeverity) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
+func (a BySeverity) Less(i, j int) bool { return a[i].Severity() > a[j].Severity() }
+
+// ByNextActionDate implements sort.Interface for []KeyReport, putting the
+// keys whose owners need to act soonest first. Keys without a next action
+// date come last.
+type ByNextActionDate []KeyReport
+
+func (a ByNextActionDate) Len() int      { return len(a) }
+func (a ByNextActionDate) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
+func (a ByNextActionDate) Less(i, j int) bool {
+	if a[j].NextActionDate.IsZero() {
+		return !a[i].NextActionDate.IsZero()
+	}
+	if a[i].NextActionDate.IsZero() {
+		return false
+	}
+	return a[i].NextActionDate.Before(a[j].NextActionDate)
+}
+
+// ByReportSeverity implements sort.Interface for []KeyReport, putting the
+// most severe keys first. Keys of the same severity are ordered by
+// ByNextActionDate.
+type ByReportSeverity []KeyReport
+
+func (a ByReportSeverity) Len() int      { return len(a) }
+func (a ByReportSeverity) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
+func (a ByReportSeverity) Less(i, j int) bool {
+	if a[i].Severity != a[j].Severity {
+		return a[i].Severity > a[j].Severity
+	}
+	return ByNextActionDate(a).Less(i, j)
+}
+
+// ByEventDate implements sort.Interface for []KeyEvent, putting the earliest
+// events first.
+type ByEventDate []KeyEvent
+
+func (a ByEventDate) Len() int           { return len(a) }
+func (a ByEventDate) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
+func (a ByEventDate) Less(i, j int) bool { return a[i].Date.Before(a[j].Date) }
+
+// ByWarningOrder implements sort.Interface for []KeyWarning, putting the most
+// severe warnings first, then ordering by Type, SubkeyId, UserId and Detail
+// so that the order doesn't depend on how the warnings were found.
+type ByWarningOrder []KeyWarning
+
+func (a ByWarningOrder) Len() int      { return len(a) }
+func (a ByWarningOrder) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
+func (a ByWarningOrder) Less(i, j int) bool {
+	if a[i].Severity() != a[j].Severity() {
+		return a[i].Severity() > a[j].Severity()
+	}
+	if a[i].Type != a[j].Type {
+		return a[i].Type < a[j].Type
+	}
+	if a[i].SubkeyId != a[j].SubkeyId {
+		return a[i].SubkeyId < a[j].SubkeyId
+	}
+	if a[i].UserId != a[j].UserId {
+		return a[i].UserId < a[j].UserId
+	}
+	return a[i].Detail < a[j].Detail
+}
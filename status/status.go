@@ -19,11 +19,16 @@ package status
 
 import (
 	"crypto"
+	"fmt"
 	"log"
+	"math"
+	"sort"
 	"strings"
 	"time"
 
+	"github.com/fluidkeys/crypto/openpgp"
 	"github.com/fluidkeys/crypto/openpgp/packet"
+	"github.com/fluidkeys/crypto/openpgp/s2k"
 	"github.com/fluidkeys/fluidkeys/config"
 	"github.com/fluidkeys/fluidkeys/openpgpdefs/compression"
 	"github.com/fluidkeys/fluidkeys/openpgpdefs/hash"
@@ -33,73 +38,353 @@ import (
 )
 
 // GetKeyWarnings returns a slice of KeyWarnings indicating problems found
-// with the given PgpKey.
+// with the given PgpKey. Duplicate warnings are removed (see DedupeWarnings).
 func GetKeyWarnings(key pgpkey.PgpKey, config *config.Config) []KeyWarning {
+	return GetKeyWarningsAt(key, config, time.Now())
+}
+
+// GetKeyWarningsAt is like GetKeyWarnings, but checks the key as of `now`
+// rather than the current time, for example to see which warnings a key will
+// have next month.
+func GetKeyWarningsAt(key pgpkey.PgpKey, config *config.Config, now time.Time) []KeyWarning {
+	return getKeyWarnings(key, config, policy.Policy{}, now)
+}
+
+// GetKeyWarningsWithPolicy is like GetKeyWarnings, but checks the key against
+// the given policy (rather than the default) as of `now`.
+// If config is nil, warnings about Fluidkeys' configuration for the key are
+// skipped.
+func GetKeyWarningsWithPolicy(key pgpkey.PgpKey, config *config.Config, p policy.Policy, now time.Time) []KeyWarning {
+	return getKeyWarnings(key, config, p, now)
+}
+
+// EvaluatePolicies checks the key against each of the named policies, for
+// example a "baseline" and an "ideal" policy, and returns the clean warnings
+// (see GetKeyWarningsClean) for each policy name. A policy with no warnings
+// maps to an empty slice.
+//
+// Only the key itself is checked: warnings about Fluidkeys' configuration
+// don't depend on the policy, so they're left out.
+func EvaluatePolicies(key pgpkey.PgpKey, policies map[string]policy.Policy, now time.Time) map[string][]KeyWarning {
+	results := make(map[string][]KeyWarning)
+
+	for name, p := range policies {
+		results[name] = cleanWarnings(GetKeyWarningsWithPolicy(key, nil, p, now))
+	}
+	return results
+}
+
+// KeyNeedsAttention returns true if any of the warnings is more severe than
+// SeverityInfo. Informational warnings alone don't make a key need attention.
+func KeyNeedsAttention(warnings []KeyWarning) bool {
+	for _, warning := range warnings {
+		if warning.Severity() > SeverityInfo {
+			return true
+		}
+	}
+	return false
+}
+
+// Expiry returns when the key's primary key expires (the earliest expiry of
+// its unrevoked user IDs), and false if it never expires. To find when the
+// key should be rotated, pass the expiry to policy.NextRotation.
+func Expiry(key pgpkey.PgpKey) (time.Time, bool) {
+	hasExpiry, expiry := getEarliestUidExpiry(key)
+	if !hasExpiry {
+		return time.Time{}, false
+	}
+	return *expiry, true
+}
+
+// WholeKeyExpiry returns when the key stops working: the earliest expiry of
+// any user ID or subkey (see getEarliestExpiryTime), and false if none of
+// them expire. This can be earlier than Expiry, for example if the
+// encryption subkey expires before the primary key.
+func WholeKeyExpiry(key pgpkey.PgpKey) (time.Time, bool) {
+	hasExpiry, expiry := getEarliestExpiryTime(key)
+	if !hasExpiry {
+		return time.Time{}, false
+	}
+	return *expiry, true
+}
+
+// DaysUntilWholeKeyExpiry returns the number of whole days from `now` until
+// WholeKeyExpiry, or 0 if it's already passed, and false if the key never
+// expires.
+func DaysUntilWholeKeyExpiry(key pgpkey.PgpKey, now time.Time) (uint, bool) {
+	expiry, hasExpiry := WholeKeyExpiry(key)
+	if !hasExpiry {
+		return 0, false
+	}
+	return getDaysUntilExpiry(expiry, now, policy.DayRoundingFloor), true
+}
+
+// FilterWarningsByType returns the warnings whose type is one of the given
+// types, in their original order.
+func FilterWarningsByType(warnings []KeyWarning, types ...WarningType) []KeyWarning {
+	wanted := make(map[WarningType]bool)
+	for _, warningType := range types {
+		wanted[warningType] = true
+	}
+
+	filtered := []KeyWarning{}
+	for _, warning := range warnings {
+		if wanted[warning.Type] {
+			filtered = append(filtered, warning)
+		}
+	}
+	return filtered
+}
+
+// HasWarning returns true if any of the warnings has the given type.
+func HasWarning(warnings []KeyWarning, warningType WarningType) bool {
+	for _, warning := range warnings {
+		if warning.Type == warningType {
+			return true
+		}
+	}
+	return false
+}
+
+// GetKeyWarningsClean returns the KeyWarnings for the given PgpKey tidied up
+// for displaying to a user. Compared to GetKeyWarnings:
+//
+//  1. warnings made redundant by another warning on the key are removed (see
+//     supersededWarnings)
+//  2. the warnings are sorted by severity, most severe first. Warnings of
+//     the same severity stay in the order GetKeyWarnings returned them.
+func GetKeyWarningsClean(key pgpkey.PgpKey, config *config.Config, now time.Time) []KeyWarning {
+	return cleanWarnings(getKeyWarnings(key, config, policy.Policy{}, now))
+}
+
+func getKeyWarnings(key pgpkey.PgpKey, config *config.Config, p policy.Policy, now time.Time) []KeyWarning {
+	if revoked := getPrimaryKeyRevokedWarnings(key); len(revoked) > 0 {
+		// nothing else about the key matters any more
+		return revoked
+	}
+
 	var warnings []KeyWarning
-	now := time.Now()
 
-	warnings = append(warnings, getPrimaryKeyWarnings(key, now)...)
-	warnings = append(warnings, getEncryptionSubkeyWarnings(key, now)...)
+	warnings = append(warnings, getPrimaryKeyAlgorithmWarnings(key)...)
+	warnings = append(warnings, getPrimaryKeyWeakCipherWarnings(key)...)
+	warnings = append(warnings, getUserIdRevocationWarnings(key)...)
+	warnings = append(warnings, getVulnerableGenerationWarnings(key)...)
+	warnings = append(warnings, getPrimaryKeyWarnings(key, p, now)...)
+	warnings = append(warnings, getSecondaryUidExpiryWarnings(key, p, now)...)
+	warnings = append(warnings, getEncryptionSubkeyWarnings(key, p, now)...)
+	warnings = append(warnings, getSigningSubkeyWarnings(key, p, now)...)
+	warnings = append(warnings, getEncryptionCapabilityWarnings(key)...)
+	warnings = append(warnings, getEncryptionBrokenSigningIntactWarnings(key, now)...)
+	warnings = append(warnings, getEncryptionSubkeyRevokedWarnings(key, now)...)
+	warnings = append(warnings, getExpiryMismatchWarnings(key, p, now)...)
+	warnings = append(warnings, getDesignatedRevokerWarnings(key, p)...)
+	warnings = append(warnings, getRoleCapabilityWarnings(key, p)...)
+	warnings = append(warnings, getMinimumStrengthWarnings(key, p)...)
+
+	for name, identity := range key.Identities {
+		warnings = append(warnings, getSelfSignatureHashWarnings(identity.SelfSignature, name)...)
+	}
 
 	for _, selfSignature := range getIdentitySelfSignatures(&key) {
-		warnings = append(warnings, getSelfSignatureHashWarnings(selfSignature)...)
 		warnings = append(warnings, getCipherPreferenceWarnings(selfSignature.PreferredSymmetric)...)
 		warnings = append(warnings, getHashPreferenceWarnings(selfSignature.PreferredHash)...)
 		warnings = append(warnings, getCompressionPreferenceWarnings(selfSignature.PreferredCompression)...)
 	}
 
-	for _, bindingSignature := range getSubkeyBindingSignatures(&key) {
-		warnings = append(warnings, getSelfSignatureHashWarnings(bindingSignature)...)
+	for _, subkey := range key.Subkeys {
+		warnings = append(warnings, getSubkeyBindingSignatureHashWarnings(subkey.Sig, subkey.PublicKey.KeyId)...)
 		// TODO: check preferences (tho if missing, it's acceptable)
 	}
 
-	warnings = append(warnings, getConfigurationWarnings(key, config)...)
+	warnings = append(warnings, getSignatureHashPreferenceWarnings(key)...)
 
-	return warnings
+	if config != nil {
+		warnings = append(warnings, getConfigurationWarnings(key, config)...)
+	}
+
+	return DedupeWarnings(warnings)
+}
+
+// cleanWarnings removes duplicate and superseded warnings, then sorts by
+// severity.
+func cleanWarnings(warnings []KeyWarning) []KeyWarning {
+	deduped := DedupeWarnings(warnings)
+	clean := removeSupersededWarnings(deduped)
+	sort.Stable(BySeverity(clean))
+	return clean
+}
+
+// DedupeWarnings collapses warnings about the same thing into the first one
+// seen, keeping the order. Two warnings are about the same thing if they have
+// the same Type, SubkeyId, UserId and Detail: for example, each user ID has
+// its own self signature, so a key with 2 user IDs gets every preference
+// warning twice.
+//
+// Fields calculated from the key, like CurrentValidUntil and DaysUntilExpiry,
+// aren't compared.
+func DedupeWarnings(warnings []KeyWarning) []KeyWarning {
+	type warningSubject struct {
+		warningType WarningType
+		subkeyId    uint64
+		userId      string
+		detail      string
+	}
+
+	warningsSeen := make(map[warningSubject]bool)
+	deduped := []KeyWarning{}
+
+	for _, warning := range warnings {
+		subject := warningSubject{warning.Type, warning.SubkeyId, warning.UserId, warning.Detail}
+
+		if !warningsSeen[subject] {
+			deduped = append(deduped, warning)
+			warningsSeen[subject] = true
+		}
+	}
+	return deduped
 }
 
-func getEncryptionSubkeyWarnings(key pgpkey.PgpKey, now time.Time) []KeyWarning {
-	encryptionSubkey := key.EncryptionSubkey(now)
+// removeSupersededWarnings removes any warning whose type is superseded by
+// another warning present in the slice.
+func removeSupersededWarnings(warnings []KeyWarning) []KeyWarning {
+	var superseded = make(map[WarningType]bool)
+	for _, warning := range warnings {
+		for _, supersededType := range supersededBy(warning) {
+			superseded[supersededType] = true
+		}
+	}
+
+	remaining := []KeyWarning{}
+	for _, warning := range warnings {
+		if !superseded[warning.Type] {
+			remaining = append(remaining, warning)
+		}
+	}
+	return remaining
+}
+
+// supersededWarnings maps a warning type to the types it makes redundant:
+//
+//   - when the primary key has expired, the key is unusable until it's
+//     extended, so there's no point nagging about rotating the encryption
+//     subkey as well
+//   - a key which can't encrypt at all obviously has no valid encryption
+//     subkey
+//   - EncryptionBrokenSigningIntact and EncryptionSubkeyRevoked are more
+//     precise versions of NoValidEncryptionSubkey
+//   - RoleCapabilityMismatch for the encryption role explains why the key
+//     has no usable encryption subkey (see supersededBy)
+//   - a weak preferences warning already lists every preferred algorithm, and
+//     fixing it also removes any unsupported algorithm
+var supersededWarnings = map[WarningType][]WarningType{
+	PrimaryKeyExpired: []WarningType{
+		SubkeyDueForRotation,
+		SubkeyOverdueForRotation,
+	},
+	KeyCannotEncrypt: []WarningType{
+		NoValidEncryptionSubkey,
+	},
+	EncryptionBrokenSigningIntact: []WarningType{
+		NoValidEncryptionSubkey,
+	},
+	EncryptionSubkeyRevoked: []WarningType{
+		NoValidEncryptionSubkey,
+	},
+	RoleCapabilityMismatch: []WarningType{
+		NoValidEncryptionSubkey,
+		KeyCannotEncrypt,
+		EncryptionSubkeyRevoked,
+	},
+	WeakPreferredSymmetricAlgorithms: []WarningType{
+		UnsupportedPreferredSymmetricAlgorithm,
+	},
+	WeakPreferredHashAlgorithms: []WarningType{
+		UnsupportedPreferredHashAlgorithm,
+	},
+}
+
+// supersededBy returns the warning types made redundant by the given
+// warning, see supersededWarnings. A RoleCapabilityMismatch only supersedes
+// the encryption warnings if the key is meant for encryption: a key meant
+// for signing which can't sign may still have a broken encryption subkey.
+func supersededBy(warning KeyWarning) []WarningType {
+	if warning.Type == RoleCapabilityMismatch && !isRoleMismatchFor(warning, policy.RoleEncryption) {
+		return []WarningType{}
+	}
+	return supersededWarnings[warning.Type]
+}
+
+func getEncryptionSubkeyWarnings(key pgpkey.PgpKey, p policy.Policy, now time.Time) []KeyWarning {
+	encryptionSubkey := getBestEncryptionSubkey(key, now)
+
+	if encryptionSubkey == nil && primaryKeyCanEncrypt(key) {
+		// the key still works, but the primary key can't be rotated
+		// like an encryption subkey can
+		return []KeyWarning{KeyWarning{Type: PrimaryKeyUsedForEncryption}}
+	}
 
 	if encryptionSubkey == nil {
-		return []KeyWarning{KeyWarning{Type: NoValidEncryptionSubkey}}
+		warning := KeyWarning{Type: NoValidEncryptionSubkey}
+
+		if expired, expiry := getLatestExpiredEncryptionSubkey(key, now); expired != nil {
+			// say when encryption stopped working
+			warning.SubkeyId = expired.PublicKey.KeyId
+			warning.DaysSinceExpiry = getDaysSinceExpiry(*expiry, now, p.DayRounding)
+			warning.CurrentValidUntil = expiry
+		}
+		return []KeyWarning{warning}
 	}
 
 	subkeyId := encryptionSubkey.PublicKey.KeyId
 
+	if !pgpkey.IsPlausibleCreationTime(encryptionSubkey.PublicKey.CreationTime) {
+		// expiry warnings would be meaningless
+		return []KeyWarning{KeyWarning{Type: InvalidCreationTime, SubkeyId: subkeyId}}
+	}
+
+	if pgpkey.IsCreatedInFuture(encryptionSubkey.PublicKey.CreationTime, now) {
+		return []KeyWarning{KeyWarning{Type: KeyCreatedInFuture, SubkeyId: subkeyId}}
+	}
+
 	var warnings []KeyWarning
 
 	hasExpiry, expiry := pgpkey.SubkeyExpiry(*encryptionSubkey)
 
 	if hasExpiry {
-		nextRotation := policy.NextRotation(*expiry)
+		nextRotation := p.NextRotation(*expiry)
 
-		if isExpired(*expiry, now) {
+		// the subkey hasn't expired, see getBestEncryptionSubkey
+		if p.IsOverdueForRotation(nextRotation, now) {
 			warning := KeyWarning{
-				Type:              NoValidEncryptionSubkey,
+				Type:              SubkeyOverdueForRotation,
+				SubkeyId:          subkeyId,
+				DaysUntilExpiry:   getDaysUntilExpiry(*expiry, now, p.DayRounding),
 				CurrentValidUntil: expiry,
 			}
 			warnings = append(warnings, warning)
 
-		} else if policy.IsOverdueForRotation(nextRotation, now) {
+		} else if policy.IsDueForRotation(nextRotation, now) {
 			warning := KeyWarning{
-				Type:              SubkeyOverdueForRotation,
+				Type:              SubkeyDueForRotation,
 				SubkeyId:          subkeyId,
-				DaysUntilExpiry:   getDaysUntilExpiry(*expiry, now),
 				CurrentValidUntil: expiry,
 			}
 			warnings = append(warnings, warning)
+		} else {
+			warnings = append(warnings, getRotationScheduleWarnings(nextRotation, subkeyId, p)...)
+		}
 
-		} else if policy.IsDueForRotation(nextRotation, now) {
+		if needsExpiringSoonWarning(*expiry, nextRotation, p, now) {
 			warning := KeyWarning{
-				Type:              SubkeyDueForRotation,
+				Type:              ExpiringSoon,
 				SubkeyId:          subkeyId,
+				DaysUntilExpiry:   getDaysUntilExpiry(*expiry, now, p.DayRounding),
 				CurrentValidUntil: expiry,
 			}
 			warnings = append(warnings, warning)
 		}
 
-		if policy.IsExpiryTooLong(*expiry, now) {
+		if p.IsExpiryTooLong(*expiry, now) {
 			warning := KeyWarning{
 				Type:              SubkeyLongExpiry,
 				SubkeyId:          subkeyId,
@@ -108,36 +393,379 @@ func getEncryptionSubkeyWarnings(key pgpkey.PgpKey, now time.Time) []KeyWarning
 			warnings = append(warnings, warning)
 		}
 	} else { // no expiry
-		warning := KeyWarning{
-			Type:     SubkeyNoExpiry,
-			SubkeyId: subkeyId,
-		}
-		warnings = append(warnings, warning)
+		warnings = append(warnings, makeNoExpiryWarnings(
+			KeyWarning{Type: SubkeyNoExpiry, SubkeyId: subkeyId}, p,
+		)...)
+	}
+
+	if encryptionSubkey.Sig.FlagsValid && encryptionSubkey.Sig.FlagSign {
+		// encryption subkeys get rotated, and a signing key shouldn't
+		// have to be replaced along with them.
+		warnings = append(warnings, KeyWarning{Type: SubkeyCombinedEncryptAndSign, SubkeyId: subkeyId})
 	}
 
 	return warnings
 }
 
-func getPrimaryKeyWarnings(key pgpkey.PgpKey, now time.Time) []KeyWarning {
+// getExpiryMismatchWarnings returns MismatchedExpiryDates if the primary key
+// and the encryption subkey expire more than the policy's MaxExpiryGapDays
+// apart, since the owner then gets prompted to rotate one long before the
+// other.
+func getExpiryMismatchWarnings(key pgpkey.PgpKey, p policy.Policy, now time.Time) []KeyWarning {
+	if pgpkey.IsCreatedInFuture(key.PrimaryKey.CreationTime, now) {
+		return []KeyWarning{} // see KeyCreatedInFuture
+	}
+
+	hasPrimaryExpiry, primaryExpiry := getEarliestUidExpiry(key)
+	if !hasPrimaryExpiry || isExpired(*primaryExpiry, now) {
+		return []KeyWarning{}
+	}
+
+	encryptionSubkey := getBestEncryptionSubkey(key, now)
+	if encryptionSubkey == nil {
+		return []KeyWarning{}
+	}
+
+	hasSubkeyExpiry, subkeyExpiry := pgpkey.SubkeyExpiry(*encryptionSubkey)
+	if !hasSubkeyExpiry || !p.IsExpiryGapTooLarge(*primaryExpiry, *subkeyExpiry) {
+		return []KeyWarning{}
+	}
+
+	return []KeyWarning{KeyWarning{
+		Type:              MismatchedExpiryDates,
+		SubkeyId:          encryptionSubkey.PublicKey.KeyId,
+		CurrentValidUntil: primaryExpiry,
+		SubkeyValidUntil:  subkeyExpiry,
+	}}
+}
+
+// getBestEncryptionSubkey returns the currently valid encryption subkey which
+// keeps the key working for longest, or nil if there isn't one:
+//
+//  1. a subkey which never expires is preferred over one which does
+//  2. otherwise the subkey with the latest expiry is preferred
+//  3. subkeys with the same expiry are decided by creation time, newest first
+//     (the same as key.EncryptionSubkey)
+//
+// key.EncryptionSubkey picks the newest subkey, which is what senders
+// encrypt to, but if it expires sooner than an older subkey the key keeps
+// working until the older one expires.
+func getBestEncryptionSubkey(key pgpkey.PgpKey, now time.Time) *openpgp.Subkey {
+	var best *openpgp.Subkey
+
+	for _, subkey := range key.ValidEncryptionSubkeys(now) {
+		subkey := subkey
+		if best == nil || encryptionSubkeyLastsLonger(subkey, *best) {
+			best = &subkey
+		}
+	}
+	return best
+}
+
+// getLatestExpiredEncryptionSubkey returns the unrevoked encryption subkey
+// which expired most recently before `now`, and its expiry, or nil if there
+// isn't one.
+func getLatestExpiredEncryptionSubkey(key pgpkey.PgpKey, now time.Time) (*openpgp.Subkey, *time.Time) {
+	var latest *openpgp.Subkey
+	var latestExpiry *time.Time
+
+	for i := range key.Subkeys {
+		subkey := &key.Subkeys[i]
+		if !subkeyCanEncrypt(key, *subkey) || subkey.Sig.SigType == packet.SigTypeSubkeyRevocation {
+			continue
+		}
+		if subkey.PublicKey.CreationTime.After(now) {
+			continue
+		}
+
+		hasExpiry, expiry := pgpkey.SubkeyExpiry(*subkey)
+		if !hasExpiry || expiry.After(now) {
+			continue
+		}
+		if latestExpiry == nil || expiry.After(*latestExpiry) {
+			latest, latestExpiry = subkey, expiry
+		}
+	}
+	return latest, latestExpiry
+}
+
+// encryptionSubkeyLastsLonger returns true if subkey a should be preferred
+// over b, see getBestEncryptionSubkey.
+func encryptionSubkeyLastsLonger(a openpgp.Subkey, b openpgp.Subkey) bool {
+	aHasExpiry, aExpiry := pgpkey.SubkeyExpiry(a)
+	bHasExpiry, bExpiry := pgpkey.SubkeyExpiry(b)
+
+	switch {
+	case aHasExpiry != bHasExpiry:
+		return !aHasExpiry
+
+	case aHasExpiry && !aExpiry.Equal(*bExpiry):
+		return aExpiry.After(*bExpiry)
+	}
+	return a.PublicKey.CreationTime.After(b.PublicKey.CreationTime)
+}
+
+// getSigningSubkeyWarnings mirrors getEncryptionSubkeyWarnings for signing.
+// A key whose primary key signs doesn't need a signing subkey, so this only
+// returns NoValidSigningSubkey if neither the primary key nor any subkey can
+// sign. If there is a signing subkey, the newest one is checked for rotation.
+func getSigningSubkeyWarnings(key pgpkey.PgpKey, p policy.Policy, now time.Time) []KeyWarning {
+	signingSubkey := key.SigningSubkey(now)
+
+	if signingSubkey == nil {
+		if primaryKeyCanSign(key) {
+			return []KeyWarning{}
+		}
+		return []KeyWarning{KeyWarning{Type: NoValidSigningSubkey}}
+	}
+
+	subkeyId := signingSubkey.PublicKey.KeyId
+
+	if !pgpkey.IsPlausibleCreationTime(signingSubkey.PublicKey.CreationTime) {
+		return []KeyWarning{KeyWarning{Type: InvalidCreationTime, SubkeyId: subkeyId}}
+	}
+
+	if pgpkey.IsCreatedInFuture(signingSubkey.PublicKey.CreationTime, now) {
+		return []KeyWarning{KeyWarning{Type: KeyCreatedInFuture, SubkeyId: subkeyId}}
+	}
+
+	hasExpiry, expiry := pgpkey.SubkeyExpiry(*signingSubkey)
+	if !hasExpiry {
+		return makeNoExpiryWarnings(KeyWarning{Type: SigningSubkeyNoExpiry, SubkeyId: subkeyId}, p)
+	}
+
+	nextRotation := p.NextRotation(*expiry)
+
+	if p.IsOverdueForRotation(nextRotation, now) {
+		return []KeyWarning{KeyWarning{
+			Type:              SigningSubkeyOverdueForRotation,
+			SubkeyId:          subkeyId,
+			DaysUntilExpiry:   getDaysUntilExpiry(*expiry, now, p.DayRounding),
+			CurrentValidUntil: expiry,
+		}}
+	} else if policy.IsDueForRotation(nextRotation, now) {
+		return []KeyWarning{KeyWarning{
+			Type:              SigningSubkeyDueForRotation,
+			SubkeyId:          subkeyId,
+			CurrentValidUntil: expiry,
+		}}
+	}
+	return []KeyWarning{}
+}
+
+// getEncryptionCapabilityWarnings returns KeyCannotEncrypt if neither the
+// primary key nor any subkey (valid or not) is capable of encryption, for
+// example a signing-only key. Such a key can never be used as a recipient.
+func getEncryptionCapabilityWarnings(key pgpkey.PgpKey) []KeyWarning {
+	if primaryKeyCanEncrypt(key) {
+		return []KeyWarning{}
+	}
+
+	for _, subkey := range key.Subkeys {
+		if subkeyCanEncrypt(key, subkey) {
+			return []KeyWarning{}
+		}
+	}
+
+	return []KeyWarning{KeyWarning{Type: KeyCannotEncrypt}}
+}
+
+// getPrimaryKeyAlgorithmWarnings returns PrimaryKeyCannotSign if the primary
+// key uses an encryption-only algorithm (e.g. ElGamal). Such a key can't make
+// valid self signatures over its user IDs and subkeys, whatever its key flags
+// say.
+func getPrimaryKeyAlgorithmWarnings(key pgpkey.PgpKey) []KeyWarning {
+	if key.PrimaryKey.PubKeyAlgo.CanSign() {
+		return []KeyWarning{}
+	}
+	return []KeyWarning{KeyWarning{Type: PrimaryKeyCannotSign}}
+}
+
+// getDesignatedRevokerWarnings returns MissingDesignatedRevoker if the policy
+// requires a designated revoker and the key's self signatures don't name one.
+func getDesignatedRevokerWarnings(key pgpkey.PgpKey, p policy.Policy) []KeyWarning {
+	if !p.RequireDesignatedRevoker || len(key.DesignatedRevokers()) > 0 {
+		return []KeyWarning{}
+	}
+	return []KeyWarning{KeyWarning{Type: MissingDesignatedRevoker}}
+}
+
+// getEncryptionBrokenSigningIntactWarnings returns
+// EncryptionBrokenSigningIntact if the newest encryption subkey has expired
+// but the key can still sign. This is common after a partial rotation, and
+// only the encryption subkey needs replacing.
+func getEncryptionBrokenSigningIntactWarnings(key pgpkey.PgpKey, now time.Time) []KeyWarning {
+	if key.EncryptionSubkey(now) != nil || primaryKeyCanEncrypt(key) {
+		return []KeyWarning{} // encryption works
+	}
+
+	newest := newestEncryptionCapableSubkey(key)
+	if newest == nil {
+		return []KeyWarning{} // never could encrypt: see KeyCannotEncrypt
+	}
+
+	hasExpiry, expiry := pgpkey.SubkeyExpiry(*newest)
+	if !hasExpiry || !isExpired(*expiry, now) {
+		return []KeyWarning{}
+	}
+
+	if !keyCanSign(key, now) {
+		return []KeyWarning{}
+	}
+
+	return []KeyWarning{KeyWarning{
+		Type:              EncryptionBrokenSigningIntact,
+		SubkeyId:          newest.PublicKey.KeyId,
+		CurrentValidUntil: expiry,
+	}}
+}
+
+// getEncryptionSubkeyRevokedWarnings returns EncryptionSubkeyRevoked if the
+// key has no valid encryption subkey because every subkey flagged for
+// encryption has been revoked. The SubkeyId is the newest revoked subkey.
+func getEncryptionSubkeyRevokedWarnings(key pgpkey.PgpKey, now time.Time) []KeyWarning {
+	if key.EncryptionSubkey(now) != nil || primaryKeyCanEncrypt(key) {
+		return []KeyWarning{} // encryption works
+	}
+
+	newest := newestEncryptionCapableSubkey(key)
+	if newest == nil {
+		return []KeyWarning{} // never could encrypt: see KeyCannotEncrypt
+	}
+
+	for _, subkey := range key.Subkeys {
+		if subkeyCanEncrypt(key, subkey) && subkey.Sig.SigType != packet.SigTypeSubkeyRevocation {
+			return []KeyWarning{} // not every candidate is revoked
+		}
+	}
+
+	return []KeyWarning{KeyWarning{
+		Type:     EncryptionSubkeyRevoked,
+		SubkeyId: newest.PublicKey.KeyId,
+	}}
+}
+
+// newestEncryptionCapableSubkey returns the most recently created subkey
+// flagged for encryption (whether or not it's still valid), or nil if there
+// isn't one.
+func newestEncryptionCapableSubkey(key pgpkey.PgpKey) *openpgp.Subkey {
+	var newest *openpgp.Subkey
+
+	for i := range key.Subkeys {
+		subkey := &key.Subkeys[i]
+		if !subkeyCanEncrypt(key, *subkey) {
+			continue
+		}
+		if newest == nil || subkey.PublicKey.CreationTime.After(newest.PublicKey.CreationTime) {
+			newest = subkey
+		}
+	}
+	return newest
+}
+
+// keyCanSign returns true if the key is unrevoked and unexpired, and either
+// the primary key or a valid subkey is able to make signatures.
+func keyCanSign(key pgpkey.PgpKey, now time.Time) bool {
+	if len(key.Revocations) > 0 {
+		return false
+	}
+
+	if hasExpiry, expiry := getEarliestUidExpiry(key); hasExpiry && isExpired(*expiry, now) {
+		return false
+	}
+
+	if primaryKeyCanSign(key) {
+		return true
+	}
+
+	for _, subkey := range key.Subkeys {
+		if !subkey.PublicKey.PubKeyAlgo.CanSign() || !subkey.Sig.FlagsValid || !subkey.Sig.FlagSign {
+			continue
+		}
+		if subkey.Sig.SigType == packet.SigTypeSubkeyRevocation {
+			continue
+		}
+		if hasExpiry, expiry := pgpkey.SubkeyExpiry(subkey); hasExpiry && isExpired(*expiry, now) {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// primaryKeyCanSign returns true if the primary key's algorithm can sign and
+// its self signatures either flag it for signing or don't set key flags at
+// all.
+func primaryKeyCanSign(key pgpkey.PgpKey) bool {
+	if !key.PrimaryKey.PubKeyAlgo.CanSign() {
+		return false
+	}
+
+	for _, selfSig := range getIdentitySelfSignatures(&key) {
+		if !selfSig.FlagsValid || selfSig.FlagSign {
+			return true
+		}
+	}
+	return false
+}
+
+// getPrimaryKeyRevokedWarnings returns PrimaryKeyRevoked if the primary key
+// has a revocation signature. The openpgp package only keeps revocations
+// which verify against the primary key. Detail is the reason given, if any.
+func getPrimaryKeyRevokedWarnings(key pgpkey.PgpKey) []KeyWarning {
+	if len(key.Revocations) == 0 {
+		return []KeyWarning{}
+	}
+
+	warning := KeyWarning{Type: PrimaryKeyRevoked}
+	for _, revocation := range key.Revocations {
+		if revocation.RevocationReasonText != "" {
+			warning.Detail = revocation.RevocationReasonText
+			break
+		}
+	}
+	return []KeyWarning{warning}
+}
+
+func getPrimaryKeyWarnings(key pgpkey.PgpKey, p policy.Policy, now time.Time) []KeyWarning {
+	if len(key.Identities) == 0 {
+		// the expiry comes from the user ID self signatures, and a key
+		// without a user ID can't be used or uploaded anyway.
+		return []KeyWarning{KeyWarning{Type: PrimaryKeyNoUserId}}
+	}
+
+	if !pgpkey.IsPlausibleCreationTime(key.PrimaryKey.CreationTime) {
+		// the expiry is calculated from the creation time, so rather
+		// than calling a malformed key expired (or never expiring), say
+		// what's actually wrong.
+		return []KeyWarning{KeyWarning{Type: InvalidCreationTime}}
+	}
+
+	if pgpkey.IsCreatedInFuture(key.PrimaryKey.CreationTime, now) {
+		// the expiry would be counted from a time which hasn't happened
+		// yet, so the number of days until (or since) it is meaningless.
+		return []KeyWarning{KeyWarning{Type: KeyCreatedInFuture}}
+	}
+
 	var warnings []KeyWarning
 
 	hasExpiry, expiry := getEarliestUidExpiry(key)
 
 	if hasExpiry {
-		nextRotation := policy.NextRotation(*expiry)
+		nextRotation := p.NextRotation(*expiry)
 
 		if isExpired(*expiry, now) {
 			warning := KeyWarning{
 				Type:              PrimaryKeyExpired,
-				DaysSinceExpiry:   getDaysSinceExpiry(*expiry, now),
+				DaysSinceExpiry:   getDaysSinceExpiry(*expiry, now, p.DayRounding),
 				CurrentValidUntil: expiry,
 			}
 			warnings = append(warnings, warning)
 
-		} else if policy.IsOverdueForRotation(nextRotation, now) {
+		} else if p.IsOverdueForRotation(nextRotation, now) {
 			warning := KeyWarning{
 				Type:              PrimaryKeyOverdueForRotation,
-				DaysUntilExpiry:   getDaysUntilExpiry(*expiry, now),
+				DaysUntilExpiry:   getDaysUntilExpiry(*expiry, now, p.DayRounding),
 				CurrentValidUntil: expiry,
 			}
 
@@ -149,9 +777,20 @@ func getPrimaryKeyWarnings(key pgpkey.PgpKey, now time.Time) []KeyWarning {
 				CurrentValidUntil: expiry,
 			}
 			warnings = append(warnings, warning)
+		} else {
+			warnings = append(warnings, getRotationScheduleWarnings(nextRotation, 0, p)...)
 		}
 
-		if policy.IsExpiryTooLong(*expiry, now) {
+		if needsExpiringSoonWarning(*expiry, nextRotation, p, now) {
+			warning := KeyWarning{
+				Type:              ExpiringSoon,
+				DaysUntilExpiry:   getDaysUntilExpiry(*expiry, now, p.DayRounding),
+				CurrentValidUntil: expiry,
+			}
+			warnings = append(warnings, warning)
+		}
+
+		if p.IsExpiryTooLong(*expiry, now) {
 			warning := KeyWarning{
 				Type:              PrimaryKeyLongExpiry,
 				CurrentValidUntil: expiry,
@@ -159,13 +798,52 @@ func getPrimaryKeyWarnings(key pgpkey.PgpKey, now time.Time) []KeyWarning {
 			warnings = append(warnings, warning)
 		}
 	} else { // no expiry
-		warning := KeyWarning{Type: PrimaryKeyNoExpiry}
-		warnings = append(warnings, warning)
+		warnings = append(warnings, makeNoExpiryWarnings(KeyWarning{Type: PrimaryKeyNoExpiry}, p)...)
 	}
 
 	return warnings
 }
 
+// needsExpiringSoonWarning returns true if the expiry is within the policy's
+// ExpiringSoonDays, whatever the rotation schedule says. If the key is
+// already overdue for rotation the overdue warning includes the same
+// countdown, so ExpiringSoon would only repeat it.
+func needsExpiringSoonWarning(expiry time.Time, nextRotation time.Time, p policy.Policy, now time.Time) bool {
+	return !isExpired(expiry, now) &&
+		!p.IsOverdueForRotation(nextRotation, now) &&
+		p.IsExpiringSoon(expiry, now)
+}
+
+// getRotationScheduleWarnings returns RotationDueOnNonBusinessDay if the
+// policy has a calendar and the upcoming rotation falls on a day the owner
+// is unlikely to act, so they can be reminded to rotate the business day
+// before. subkeyId is 0 for the primary key.
+func getRotationScheduleWarnings(nextRotation time.Time, subkeyId uint64, p policy.Policy) []KeyWarning {
+	if p.Calendar == nil || p.Calendar.IsBusinessDay(nextRotation) {
+		return []KeyWarning{}
+	}
+
+	rotateBy := policy.PreviousBusinessDay(p.Calendar, nextRotation)
+	return []KeyWarning{KeyWarning{
+		Type:     RotationDueOnNonBusinessDay,
+		SubkeyId: subkeyId,
+		Detail:   rotateBy.Format("Monday 2 January 2006"),
+	}}
+}
+
+// makeNoExpiryWarnings applies the policy's NoExpiryTreatment to the given
+// PrimaryKeyNoExpiry, SubkeyNoExpiry or SigningSubkeyNoExpiry warning.
+func makeNoExpiryWarnings(warning KeyWarning, p policy.Policy) []KeyWarning {
+	switch p.NoExpiryTreatment {
+	case policy.NoExpiryIgnore:
+		return []KeyWarning{}
+
+	case policy.NoExpiryCritical:
+		warning.Escalated = true
+	}
+	return []KeyWarning{warning}
+}
+
 func getIdentitySelfSignatures(key *pgpkey.PgpKey) []*packet.Signature {
 	var selfSigs []*packet.Signature
 	for name, _ := range key.Identities {
@@ -175,15 +853,6 @@ func getIdentitySelfSignatures(key *pgpkey.PgpKey) []*packet.Signature {
 	return selfSigs
 }
 
-func getSubkeyBindingSignatures(key *pgpkey.PgpKey) []*packet.Signature {
-	var sigs []*packet.Signature
-	for _, subkey := range key.Subkeys {
-		sigs = append(sigs, subkey.Sig)
-	}
-
-	return sigs
-}
-
 func getCipherPreferenceWarnings(prefs []uint8) []KeyWarning {
 	if len(prefs) == 0 {
 		return []KeyWarning{KeyWarning{Type: MissingPreferredSymmetricAlgorithms}}
@@ -300,11 +969,16 @@ func getCompressionPreferenceWarnings(prefs []uint8) []KeyWarning {
 	return warnings
 
 }
-func getSelfSignatureHashWarnings(signature *packet.Signature) []KeyWarning {
+
+// getSelfSignatureHashWarnings returns WeakSelfSignatureHash if the self
+// signature on the given user ID uses a weak hash such as SHA1 or MD5, which
+// modern tools increasingly reject.
+func getSelfSignatureHashWarnings(signature *packet.Signature, userId string) []KeyWarning {
 	if !acceptableSignatureHash(&signature.Hash) {
 		return []KeyWarning{
 			KeyWarning{
 				Type:   WeakSelfSignatureHash,
+				UserId: userId,
 				Detail: nameOfHash(signature.Hash),
 			},
 		}
@@ -313,12 +987,92 @@ func getSelfSignatureHashWarnings(signature *packet.Signature) []KeyWarning {
 	}
 }
 
-func getSubkeyBindingSignatureHashWarnings(signature *packet.Signature) []KeyWarning {
+// getSignatureHashPreferenceWarnings returns SelfSigHashBelowPreferences for
+// each self signature or subkey binding signature made with a hash weaker
+// than the first (most preferred) hash, for example SHA224 when the key
+// prefers SHA512. This suggests the signatures were made by a tool which
+// ignored the preferences. A signature with a hash at least as strong as the
+// first preference is fine, even if that hash isn't listed.
+//
+// Each self signature is checked against its own preferences. Binding
+// signatures are checked against the preferences of the primary user ID.
+func getSignatureHashPreferenceWarnings(key pgpkey.PgpKey) []KeyWarning {
+	warnings := []KeyWarning{}
+
+	names := []string{}
+	for name := range key.Identities {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		selfSig := key.Identities[name].SelfSignature
+		warnings = append(warnings, checkSignatureHashPreference(selfSig, selfSig.PreferredHash, name, 0)...)
+	}
+
+	primaryIdentity := key.PrimaryIdentity()
+	if primaryIdentity == nil {
+		return warnings
+	}
+	for _, subkey := range key.Subkeys {
+		warnings = append(warnings, checkSignatureHashPreference(
+			key.SubkeyBindingSignature(subkey),
+			primaryIdentity.SelfSignature.PreferredHash,
+			"",
+			subkey.PublicKey.KeyId,
+		)...)
+	}
+	return warnings
+}
+
+// checkSignatureHashPreference returns SelfSigHashBelowPreferences if the
+// signature's hash is weaker than the first of the given preferences. It
+// returns no warnings if there are no preferences (see
+// MissingPreferredHashAlgorithms) or the first one isn't a hash we can rank.
+func checkSignatureHashPreference(signature *packet.Signature, preferences []uint8, userId string, subkeyId uint64) []KeyWarning {
+	if len(preferences) == 0 {
+		return []KeyWarning{}
+	}
+
+	preferredHash, ok := s2k.HashIdToHash(preferences[0])
+	if !ok || hashStrength[preferredHash] == 0 {
+		return []KeyWarning{} // can't tell what's weaker than it
+	}
+
+	if hashStrength[signature.Hash] >= hashStrength[preferredHash] {
+		return []KeyWarning{}
+	}
+	return []KeyWarning{KeyWarning{
+		Type:     SelfSigHashBelowPreferences,
+		UserId:   userId,
+		SubkeyId: subkeyId,
+		Detail:   fmt.Sprintf("%s instead of %s", nameOfHash(signature.Hash), hash.Name(preferences[0])),
+	}}
+}
+
+// hashStrength ranks signature hashes from weakest to strongest, so a
+// signature's hash can be compared with the key's preferences. Hashes which
+// aren't listed rank as 0, weaker than any of these.
+var hashStrength = map[crypto.Hash]int{
+	crypto.MD5:       1,
+	crypto.SHA1:      2,
+	crypto.RIPEMD160: 2,
+	crypto.SHA224:    3,
+	crypto.SHA256:    4,
+	crypto.SHA384:    5,
+	crypto.SHA512:    6,
+}
+
+// getSubkeyBindingSignatureHashWarnings returns
+// WeakSubkeyBindingSignatureHash if the given subkey's binding signature uses
+// a weak hash.
+func getSubkeyBindingSignatureHashWarnings(signature *packet.Signature, subkeyId uint64) []KeyWarning {
 	if !acceptableSignatureHash(&signature.Hash) {
 		return []KeyWarning{
 			KeyWarning{
-				Type:   WeakSubkeyBindingSignatureHash,
-				Detail: nameOfHash(signature.Hash),
+				Type:     WeakSubkeyBindingSignatureHash,
+				SubkeyId: subkeyId,
+				Detail:   nameOfHash(signature.Hash),
 			},
 		}
 	} else {
@@ -388,24 +1142,36 @@ func isExpired(expiry time.Time, now time.Time) bool {
 	return expiry.Before(now)
 }
 
-// getDaysSinceExpiry returns the number of whole 24-hour periods until the
-// `expiry`
-func getDaysUntilExpiry(expiry time.Time, now time.Time) uint {
-	days := inDays(expiry.Sub(now))
+// getDaysUntilExpiry returns the number of 24-hour periods until the
+// `expiry`, rounded according to `rounding`, or 0 if it has already passed.
+// Callers check isExpired first, so a past expiry only happens if the key is
+// checked at a different `now`, but that's no reason to crash.
+func getDaysUntilExpiry(expiry time.Time, now time.Time, rounding policy.DayRounding) uint {
+	days := inDays(expiry.Sub(now), rounding)
 	if days < 0 {
-		log.Panicf("getDaysUntilExpiry: expiry has already passed: %v", expiry)
+		return 0
 	}
 	return uint(days)
 }
 
-func inDays(duration time.Duration) int {
-	return int(duration.Seconds() / 86400)
+// inDays converts the duration to a number of days. DayRoundingFloor rounds
+// towards zero, so a negative duration of less than a day is 0 days.
+func inDays(duration time.Duration, rounding policy.DayRounding) int {
+	days := duration.Hours() / 24
+
+	switch rounding {
+	case policy.DayRoundingNearest:
+		return int(math.Round(days))
+	case policy.DayRoundingCeil:
+		return int(math.Ceil(days))
+	}
+	return int(math.Trunc(days))
 }
 
-// getDaysSinceExpiry returns the number of whole 24-hour periods that have
-// elapsed since `expiry`
-func getDaysSinceExpiry(expiry time.Time, now time.Time) uint {
-	days := inDays(now.Sub(expiry))
+// getDaysSinceExpiry returns the number of 24-hour periods that have elapsed
+// since `expiry`, rounded according to `rounding`.
+func getDaysSinceExpiry(expiry time.Time, now time.Time, rounding policy.DayRounding) uint {
+	days := inDays(now.Sub(expiry), rounding)
 	if days < 0 {
 		log.Panicf("getDaysSinceExpiry: expiry is in the future: %v", expiry)
 	}
@@ -423,13 +1189,15 @@ func getDaysSinceExpiry(expiry time.Time, now time.Time) uint {
 // If there are multiple UIDs we choose the earliest expiry, since that'll
 // disrupt the working of the key (plus, Keyflow advises not to use multiple
 // UIDs at all, let alone different expiry dates, so this is an edge-case)
+//
+// Revoked UIDs are ignored, see unrevokedIdentities.
 func getEarliestUidExpiry(key pgpkey.PgpKey) (bool, *time.Time) {
 	var allExpiryTimes []time.Time
 
-	for _, id := range key.Identities {
-		hasExpiry, expiryTime := pgpkey.CalculateExpiry(
+	for _, id := range unrevokedIdentities(key) {
+		hasExpiry, expiryTime := pgpkey.ExpiryFromSignature(
 			key.PrimaryKey.CreationTime, // not to be confused with the time of the *signature*
-			id.SelfSignature.KeyLifetimeSecs,
+			id.SelfSignature,
 		)
 		if hasExpiry {
 			allExpiryTimes = append(allExpiryTimes, *expiryTime)
@@ -444,6 +1212,84 @@ func getEarliestUidExpiry(key pgpkey.PgpKey) (bool, *time.Time) {
 	}
 }
 
+// unrevokedIdentities returns the key's identities which haven't been
+// revoked. If every identity has been revoked it returns all of them, so that
+// the key's expiry is still meaningful: PrimaryKeyNoValidUserId covers that
+// case.
+func unrevokedIdentities(key pgpkey.PgpKey) []*openpgp.Identity {
+	var unrevoked, all []*openpgp.Identity
+
+	for name, id := range key.Identities {
+		all = append(all, id)
+		if !key.IsUserIdRevoked(name) {
+			unrevoked = append(unrevoked, id)
+		}
+	}
+
+	if len(unrevoked) == 0 {
+		return all
+	}
+	return unrevoked
+}
+
+// getUserIdRevocationWarnings returns PrimaryKeyNoValidUserId if every user
+// ID on the key has been revoked. Most OpenPGP software won't use a key
+// without a valid user ID. A key with no user IDs at all gets
+// PrimaryKeyNoUserId from getPrimaryKeyWarnings instead.
+func getUserIdRevocationWarnings(key pgpkey.PgpKey) []KeyWarning {
+	if len(key.Identities) == 0 {
+		return []KeyWarning{}
+	}
+	for name := range key.Identities {
+		if !key.IsUserIdRevoked(name) {
+			return []KeyWarning{}
+		}
+	}
+	return []KeyWarning{KeyWarning{Type: PrimaryKeyNoValidUserId}}
+}
+
+// getSecondaryUidExpiryWarnings returns ExpiryDrivenBySecondaryUid if the
+// primary key is due for rotation only because of a user ID which isn't the
+// primary user ID, while another user ID expires later (or never). Revoking
+// that user ID is often easier than rotating the whole key, for example if
+// it's an old email address the owner has forgotten about.
+func getSecondaryUidExpiryWarnings(key pgpkey.PgpKey, p policy.Policy, now time.Time) []KeyWarning {
+	if pgpkey.IsCreatedInFuture(key.PrimaryKey.CreationTime, now) {
+		return []KeyWarning{} // see KeyCreatedInFuture
+	}
+
+	hasExpiry, earliestExpiry := getEarliestUidExpiry(key)
+	if !hasExpiry || !policy.IsDueForRotation(p.NextRotation(*earliestExpiry), now) {
+		return []KeyWarning{}
+	}
+
+	var constrainingUid *openpgp.Identity
+	otherUidExpiresLater := false
+
+	for _, id := range unrevokedIdentities(key) {
+		hasExpiry, expiry := pgpkey.ExpiryFromSignature(key.PrimaryKey.CreationTime, id.SelfSignature)
+		if hasExpiry && expiry.Equal(*earliestExpiry) {
+			if constrainingUid != nil {
+				return []KeyWarning{} // several user IDs share the earliest expiry
+			}
+			constrainingUid = id
+		} else {
+			otherUidExpiresLater = true
+		}
+	}
+
+	isPrimaryUid := constrainingUid.SelfSignature.IsPrimaryId != nil && *constrainingUid.SelfSignature.IsPrimaryId
+	if !otherUidExpiresLater || isPrimaryUid {
+		return []KeyWarning{}
+	}
+
+	return []KeyWarning{KeyWarning{
+		Type:              ExpiryDrivenBySecondaryUid,
+		Detail:            constrainingUid.Name,
+		CurrentValidUntil: earliestExpiry,
+	}}
+}
+
 // getEarliestExpiryTime returns the soonest expiry time from the key that
 // would cause it to lose functionality.
 //
@@ -464,15 +1310,16 @@ func getEarliestUidExpiry(key pgpkey.PgpKey) (bool, *time.Time) {
 //
 // There are also *signature expiration times* - the validity period of the
 // signature. https://tools.ietf.org/html/rfc4880#section-5.2.3.10
-// this is in Signature.SigLifetimeSecs
+// this is in Signature.SigLifetimeSecs, and pgpkey.ExpiryFromSignature takes
+// whichever of the two ends first.
 
 func getEarliestExpiryTime(key pgpkey.PgpKey) (bool, *time.Time) {
 	var allExpiryTimes []time.Time
 
 	for _, id := range key.Identities {
-		hasExpiry, expiryTime := pgpkey.CalculateExpiry(
+		hasExpiry, expiryTime := pgpkey.ExpiryFromSignature(
 			key.PrimaryKey.CreationTime, // not to be confused with the time of the *signature*
-			id.SelfSignature.KeyLifetimeSecs,
+			id.SelfSignature,
 		)
 		if hasExpiry {
 			allExpiryTimes = append(allExpiryTimes, *expiryTime)
@@ -6,7 +6,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/fluidkeys/crypto/openpgp"
 	"github.com/fluidkeys/crypto/openpgp/packet"
+	"github.com/fluidkeys/fluidkeys/assert"
 	"github.com/fluidkeys/fluidkeys/config"
 	"github.com/fluidkeys/fluidkeys/exampledata"
 	"github.com/fluidkeys/fluidkeys/openpgpdefs/compression"
@@ -42,113 +44,1287 @@ func TestGetEarliestExpiryTime(t *testing.T) {
 	}
 }
 
+func TestRevokedUserIds(t *testing.T) {
+	key, err := pgpkey.LoadFromArmoredPublicKey(exampledata.ExamplePublicKey11)
+	if err != nil {
+		t.Fatalf("failed to load example key: %v", err)
+	}
+
+	t.Run("getEarliestUidExpiry ignores the revoked user ID", func(t *testing.T) {
+		// the revoked "Old" user ID expires on 2025-01-01
+		hasExpiry, expiry := getEarliestUidExpiry(*key)
+		assert.Equal(t, true, hasExpiry)
+		assert.Equal(t, 2030, expiry.UTC().Year())
+	})
+
+	t.Run("getUserIdRevocationWarnings with a valid user ID", func(t *testing.T) {
+		assert.Equal(t, []KeyWarning{}, getUserIdRevocationWarnings(*key))
+	})
+
+	t.Run("getUserIdRevocationWarnings with every user ID revoked", func(t *testing.T) {
+		key, err := pgpkey.LoadFromArmoredPublicKey(exampledata.ExamplePublicKey11)
+		if err != nil {
+			t.Fatalf("failed to load example key: %v", err)
+		}
+		delete(key.Identities, "New <new11@example.com>")
+
+		expected := []KeyWarning{KeyWarning{Type: PrimaryKeyNoValidUserId}}
+		assert.Equal(t, expected, getUserIdRevocationWarnings(*key))
+
+		hasExpiry, expiry := getEarliestUidExpiry(*key)
+		assert.Equal(t, true, hasExpiry)
+		assert.Equal(t, 2025, expiry.UTC().Year())
+	})
+}
+
+func TestExpiry(t *testing.T) {
+	t.Run("with a key that expires", func(t *testing.T) {
+		key, err := pgpkey.LoadFromArmoredPublicKey(exampledata.ExamplePublicKey11)
+		if err != nil {
+			t.Fatalf("failed to load example key: %v", err)
+		}
+
+		expiry, hasExpiry := Expiry(*key)
+		assert.Equal(t, true, hasExpiry)
+		assert.Equal(t, time.Date(2030, 1, 1, 12, 0, 0, 0, time.UTC), expiry.UTC())
+	})
+
+	t.Run("with a key that never expires", func(t *testing.T) {
+		key, err := pgpkey.LoadFromArmoredPublicKey(exampledata.ExamplePublicKey4)
+		if err != nil {
+			t.Fatalf("failed to load example key: %v", err)
+		}
+
+		_, hasExpiry := Expiry(*key)
+		assert.Equal(t, false, hasExpiry)
+	})
+}
+
+func TestWholeKeyExpiry(t *testing.T) {
+	key, err := pgpkey.LoadFromArmoredPublicKey(exampledata.ExamplePublicKey14)
+	if err != nil {
+		t.Fatalf("failed to load example key: %v", err)
+	}
+	subkeyExpiry := time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("the encryption subkey expires before the user ID", func(t *testing.T) {
+		expiry, hasExpiry := WholeKeyExpiry(*key)
+		assert.Equal(t, true, hasExpiry)
+		assert.Equal(t, subkeyExpiry, expiry.UTC())
+
+		primaryExpiry, _ := Expiry(*key)
+		assert.Equal(t, 2030, primaryExpiry.UTC().Year())
+	})
+
+	t.Run("DaysUntilWholeKeyExpiry counts down to the subkey expiry", func(t *testing.T) {
+		days, hasExpiry := DaysUntilWholeKeyExpiry(*key, subkeyExpiry.Add(time.Duration(-49)*time.Hour))
+		assert.Equal(t, true, hasExpiry)
+		assert.Equal(t, uint(2), days)
+	})
+
+	t.Run("DaysUntilWholeKeyExpiry after the key has expired", func(t *testing.T) {
+		days, hasExpiry := DaysUntilWholeKeyExpiry(*key, subkeyExpiry.Add(time.Hour))
+		assert.Equal(t, true, hasExpiry)
+		assert.Equal(t, uint(0), days)
+	})
+
+	t.Run("with a key that never expires", func(t *testing.T) {
+		key, err := pgpkey.LoadFromArmoredPublicKey(exampledata.ExamplePublicKey4)
+		if err != nil {
+			t.Fatalf("failed to load example key: %v", err)
+		}
+		for _, subkey := range key.Subkeys {
+			subkey.Sig.KeyLifetimeSecs = nil
+		}
+
+		_, hasExpiry := WholeKeyExpiry(*key)
+		assert.Equal(t, false, hasExpiry)
+
+		_, hasExpiry = DaysUntilWholeKeyExpiry(*key, time.Now())
+		assert.Equal(t, false, hasExpiry)
+	})
+}
+
 func TestEarliest(t *testing.T) {
 	times := []time.Time{feb1st, march1st}
 
 	expected := feb1st
 
-	got := earliest(times)
-	if got != expected {
-		t.Fatalf("earliest(): expected '%v', got '%v'", expected, got)
+	got := earliest(times)
+	if got != expected {
+		t.Fatalf("earliest(): expected '%v', got '%v'", expected, got)
+	}
+}
+
+func TestDateHelpers(t *testing.T) {
+	now := time.Date(2018, 6, 15, 0, 0, 0, 0, time.UTC)
+	expiryInFuture := now.Add(time.Duration(1) * time.Hour)
+	expiryInPast := now.Add(time.Duration(-1) * time.Hour)
+
+	t.Run("isExpired with past date", func(t *testing.T) {
+		if isExpired(expiryInPast, now) != true {
+			t.Errorf("expected isExpired(%v, %v) to return true", expiryInPast, now)
+		}
+
+	})
+
+	t.Run("isExpired with future date", func(t *testing.T) {
+		if isExpired(expiryInFuture, now) != false {
+			t.Errorf("expected isExpired(%v, %v) to return true", expiryInFuture, now)
+		}
+	})
+
+	t.Run("getDaysSinceExpiry 1 hour in the past", func(t *testing.T) {
+		expected := uint(0)
+		got := getDaysSinceExpiry(now.Add(time.Duration(-1)*time.Hour), now, policy.DayRoundingFloor)
+
+		if got != expected {
+			t.Errorf("expected %v, got %v", expected, got)
+		}
+	})
+
+	t.Run("getDaysSinceExpiry 25 hours in the past", func(t *testing.T) {
+		expected := uint(1)
+		got := getDaysSinceExpiry(now.Add(time.Duration(-25)*time.Hour), now, policy.DayRoundingFloor)
+
+		if got != expected {
+			t.Errorf("expected %v, got %v", expected, got)
+		}
+	})
+
+	t.Run("getDaysUntilExpiry 1 hour in the future", func(t *testing.T) {
+		expected := uint(0)
+		got := getDaysUntilExpiry(now.Add(time.Duration(1)*time.Hour), now, policy.DayRoundingFloor)
+
+		if got != expected {
+			t.Errorf("expected %v, got %v", expected, got)
+		}
+	})
+
+	t.Run("getDaysUntilExpiry 25 hours in the future", func(t *testing.T) {
+		expected := uint(1)
+		got := getDaysUntilExpiry(now.Add(time.Duration(25)*time.Hour), now, policy.DayRoundingFloor)
+
+		if got != expected {
+			t.Errorf("expected %v, got %v", expected, got)
+		}
+	})
+
+	t.Run("getDaysUntilExpiry 25 hours in the past", func(t *testing.T) {
+		expected := uint(0)
+		got := getDaysUntilExpiry(now.Add(time.Duration(-25)*time.Hour), now, policy.DayRoundingFloor)
+
+		if got != expected {
+			t.Errorf("expected %v, got %v", expected, got)
+		}
+	})
+}
+
+func TestDayRounding(t *testing.T) {
+	now := time.Date(2018, 6, 15, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		hours    int
+		rounding policy.DayRounding
+		expected uint
+	}{
+		{23, policy.DayRoundingFloor, 0},
+		{25, policy.DayRoundingFloor, 1},
+		{47, policy.DayRoundingFloor, 1},
+		{49, policy.DayRoundingFloor, 2},
+
+		{23, policy.DayRoundingNearest, 1},
+		{25, policy.DayRoundingNearest, 1},
+		{47, policy.DayRoundingNearest, 2},
+		{49, policy.DayRoundingNearest, 2},
+
+		{23, policy.DayRoundingCeil, 1},
+		{25, policy.DayRoundingCeil, 2},
+		{47, policy.DayRoundingCeil, 2},
+		{49, policy.DayRoundingCeil, 3},
+	}
+
+	for _, test := range tests {
+		duration := time.Duration(test.hours) * time.Hour
+
+		t.Run(fmt.Sprintf("getDaysUntilExpiry %d hours in the future, rounding %d", test.hours, test.rounding), func(t *testing.T) {
+			got := getDaysUntilExpiry(now.Add(duration), now, test.rounding)
+			assert.Equal(t, test.expected, got)
+		})
+
+		t.Run(fmt.Sprintf("getDaysSinceExpiry %d hours in the past, rounding %d", test.hours, test.rounding), func(t *testing.T) {
+			got := getDaysSinceExpiry(now.Add(-duration), now, test.rounding)
+			assert.Equal(t, test.expected, got)
+		})
+	}
+
+	t.Run("getPrimaryKeyWarnings uses the policy's rounding", func(t *testing.T) {
+		key, err := pgpkey.LoadFromArmoredPublicKey(exampledata.ExamplePublicKey3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		hasExpiry, expiry := getEarliestUidExpiry(*key)
+		if !hasExpiry {
+			t.Fatalf("expected example key 3 to expire")
+		}
+		later := expiry.Add(47 * time.Hour)
+
+		got := FilterWarningsByType(getPrimaryKeyWarnings(*key, policy.Policy{DayRounding: policy.DayRoundingCeil}, later), PrimaryKeyExpired)
+		assertEqualSliceOfKeyWarningTypes(t, []KeyWarning{KeyWarning{Type: PrimaryKeyExpired}}, got)
+		assert.Equal(t, uint(2), got[0].DaysSinceExpiry)
+	})
+}
+
+func TestGetEncryptionSubkeyWarnings(t *testing.T) {
+	t.Run("with a primary key with long expiry date and a subkey overdue for rotation", func(t *testing.T) {
+		pgpKey, err := pgpkey.LoadFromArmoredEncryptedPrivateKey(exampledata.ExamplePrivateKey2, "test2")
+		if err != nil {
+			t.Fatalf("Failed to load example test data: %v", err)
+		}
+
+		now := time.Date(2018, 9, 24, 18, 0, 0, 0, time.UTC)
+		verySoon := now.Add(time.Duration(6) * time.Hour)
+		veryFarAway := now.Add(time.Duration(100*24) * time.Hour)
+
+		err = pgpKey.UpdateSubkeyValidUntil(pgpKey.EncryptionSubkey(now).PublicKey.KeyId, verySoon, now)
+		if err != nil {
+			t.Fatalf("failed to update expiry on test subkey")
+		}
+
+		err = pgpKey.UpdateExpiryForAllUserIds(veryFarAway, now)
+		if err != nil {
+			t.Fatalf("failed to update expiry on test key")
+		}
+
+		t.Run("test we get subkey overdue for rotation warning", func(t *testing.T) {
+			expected := []KeyWarning{
+				KeyWarning{Type: SubkeyOverdueForRotation},
+			}
+
+			got := getEncryptionSubkeyWarnings(*pgpKey, policy.Policy{}, now)
+
+			assertEqualSliceOfKeyWarningTypes(t, expected, got)
+		})
+
+		t.Run("test we get primary key long expiry warning", func(t *testing.T) {
+			expected := []KeyWarning{
+				KeyWarning{Type: PrimaryKeyLongExpiry},
+			}
+
+			now := time.Date(2018, 9, 24, 18, 0, 0, 0, time.UTC)
+			got := getPrimaryKeyWarnings(*pgpKey, policy.Policy{}, now)
+
+			assertEqualSliceOfKeyWarningTypes(t, expected, got)
+		})
+	})
+
+	t.Run("with a subkey binding signature which expires before the subkey", func(t *testing.T) {
+		pgpKey, err := pgpkey.LoadFromArmoredEncryptedPrivateKey(exampledata.ExamplePrivateKey2, "test2")
+		if err != nil {
+			t.Fatalf("Failed to load example test data: %v", err)
+		}
+
+		now := time.Date(2018, 9, 24, 18, 0, 0, 0, time.UTC)
+		veryFarAway := now.Add(time.Duration(100*24) * time.Hour)
+
+		subkey := pgpKey.EncryptionSubkey(now)
+		err = pgpKey.UpdateSubkeyValidUntil(subkey.PublicKey.KeyId, veryFarAway, now)
+		if err != nil {
+			t.Fatalf("failed to update expiry on test subkey")
+		}
+		err = pgpKey.UpdateExpiryForAllUserIds(veryFarAway, now)
+		if err != nil {
+			t.Fatalf("failed to update expiry on test key")
+		}
+
+		fiveDays := uint32(5 * 24 * 60 * 60)
+		subkey.Sig.SigLifetimeSecs = &fiveDays
+
+		t.Run("the signature expiry drives the rotation warning", func(t *testing.T) {
+			expected := []KeyWarning{
+				KeyWarning{Type: SubkeyOverdueForRotation},
+			}
+			got := getEncryptionSubkeyWarnings(*pgpKey, policy.Policy{}, now)
+			assertEqualSliceOfKeyWarningTypes(t, expected, got)
+			assert.Equal(t, now.Add(5*24*time.Hour), *got[0].CurrentValidUntil)
+		})
+
+		t.Run("the subkey stops working when the signature expires", func(t *testing.T) {
+			expected := []KeyWarning{
+				KeyWarning{Type: NoValidEncryptionSubkey},
+			}
+			got := getEncryptionSubkeyWarnings(*pgpKey, policy.Policy{}, now.Add(6*24*time.Hour))
+			assertEqualSliceOfKeyWarningTypes(t, expected, got)
+		})
+	})
+
+	t.Run("with a self signature which expires before the primary key", func(t *testing.T) {
+		pgpKey, err := pgpkey.LoadFromArmoredPublicKey(exampledata.ExamplePublicKey11)
+		if err != nil {
+			t.Fatalf("failed to load example key: %v", err)
+		}
+		now := time.Date(2018, 11, 15, 0, 0, 0, 0, time.UTC)
+
+		tenDays := uint32(10 * 24 * 60 * 60)
+		for _, identity := range pgpKey.Identities {
+			identity.SelfSignature.SigLifetimeSecs = &tenDays
+		}
+
+		// the self signatures were made on 1st November 2018, so they
+		// expired on the 11th, long before the key in 2030
+		expected := []KeyWarning{
+			KeyWarning{Type: PrimaryKeyExpired},
+		}
+		got := getPrimaryKeyWarnings(*pgpKey, policy.Policy{}, now)
+		assertEqualSliceOfKeyWarningTypes(t, expected, got)
+	})
+
+	t.Run("with a primary key used for encryption and no encryption subkey", func(t *testing.T) {
+		pgpKey, err := pgpkey.LoadFromArmoredPublicKey(exampledata.ExamplePublicKey12)
+		if err != nil {
+			t.Fatalf("failed to load example key: %v", err)
+		}
+		now := time.Date(2018, 11, 15, 0, 0, 0, 0, time.UTC)
+
+		expected := []KeyWarning{
+			KeyWarning{Type: PrimaryKeyUsedForEncryption},
+		}
+		got := getEncryptionSubkeyWarnings(*pgpKey, policy.Policy{}, now)
+		assert.Equal(t, expected, got)
+
+		t.Run("the key doesn't get other encryption warnings", func(t *testing.T) {
+			for _, warning := range GetKeyWarningsAt(*pgpKey, nil, now) {
+				switch warning.Type {
+				case NoValidEncryptionSubkey, KeyCannotEncrypt, EncryptionSubkeyRevoked:
+					t.Fatalf("unexpected warning %s", warning.Type.Name())
+				}
+			}
+		})
+	})
+
+	t.Run("with a primary key flagged for encryption and an encryption subkey", func(t *testing.T) {
+		pgpKey, err := pgpkey.LoadFromArmoredPublicKey(exampledata.ExamplePublicKey2)
+		if err != nil {
+			t.Fatalf("failed to load example key: %v", err)
+		}
+		now := time.Date(2018, 11, 15, 0, 0, 0, 0, time.UTC)
+
+		for _, identity := range pgpKey.Identities {
+			identity.SelfSignature.FlagEncryptCommunications = true
+		}
+
+		for _, warning := range getEncryptionSubkeyWarnings(*pgpKey, policy.Policy{}, now) {
+			if warning.Type == PrimaryKeyUsedForEncryption {
+				t.Fatalf("didn't expect PrimaryKeyUsedForEncryption for a key with an encryption subkey")
+			}
+		}
+	})
+
+	t.Run("with a subkey for both encryption and signing", func(t *testing.T) {
+		pgpKey, err := pgpkey.LoadFromArmoredPublicKey(exampledata.ExamplePublicKey9)
+		if err != nil {
+			t.Fatalf("failed to load example key: %v", err)
+		}
+		now := time.Date(2018, 11, 15, 0, 0, 0, 0, time.UTC)
+
+		expected := []KeyWarning{
+			KeyWarning{Type: SubkeyCombinedEncryptAndSign, SubkeyId: 0xD976E89E7D3A2633},
+		}
+		got := FilterWarningsByType(getEncryptionSubkeyWarnings(*pgpKey, policy.Policy{}, now), SubkeyCombinedEncryptAndSign)
+		assert.Equal(t, expected, got)
+	})
+
+	t.Run("with separate encryption and signing", func(t *testing.T) {
+		pgpKey, err := pgpkey.LoadFromArmoredPublicKey(exampledata.ExamplePublicKey13)
+		if err != nil {
+			t.Fatalf("failed to load example key: %v", err)
+		}
+		now := time.Date(2018, 11, 15, 0, 0, 0, 0, time.UTC)
+
+		got := getEncryptionSubkeyWarnings(*pgpKey, policy.Policy{}, now)
+		assert.Equal(t, false, HasWarning(got, SubkeyCombinedEncryptAndSign))
+	})
+}
+
+func TestExpiringSoonWarnings(t *testing.T) {
+	now := time.Date(2018, 9, 24, 18, 0, 0, 0, time.UTC)
+	sevenDays := time.Duration(7*24) * time.Hour
+
+	// rotating 3 days before expiry means the keys are never overdue for
+	// rotation, so only ExpiringSoon says they're about to expire
+	p := policy.Policy{RotateDaysBeforeExpiry: 3}
+
+	loadKey2 := func(t *testing.T, primaryExpiry time.Time, subkeyExpiry time.Time) *pgpkey.PgpKey {
+		t.Helper()
+		return loadKey2WithExpiry(t, now, primaryExpiry, subkeyExpiry)
+	}
+
+	t.Run("primary key exactly at the threshold", func(t *testing.T) {
+		key := loadKey2(t, now.Add(sevenDays), now.Add(2*sevenDays))
+		got := FilterWarningsByType(getPrimaryKeyWarnings(*key, p, now), ExpiringSoon)
+
+		assertEqualSliceOfKeyWarningTypes(t, []KeyWarning{KeyWarning{Type: ExpiringSoon}}, got)
+		assert.Equal(t, uint(7), got[0].DaysUntilExpiry)
+		assert.Equal(t, uint64(0), got[0].SubkeyId)
+	})
+
+	t.Run("primary key just beyond the threshold", func(t *testing.T) {
+		key := loadKey2(t, now.Add(sevenDays+time.Second), now.Add(2*sevenDays))
+		got := getPrimaryKeyWarnings(*key, p, now)
+		assert.Equal(t, false, HasWarning(got, ExpiringSoon))
+	})
+
+	t.Run("encryption subkey exactly at the threshold", func(t *testing.T) {
+		key := loadKey2(t, now.Add(2*sevenDays), now.Add(sevenDays))
+		got := FilterWarningsByType(getEncryptionSubkeyWarnings(*key, p, now), ExpiringSoon)
+
+		assertEqualSliceOfKeyWarningTypes(t, []KeyWarning{KeyWarning{Type: ExpiringSoon}}, got)
+		assert.Equal(t, key.EncryptionSubkey(now).PublicKey.KeyId, got[0].SubkeyId)
+	})
+
+	t.Run("encryption subkey just beyond the threshold", func(t *testing.T) {
+		key := loadKey2(t, now.Add(2*sevenDays), now.Add(sevenDays+time.Second))
+		got := getEncryptionSubkeyWarnings(*key, p, now)
+		assert.Equal(t, false, HasWarning(got, ExpiringSoon))
+	})
+
+	t.Run("with a configured threshold", func(t *testing.T) {
+		key := loadKey2(t, now.Add(2*sevenDays), now.Add(2*sevenDays))
+		p := policy.Policy{RotateDaysBeforeExpiry: 3, ExpiringSoonDays: 14}
+		assert.Equal(t, true, HasWarning(getPrimaryKeyWarnings(*key, p, now), ExpiringSoon))
+	})
+
+	t.Run("not repeated when already overdue for rotation", func(t *testing.T) {
+		key := loadKey2(t, now.Add(sevenDays), now.Add(sevenDays))
+		got := getPrimaryKeyWarnings(*key, policy.Policy{}, now)
+		assert.Equal(t, true, HasWarning(got, PrimaryKeyOverdueForRotation))
+		assert.Equal(t, false, HasWarning(got, ExpiringSoon))
+	})
+}
+
+func TestGetBestEncryptionSubkey(t *testing.T) {
+	// key 13's newer subkey expires on 2019-01-01, but its older subkey
+	// keeps working until 2030
+	key, err := pgpkey.LoadFromArmoredPublicKey(exampledata.ExamplePublicKey13)
+	if err != nil {
+		t.Fatalf("failed to load example key: %v", err)
+	}
+	olderSubkeyId := uint64(0xDDCFF77E7004C3AB)
+	newerSubkeyId := uint64(0xCF17D17D6C71C4B2)
+	now := time.Date(2018, 12, 15, 0, 0, 0, 0, time.UTC)
+
+	t.Run("prefers the subkey with the latest expiry", func(t *testing.T) {
+		assert.Equal(t, newerSubkeyId, key.EncryptionSubkey(now).PublicKey.KeyId)
+		assert.Equal(t, olderSubkeyId, getBestEncryptionSubkey(*key, now).PublicKey.KeyId)
+	})
+
+	t.Run("warnings are about the subkey with the latest expiry", func(t *testing.T) {
+		expected := []KeyWarning{
+			KeyWarning{Type: SubkeyLongExpiry, SubkeyId: olderSubkeyId},
+		}
+		got := getEncryptionSubkeyWarnings(*key, policy.Policy{}, now)
+		assertEqualSliceOfKeyWarningTypes(t, expected, got)
+		assert.Equal(t, olderSubkeyId, got[0].SubkeyId)
+	})
+
+	t.Run("prefers a subkey which never expires", func(t *testing.T) {
+		key, err := pgpkey.LoadFromArmoredPublicKey(exampledata.ExamplePublicKey13)
+		if err != nil {
+			t.Fatalf("failed to load example key: %v", err)
+		}
+		subkey, err := key.Subkey(newerSubkeyId)
+		if err != nil {
+			t.Fatalf("failed to get subkey: %v", err)
+		}
+		subkey.Sig.KeyLifetimeSecs = nil
+
+		assert.Equal(t, newerSubkeyId, getBestEncryptionSubkey(*key, now).PublicKey.KeyId)
+	})
+
+	t.Run("with the same expiry, prefers the newest subkey", func(t *testing.T) {
+		key, err := pgpkey.LoadFromArmoredPublicKey(exampledata.ExamplePublicKey13)
+		if err != nil {
+			t.Fatalf("failed to load example key: %v", err)
+		}
+		for _, subkey := range key.Subkeys {
+			subkey.Sig.KeyLifetimeSecs = nil
+		}
+
+		assert.Equal(t, newerSubkeyId, getBestEncryptionSubkey(*key, now).PublicKey.KeyId)
+	})
+
+	t.Run("with no valid encryption subkey", func(t *testing.T) {
+		key, err := pgpkey.LoadFromArmoredPublicKey(exampledata.ExamplePublicKey6)
+		if err != nil {
+			t.Fatalf("failed to load example key: %v", err)
+		}
+		if got := getBestEncryptionSubkey(*key, now); got != nil {
+			t.Fatalf("expected nil, got subkey 0x%X", got.PublicKey.KeyId)
+		}
+	})
+}
+
+func TestGetExpiryMismatchWarnings(t *testing.T) {
+	now := time.Date(2018, 11, 15, 0, 0, 0, 0, time.UTC)
+
+	t.Run("with aligned expiries", func(t *testing.T) {
+		key, err := pgpkey.LoadFromArmoredPublicKey(exampledata.ExamplePublicKey13)
+		if err != nil {
+			t.Fatalf("failed to load example key: %v", err)
+		}
+		assert.Equal(t, []KeyWarning{}, getExpiryMismatchWarnings(*key, policy.Policy{}, now))
+	})
+
+	key, err := pgpkey.LoadFromArmoredPublicKey(exampledata.ExamplePublicKey14)
+	if err != nil {
+		t.Fatalf("failed to load example key: %v", err)
+	}
+	primaryExpiry := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	subkeyExpiry := time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("with divergent expiries", func(t *testing.T) {
+		expected := []KeyWarning{KeyWarning{
+			Type:              MismatchedExpiryDates,
+			SubkeyId:          0xCEB2953975E4E846,
+			CurrentValidUntil: &primaryExpiry,
+			SubkeyValidUntil:  &subkeyExpiry,
+		}}
+		got := getExpiryMismatchWarnings(*key, policy.Policy{}, now)
+		assert.Equal(t, expected, got)
+		assert.Equal(t, true, HasWarning(GetKeyWarningsAt(*key, nil, now), MismatchedExpiryDates))
+	})
+
+	t.Run("with a gap the policy allows", func(t *testing.T) {
+		p := policy.Policy{MaxExpiryGapDays: 4000}
+		assert.Equal(t, []KeyWarning{}, getExpiryMismatchWarnings(*key, p, now))
+	})
+
+	t.Run("once the encryption subkey has expired", func(t *testing.T) {
+		later := subkeyExpiry.Add(time.Hour)
+		assert.Equal(t, []KeyWarning{}, getExpiryMismatchWarnings(*key, policy.Policy{}, later))
+	})
+}
+
+func TestGetSigningSubkeyWarnings(t *testing.T) {
+	now := time.Date(2018, 11, 15, 0, 0, 0, 0, time.UTC)
+
+	loadKey := func(t *testing.T, armoredKey string) *pgpkey.PgpKey {
+		t.Helper()
+		key, err := pgpkey.LoadFromArmoredPublicKey(armoredKey)
+		if err != nil {
+			t.Fatalf("failed to load example key: %v", err)
+		}
+		return key
+	}
+
+	t.Run("primary key signs and there's no signing subkey", func(t *testing.T) {
+		key := loadKey(t, exampledata.ExamplePublicKey5)
+		assert.Equal(t, []KeyWarning{}, getSigningSubkeyWarnings(*key, policy.Policy{}, now))
+	})
+
+	t.Run("certify-only primary key with an encryption-only subkey", func(t *testing.T) {
+		key := loadKey(t, exampledata.ExamplePublicKey8)
+		expected := []KeyWarning{KeyWarning{Type: NoValidSigningSubkey}}
+		assert.Equal(t, expected, getSigningSubkeyWarnings(*key, policy.Policy{}, now))
+	})
+
+	key := loadKey(t, exampledata.ExamplePublicKey9)
+	signingSubkey := key.SigningSubkey(now)
+	_, expiry := pgpkey.SubkeyExpiry(*signingSubkey)
+
+	t.Run("signing subkey and combined subkey, neither due", func(t *testing.T) {
+		assert.Equal(t, []KeyWarning{}, getSigningSubkeyWarnings(*key, policy.Policy{}, now))
+	})
+
+	t.Run("signing subkey due for rotation", func(t *testing.T) {
+		now := expiry.Add(time.Duration(-25*24) * time.Hour)
+		expected := []KeyWarning{KeyWarning{
+			Type:              SigningSubkeyDueForRotation,
+			SubkeyId:          0xD976E89E7D3A2633,
+			CurrentValidUntil: expiry,
+		}}
+		assert.Equal(t, expected, getSigningSubkeyWarnings(*key, policy.Policy{}, now))
+	})
+
+	t.Run("signing subkey overdue for rotation", func(t *testing.T) {
+		now := expiry.Add(time.Duration(-5*24) * time.Hour)
+		expected := []KeyWarning{KeyWarning{
+			Type:              SigningSubkeyOverdueForRotation,
+			SubkeyId:          0xD976E89E7D3A2633,
+			DaysUntilExpiry:   5,
+			CurrentValidUntil: expiry,
+		}}
+		assert.Equal(t, expected, getSigningSubkeyWarnings(*key, policy.Policy{}, now))
+	})
+
+	t.Run("signing subkeys expired", func(t *testing.T) {
+		now := expiry.Add(time.Hour)
+		expected := []KeyWarning{KeyWarning{Type: NoValidSigningSubkey}}
+		assert.Equal(t, expected, getSigningSubkeyWarnings(*key, policy.Policy{}, now))
+	})
+}
+
+func TestInvalidCreationTime(t *testing.T) {
+	now := time.Date(2018, 9, 24, 18, 0, 0, 0, time.UTC)
+
+	t.Run("with a zero primary key creation time", func(t *testing.T) {
+		key, err := pgpkey.LoadFromArmoredPublicKey(exampledata.ExamplePublicKey3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		key.PrimaryKey.CreationTime = time.Unix(0, 0)
+
+		expected := []KeyWarning{KeyWarning{Type: InvalidCreationTime}}
+		assertEqualSliceOfKeyWarningTypes(t, expected, getPrimaryKeyWarnings(*key, policy.Policy{}, now))
+	})
+
+	t.Run("with a zero subkey creation time", func(t *testing.T) {
+		key, err := pgpkey.LoadFromArmoredPublicKey(exampledata.ExamplePublicKey3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		subkeyId := key.Subkeys[0].PublicKey.KeyId
+		key.Subkeys[0].PublicKey.CreationTime = time.Unix(0, 0)
+
+		got := getEncryptionSubkeyWarnings(*key, policy.Policy{}, now)
+		expected := []KeyWarning{KeyWarning{Type: InvalidCreationTime}}
+		assertEqualSliceOfKeyWarningTypes(t, expected, got)
+		assert.Equal(t, subkeyId, got[0].SubkeyId)
+	})
+}
+
+func TestExpiredEncryptionSubkey(t *testing.T) {
+	// key 16's only encryption subkey expired on 2018-12-01
+	key, err := pgpkey.LoadFromArmoredPublicKey(exampledata.ExamplePublicKey16)
+	if err != nil {
+		t.Fatalf("failed to load example key: %v", err)
+	}
+	subkeyExpiry := time.Date(2018, 12, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("says how long ago the subkey expired", func(t *testing.T) {
+		now := time.Date(2018, 12, 13, 12, 0, 0, 0, time.UTC)
+		got := getEncryptionSubkeyWarnings(*key, policy.Policy{}, now)
+
+		assert.Equal(t, 1, len(got))
+		assert.Equal(t, WarningType(NoValidEncryptionSubkey), got[0].Type)
+		assert.Equal(t, uint64(0xA3B50F1D21870240), got[0].SubkeyId)
+		assert.Equal(t, uint(12), got[0].DaysSinceExpiry)
+		assert.Equal(t, subkeyExpiry, got[0].CurrentValidUntil.UTC())
+	})
+
+	t.Run("rounds the days according to the policy", func(t *testing.T) {
+		now := time.Date(2018, 12, 13, 18, 0, 0, 0, time.UTC)
+		got := getEncryptionSubkeyWarnings(*key, policy.Policy{DayRounding: policy.DayRoundingCeil}, now)
+
+		assert.Equal(t, 1, len(got))
+		assert.Equal(t, uint(13), got[0].DaysSinceExpiry)
+	})
+
+	t.Run("before the subkey expired", func(t *testing.T) {
+		now := time.Date(2018, 11, 2, 0, 0, 0, 0, time.UTC)
+		for _, warning := range getEncryptionSubkeyWarnings(*key, policy.Policy{}, now) {
+			assert.Equal(t, false, warning.Type == NoValidEncryptionSubkey)
+		}
+	})
+}
+
+func TestPrimaryKeyRevoked(t *testing.T) {
+	// key 17 was revoked on 2018-11-15, and would have expired 2019-12-01
+	key, err := pgpkey.LoadFromArmoredPublicKey(exampledata.ExamplePublicKey17)
+	if err != nil {
+		t.Fatalf("failed to load example key: %v", err)
+	}
+	expected := []KeyWarning{KeyWarning{Type: PrimaryKeyRevoked, Detail: "Key was compromised"}}
+
+	for _, now := range []time.Time{
+		time.Date(2018, 11, 20, 0, 0, 0, 0, time.UTC), // otherwise fine
+		time.Date(2019, 11, 20, 0, 0, 0, 0, time.UTC), // due for rotation
+		time.Date(2019, 12, 20, 0, 0, 0, 0, time.UTC), // expired
+	} {
+		t.Run(fmt.Sprintf("at %s", now.Format("2006-01-02")), func(t *testing.T) {
+			assert.Equal(t, expected, GetKeyWarningsWithPolicy(*key, nil, policy.Policy{}, now))
+		})
+	}
+
+	t.Run("with a key that isn't revoked", func(t *testing.T) {
+		key, err := pgpkey.LoadFromArmoredPublicKey(exampledata.ExamplePublicKey14)
+		if err != nil {
+			t.Fatalf("failed to load example key: %v", err)
+		}
+		assert.Equal(t, []KeyWarning{}, getPrimaryKeyRevokedWarnings(*key))
+	})
+}
+
+func TestKeyCreatedInFuture(t *testing.T) {
+	now := time.Date(2018, 9, 24, 18, 0, 0, 0, time.UTC)
+
+	loadKey := func() *pgpkey.PgpKey {
+		key, err := pgpkey.LoadFromArmoredPublicKey(exampledata.ExamplePublicKey15)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return key
+	}
+
+	t.Run("with a primary key created in the future", func(t *testing.T) {
+		key := loadKey()
+		expected := []KeyWarning{KeyWarning{Type: KeyCreatedInFuture}}
+		assertEqualSliceOfKeyWarningTypes(t, expected, getPrimaryKeyWarnings(*key, policy.Policy{}, now))
+	})
+
+	t.Run("within the tolerance for clock skew", func(t *testing.T) {
+		key := loadKey()
+		justBefore := key.PrimaryKey.CreationTime.Add(-10 * time.Minute)
+
+		for _, warning := range getPrimaryKeyWarnings(*key, policy.Policy{}, justBefore) {
+			assert.Equal(t, false, warning.Type == KeyCreatedInFuture)
+		}
+	})
+
+	t.Run("GetKeyWarningsWithPolicy doesn't panic", func(t *testing.T) {
+		for _, rounding := range []policy.DayRounding{
+			policy.DayRoundingFloor, policy.DayRoundingNearest, policy.DayRoundingCeil,
+		} {
+			key := loadKey()
+			warnings := GetKeyWarningsWithPolicy(*key, nil, policy.Policy{DayRounding: rounding}, now)
+
+			gotKeyCreatedInFuture := false
+			for _, warning := range warnings {
+				if warning.Type == KeyCreatedInFuture && warning.SubkeyId == 0 {
+					gotKeyCreatedInFuture = true
+				}
+				assert.Equal(t, uint(0), warning.DaysSinceExpiry)
+			}
+			assert.Equal(t, true, gotKeyCreatedInFuture)
+		}
+	})
+}
+
+func TestNoUserId(t *testing.T) {
+	now := time.Date(2018, 9, 24, 18, 0, 0, 0, time.UTC)
+
+	key, err := pgpkey.LoadFromArmoredPublicKey(exampledata.ExamplePublicKey3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key.Identities = map[string]*openpgp.Identity{}
+
+	t.Run("getPrimaryKeyWarnings", func(t *testing.T) {
+		expected := []KeyWarning{KeyWarning{Type: PrimaryKeyNoUserId}}
+		assertEqualSliceOfKeyWarningTypes(t, expected, getPrimaryKeyWarnings(*key, policy.Policy{}, now))
+	})
+
+	t.Run("getUserIdRevocationWarnings doesn't also say every user ID is revoked", func(t *testing.T) {
+		assert.Equal(t, []KeyWarning{}, getUserIdRevocationWarnings(*key))
+	})
+}
+
+func TestGetEncryptionCapabilityWarnings(t *testing.T) {
+	t.Run("with an encryption subkey", func(t *testing.T) {
+		key, err := pgpkey.LoadFromArmoredPublicKey(exampledata.ExamplePublicKey3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertEqualSliceOfKeyWarningTypes(t, []KeyWarning{}, getEncryptionCapabilityWarnings(*key))
+	})
+
+	t.Run("with an expired encryption subkey", func(t *testing.T) {
+		key, err := pgpkey.LoadFromArmoredEncryptedPrivateKey(exampledata.ExamplePrivateKey2, "test2")
+		if err != nil {
+			t.Fatal(err)
+		}
+		now := time.Date(2018, 9, 24, 18, 0, 0, 0, time.UTC)
+		err = key.ExpireSubkey(key.EncryptionSubkey(now).PublicKey.KeyId, now)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// the key can still encrypt once a new subkey is added
+		assertEqualSliceOfKeyWarningTypes(t, []KeyWarning{}, getEncryptionCapabilityWarnings(*key))
+	})
+
+	t.Run("with a signing-only key", func(t *testing.T) {
+		key, err := pgpkey.LoadFromArmoredPublicKey(exampledata.ExamplePublicKey3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		key.Subkeys = nil
+
+		expected := []KeyWarning{KeyWarning{Type: KeyCannotEncrypt}}
+		assertEqualSliceOfKeyWarningTypes(t, expected, getEncryptionCapabilityWarnings(*key))
+	})
+}
+
+func TestNoExpiryTreatment(t *testing.T) {
+	now := time.Date(2018, 9, 24, 18, 0, 0, 0, time.UTC)
+	key, err := pgpkey.LoadFromArmoredPublicKey(exampledata.ExamplePublicKey2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// make the primary key and encryption subkey never expire
+	for _, identity := range key.Identities {
+		identity.SelfSignature.KeyLifetimeSecs = nil
+	}
+	for _, subkey := range key.Subkeys {
+		subkey.Sig.KeyLifetimeSecs = nil
+	}
+
+	findNoExpiryWarnings := func(warnings []KeyWarning) []KeyWarning {
+		found := []KeyWarning{}
+		for _, warning := range warnings {
+			if warning.Type == PrimaryKeyNoExpiry || warning.Type == SubkeyNoExpiry {
+				found = append(found, warning)
+			}
+		}
+		return found
+	}
+
+	t.Run("warns by default", func(t *testing.T) {
+		got := findNoExpiryWarnings(GetKeyWarningsWithPolicy(*key, &config.Config{}, policy.Policy{}, now))
+
+		expected := []KeyWarning{
+			KeyWarning{Type: PrimaryKeyNoExpiry},
+			KeyWarning{Type: SubkeyNoExpiry},
+		}
+		assertEqualSliceOfKeyWarningTypes(t, expected, got)
+		assert.Equal(t, SeverityMedium, got[0].Severity())
+	})
+
+	t.Run("with NoExpiryIgnore", func(t *testing.T) {
+		p := policy.Policy{NoExpiryTreatment: policy.NoExpiryIgnore}
+		got := findNoExpiryWarnings(GetKeyWarningsWithPolicy(*key, &config.Config{}, p, now))
+
+		assertEqualSliceOfKeyWarningTypes(t, []KeyWarning{}, got)
+	})
+
+	t.Run("with NoExpiryCritical", func(t *testing.T) {
+		p := policy.Policy{NoExpiryTreatment: policy.NoExpiryCritical}
+		got := findNoExpiryWarnings(GetKeyWarningsWithPolicy(*key, &config.Config{}, p, now))
+
+		expected := []KeyWarning{
+			KeyWarning{Type: PrimaryKeyNoExpiry},
+			KeyWarning{Type: SubkeyNoExpiry},
+		}
+		assertEqualSliceOfKeyWarningTypes(t, expected, got)
+		assert.Equal(t, SeverityCritical, got[0].Severity())
+		assert.Equal(t, SeverityCritical, got[1].Severity())
+	})
+}
+
+func TestGetSignatureHashPreferenceWarnings(t *testing.T) {
+	loadKeyWithPreferences := func(t *testing.T, preferences []uint8, selfSigHash crypto.Hash, bindingSigHash crypto.Hash) *pgpkey.PgpKey {
+		t.Helper()
+		key, err := pgpkey.LoadFromArmoredPublicKey(exampledata.ExamplePublicKey4)
+		if err != nil {
+			t.Fatalf("failed to load example key: %v", err)
+		}
+		for _, identity := range key.Identities {
+			identity.SelfSignature.PreferredHash = preferences
+			identity.SelfSignature.Hash = selfSigHash
+		}
+		for _, subkey := range key.Subkeys {
+			subkey.Sig.Hash = bindingSigHash
+		}
+		return key
+	}
+	loadKey := func(t *testing.T, selfSigHash crypto.Hash, bindingSigHash crypto.Hash) *pgpkey.PgpKey {
+		t.Helper()
+		return loadKeyWithPreferences(t, []uint8{hash.Sha512, hash.Sha384}, selfSigHash, bindingSigHash)
+	}
+
+	t.Run("signatures match the first preference", func(t *testing.T) {
+		key := loadKey(t, crypto.SHA512, crypto.SHA512)
+		assertEqualSliceOfKeyWarningTypes(t, []KeyWarning{}, getSignatureHashPreferenceWarnings(*key))
+	})
+
+	t.Run("self signature hash is weaker than the first preference", func(t *testing.T) {
+		key := loadKey(t, crypto.SHA256, crypto.SHA512)
+		expected := []KeyWarning{KeyWarning{
+			Type:   SelfSigHashBelowPreferences,
+			UserId: "test4@example.com",
+			Detail: "SHA256 instead of SHA512",
+		}}
+		assert.Equal(t, expected, getSignatureHashPreferenceWarnings(*key))
+	})
+
+	t.Run("binding signature hash is weaker than the first preference", func(t *testing.T) {
+		key := loadKey(t, crypto.SHA512, crypto.SHA224)
+		got := getSignatureHashPreferenceWarnings(*key)
+		assertEqualSliceOfKeyWarningTypes(t, []KeyWarning{KeyWarning{Type: SelfSigHashBelowPreferences}}, got)
+		assert.Equal(t, key.Subkeys[0].PublicKey.KeyId, got[0].SubkeyId)
+	})
+
+	t.Run("with SHA512 first and a SHA1 self signature", func(t *testing.T) {
+		key := loadKeyWithPreferences(t, []uint8{hash.Sha512, hash.Sha256, hash.Sha1}, crypto.SHA1, crypto.SHA512)
+		expected := []KeyWarning{KeyWarning{
+			Type:   SelfSigHashBelowPreferences,
+			UserId: "test4@example.com",
+			Detail: "SHA1 instead of SHA512",
+		}}
+		assert.Equal(t, expected, getSignatureHashPreferenceWarnings(*key))
+	})
+
+	t.Run("with SHA1 first, any signature hash is as strong", func(t *testing.T) {
+		key := loadKeyWithPreferences(t, []uint8{hash.Sha1, hash.Sha512}, crypto.SHA256, crypto.SHA1)
+		assertEqualSliceOfKeyWarningTypes(t, []KeyWarning{}, getSignatureHashPreferenceWarnings(*key))
+	})
+
+	t.Run("signature hash stronger than any preference", func(t *testing.T) {
+		key := loadKeyWithPreferences(t, []uint8{hash.Sha256}, crypto.SHA512, crypto.SHA384)
+		assertEqualSliceOfKeyWarningTypes(t, []KeyWarning{}, getSignatureHashPreferenceWarnings(*key))
+	})
+
+	t.Run("each self signature is checked against its own preferences", func(t *testing.T) {
+		key, err := pgpkey.LoadFromArmoredPublicKey(exampledata.ExamplePublicKey3)
+		if err != nil {
+			t.Fatalf("failed to load example key: %v", err)
+		}
+		isPrimary := true
+		for name, identity := range key.Identities {
+			identity.SelfSignature.IsPrimaryId = nil
+			identity.SelfSignature.Hash = crypto.SHA256
+			identity.SelfSignature.PreferredHash = []uint8{hash.Sha256}
+			if name == "<test3@example.com>" {
+				identity.SelfSignature.IsPrimaryId = &isPrimary
+				identity.SelfSignature.PreferredHash = []uint8{hash.Sha512}
+			}
+		}
+		for _, subkey := range key.Subkeys {
+			subkey.Sig.Hash = crypto.SHA256
+		}
+
+		got := getSignatureHashPreferenceWarnings(*key)
+
+		expected := []KeyWarning{
+			KeyWarning{
+				Type:   SelfSigHashBelowPreferences,
+				UserId: "<test3@example.com>",
+				Detail: "SHA256 instead of SHA512",
+			},
+		}
+		for _, subkey := range key.Subkeys {
+			expected = append(expected, KeyWarning{
+				Type:     SelfSigHashBelowPreferences,
+				SubkeyId: subkey.PublicKey.KeyId,
+				Detail:   "SHA256 instead of SHA512",
+			})
+		}
+		assert.Equal(t, expected, got)
+	})
+
+	t.Run("checks the binding signature of a revoked subkey", func(t *testing.T) {
+		key, err := pgpkey.LoadFromArmoredPublicKey(exampledata.ExamplePublicKey10)
+		if err != nil {
+			t.Fatalf("failed to load example key: %v", err)
+		}
+		for _, identity := range key.Identities {
+			identity.SelfSignature.Hash = crypto.SHA512
+			identity.SelfSignature.PreferredHash = []uint8{hash.Sha512}
+		}
+		var revokedSubkeyId uint64 = 0xF291DE5ADD97892E
+		for _, subkey := range key.Subkeys {
+			subkey.Sig.Hash = crypto.SHA512
+			if subkey.PublicKey.KeyId == revokedSubkeyId {
+				subkey.Sig.Hash = crypto.SHA1 // the revocation signature
+				key.SubkeyBindingSignature(subkey).Hash = crypto.SHA256
+			}
+		}
+
+		expected := []KeyWarning{KeyWarning{
+			Type:     SelfSigHashBelowPreferences,
+			SubkeyId: revokedSubkeyId,
+			Detail:   "SHA256 instead of SHA512",
+		}}
+		assert.Equal(t, expected, getSignatureHashPreferenceWarnings(*key))
+	})
+}
+
+func TestGetKeyWarningsAt(t *testing.T) {
+	key, err := pgpkey.LoadFromArmoredPublicKey(exampledata.ExamplePublicKey2)
+	if err != nil {
+		t.Fatalf("failed to load example key: %v", err)
 	}
+	primaryKeyExpiry := time.Unix(2167466389, 0).UTC()
+	nextRotation := primaryKeyExpiry.Add(-30 * 24 * time.Hour)
+
+	t.Run("just before the rotation date", func(t *testing.T) {
+		got := GetKeyWarningsAt(*key, &config.Config{}, nextRotation.Add(-time.Second))
+		assert.Equal(t, false, HasWarning(got, PrimaryKeyDueForRotation))
+	})
+
+	t.Run("just after the rotation date", func(t *testing.T) {
+		got := GetKeyWarningsAt(*key, &config.Config{}, nextRotation.Add(time.Second))
+		assert.Equal(t, true, HasWarning(got, PrimaryKeyDueForRotation))
+	})
+
+	t.Run("just after expiry", func(t *testing.T) {
+		got := GetKeyWarningsAt(*key, &config.Config{}, primaryKeyExpiry.Add(time.Second))
+		assert.Equal(t, true, HasWarning(got, PrimaryKeyExpired))
+	})
+
+	t.Run("with a policy rotating 7 days before expiry", func(t *testing.T) {
+		p := policy.Policy{RotateDaysBeforeExpiry: 7}
+		now := primaryKeyExpiry.Add(time.Duration(-8*24) * time.Hour)
+
+		got := GetKeyWarningsWithPolicy(*key, &config.Config{}, p, now)
+		assert.Equal(t, false, HasWarning(got, PrimaryKeyDueForRotation))
+
+		got = GetKeyWarningsWithPolicy(*key, &config.Config{}, policy.DefaultPolicy(), now)
+		assert.Equal(t, true, HasWarning(got, PrimaryKeyOverdueForRotation))
+	})
 }
 
-func TestDateHelpers(t *testing.T) {
-	now := time.Date(2018, 6, 15, 0, 0, 0, 0, time.UTC)
-	expiryInFuture := now.Add(time.Duration(1) * time.Hour)
-	expiryInPast := now.Add(time.Duration(-1) * time.Hour)
+func TestGetSecondaryUidExpiryWarnings(t *testing.T) {
+	now := time.Date(2018, 9, 24, 18, 0, 0, 0, time.UTC)
+	twentyDays := uint32(20 * 24 * 60 * 60)
 
-	t.Run("isExpired with past date", func(t *testing.T) {
-		if isExpired(expiryInPast, now) != true {
-			t.Errorf("expected isExpired(%v, %v) to return true", expiryInPast, now)
+	// loadKey returns key 3 with <test3@example.com> as the primary user ID,
+	// and the given lifetimes for it and <another@example.com>
+	loadKey := func(t *testing.T, primaryLifetime *uint32, secondaryLifetime *uint32) *pgpkey.PgpKey {
+		t.Helper()
+		key, err := pgpkey.LoadFromArmoredPublicKey(exampledata.ExamplePublicKey3)
+		if err != nil {
+			t.Fatalf("failed to load example key: %v", err)
+		}
+		isPrimary := true
+		for name, identity := range key.Identities {
+			identity.SelfSignature.IsPrimaryId = nil
+			identity.SelfSignature.KeyLifetimeSecs = nil
+
+			switch name {
+			case "<test3@example.com>":
+				identity.SelfSignature.IsPrimaryId = &isPrimary
+				identity.SelfSignature.KeyLifetimeSecs = primaryLifetime
+			case "Example Name <another@example.com>":
+				identity.SelfSignature.KeyLifetimeSecs = secondaryLifetime
+			}
 		}
+		return key
+	}
 
+	t.Run("secondary user ID drives the expiry", func(t *testing.T) {
+		key := loadKey(t, nil, &twentyDays)
+		got := getSecondaryUidExpiryWarnings(*key, policy.Policy{}, now)
+
+		assertEqualSliceOfKeyWarningTypes(t, []KeyWarning{KeyWarning{Type: ExpiryDrivenBySecondaryUid}}, got)
+		assert.Equal(t, "Example Name <another@example.com>", got[0].Detail)
+		assert.AssertEqualTimes(t, key.PrimaryKey.CreationTime.Add(20*24*time.Hour).UTC(), got[0].CurrentValidUntil.UTC())
 	})
 
-	t.Run("isExpired with future date", func(t *testing.T) {
-		if isExpired(expiryInFuture, now) != false {
-			t.Errorf("expected isExpired(%v, %v) to return true", expiryInFuture, now)
-		}
+	t.Run("primary user ID drives the expiry", func(t *testing.T) {
+		key := loadKey(t, &twentyDays, nil)
+		assertEqualSliceOfKeyWarningTypes(t, []KeyWarning{}, getSecondaryUidExpiryWarnings(*key, policy.Policy{}, now))
 	})
 
-	t.Run("getDaysSinceExpiry 1 hour in the past", func(t *testing.T) {
-		expected := uint(0)
-		got := getDaysSinceExpiry(now.Add(time.Duration(-1)*time.Hour), now)
+	t.Run("key isn't due for rotation", func(t *testing.T) {
+		tenYears := uint32(10 * 365 * 24 * 60 * 60)
+		key := loadKey(t, nil, &tenYears)
+		assertEqualSliceOfKeyWarningTypes(t, []KeyWarning{}, getSecondaryUidExpiryWarnings(*key, policy.Policy{}, now))
+	})
+}
 
-		if got != expected {
-			t.Errorf("expected %v, got %v", expected, got)
-		}
+func TestGetRotationScheduleWarnings(t *testing.T) {
+	sunday := time.Date(2018, 11, 4, 16, 0, 0, 0, time.UTC)
+	monday := sunday.AddDate(0, 0, 1)
+	withCalendar := policy.Policy{Calendar: policy.WeekdayCalendar{}}
+
+	t.Run("without a calendar", func(t *testing.T) {
+		got := getRotationScheduleWarnings(sunday, 0, policy.Policy{})
+		assertEqualSliceOfKeyWarningTypes(t, []KeyWarning{}, got)
 	})
 
-	t.Run("getDaysSinceExpiry 25 hours in the past", func(t *testing.T) {
-		expected := uint(1)
-		got := getDaysSinceExpiry(now.Add(time.Duration(-25)*time.Hour), now)
+	t.Run("rotation on a business day", func(t *testing.T) {
+		got := getRotationScheduleWarnings(monday, 0, withCalendar)
+		assertEqualSliceOfKeyWarningTypes(t, []KeyWarning{}, got)
+	})
 
-		if got != expected {
-			t.Errorf("expected %v, got %v", expected, got)
-		}
+	t.Run("subkey rotation on a weekend", func(t *testing.T) {
+		got := getRotationScheduleWarnings(sunday, 0xABCD, withCalendar)
+		expected := []KeyWarning{KeyWarning{
+			Type:     RotationDueOnNonBusinessDay,
+			SubkeyId: 0xABCD,
+			Detail:   "Friday 2 November 2018",
+		}}
+		assert.Equal(t, expected, got)
 	})
+}
 
-	t.Run("getDaysUntilExpiry 1 hour in the future", func(t *testing.T) {
-		expected := uint(0)
-		got := getDaysUntilExpiry(now.Add(time.Duration(1)*time.Hour), now)
+func TestGetDesignatedRevokerWarnings(t *testing.T) {
+	withoutRevoker, err := pgpkey.LoadFromArmoredPublicKey(exampledata.ExamplePublicKey4)
+	if err != nil {
+		t.Fatalf("failed to load example key: %v", err)
+	}
+	withRevoker, err := pgpkey.LoadFromArmoredPublicKey(exampledata.ExamplePublicKey5)
+	if err != nil {
+		t.Fatalf("failed to load example key: %v", err)
+	}
+	requireRevoker := policy.Policy{RequireDesignatedRevoker: true}
 
-		if got != expected {
-			t.Errorf("expected %v, got %v", expected, got)
-		}
+	t.Run("with the default policy", func(t *testing.T) {
+		got := getDesignatedRevokerWarnings(*withoutRevoker, policy.Policy{})
+		assertEqualSliceOfKeyWarningTypes(t, []KeyWarning{}, got)
 	})
 
-	t.Run("getDaysUntilExpiry 25 hours in the future", func(t *testing.T) {
-		expected := uint(1)
-		got := getDaysUntilExpiry(now.Add(time.Duration(25)*time.Hour), now)
+	t.Run("policy requires a revoker and key has none", func(t *testing.T) {
+		got := getDesignatedRevokerWarnings(*withoutRevoker, requireRevoker)
+		assertEqualSliceOfKeyWarningTypes(t, []KeyWarning{KeyWarning{Type: MissingDesignatedRevoker}}, got)
+	})
 
-		if got != expected {
-			t.Errorf("expected %v, got %v", expected, got)
-		}
+	t.Run("policy requires a revoker and key has one", func(t *testing.T) {
+		got := getDesignatedRevokerWarnings(*withRevoker, requireRevoker)
+		assertEqualSliceOfKeyWarningTypes(t, []KeyWarning{}, got)
 	})
 }
 
-func TestGetEncryptionSubkeyWarnings(t *testing.T) {
-	t.Run("with a primary key with long expiry date and a subkey overdue for rotation", func(t *testing.T) {
-		pgpKey, err := pgpkey.LoadFromArmoredEncryptedPrivateKey(exampledata.ExamplePrivateKey2, "test2")
+func TestGetEncryptionBrokenSigningIntactWarnings(t *testing.T) {
+	now := time.Date(2018, 9, 24, 18, 0, 0, 0, time.UTC)
+	yesterday := now.Add(-24 * time.Hour)
+	lastWeek := now.Add(-7 * 24 * time.Hour)
+
+	loadKeyWithExpiredEncryptionSubkey := func(t *testing.T) *pgpkey.PgpKey {
+		t.Helper()
+		key, err := pgpkey.LoadFromArmoredEncryptedPrivateKey(exampledata.ExamplePrivateKey2, "test2")
 		if err != nil {
-			t.Fatalf("Failed to load example test data: %v", err)
+			t.Fatal(err)
 		}
+		subkeyId := key.EncryptionSubkey(lastWeek).PublicKey.KeyId
+		if err := key.UpdateSubkeyValidUntil(subkeyId, yesterday, lastWeek); err != nil {
+			t.Fatal(err)
+		}
+		return key
+	}
 
-		now := time.Date(2018, 9, 24, 18, 0, 0, 0, time.UTC)
-		verySoon := now.Add(time.Duration(6) * time.Hour)
-		veryFarAway := now.Add(time.Duration(100*24) * time.Hour)
+	t.Run("with a valid encryption subkey", func(t *testing.T) {
+		key, err := pgpkey.LoadFromArmoredPublicKey(exampledata.ExamplePublicKey2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertEqualSliceOfKeyWarningTypes(t, []KeyWarning{}, getEncryptionBrokenSigningIntactWarnings(*key, now))
+	})
 
-		err = pgpKey.UpdateSubkeyValidUntil(pgpKey.EncryptionSubkey(now).PublicKey.KeyId, verySoon, now)
+	t.Run("with an expired encryption subkey and a primary key that can sign", func(t *testing.T) {
+		key := loadKeyWithExpiredEncryptionSubkey(t)
+
+		got := getEncryptionBrokenSigningIntactWarnings(*key, now)
+		assertEqualSliceOfKeyWarningTypes(t, []KeyWarning{KeyWarning{Type: EncryptionBrokenSigningIntact}}, got)
+		assert.Equal(t, key.Subkeys[0].PublicKey.KeyId, got[0].SubkeyId)
+
+		t.Run("replaces NoValidEncryptionSubkey in the clean warnings", func(t *testing.T) {
+			clean := GetKeyWarningsClean(*key, &config.Config{}, now)
+			for _, warning := range clean {
+				if warning.Type == NoValidEncryptionSubkey {
+					t.Fatalf("expected NoValidEncryptionSubkey to be superseded, got %v", clean)
+				}
+			}
+		})
+	})
+
+	t.Run("with an expired encryption subkey and an expired primary key", func(t *testing.T) {
+		key := loadKeyWithExpiredEncryptionSubkey(t)
+		if err := key.UpdateExpiryForAllUserIds(yesterday, lastWeek); err != nil {
+			t.Fatal(err)
+		}
+		assertEqualSliceOfKeyWarningTypes(t, []KeyWarning{}, getEncryptionBrokenSigningIntactWarnings(*key, now))
+	})
+}
+
+func TestGetEncryptionSubkeyRevokedWarnings(t *testing.T) {
+	now := time.Date(2018, 11, 15, 0, 0, 0, 0, time.UTC)
+
+	t.Run("with an older valid encryption subkey", func(t *testing.T) {
+		key, err := pgpkey.LoadFromArmoredPublicKey(exampledata.ExamplePublicKey10)
 		if err != nil {
-			t.Fatalf("failed to update expiry on test subkey")
+			t.Fatalf("failed to load example key: %v", err)
 		}
+		assert.Equal(t, []KeyWarning{}, getEncryptionSubkeyRevokedWarnings(*key, now))
+	})
 
-		err = pgpKey.UpdateExpiryForAllUserIds(veryFarAway, now)
+	t.Run("with only the revoked encryption subkey", func(t *testing.T) {
+		key, err := pgpkey.LoadFromArmoredPublicKey(exampledata.ExamplePublicKey10)
 		if err != nil {
-			t.Fatalf("failed to update expiry on test key")
+			t.Fatalf("failed to load example key: %v", err)
 		}
+		key.Subkeys = key.Subkeys[1:] // drop the valid subkey 0x0A5B7B748CBF7498
 
-		t.Run("test we get subkey overdue for rotation warning", func(t *testing.T) {
-			expected := []KeyWarning{
-				KeyWarning{Type: SubkeyOverdueForRotation},
+		expected := []KeyWarning{KeyWarning{Type: EncryptionSubkeyRevoked, SubkeyId: 0xF291DE5ADD97892E}}
+		assert.Equal(t, expected, getEncryptionSubkeyRevokedWarnings(*key, now))
+
+		t.Run("and it replaces NoValidEncryptionSubkey", func(t *testing.T) {
+			got := cleanWarnings(GetKeyWarningsAt(*key, nil, now))
+			for _, warning := range got {
+				if warning.Type == NoValidEncryptionSubkey || warning.Type == KeyCannotEncrypt {
+					t.Fatalf("expected %s to be superseded, got %v", warning.Type.Name(), got)
+				}
 			}
+		})
+	})
+}
 
-			got := getEncryptionSubkeyWarnings(*pgpKey, now)
+func TestEvaluatePolicies(t *testing.T) {
+	now := time.Date(2018, 9, 24, 18, 0, 0, 0, time.UTC)
+	key, err := pgpkey.LoadFromArmoredPublicKey(exampledata.ExamplePublicKey2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, identity := range key.Identities {
+		identity.SelfSignature.KeyLifetimeSecs = nil // never expires
+	}
 
-			assertEqualSliceOfKeyWarningTypes(t, expected, got)
-		})
+	policies := map[string]policy.Policy{
+		"baseline": policy.Policy{NoExpiryTreatment: policy.NoExpiryIgnore},
+		"ideal":    policy.Policy{NoExpiryTreatment: policy.NoExpiryCritical},
+	}
 
-		t.Run("test we get primary key long expiry warning", func(t *testing.T) {
-			expected := []KeyWarning{
-				KeyWarning{Type: PrimaryKeyLongExpiry},
+	got := EvaluatePolicies(*key, policies, now)
+	assert.Equal(t, 2, len(got))
+
+	hasNoExpiryWarning := func(warnings []KeyWarning) bool {
+		for _, warning := range warnings {
+			if warning.Type == PrimaryKeyNoExpiry {
+				return true
+			}
+		}
+		return false
+	}
+
+	t.Run("baseline policy ignores no expiry", func(t *testing.T) {
+		assert.Equal(t, false, hasNoExpiryWarning(got["baseline"]))
+	})
+
+	t.Run("ideal policy makes no expiry critical", func(t *testing.T) {
+		assert.Equal(t, true, hasNoExpiryWarning(got["ideal"]))
+		assert.Equal(t, SeverityCritical, got["ideal"][0].Severity())
+	})
+
+	t.Run("leaves out configuration warnings", func(t *testing.T) {
+		for name, warnings := range got {
+			for _, warning := range warnings {
+				if warning.Type == ConfigMaintainAutomaticallyNotSet || warning.Type == ConfigPublishToAPINotSet {
+					t.Fatalf("policy %s: unexpected configuration warning %v", name, warning)
+				}
 			}
+		}
+	})
+}
 
-			now := time.Date(2018, 9, 24, 18, 0, 0, 0, time.UTC)
-			got := getPrimaryKeyWarnings(*pgpKey, now)
+func TestGetPrimaryKeyAlgorithmWarnings(t *testing.T) {
+	t.Run("with an RSA primary key", func(t *testing.T) {
+		key, err := pgpkey.LoadFromArmoredPublicKey(exampledata.ExamplePublicKey3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertEqualSliceOfKeyWarningTypes(t, []KeyWarning{}, getPrimaryKeyAlgorithmWarnings(*key))
+	})
 
-			assertEqualSliceOfKeyWarningTypes(t, expected, got)
-		})
+	t.Run("with an ElGamal primary key", func(t *testing.T) {
+		key, err := pgpkey.LoadFromArmoredPublicKey(exampledata.ExamplePublicKey3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		key.PrimaryKey.PubKeyAlgo = packet.PubKeyAlgoElGamal
+
+		expected := []KeyWarning{KeyWarning{Type: PrimaryKeyCannotSign}}
+		assertEqualSliceOfKeyWarningTypes(t, expected, getPrimaryKeyAlgorithmWarnings(*key))
 	})
 }
 
@@ -175,27 +1351,29 @@ func TestGetSignatureHashWarnings(t *testing.T) {
 			sig := packet.Signature{Hash: algo}
 
 			t.Run("getSelfSignatureHashWarnings should return WeakSelfSignatureHash", func(t *testing.T) {
-				got := getSelfSignatureHashWarnings(&sig)
+				got := getSelfSignatureHashWarnings(&sig, "<test@example.com>")
 				expected := []KeyWarning{
 					KeyWarning{
 						Type:   WeakSelfSignatureHash,
+						UserId: "<test@example.com>",
 						Detail: nameOfHash(algo),
 					},
 				}
 
-				assertEqualSliceOfKeyWarningTypes(t, expected, got)
+				assert.Equal(t, expected, got)
 			})
 
 			t.Run("getSubkeyBindingSignatureHashWarnings should return WeakSubkeyBindingSignatureHash", func(t *testing.T) {
-				got := getSubkeyBindingSignatureHashWarnings(&sig)
+				got := getSubkeyBindingSignatureHashWarnings(&sig, 0xABCD)
 				expected := []KeyWarning{
 					KeyWarning{
-						Type:   WeakSubkeyBindingSignatureHash,
-						Detail: nameOfHash(algo),
+						Type:     WeakSubkeyBindingSignatureHash,
+						SubkeyId: 0xABCD,
+						Detail:   nameOfHash(algo),
 					},
 				}
 
-				assertEqualSliceOfKeyWarningTypes(t, expected, got)
+				assert.Equal(t, expected, got)
 			})
 		})
 	}
@@ -205,13 +1383,13 @@ func TestGetSignatureHashWarnings(t *testing.T) {
 			sig := packet.Signature{Hash: algo}
 
 			t.Run("getSelfSignatureHashWarnings should return WeakSelfSignatureHash", func(t *testing.T) {
-				got := getSelfSignatureHashWarnings(&sig)
+				got := getSelfSignatureHashWarnings(&sig, "<test@example.com>")
 				expected := []KeyWarning{}
 				assertEqualSliceOfKeyWarningTypes(t, expected, got)
 			})
 
 			t.Run("getSubkeyBindingSignatureHashWarnings should return WeakSubkeyBindingSignatureHash", func(t *testing.T) {
-				got := getSubkeyBindingSignatureHashWarnings(&sig)
+				got := getSubkeyBindingSignatureHashWarnings(&sig, 0xABCD)
 				expected := []KeyWarning{}
 				assertEqualSliceOfKeyWarningTypes(t, expected, got)
 			})
@@ -220,6 +1398,41 @@ func TestGetSignatureHashWarnings(t *testing.T) {
 
 }
 
+func TestGetKeyWarningsForWeakSignatureHashes(t *testing.T) {
+	now := time.Date(2018, 11, 2, 0, 0, 0, 0, time.UTC)
+
+	findHashWarnings := func(warnings []KeyWarning) []KeyWarning {
+		found := []KeyWarning{}
+		for _, warning := range warnings {
+			switch warning.Type {
+			case WeakSelfSignatureHash, WeakSubkeyBindingSignatureHash:
+				found = append(found, warning)
+			}
+		}
+		return found
+	}
+
+	t.Run("key signed with SHA1", func(t *testing.T) {
+		key, err := pgpkey.LoadFromArmoredPublicKey(exampledata.ExamplePublicKey7)
+		if err != nil {
+			t.Fatalf("failed to load example key: %v", err)
+		}
+		expected := []KeyWarning{
+			KeyWarning{Type: WeakSelfSignatureHash, UserId: "<test7@example.com>", Detail: "SHA1"},
+			KeyWarning{Type: WeakSubkeyBindingSignatureHash, SubkeyId: 0x061DDECA7C8BCFE2, Detail: "SHA1"},
+		}
+		assert.Equal(t, expected, findHashWarnings(GetKeyWarningsAt(*key, nil, now)))
+	})
+
+	t.Run("key signed with SHA512", func(t *testing.T) {
+		key, err := pgpkey.LoadFromArmoredPublicKey(exampledata.ExamplePublicKey5)
+		if err != nil {
+			t.Fatalf("failed to load example key: %v", err)
+		}
+		assert.Equal(t, []KeyWarning{}, findHashWarnings(GetKeyWarningsAt(*key, nil, now)))
+	})
+}
+
 func TestGetCipherPreferenceWarnings(t *testing.T) {
 	const (
 		// https://tools.ietf.org/html/rfc4880#section-9.2
@@ -499,6 +1712,260 @@ func TestGetConfiguartionWarnings(t *testing.T) {
 	}
 }
 
+func TestFilterWarningsByType(t *testing.T) {
+	warnings := []KeyWarning{
+		KeyWarning{Type: PrimaryKeyDueForRotation},
+		KeyWarning{Type: MissingPreferredHashAlgorithms},
+		KeyWarning{Type: SubkeyOverdueForRotation, SubkeyId: 1},
+		KeyWarning{Type: SubkeyOverdueForRotation, SubkeyId: 2},
+	}
+
+	var tests = []struct {
+		name     string
+		warnings []KeyWarning
+		types    []WarningType
+		expected []KeyWarning
+	}{
+		{
+			"with no warnings",
+			nil,
+			[]WarningType{PrimaryKeyDueForRotation},
+			[]KeyWarning{},
+		},
+		{
+			"with no types",
+			warnings,
+			nil,
+			[]KeyWarning{},
+		},
+		{
+			"with a type that isn't present",
+			warnings,
+			[]WarningType{PrimaryKeyExpired},
+			[]KeyWarning{},
+		},
+		{
+			"with several types",
+			warnings,
+			[]WarningType{SubkeyOverdueForRotation, PrimaryKeyDueForRotation},
+			[]KeyWarning{
+				KeyWarning{Type: PrimaryKeyDueForRotation},
+				KeyWarning{Type: SubkeyOverdueForRotation, SubkeyId: 1},
+				KeyWarning{Type: SubkeyOverdueForRotation, SubkeyId: 2},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, FilterWarningsByType(test.warnings, test.types...))
+		})
+	}
+}
+
+func TestHasWarning(t *testing.T) {
+	warnings := []KeyWarning{
+		KeyWarning{Type: PrimaryKeyDueForRotation},
+		KeyWarning{Type: MissingPreferredHashAlgorithms},
+	}
+
+	assert.Equal(t, true, HasWarning(warnings, MissingPreferredHashAlgorithms))
+	assert.Equal(t, false, HasWarning(warnings, PrimaryKeyExpired))
+	assert.Equal(t, false, HasWarning(nil, PrimaryKeyExpired))
+}
+
+func TestKeyNeedsAttention(t *testing.T) {
+	t.Run("with no warnings", func(t *testing.T) {
+		assert.Equal(t, false, KeyNeedsAttention([]KeyWarning{}))
+	})
+
+	t.Run("with only informational warnings", func(t *testing.T) {
+		warnings := []KeyWarning{
+			KeyWarning{Type: MissingUncompressedPreference},
+			KeyWarning{Type: UnsupportedPreferredCompressionAlgorithm, Detail: "BZIP2"},
+		}
+		assert.Equal(t, false, KeyNeedsAttention(warnings))
+	})
+
+	t.Run("with a low severity warning", func(t *testing.T) {
+		warnings := []KeyWarning{
+			KeyWarning{Type: MissingUncompressedPreference},
+			KeyWarning{Type: ConfigPublishToAPINotSet},
+		}
+		assert.Equal(t, true, KeyNeedsAttention(warnings))
+	})
+}
+
+func TestCleanWarnings(t *testing.T) {
+	t.Run("removes identical warnings", func(t *testing.T) {
+		warnings := []KeyWarning{
+			KeyWarning{Type: MissingPreferredHashAlgorithms},
+			KeyWarning{Type: MissingPreferredHashAlgorithms},
+			KeyWarning{Type: SubkeyNoExpiry, SubkeyId: 1},
+			KeyWarning{Type: SubkeyNoExpiry, SubkeyId: 2},
+		}
+		expected := []KeyWarning{
+			KeyWarning{Type: SubkeyNoExpiry, SubkeyId: 1},
+			KeyWarning{Type: SubkeyNoExpiry, SubkeyId: 2},
+			KeyWarning{Type: MissingPreferredHashAlgorithms},
+		}
+
+		got := cleanWarnings(warnings)
+		assertEqualSliceOfKeyWarningTypes(t, expected, got)
+		assert.Equal(t, uint64(1), got[0].SubkeyId)
+		assert.Equal(t, uint64(2), got[1].SubkeyId)
+	})
+
+	t.Run("removes warnings superseded by an expired primary key", func(t *testing.T) {
+		warnings := []KeyWarning{
+			KeyWarning{Type: PrimaryKeyExpired},
+			KeyWarning{Type: SubkeyOverdueForRotation},
+		}
+		expected := []KeyWarning{
+			KeyWarning{Type: PrimaryKeyExpired},
+		}
+		assertEqualSliceOfKeyWarningTypes(t, expected, cleanWarnings(warnings))
+	})
+
+	t.Run("removes unsupported algorithm warnings superseded by weak preferences", func(t *testing.T) {
+		warnings := []KeyWarning{
+			KeyWarning{Type: UnsupportedPreferredSymmetricAlgorithm, Detail: "IDEA"},
+			KeyWarning{Type: WeakPreferredSymmetricAlgorithms, Detail: "IDEA"},
+			KeyWarning{Type: UnsupportedPreferredHashAlgorithm, Detail: "Reserved"},
+		}
+		expected := []KeyWarning{
+			KeyWarning{Type: WeakPreferredSymmetricAlgorithms},
+			KeyWarning{Type: UnsupportedPreferredHashAlgorithm},
+		}
+		assertEqualSliceOfKeyWarningTypes(t, expected, cleanWarnings(warnings))
+	})
+
+	t.Run("sorts by severity, most severe first", func(t *testing.T) {
+		warnings := []KeyWarning{
+			KeyWarning{Type: ConfigPublishToAPINotSet},
+			KeyWarning{Type: PrimaryKeyNoExpiry},
+			KeyWarning{Type: NoValidEncryptionSubkey},
+			KeyWarning{Type: WeakSelfSignatureHash},
+		}
+		expected := []KeyWarning{
+			KeyWarning{Type: NoValidEncryptionSubkey},
+			KeyWarning{Type: WeakSelfSignatureHash},
+			KeyWarning{Type: PrimaryKeyNoExpiry},
+			KeyWarning{Type: ConfigPublishToAPINotSet},
+		}
+		assertEqualSliceOfKeyWarningTypes(t, expected, cleanWarnings(warnings))
+	})
+
+	t.Run("with no warnings", func(t *testing.T) {
+		assertEqualSliceOfKeyWarningTypes(t, []KeyWarning{}, cleanWarnings(nil))
+	})
+}
+
+func TestDedupeWarnings(t *testing.T) {
+	validUntil := time.Date(2018, 9, 24, 18, 0, 0, 0, time.UTC)
+	sameValidUntil := validUntil
+
+	var tests = []struct {
+		name     string
+		warnings []KeyWarning
+		expected []KeyWarning
+	}{
+		{
+			"with no warnings",
+			nil,
+			[]KeyWarning{},
+		},
+		{
+			"collapses warnings about the same subkey",
+			[]KeyWarning{
+				KeyWarning{Type: SubkeyNoExpiry, SubkeyId: 1},
+				KeyWarning{Type: SubkeyNoExpiry, SubkeyId: 2},
+				KeyWarning{Type: SubkeyNoExpiry, SubkeyId: 1},
+			},
+			[]KeyWarning{
+				KeyWarning{Type: SubkeyNoExpiry, SubkeyId: 1},
+				KeyWarning{Type: SubkeyNoExpiry, SubkeyId: 2},
+			},
+		},
+		{
+			"keeps a single instance of warnings without a subkey",
+			[]KeyWarning{
+				KeyWarning{Type: MissingPreferredHashAlgorithms},
+				KeyWarning{Type: PrimaryKeyNoExpiry},
+				KeyWarning{Type: MissingPreferredHashAlgorithms},
+			},
+			[]KeyWarning{
+				KeyWarning{Type: MissingPreferredHashAlgorithms},
+				KeyWarning{Type: PrimaryKeyNoExpiry},
+			},
+		},
+		{
+			"keeps warnings with a different detail",
+			[]KeyWarning{
+				KeyWarning{Type: UnsupportedPreferredSymmetricAlgorithm, Detail: "IDEA"},
+				KeyWarning{Type: UnsupportedPreferredSymmetricAlgorithm, Detail: "CAST5"},
+			},
+			[]KeyWarning{
+				KeyWarning{Type: UnsupportedPreferredSymmetricAlgorithm, Detail: "IDEA"},
+				KeyWarning{Type: UnsupportedPreferredSymmetricAlgorithm, Detail: "CAST5"},
+			},
+		},
+		{
+			"ignores calculated fields",
+			[]KeyWarning{
+				KeyWarning{Type: SubkeyOverdueForRotation, SubkeyId: 1, CurrentValidUntil: &validUntil},
+				KeyWarning{Type: SubkeyOverdueForRotation, SubkeyId: 1, CurrentValidUntil: &sameValidUntil},
+			},
+			[]KeyWarning{
+				KeyWarning{Type: SubkeyOverdueForRotation, SubkeyId: 1, CurrentValidUntil: &validUntil},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, DedupeWarnings(test.warnings))
+		})
+	}
+
+	t.Run("GetKeyWarnings with a key with 2 user IDs", func(t *testing.T) {
+		// each user ID's self signature has the same (missing)
+		// preferences, so each preference warning appears twice before
+		// deduplicating
+		key, err := pgpkey.LoadFromArmoredPublicKey(exampledata.ExamplePublicKey11)
+		if err != nil {
+			t.Fatalf("failed to load example key: %v", err)
+		}
+		now := time.Date(2018, 9, 24, 18, 0, 0, 0, time.UTC)
+
+		seen := make(map[string]bool)
+		for _, warning := range GetKeyWarningsAt(*key, nil, now) {
+			asString := fmt.Sprintf("%v %d %s", warning.Type, warning.SubkeyId, warning.Detail)
+			if seen[asString] {
+				t.Fatalf("got duplicate warning %s", asString)
+			}
+			seen[asString] = true
+		}
+	})
+}
+
+// loadKey2WithExpiry returns example key 2 with the primary key and
+// encryption subkey set, as of `now`, to expire at the given times.
+func loadKey2WithExpiry(t *testing.T, now time.Time, primaryExpiry time.Time, subkeyExpiry time.Time) *pgpkey.PgpKey {
+	t.Helper()
+	key, err := pgpkey.LoadFromArmoredEncryptedPrivateKey(exampledata.ExamplePrivateKey2, "test2")
+	if err != nil {
+		t.Fatalf("failed to load example key: %v", err)
+	}
+	if err := key.UpdateSubkeyValidUntil(key.EncryptionSubkey(now).PublicKey.KeyId, subkeyExpiry, now); err != nil {
+		t.Fatalf("failed to update subkey expiry: %v", err)
+	}
+	if err := key.UpdateExpiryForAllUserIds(primaryExpiry, now); err != nil {
+		t.Fatalf("failed to update primary key expiry: %v", err)
+	}
+	return key
+}
+
 func assertKeyWarningsContains(t *testing.T, gotWarnings []KeyWarning, expectedWarning KeyWarning) {
 	t.Helper()
 
@@ -0,0 +1,129 @@
+// Copyright 2018 Paul Furley and Ian Drysdale
+//
+// This file is part of Fluidkeys Client which makes it simple to use OpenPGP.
+//
+// Fluidkeys Client is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Fluidkeys Client is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with Fluidkeys Client.  If not, see <https://www.gnu.org/licenses/>.
+
+package status
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+
+	"github.com/fluidkeys/crypto/openpgp/packet"
+	"github.com/fluidkeys/fluidkeys/pgpkey"
+	"github.com/fluidkeys/fluidkeys/policy"
+)
+
+// MeetsMinimumStrength returns true if the primary key and every subkey meet
+// the policy's MinimumBitStrength, for example to reject weak keys when
+// someone joins a team. If not, it returns a KeyBelowMinimumStrength
+// warning for each component that's too weak.
+//
+// Revoked subkeys are ignored, but expired ones are checked since their
+// expiry can be extended. Components whose strength can't be estimated (for
+// example an unsupported algorithm) are skipped rather than reported as
+// 0-bit.
+func MeetsMinimumStrength(key pgpkey.PgpKey, p policy.Policy) (bool, []KeyWarning) {
+	warnings := getMinimumStrengthWarnings(key, p)
+	return len(warnings) == 0, warnings
+}
+
+func getMinimumStrengthWarnings(key pgpkey.PgpKey, p policy.Policy) []KeyWarning {
+	if p.MinimumBitStrength == 0 {
+		return []KeyWarning{}
+	}
+
+	warnings := []KeyWarning{}
+	check := func(publicKey *packet.PublicKey, subkeyId uint64) {
+		strength, err := estimatedBitStrength(publicKey)
+		if err != nil || strength >= p.MinimumBitStrength {
+			return
+		}
+		warnings = append(warnings, KeyWarning{
+			Type:     KeyBelowMinimumStrength,
+			SubkeyId: subkeyId,
+			Detail:   fmt.Sprintf("%d-bit, policy requires %d-bit", strength, p.MinimumBitStrength),
+		})
+	}
+
+	check(key.PrimaryKey, 0)
+	for _, subkey := range key.Subkeys {
+		if subkey.Sig.SigType == packet.SigTypeSubkeyRevocation {
+			continue
+		}
+		check(subkey.PublicKey, subkey.PublicKey.KeyId)
+	}
+	return warnings
+}
+
+// minimumPrimaryKeyRsaBits is the smallest RSA modulus we don't consider weak
+// for a primary key.
+const minimumPrimaryKeyRsaBits = 2048
+
+// getPrimaryKeyWeakCipherWarnings returns PrimaryKeyWeakCipher if the primary
+// key is DSA or ElGamal (whatever its size), or RSA with a modulus under 2048
+// bits. The Detail is the key's size and algorithm, e.g. "1024-bit RSA".
+func getPrimaryKeyWeakCipherWarnings(key pgpkey.PgpKey) []KeyWarning {
+	var algorithm string
+
+	switch key.PrimaryKey.PubKeyAlgo {
+	case packet.PubKeyAlgoDSA:
+		algorithm = "DSA"
+	case packet.PubKeyAlgoElGamal:
+		algorithm = "ElGamal"
+	case packet.PubKeyAlgoRSA, packet.PubKeyAlgoRSAEncryptOnly, packet.PubKeyAlgoRSASignOnly:
+		algorithm = "RSA"
+	default:
+		return []KeyWarning{}
+	}
+
+	bits, err := key.PrimaryKey.BitLength()
+	if err != nil {
+		return []KeyWarning{}
+	}
+	if algorithm == "RSA" && bits >= minimumPrimaryKeyRsaBits {
+		return []KeyWarning{}
+	}
+
+	return []KeyWarning{KeyWarning{
+		Type:   PrimaryKeyWeakCipher,
+		Detail: fmt.Sprintf("%d-bit %s", bits, algorithm),
+	}}
+}
+
+// estimatedBitStrength returns the approximate security of the public key in
+// bits, matching gpgwrapper's EstimatedBitStrength: RSA, DSA and ElGamal
+// use policy.FiniteFieldBitStrength and elliptic curves use
+// policy.EllipticCurveBitStrength.
+func estimatedBitStrength(publicKey *packet.PublicKey) (int, error) {
+	switch publicKey.PubKeyAlgo {
+	case packet.PubKeyAlgoRSA, packet.PubKeyAlgoRSAEncryptOnly, packet.PubKeyAlgoRSASignOnly,
+		packet.PubKeyAlgoDSA, packet.PubKeyAlgoElGamal:
+		bits, err := publicKey.BitLength()
+		if err != nil {
+			return 0, err
+		}
+		return policy.FiniteFieldBitStrength(int(bits)), nil
+
+	case packet.PubKeyAlgoECDSA, packet.PubKeyAlgoECDH:
+		// both are stored as an ecdsa.PublicKey
+		ecKey, ok := publicKey.PublicKey.(*ecdsa.PublicKey)
+		if !ok {
+			return 0, fmt.Errorf("unexpected elliptic curve key type %T", publicKey.PublicKey)
+		}
+		return policy.EllipticCurveBitStrength(ecKey.Curve.Params().BitSize), nil
+	}
+	return 0, fmt.Errorf("unknown public key algorithm %d", publicKey.PubKeyAlgo)
+}
@@ -0,0 +1,106 @@
+package status
+
+import (
+	"testing"
+
+	"github.com/fluidkeys/fluidkeys/assert"
+	"github.com/fluidkeys/fluidkeys/exampledata"
+	"github.com/fluidkeys/fluidkeys/pgpkey"
+	"github.com/fluidkeys/fluidkeys/policy"
+)
+
+func TestMeetsMinimumStrength(t *testing.T) {
+	// key 4 is RSA 1024 (80-bit), key 5 is RSA 2048 (112-bit)
+	key4, err := pgpkey.LoadFromArmoredPublicKey(exampledata.ExamplePublicKey4)
+	if err != nil {
+		t.Fatalf("failed to load example key: %v", err)
+	}
+	key5, err := pgpkey.LoadFromArmoredPublicKey(exampledata.ExamplePublicKey5)
+	if err != nil {
+		t.Fatalf("failed to load example key: %v", err)
+	}
+
+	t.Run("without a minimum", func(t *testing.T) {
+		ok, warnings := MeetsMinimumStrength(*key4, policy.Policy{})
+		assert.Equal(t, true, ok)
+		assert.Equal(t, []KeyWarning{}, warnings)
+	})
+
+	t.Run("with a minimum the key meets", func(t *testing.T) {
+		ok, warnings := MeetsMinimumStrength(*key5, policy.Policy{MinimumBitStrength: 112})
+		assert.Equal(t, true, ok)
+		assert.Equal(t, []KeyWarning{}, warnings)
+	})
+
+	t.Run("with a minimum the key doesn't meet", func(t *testing.T) {
+		ok, warnings := MeetsMinimumStrength(*key4, policy.Policy{MinimumBitStrength: 112})
+		assert.Equal(t, false, ok)
+
+		expected := []KeyWarning{
+			KeyWarning{
+				Type:   KeyBelowMinimumStrength,
+				Detail: "80-bit, policy requires 112-bit",
+			},
+			KeyWarning{
+				Type:     KeyBelowMinimumStrength,
+				SubkeyId: key4.Subkeys[0].PublicKey.KeyId,
+				Detail:   "80-bit, policy requires 112-bit",
+			},
+		}
+		assert.Equal(t, expected, warnings)
+	})
+
+	t.Run("skips components whose strength can't be estimated", func(t *testing.T) {
+		key, err := pgpkey.LoadFromArmoredPublicKey(exampledata.ExamplePublicKey4)
+		if err != nil {
+			t.Fatalf("failed to load example key: %v", err)
+		}
+		key.PrimaryKey.PubKeyAlgo = 99 // not an algorithm we know the strength of
+
+		ok, warnings := MeetsMinimumStrength(*key, policy.Policy{MinimumBitStrength: 112})
+		assert.Equal(t, false, ok)
+
+		expected := []KeyWarning{
+			KeyWarning{
+				Type:     KeyBelowMinimumStrength,
+				SubkeyId: key.Subkeys[0].PublicKey.KeyId,
+				Detail:   "80-bit, policy requires 112-bit",
+			},
+		}
+		assert.Equal(t, expected, warnings)
+	})
+}
+
+func TestGetPrimaryKeyWeakCipherWarnings(t *testing.T) {
+	var tests = []struct {
+		name          string
+		armoredKey    string
+		expectWarning []KeyWarning
+	}{
+		{
+			"RSA 1024",
+			exampledata.ExamplePublicKey4,
+			[]KeyWarning{KeyWarning{Type: PrimaryKeyWeakCipher, Detail: "1024-bit RSA"}},
+		},
+		{
+			"RSA 2048",
+			exampledata.ExamplePublicKey5,
+			[]KeyWarning{},
+		},
+		{
+			"DSA 2048",
+			exampledata.ExamplePublicKey6,
+			[]KeyWarning{KeyWarning{Type: PrimaryKeyWeakCipher, Detail: "2048-bit DSA"}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			key, err := pgpkey.LoadFromArmoredPublicKey(test.armoredKey)
+			if err != nil {
+				t.Fatalf("failed to load example key: %v", err)
+			}
+			assert.Equal(t, test.expectWarning, getPrimaryKeyWeakCipherWarnings(*key))
+		})
+	}
+}
@@ -0,0 +1,135 @@
+// Copyright 2018 Paul Furley and Ian Drysdale
+//
+// This file is part of Fluidkeys Client which makes it simple to use OpenPGP.
+//
+// Fluidkeys Client is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Fluidkeys Client is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with Fluidkeys Client.  If not, see <https://www.gnu.org/licenses/>.
+
+package status
+
+import (
+	"time"
+
+	"github.com/fluidkeys/crypto/openpgp/packet"
+	"github.com/fluidkeys/fluidkeys/pgpkey"
+	"github.com/fluidkeys/fluidkeys/policy"
+)
+
+// KeyStatus is a single, at-a-glance summary of a key's expiry and rotation
+// state, for example to show on one line next to the key in a list.
+//
+// The statuses are ordered from best to worst, so a greater KeyStatus is
+// always more urgent.
+type KeyStatus int
+
+const (
+	// Healthy means neither the primary key nor the encryption subkey needs
+	// attention yet.
+	Healthy KeyStatus = 0
+
+	// DueForRotation means the primary key or encryption subkey has passed
+	// its rotation date.
+	DueForRotation KeyStatus = 1
+
+	// OverdueForRotation means the rotation date passed a while ago and the
+	// key will expire soon.
+	OverdueForRotation KeyStatus = 2
+
+	// NoEncryptionSubkey means nobody can encrypt to the key, for example
+	// because it never had an encryption subkey or it's been revoked.
+	NoEncryptionSubkey KeyStatus = 3
+
+	// Expired means the primary key or encryption subkey has expired.
+	Expired KeyStatus = 4
+
+	// Revoked means the primary key has been revoked, so nothing else about
+	// the key matters any more.
+	Revoked KeyStatus = 5
+)
+
+// String returns a short description of the status, e.g. "due for rotation".
+func (s KeyStatus) String() string {
+	switch s {
+	case Healthy:
+		return "healthy"
+	case DueForRotation:
+		return "due for rotation"
+	case OverdueForRotation:
+		return "overdue for rotation"
+	case NoEncryptionSubkey:
+		return "no encryption subkey"
+	case Expired:
+		return "expired"
+	case Revoked:
+		return "revoked"
+	}
+	return "unknown"
+}
+
+// GetKeyExpirySummary returns the worst KeyStatus across the primary key and
+// the encryption subkey as of `now`, checked against the default policy.
+func GetKeyExpirySummary(key pgpkey.PgpKey, now time.Time) KeyStatus {
+	if len(getPrimaryKeyRevokedWarnings(key)) > 0 {
+		return Revoked
+	}
+
+	var warnings []KeyWarning
+	warnings = append(warnings, getPrimaryKeyWarnings(key, policy.Policy{}, now)...)
+	warnings = append(warnings, getEncryptionSubkeyWarnings(key, policy.Policy{}, now)...)
+	warnings = append(warnings, getEncryptionCapabilityWarnings(key)...)
+	warnings = append(warnings, getEncryptionSubkeyRevokedWarnings(key, now)...)
+
+	summary := Healthy
+	for _, warning := range warnings {
+		status := keyStatusForWarning(warning)
+		if status == NoEncryptionSubkey && encryptionSubkeyExpired(key, now) {
+			status = Expired
+		}
+		if status > summary {
+			summary = status
+		}
+	}
+	return summary
+}
+
+// keyStatusForWarning returns the KeyStatus the warning puts the key in, or
+// Healthy if the warning doesn't affect the summary.
+func keyStatusForWarning(warning KeyWarning) KeyStatus {
+	switch warning.Type {
+	case PrimaryKeyDueForRotation, SubkeyDueForRotation:
+		return DueForRotation
+
+	case PrimaryKeyOverdueForRotation, SubkeyOverdueForRotation:
+		return OverdueForRotation
+
+	case PrimaryKeyExpired:
+		return Expired
+
+	case NoValidEncryptionSubkey, KeyCannotEncrypt, EncryptionSubkeyRevoked:
+		return NoEncryptionSubkey
+	}
+	return Healthy
+}
+
+// encryptionSubkeyExpired returns true if the key's newest encryption subkey
+// has expired (rather than been revoked), so the key has no valid encryption
+// subkey only because it wasn't rotated in time.
+func encryptionSubkeyExpired(key pgpkey.PgpKey, now time.Time) bool {
+	newest := newestEncryptionCapableSubkey(key)
+	if newest == nil || newest.Sig.SigType == packet.SigTypeSubkeyRevocation {
+		return false
+	}
+
+	hasExpiry, expiry := pgpkey.SubkeyExpiry(*newest)
+	return hasExpiry && isExpired(*expiry, now)
+}
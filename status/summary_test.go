@@ -0,0 +1,96 @@
+package status
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fluidkeys/fluidkeys/assert"
+	"github.com/fluidkeys/fluidkeys/exampledata"
+	"github.com/fluidkeys/fluidkeys/pgpkey"
+)
+
+func TestGetKeyExpirySummary(t *testing.T) {
+	now := time.Date(2018, 9, 24, 18, 0, 0, 0, time.UTC)
+	inDays := func(n int) time.Time {
+		return now.Add(time.Duration(n*24) * time.Hour)
+	}
+
+	// loadKey2 returns example key 2 with the primary key and encryption
+	// subkey set to expire the given number of days after `now`.
+	loadKey2 := func(t *testing.T, primaryDays int, subkeyDays int) pgpkey.PgpKey {
+		t.Helper()
+		return *loadKey2WithExpiry(t, now, inDays(primaryDays), inDays(subkeyDays))
+	}
+
+	t.Run("healthy", func(t *testing.T) {
+		key := loadKey2(t, 50, 50)
+		assert.Equal(t, Healthy, GetKeyExpirySummary(key, now))
+	})
+
+	t.Run("encryption subkey due for rotation", func(t *testing.T) {
+		key := loadKey2(t, 50, 25)
+		assert.Equal(t, DueForRotation, GetKeyExpirySummary(key, now))
+	})
+
+	t.Run("primary key due for rotation", func(t *testing.T) {
+		key := loadKey2(t, 25, 50)
+		assert.Equal(t, DueForRotation, GetKeyExpirySummary(key, now))
+	})
+
+	t.Run("overdue outranks due", func(t *testing.T) {
+		key := loadKey2(t, 25, 15)
+		assert.Equal(t, OverdueForRotation, GetKeyExpirySummary(key, now))
+	})
+
+	t.Run("encryption subkey expired", func(t *testing.T) {
+		key := loadKey2(t, 50, 10)
+		assert.Equal(t, Expired, GetKeyExpirySummary(key, inDays(11)))
+	})
+
+	t.Run("primary key expired", func(t *testing.T) {
+		key := loadKey2(t, 10, 50)
+		assert.Equal(t, Expired, GetKeyExpirySummary(key, inDays(11)))
+	})
+
+	t.Run("no encryption subkey", func(t *testing.T) {
+		key, err := pgpkey.LoadFromArmoredPublicKey(exampledata.ExamplePublicKey6)
+		if err != nil {
+			t.Fatalf("failed to load example key: %v", err)
+		}
+		assert.Equal(t, NoEncryptionSubkey, GetKeyExpirySummary(*key, now))
+	})
+
+	t.Run("revoked outranks everything else", func(t *testing.T) {
+		// key 17 was revoked on 2018-11-15, and expired 2019-12-01
+		key, err := pgpkey.LoadFromArmoredPublicKey(exampledata.ExamplePublicKey17)
+		if err != nil {
+			t.Fatalf("failed to load example key: %v", err)
+		}
+		for _, now := range []time.Time{
+			time.Date(2018, 11, 20, 0, 0, 0, 0, time.UTC),
+			time.Date(2019, 12, 20, 0, 0, 0, 0, time.UTC),
+		} {
+			assert.Equal(t, Revoked, GetKeyExpirySummary(*key, now))
+		}
+	})
+}
+
+func TestKeyStatusString(t *testing.T) {
+	var tests = []struct {
+		status   KeyStatus
+		expected string
+	}{
+		{Healthy, "healthy"},
+		{DueForRotation, "due for rotation"},
+		{OverdueForRotation, "overdue for rotation"},
+		{NoEncryptionSubkey, "no encryption subkey"},
+		{Expired, "expired"},
+		{Revoked, "revoked"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.expected, func(t *testing.T) {
+			assert.Equal(t, test.expected, test.status.String())
+		})
+	}
+}